@@ -8,57 +8,111 @@ import (
 	"auth-service/internal/auth/delivery/grpc/authpb"
 	"auth-service/internal/auth/infra"
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
+	"shared/health"
+	"shared/lifecycle"
+	"shared/logging"
+	"shared/metrics"
+	"shared/tracing"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	// Load ENV
-	config.LoadEnv()
+	// Load config dari flag, env dan .env (opsional), semua error dilaporkan sekaligus
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logging.Fatal("failed to load config", "error", err)
+	}
+	logging.Setup("auth-service", cfg.LogLevel)
+	slog.Info("config loaded", "config", cfg)
 
-	// Connect to MongoDB
-	mongoURI := os.Getenv("AUTH_MONGO_URI")
-	dbName := os.Getenv("AUTH_DB_NAME")
+	// tracing OpenTelemetry, exporter none/stdout/otlp
+	shutdownTracing, err := tracing.Setup(context.Background(), "auth-service", cfg.TracesExporter)
+	if err != nil {
+		logging.Fatal("failed to setup tracing", "error", err)
+	}
 
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(cfg.MongoURI.Value()).
+		SetMonitor(tracing.MongoMonitor()).
+		SetPoolMonitor(metrics.MongoPoolMonitor()))
 	if err != nil {
-		log.Fatalf("failed to create mongo client: %v", err)
+		logging.Fatal("failed to create mongo client", "error", err)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := client.Connect(ctx); err != nil {
-		log.Fatalf("failed to connect to mongo: %v", err)
+		logging.Fatal("failed to connect to mongo", "error", err)
 	}
 
-	db := client.Database(dbName)
+	db := client.Database(cfg.MongoDatabase)
 
 	// Init dependencies
 	repo := infra.NewMongoUserRepository(db)
-	jwtManager := app.NewJWTManager()
+	jwtManager := app.NewJWTManager(cfg.JWTSecret.Value())
 	hasher := &app.BcryptHasher{}
 	service := app.NewAuthService(repo, jwtManager, hasher)
 	handler := authgrpc.NewAuthHandler(service)
 
 	// Start gRPC server
-	port := os.Getenv("PORT")
+	port := cfg.Port
 	listener, err := net.Listen("tcp", ":"+port)
 	if err != nil {
-		log.Fatalf("failed to listen on port %s: %v", port, err)
+		logging.Fatal("failed to listen", "port", port, "error", err)
 	}
 
-	server := grpc.NewServer()
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor, metrics.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor, metrics.StreamServerInterceptor),
+	)
 	authpb.RegisterAuthServiceServer(server, handler)
 
-	fmt.Printf("Auth Service running on port %s...\n", port)
-	if err := server.Serve(listener); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	// status grpc.health.v1 berhenti (NOT_SERVING) begitu shutdown dimulai
+	lm := lifecycle.NewManager(cfg.ShutdownTimeout)
+
+	// readiness: Mongo, juga lewat grpc.health.v1 untuk gateway
+	checker := health.NewChecker()
+	checker.Add("mongo", health.MongoCheck(client))
+	health.RegisterGRPC(lm.Context(), server, checker, health.DefaultGRPCInterval)
+
+	// HTTP admin internal (level log, metrics, health), tidak diekspos lewat gateway publik
+	admin := newAdminServer(cfg.AdminPort, checker)
+	lm.Go("admin", admin.ListenAndServe)
+
+	slog.Info("auth service running", "port", port)
+	lm.Go("grpc", func() error { return server.Serve(listener) })
+
+	// urutan shutdown: selesaikan RPC yang berjalan, tutup admin, putus
+	// Mongo, lalu flush trace
+	lm.OnShutdown("grpc", lifecycle.StopGRPC(server))
+	lm.OnShutdown("admin", admin.Shutdown)
+	lm.OnShutdown("mongo", lifecycle.CloseMongo(client))
+	lm.OnShutdown("tracing", shutdownTracing)
+
+	if err := lm.Wait(); err != nil {
+		logging.Fatal("auth service stopped with error", "error", err)
 	}
+	slog.Info("auth service stopped")
+}
+
+// Server HTTP admin: GET/PUT /admin/log-level, GET /metrics, /healthz dan /readyz
+func newAdminServer(port string, checker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/admin/log-level", logging.LevelHandler())
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", health.LiveHandler())
+	mux.Handle("/readyz", health.ReadyHandler(checker))
+
+	slog.Info("admin server running", "port", port)
+	return &http.Server{Addr: ":" + port, Handler: mux}
 }
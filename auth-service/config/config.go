@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"shared/envconfig"
+	"shared/lifecycle"
+	"shared/tracing"
+)
+
+// Config auth-service, dibaca sekali di main lalu di-inject ke constructor
+type Config struct {
+	Port      string
+	AdminPort string
+
+	MongoURI      envconfig.Secret // bisa berisi user/password
+	MongoDatabase string
+
+	JWTSecret envconfig.Secret
+
+	LogLevel       string
+	TracesExporter string
+
+	ShutdownTimeout time.Duration
+}
+
+// Load config dari flag (args tanpa nama program), env dan file .env
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
+
+func load(args []string, env func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{}
+	l := envconfig.NewLoader("auth-service")
+	l.Port(&cfg.Port, "PORT", "50052", "port gRPC")
+	l.Port(&cfg.AdminPort, "ADMIN_PORT", "9092", "port HTTP admin (log level, metrics, health)")
+	l.Secret(&cfg.MongoURI, "AUTH_MONGO_URI", "", "URI MongoDB").Required()
+	l.String(&cfg.MongoDatabase, "AUTH_DB_NAME", "", "nama database MongoDB").Required()
+	// JWT_SECRET nama lama, harus sama dengan JWT_SECRET gateway
+	l.Secret(&cfg.JWTSecret, "AUTH_JWT_SECRET", "", "secret tanda tangan JWT").Alias("JWT_SECRET").Required()
+	l.LogLevel(&cfg.LogLevel, "LOG_LEVEL", "info", "level log awal")
+	l.OneOf(&cfg.TracesExporter, "OTEL_TRACES_EXPORTER", "none", "exporter trace", tracing.ExporterNone, tracing.ExporterStdout, "console", tracing.ExporterOTLP)
+	l.Duration(&cfg.ShutdownTimeout, "SHUTDOWN_TIMEOUT", lifecycle.DefaultTimeout.String(), "batas waktu graceful shutdown")
+
+	if err := l.Load(args, env); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
@@ -55,11 +55,17 @@ func (s *AuthService) RegisterUser(ctx context.Context, user domain.User) (domai
 func (s *AuthService) LoginUser(ctx context.Context, email, password string) (string, error) {
 	user, err := s.repo.GetByEmail(email)
 	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			loginFailures.WithLabelValues("unknown_user").Inc()
+		} else {
+			loginFailures.WithLabelValues("error").Inc()
+		}
 		return "", err
 	}
 
 	err = s.hasher.Compare(user.Password, password)
 	if err != nil {
+		loginFailures.WithLabelValues("invalid_password").Inc()
 		return "", errors.New("invalid credentials")
 	}
 
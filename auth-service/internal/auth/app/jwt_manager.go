@@ -2,7 +2,6 @@ package app
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"auth-service/internal/auth/domain"
@@ -15,12 +14,8 @@ type JWTManager struct {
 	tokenDuration time.Duration
 }
 
-// Buat JWTManager baru dengan secret dari env dan durasi 1 jam
-func NewJWTManager() *JWTManager {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "mysecretkey123" // fallback default
-	}
+// Buat JWTManager baru dengan secret dari config dan durasi 1 jam
+func NewJWTManager(secret string) *JWTManager {
 	return &JWTManager{
 		secretKey:     secret,
 		tokenDuration: time.Hour,
@@ -0,0 +1,12 @@
+package app
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// login gagal per alasan: unknown_user, invalid_password, error
+var loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_login_failures_total",
+	Help: "Jumlah login gagal per alasan.",
+}, []string{"reason"})
@@ -1,5 +1,10 @@
 package domain
 
+import "errors"
+
+// user dengan email tersebut belum terdaftar
+var ErrUserNotFound = errors.New("user not found")
+
 type UserRepository interface {
 	Save(user User) error
 	GetByEmail(email string) (User, error)
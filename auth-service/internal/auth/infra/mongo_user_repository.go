@@ -34,7 +34,7 @@ func (r *MongoUserRepository) GetByEmail(email string) (domain.User, error) {
 	err := r.collection.FindOne(context.Background(), filter).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return domain.User{}, errors.New("user not found")
+			return domain.User{}, domain.ErrUserNotFound
 		}
 		return domain.User{}, err
 	}
@@ -1,10 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"gateway-service/config"
 	http "gateway-service/internal/gateway/delivery/http"
 	"gateway-service/internal/gateway/infra"
+	"log/slog"
+	"os"
+	"shared/lifecycle"
+	"shared/logging"
+	"shared/metrics"
+	"shared/tracing"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -17,27 +23,64 @@ import (
 // @BasePath /
 // @schemes http
 func main() {
-	// Load .env config
-	config.ConnectConfig()
+	// Load config dari flag, env dan .env (opsional), semua error dilaporkan sekaligus
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logging.Fatal("failed to load config", "error", err)
+	}
+	logging.Setup("gateway-service", cfg.LogLevel)
+	slog.Info("config loaded", "config", cfg)
+
+	// tracing OpenTelemetry, exporter none/stdout/otlp
+	shutdownTracing, err := tracing.Setup(context.Background(), "gateway-service", cfg.TracesExporter)
+	if err != nil {
+		logging.Fatal("failed to setup tracing", "error", err)
+	}
 
 	// Init gRPC clients (auth, payment)
-	infra.InitGRPCClients()
+	clients, err := infra.NewGRPCClients(cfg)
+	if err != nil {
+		logging.Fatal("failed to init grpc clients", "error", err)
+	}
 
 	// Init Echo
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	// span per request (trace context W3C dari client dipakai kalau ada)
+	e.Use(tracing.Middleware())
+	// request ID selalu dibuat di gateway, diteruskan ke service lain
+	e.Use(logging.Middleware(false))
+	// metric rate, error dan durasi per route
+	e.Use(metrics.Middleware())
+
+	// Prometheus
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
 
 	// Swagger docs
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	// Register REST routes
-	http.RegisterGatewayRoutes(e)
+	http.RegisterGatewayRoutes(e, cfg, clients)
 
 	// Start server
-	startServer(e, config.AppPort)
+	lm := lifecycle.NewManager(cfg.ShutdownTimeout)
+	startServer(lm, e, cfg.Port)
+
+	// urutan shutdown: selesaikan request yang berjalan, tutup koneksi
+	// gRPC ke auth/payment, lalu flush trace
+	lm.OnShutdown("http", e.Shutdown)
+	lm.OnShutdown("grpc_clients", func(ctx context.Context) error { return clients.Close() })
+	lm.OnShutdown("tracing", shutdownTracing)
+
+	if err := lm.Wait(); err != nil {
+		logging.Fatal("gateway stopped with error", "error", err)
+	}
+	slog.Info("gateway stopped")
 }
 
-// Jalankan server di port dari .env
-func startServer(e *echo.Echo, port string) {
-	fmt.Printf("Starting Gateway on %s\n", port)
-	e.Logger.Fatal(e.Start(port)) // port format dari .env: ":8082"
+// Jalankan server di port dari config
+func startServer(lm *lifecycle.Manager, e *echo.Echo, port string) {
+	slog.Info("gateway running", "port", port)
+	lm.Go("http", func() error { return e.Start(":" + port) })
 }
@@ -1,30 +1,61 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"shared/envconfig"
+	"shared/lifecycle"
+	"shared/tracing"
+)
+
+// Config gateway-service, dibaca sekali di main lalu di-inject ke handler,
+// middleware dan client upstream
+type Config struct {
+	Port string
 
-// Variabel global config
-var (
-	AuthServiceURL     string
-	PaymentServiceURL  string
+	AuthServiceURL     string // alamat gRPC auth
+	PaymentServiceGRPC string // alamat gRPC payment
+	PaymentServiceURL  string // REST payment untuk forward
 	ShoppingServiceURL string
-	JWTSecret          string
-	AppPort            string
-	PaymentServiceGRPC string // untuk REST forward
 
-)
+	// HTTP admin internal payment & auth (level log, readiness)
+	PaymentAdminURL string
+	AuthAdminURL    string
 
-// ConnectConfig akan load semua config dari file .env atau fallback ke default
-func ConnectConfig() {
-	LoadEnv()
+	JWTSecret   envconfig.Secret
+	AdminEmails []string // user yang boleh akses endpoint /admin
 
-	AuthServiceURL = GetEnvOrDefault("AUTH_SERVICE_ADDR", "localhost:50052")
-	PaymentServiceGRPC = GetEnvOrDefault("PAYMENT_SERVICE_GRPC_ADDR", "localhost:50051")
-	PaymentServiceURL = GetEnvOrDefault("PAYMENT_SERVICE_REST_URL", "http://localhost:8081")
+	LogLevel       string
+	TracesExporter string
 
-	ShoppingServiceURL = GetEnvOrDefault("SHOPPING_SERVICE_URL", "http://localhost:8080")
-	JWTSecret = GetEnvOrDefault("JWT_SECRET", "mysecretkey123")
+	ShutdownTimeout time.Duration
+}
 
-	AppPort = GetEnvOrDefault("GATEWAY_PORT", ":8082")
+// Load config dari flag (args tanpa nama program), env dan file .env
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
 
-	fmt.Println("Gateway config loaded")
+func load(args []string, env func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{}
+	l := envconfig.NewLoader("gateway-service")
+	l.Port(&cfg.Port, "GATEWAY_PORT", "8082", "port HTTP")
+	l.String(&cfg.AuthServiceURL, "AUTH_SERVICE_ADDR", "localhost:50052", "alamat gRPC auth-service")
+	l.String(&cfg.PaymentServiceGRPC, "PAYMENT_SERVICE_GRPC_ADDR", "localhost:50051", "alamat gRPC payment-service")
+	l.URL(&cfg.PaymentServiceURL, "PAYMENT_SERVICE_REST_URL", "http://localhost:8081", "URL REST payment-service")
+	l.URL(&cfg.ShoppingServiceURL, "SHOPPING_SERVICE_URL", "http://localhost:8080", "URL shopping-service")
+	l.URL(&cfg.PaymentAdminURL, "PAYMENT_ADMIN_URL", "http://localhost:9091", "URL HTTP admin payment-service")
+	l.URL(&cfg.AuthAdminURL, "AUTH_ADMIN_URL", "http://localhost:9092", "URL HTTP admin auth-service")
+	l.Secret(&cfg.JWTSecret, "JWT_SECRET", "", "secret verifikasi JWT, sama dengan auth-service").Required()
+	l.List(&cfg.AdminEmails, "ADMIN_EMAILS", "", "email admin dipisah koma, kosong berarti tidak ada admin")
+	l.LogLevel(&cfg.LogLevel, "LOG_LEVEL", "info", "level log awal")
+	l.OneOf(&cfg.TracesExporter, "OTEL_TRACES_EXPORTER", "none", "exporter trace", tracing.ExporterNone, tracing.ExporterStdout, "console", tracing.ExporterOTLP)
+	l.Duration(&cfg.ShutdownTimeout, "SHUTDOWN_TIMEOUT", lifecycle.DefaultTimeout.String(), "batas waktu graceful shutdown")
+
+	if err := l.Load(args, env); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
 }
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway-service/proto"
+	"shared/logging"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Perubahan satu field di audit log, nilai dalam JSON aslinya
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Audit log payment untuk response REST
+type AuditLog struct {
+	ID         string                 `json:"id"`
+	Resource   string                 `json:"resource"`
+	ResourceID string                 `json:"resource_id"`
+	Action     string                 `json:"action"`
+	Actor      string                 `json:"actor"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// Payment yang di-soft delete (admin)
+func (h *GatewayHandler) ListDeletedPaymentsHandler(c echo.Context) error {
+	client := h.clients.Payment
+
+	res, err := client.ListDeletedPayments(paymentContext(c), &proto.ListDeletedPaymentsRequest{})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to fetch deleted payments", "error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, res.Payments)
+}
+
+// Kembalikan payment yang di-soft delete (admin)
+func (h *GatewayHandler) RestorePaymentHandler(c echo.Context) error {
+	client := h.clients.Payment
+
+	res, err := client.RestorePayment(paymentContext(c), &proto.RestorePaymentRequest{Id: c.Param("id")})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{"message": status.Convert(err).Message()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to restore payment", "error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Audit log payment (admin), query payment_id dan limit opsional
+func (h *GatewayHandler) ListPaymentAuditLogsHandler(c echo.Context) error {
+	var limit int64
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": "limit must be a positive integer"})
+		}
+		limit = n
+	}
+
+	client := h.clients.Payment
+	res, err := client.ListAuditLogs(paymentContext(c), &proto.ListAuditLogsRequest{
+		PaymentId: c.QueryParam("payment_id"),
+		Limit:     limit,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to fetch audit logs", "error": err.Error()})
+	}
+
+	logs := make([]AuditLog, 0, len(res.Logs))
+	for _, l := range res.Logs {
+		logs = append(logs, toAuditLog(l))
+	}
+	return c.JSON(http.StatusOK, logs)
+}
+
+func toAuditLog(l *proto.AuditLog) AuditLog {
+	changes := make(map[string]AuditChange, len(l.Changes))
+	for field, ch := range l.Changes {
+		changes[field] = AuditChange{Before: rawJSON(ch.GetBefore()), After: rawJSON(ch.GetAfter())}
+	}
+	return AuditLog{
+		ID:         l.GetId(),
+		Resource:   l.GetResource(),
+		ResourceID: l.GetResourceId(),
+		Action:     l.GetAction(),
+		Actor:      l.GetActor(),
+		RequestID:  l.GetRequestId(),
+		Changes:    changes,
+		CreatedAt:  l.GetCreatedAt().AsTime(),
+	}
+}
+
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
+
+// Level log runtime per service (GET atau PUT {"level":"debug"}), service:
+// gateway, shopping, payment, auth
+func (h *GatewayHandler) LogLevelHandler(c echo.Context) error {
+	switch c.Param("service") {
+	case "gateway":
+		return echo.WrapHandler(logging.LevelHandler())(c)
+	case "shopping":
+		return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/admin/log-level")
+	case "payment":
+		return h.forwarder.ForwardRequest(c, h.cfg.PaymentAdminURL+"/admin/log-level")
+	case "auth":
+		return h.forwarder.ForwardRequest(c, h.cfg.AuthAdminURL+"/admin/log-level")
+	}
+	return c.JSON(http.StatusNotFound, echo.Map{"message": "unknown service"})
+}
@@ -5,34 +5,83 @@ import (
 	"net/http"
 
 	"gateway-service/config"
+	"gateway-service/internal/gateway/delivery/http/middleware"
 	"gateway-service/internal/gateway/infra"
 	"gateway-service/proto"
+	"shared/logging"
 
 	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // GatewayHandler menangani permintaan REST/gRPC ke semua service
-type GatewayHandler struct{}
+type GatewayHandler struct {
+	cfg       *config.Config
+	clients   *infra.GRPCClients
+	forwarder *infra.Forwarder
+}
 
-func NewGatewayHandler() *GatewayHandler {
-	return &GatewayHandler{}
+func NewGatewayHandler(cfg *config.Config, clients *infra.GRPCClients, forwarder *infra.Forwarder) *GatewayHandler {
+	return &GatewayHandler{cfg: cfg, clients: clients, forwarder: forwarder}
 }
 
 //  PROXY FORWARD
 
 func (h *GatewayHandler) AuthProxy(c echo.Context) error {
-	targetURL := config.AuthServiceURL + c.Path()
-	return infra.ForwardRequest(c, targetURL)
+	targetURL := h.cfg.AuthServiceURL + c.Path()
+	return h.forwarder.ForwardRequest(c, targetURL)
 }
 
+// Header email user yang diteruskan ke shopping-service
+const headerUserEmail = "X-User-Email"
+
+// Header penanda admin (ADMIN_EMAILS) yang diteruskan ke shopping-service
+const headerUserAdmin = "X-User-Admin"
+
 func (h *GatewayHandler) ShoppingProxy(c echo.Context) error {
-	targetURL := config.ShoppingServiceURL + c.Path()
-	return infra.ForwardRequest(c, targetURL)
+	return h.forwarder.ForwardRequest(c, h.shoppingTarget(c))
+}
+
+// ShoppingStreamProxy untuk download besar (export), response diteruskan
+// per potongan tanpa ditampung di gateway
+func (h *GatewayHandler) ShoppingStreamProxy(c echo.Context) error {
+	return h.forwarder.StreamRequest(c, h.shoppingTarget(c))
+}
+
+// URL tujuan di shopping-service, sekaligus set header email user
+func (h *GatewayHandler) shoppingTarget(c echo.Context) string {
+	// email dan status admin hanya boleh berasal dari JWT, header dari client dibuang
+	c.Request().Header.Del(headerUserEmail)
+	c.Request().Header.Del(headerUserAdmin)
+	if email, ok := c.Get("userEmail").(string); ok && email != "" {
+		c.Request().Header.Set(headerUserEmail, email)
+	}
+	if middleware.IsAdmin(c, h.cfg.AdminEmails) {
+		c.Request().Header.Set(headerUserAdmin, "true")
+	}
+
+	// pakai path asli (bukan pola route) supaya /:id dan query ikut terkirim
+	return h.cfg.ShoppingServiceURL + c.Request().URL.RequestURI()
+}
+
+// Context gRPC ke payment-service dengan email user dari JWT dan request ID
+// di metadata, dicatat payment-service di audit log
+func paymentContext(c echo.Context) context.Context {
+	md := metadata.MD{}
+	if email, ok := c.Get("userEmail").(string); ok && email != "" {
+		md.Set("x-user-email", email)
+	}
+	if id := c.Request().Header.Get(logging.HeaderRequestID); id != "" {
+		md.Set("x-request-id", id)
+	}
+	return metadata.NewOutgoingContext(c.Request().Context(), md)
 }
 
 func (h *GatewayHandler) PaymentProxy(c echo.Context) error {
-	targetURL := config.PaymentServiceURL + c.Path()
-	return infra.ForwardRequest(c, targetURL)
+	targetURL := h.cfg.PaymentServiceURL + c.Path()
+	return h.forwarder.ForwardRequest(c, targetURL)
 }
 
 //  AUTH HANDLER
@@ -54,8 +103,8 @@ func (h *GatewayHandler) LoginHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Invalid input"})
 	}
 
-	client := infra.GetAuthClient()
-	ctx := context.Background()
+	client := h.clients.Auth
+	ctx := c.Request().Context()
 
 	req := &proto.LoginRequest{
 		Email:    input.Email,
@@ -76,8 +125,8 @@ func (h *GatewayHandler) RegisterHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Invalid input"})
 	}
 
-	client := infra.GetAuthClient()
-	ctx := context.Background()
+	client := h.clients.Auth
+	ctx := c.Request().Context()
 
 	req := &proto.RegisterRequest{
 		Name:     input.Name,
@@ -101,39 +150,71 @@ func (h *GatewayHandler) RegisterHandler(c echo.Context) error {
 
 func (h *GatewayHandler) CreatePaymentHandler(c echo.Context) error {
 	var input struct {
-		Email  string `json:"email"`
-		Amount int64  `json:"amount"`
-		Status string `json:"status"`
+		Email     string `json:"email"`
+		Amount    Money  `json:"amount"`
+		Status    string `json:"status"`
+		Method    string `json:"method"`
+		CardToken string `json:"card_token"`
 	}
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Invalid input format"})
 	}
 
-	if input.Email == "" || input.Amount <= 0 || input.Status == "" {
+	if input.Email == "" || input.Amount.MinorUnits <= 0 || input.Status == "" {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "email, amount, and status are required"})
 	}
 
-	client := infra.GetPaymentClient()
-	ctx := context.Background()
+	client := h.clients.Payment
+	ctx := paymentContext(c)
 
 	req := &proto.AddPaymentRequest{
-		Email:  input.Email,
-		Amount: float64(input.Amount),
+		Email:     input.Email,
+		Amount:    input.Amount.toPB(),
+		Method:    input.Method,
+		CardToken: input.CardToken,
 	}
 
 	res, err := client.AddPayment(ctx, req)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Failed to create payment", "error": err.Error()})
+		return c.JSON(createPaymentStatus(err), echo.Map{"message": "Failed to create payment", "error": status.Convert(err).Message()})
 	}
 
 	return c.JSON(http.StatusCreated, res)
 }
 
+// Status HTTP untuk error AddPayment: ditolak provider jadi 402,
+// provider timeout 504, provider tidak tersedia 502
+func createPaymentStatus(err error) int {
+	switch status.Code(err) {
+	case codes.FailedPrecondition:
+		return http.StatusPaymentRequired
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unavailable:
+		return http.StatusBadGateway
+	case codes.Internal:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// Ambil payment, user biasa hanya miliknya (lihat paymentEmail)
 func (h *GatewayHandler) GetAllPaymentsHandler(c echo.Context) error {
-	client := infra.GetPaymentClient()
-	ctx := context.Background()
+	email, ok := h.paymentEmail(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
 
-	res, err := client.GetAllPayments(ctx, &proto.GetAllPaymentsRequest{})
+	filter, err := parsePaymentFilter(c, email)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+	}
+
+	client := h.clients.Payment
+	ctx := c.Request().Context()
+
+	res, err := client.GetAllPayments(ctx, &proto.GetAllPaymentsRequest{Filter: filter})
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to fetch payments", "error": err.Error()})
 	}
@@ -147,16 +228,16 @@ func (h *GatewayHandler) GetPaymentByIDHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Missing payment ID"})
 	}
 
-	client := infra.GetPaymentClient()
-	ctx := context.Background()
+	client := h.clients.Payment
+	ctx := c.Request().Context()
 
 	res, err := client.GetPaymentByID(ctx, &proto.GetPaymentByIDRequest{Id: id})
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{"message": "Payment not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to fetch payment", "error": err.Error()})
 	}
-	if res == nil {
-		return c.JSON(http.StatusNotFound, echo.Map{"message": "Payment not found"})
-	}
 
 	return c.JSON(http.StatusOK, res)
 }
@@ -167,11 +248,14 @@ func (h *GatewayHandler) DeletePaymentByIDHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Missing payment ID"})
 	}
 
-	client := infra.GetPaymentClient()
-	ctx := context.Background()
+	client := h.clients.Payment
+	ctx := paymentContext(c)
 
 	res, err := client.DeletePaymentByID(ctx, &proto.DeletePaymentByIDRequest{Id: id})
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{"message": "Payment not found"})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to delete payment", "error": err.Error()})
 	}
 
@@ -181,49 +265,49 @@ func (h *GatewayHandler) DeletePaymentByIDHandler(c echo.Context) error {
 //  SHOPPING - PRODUCT (REST PROXY)
 
 func (h *GatewayHandler) GetAllProducts(c echo.Context) error {
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/products")
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/products")
 }
 
 func (h *GatewayHandler) CreateProduct(c echo.Context) error {
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/products")
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/products")
 }
 
 func (h *GatewayHandler) GetProductByID(c echo.Context) error {
 	id := c.Param("id")
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/products/"+id)
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/products/"+id)
 }
 
 func (h *GatewayHandler) UpdateProduct(c echo.Context) error {
 	id := c.Param("id")
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/products/"+id)
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/products/"+id)
 }
 
 func (h *GatewayHandler) DeleteProduct(c echo.Context) error {
 	id := c.Param("id")
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/products/"+id)
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/products/"+id)
 }
 
 //  SHOPPING - TRANSACTION (REST PROXY)
 
 func (h *GatewayHandler) GetAllTransactions(c echo.Context) error {
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/transactions")
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/transactions")
 }
 
 func (h *GatewayHandler) CreateTransaction(c echo.Context) error {
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/transactions")
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/transactions")
 }
 
 func (h *GatewayHandler) GetTransactionByID(c echo.Context) error {
 	id := c.Param("id")
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/transactions/"+id)
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/transactions/"+id)
 }
 
 func (h *GatewayHandler) UpdateTransaction(c echo.Context) error {
 	id := c.Param("id")
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/transactions/"+id)
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/transactions/"+id)
 }
 
 func (h *GatewayHandler) DeleteTransaction(c echo.Context) error {
 	id := c.Param("id")
-	return infra.ForwardRequest(c, config.ShoppingServiceURL+"/transactions/"+id)
+	return h.forwarder.ForwardRequest(c, h.cfg.ShoppingServiceURL+"/transactions/"+id)
 }
@@ -1,14 +1,32 @@
 package http
 
 import (
+	"gateway-service/config"
 	"gateway-service/internal/gateway/delivery/http/middleware"
+	"gateway-service/internal/gateway/infra"
+	"shared/health"
 
 	"github.com/labstack/echo/v4"
 )
 
+// batas ukuran file bulk import product
+const maxImportBytes = 10 << 20
+
 // RegisterGatewayRoutes mendaftarkan semua route untuk gateway
-func RegisterGatewayRoutes(e *echo.Echo) {
-	handler := NewGatewayHandler()
+func RegisterGatewayRoutes(e *echo.Echo, cfg *config.Config, clients *infra.GRPCClients) {
+	handler := NewGatewayHandler(cfg, clients, infra.NewForwarder(cfg))
+	jwtAuth := middleware.JWT(cfg.JWTSecret.Value())
+	// EventSource tidak bisa kirim header, SSE memakai query ticket
+	streamAuth := middleware.StreamTicket(cfg.JWTSecret.Value())
+	adminOnly := middleware.AdminOnly(cfg.AdminEmails)
+	// batas upload bulk import, sama dengan batas di shopping-service
+	importLimit := middleware.BodyLimit(maxImportBytes)
+
+	// health gateway dan status gabungan semua service
+	healthHandler := NewHealthHandler(cfg, clients)
+	e.GET("/healthz", echo.WrapHandler(health.LiveHandler()))
+	e.GET("/readyz", echo.WrapHandler(health.ReadyHandler(healthHandler.Checker)))
+	e.GET("/status", healthHandler.Status)
 
 	// Public Routes
 	e.POST("/login", handler.LoginHandler)       // gRPC Login
@@ -16,21 +34,133 @@ func RegisterGatewayRoutes(e *echo.Echo) {
 
 	// with JWT
 
-	// Shopping → /products
+	// Shopping → /products, perubahan katalog hanya oleh admin
 	products := e.Group("/products")
-	products.Use(middleware.JWTMiddleware)
+	products.Use(jwtAuth)
 	products.GET("", handler.ShoppingProxy)
-	products.POST("", handler.ShoppingProxy)
+	products.POST("", handler.ShoppingProxy, adminOnly)
+	products.GET("/search", handler.ShoppingProxy)
+	products.POST("/import", handler.ShoppingProxy, adminOnly, importLimit)
+	products.GET("/import/:id", handler.ShoppingProxy, adminOnly)
+	products.GET("/export", handler.ShoppingStreamProxy)
+	products.GET("/:id", handler.ShoppingProxy)
+	products.PUT("/:id", handler.ShoppingProxy, adminOnly)   // wajib If-Match
+	products.PATCH("/:id", handler.ShoppingProxy, adminOnly) // JSON Merge Patch
+
+	// Shopping → /categories
+	categories := e.Group("/categories")
+	categories.Use(jwtAuth)
+	categories.GET("", handler.ShoppingProxy)
+	categories.POST("", handler.ShoppingProxy, adminOnly)
+	categories.GET("/:id", handler.ShoppingProxy)
 
-	// Shopping → /transactions
+	// Shopping → /inventory, restock dan adjust hanya oleh admin
+	inventory := e.Group("/inventory")
+	inventory.Use(jwtAuth)
+	inventory.GET("/:productId", handler.ShoppingProxy)
+	inventory.POST("/:productId/restock", handler.ShoppingProxy, adminOnly)
+	inventory.POST("/:productId/adjust", handler.ShoppingProxy, adminOnly)
+
+	// Shopping → /transactions. cancel dan return hanya untuk pembeli
+	// (dicek shopping), pack, ship dan deliver hanya admin
 	transactions := e.Group("/transactions")
-	transactions.Use(middleware.JWTMiddleware)
+	transactions.Use(jwtAuth)
 	transactions.GET("", handler.ShoppingProxy)
 	transactions.POST("", handler.ShoppingProxy)
+	transactions.GET("/:id", handler.ShoppingProxy)
+	transactions.PUT("/:id", handler.ShoppingProxy)   // wajib If-Match
+	transactions.PATCH("/:id", handler.ShoppingProxy) // JSON Merge Patch
+	transactions.POST("/:id/pack", handler.ShoppingProxy, adminOnly)
+	transactions.POST("/:id/ship", handler.ShoppingProxy, adminOnly)
+	transactions.POST("/:id/deliver", handler.ShoppingProxy, adminOnly)
+	transactions.POST("/:id/cancel", handler.ShoppingProxy)
+	transactions.POST("/:id/return", handler.ShoppingProxy)
+	transactions.POST("/:id/returns", handler.ShoppingProxy) // ajukan retur
+	transactions.GET("/:id/returns", handler.ShoppingProxy)
+	transactions.GET("/:id/invoice", handler.ShoppingStreamProxy) // format=html/pdf
+
+	// Shopping → /returns (approve = refund lewat payment), keputusan
+	// retur hanya oleh admin
+	returns := e.Group("/returns")
+	returns.Use(jwtAuth)
+	returns.GET("/:id", handler.ShoppingProxy)
+	returns.POST("/:id/approve", handler.ShoppingProxy, adminOnly)
+	returns.POST("/:id/reject", handler.ShoppingProxy, adminOnly)
+
+	// Shopping → /cart (per user dari JWT)
+	cart := e.Group("/cart")
+	cart.Use(jwtAuth)
+	cart.GET("", handler.ShoppingProxy)
+	cart.DELETE("", handler.ShoppingProxy)
+	cart.POST("/items", handler.ShoppingProxy)
+	cart.PUT("/items/:productId", handler.ShoppingProxy)
+	cart.DELETE("/items/:productId", handler.ShoppingProxy)
+	cart.POST("/checkout", handler.ShoppingProxy)
 
-	// Payment → /payments
+	// Shopping → /orders
+	orders := e.Group("/orders")
+	orders.Use(jwtAuth)
+	orders.GET("", handler.ShoppingProxy)
+	orders.GET("/:id", handler.ShoppingProxy)
+	orders.GET("/:id/invoice", handler.ShoppingStreamProxy) // format=html/pdf
+
+	// Shopping → /invoices dan /statements (milik user dari JWT)
+	invoices := e.Group("/invoices")
+	invoices.Use(jwtAuth)
+	invoices.GET("", handler.ShoppingProxy)
+	invoices.GET("/:id", handler.ShoppingStreamProxy) // format=html/pdf
+	e.GET("/statements", handler.ShoppingStreamProxy, jwtAuth)
+
+	// Shopping → /promotions, buat dan hapus promo hanya oleh admin
+	promotions := e.Group("/promotions")
+	promotions.Use(jwtAuth)
+	promotions.GET("", handler.ShoppingProxy)
+	promotions.POST("", handler.ShoppingProxy, adminOnly)
+	promotions.GET("/:code", handler.ShoppingProxy)
+	promotions.DELETE("/:id", handler.ShoppingProxy, adminOnly)
+
+	// Payment → /payments, user biasa hanya melihat payment miliknya, admin
+	// boleh memilih email lewat query
 	payments := e.Group("/payments")
-	payments.Use(middleware.JWTMiddleware)
+	payments.Use(jwtAuth)
 	payments.GET("", handler.GetAllPaymentsHandler)
 	payments.POST("", handler.CreatePaymentHandler)
+	payments.GET("/export", handler.ExportPaymentsHandler)             // stream NDJSON/CSV
+	payments.POST("/watch/ticket", handler.StreamTicketHandler)        // ticket untuk EventSource
+	e.GET("/payments/watch", handler.WatchPaymentsHandler, streamAuth) // SSE perubahan payment
+	closeSSEOnShutdown(e)
+
+	// Admin (ADMIN_EMAILS): data yang dihapus, restore dan audit log
+	admin := e.Group("/admin")
+	admin.Use(jwtAuth, adminOnly)
+	admin.GET("/products/deleted", handler.ShoppingProxy)
+	admin.DELETE("/products/:id", handler.ShoppingProxy)
+	admin.POST("/products/:id/restore", handler.ShoppingProxy)
+	admin.DELETE("/transactions/:id", handler.ShoppingProxy)
+	admin.GET("/transactions/deleted", handler.ShoppingProxy)
+	admin.POST("/transactions/:id/restore", handler.ShoppingProxy)
+	admin.POST("/transactions/:id/cancel", handler.ShoppingProxy) // cancel + refund transaksi user lain
+	admin.GET("/audit-logs", handler.ShoppingProxy)               // audit shopping (product, transaksi)
+	admin.POST("/reconciliations", handler.ShoppingProxy)
+	admin.GET("/reconciliations", handler.ShoppingProxy)
+	admin.GET("/reconciliations/:id", handler.ShoppingStreamProxy) // format=csv untuk unduh
+	admin.GET("/analytics/revenue", handler.ShoppingProxy)
+	admin.GET("/analytics/top-products", handler.ShoppingProxy)
+	admin.GET("/analytics/summary", handler.ShoppingProxy)
+	admin.DELETE("/payments/:id", handler.DeletePaymentByIDHandler)
+	admin.GET("/payments/deleted", handler.ListDeletedPaymentsHandler)
+	admin.POST("/payments/:id/restore", handler.RestorePaymentHandler)
+	admin.GET("/payments/audit-logs", handler.ListPaymentAuditLogsHandler)
+	admin.GET("/log-level/:service", handler.LogLevelHandler) // gateway, shopping, payment, auth
+	admin.PUT("/log-level/:service", handler.LogLevelHandler)
+
+	// Payment → /webhooks (endpoint milik user dari JWT)
+	webhooks := e.Group("/webhooks")
+	webhooks.Use(jwtAuth)
+	webhooks.POST("", handler.RegisterWebhookHandler)
+	webhooks.GET("", handler.ListWebhooksHandler)
+	webhooks.DELETE("/:id", handler.DeleteWebhookHandler)
+	webhooks.GET("/deliveries", handler.ListWebhookDeliveriesHandler)
+	webhooks.GET("/deliveries/:id/attempts", handler.ListWebhookAttemptsHandler)
+	webhooks.POST("/deliveries/:id/redeliver", handler.RedeliverWebhookHandler)
 }
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"gateway-service/config"
+	"gateway-service/internal/gateway/infra"
+	"shared/health"
+
+	"github.com/labstack/echo/v4"
+)
+
+// client HTTP untuk probe readiness upstream, tanpa tracing supaya probe
+// berkala tidak memenuhi trace
+var healthHTTPClient = &http.Client{Timeout: health.DefaultTimeout}
+
+// Status gabungan gateway dan semua service di belakangnya
+type SystemStatus struct {
+	Status    string                   `json:"status"`
+	Services  map[string]health.Report `json:"services"`
+	CheckedAt time.Time                `json:"checked_at"`
+}
+
+// handler health gateway
+type HealthHandler struct {
+	// readiness gateway: auth & payment lewat grpc.health.v1, shopping lewat /readyz
+	Checker *health.Checker
+	// URL /readyz tiap service untuk /status
+	Services map[string]string
+}
+
+func NewHealthHandler(cfg *config.Config, clients *infra.GRPCClients) *HealthHandler {
+	checker := health.NewChecker()
+	checker.Add("auth", health.GRPCCheck(clients.AuthConn()))
+	checker.Add("payment", health.GRPCCheck(clients.PaymentConn()))
+	checker.Add("shopping", health.HTTPCheck(healthHTTPClient, cfg.ShoppingServiceURL+"/readyz"))
+
+	return &HealthHandler{
+		Checker: checker,
+		Services: map[string]string{
+			"shopping": cfg.ShoppingServiceURL + "/readyz",
+			"payment":  cfg.PaymentAdminURL + "/readyz",
+			"auth":     cfg.AuthAdminURL + "/readyz",
+		},
+	}
+}
+
+// Status semua service: readiness gateway ditambah laporan /readyz tiap
+// service (detail check Mongo, broker, ...). 200 kalau semua ok, 503 kalau
+// ada yang tidak siap atau tidak bisa dihubungi.
+func (h *HealthHandler) Status(c echo.Context) error {
+	ctx := c.Request().Context()
+	result := SystemStatus{
+		Status:    health.StatusOK,
+		Services:  make(map[string]health.Report, len(h.Services)+1),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	collect := func(name string, report health.Report) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Services[name] = report
+		if report.Status != health.StatusOK {
+			result.Status = health.StatusFail
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		collect("gateway", h.Checker.Run(ctx))
+	}()
+	for name, url := range h.Services {
+		wg.Add(1)
+		go func(name, url string) {
+			defer wg.Done()
+			collect(name, fetchServiceReport(ctx, url))
+		}(name, url)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if result.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(status, result)
+}
+
+// laporan /readyz satu service, service yang tidak bisa dihubungi
+// dilaporkan fail dengan error-nya
+func fetchServiceReport(ctx context.Context, url string) health.Report {
+	ctx, cancel := context.WithTimeout(ctx, health.DefaultTimeout)
+	defer cancel()
+
+	report, err := health.FetchReport(ctx, healthHTTPClient, url)
+	if err != nil {
+		report.Status = health.StatusFail
+		if report.Error == "" {
+			report.Error = err.Error()
+		}
+	}
+	return report
+}
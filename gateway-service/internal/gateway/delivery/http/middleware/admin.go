@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminOnly hanya meloloskan user dengan email di ADMIN_EMAILS,
+// dipasang setelah middleware JWT
+func AdminOnly(admins []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if IsAdmin(c, admins) {
+				return next(c)
+			}
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"message": "Admin access required",
+			})
+		}
+	}
+}
+
+// Cek apakah email dari JWT termasuk ADMIN_EMAILS
+func IsAdmin(c echo.Context, admins []string) bool {
+	email, _ := c.Get("userEmail").(string)
+	for _, admin := range admins {
+		if email != "" && strings.EqualFold(email, admin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAdmin(t *testing.T) {
+	admins := []string{"admin@mail.com", "ops@mail.com"}
+	tests := []struct {
+		name   string
+		email  any
+		admins []string
+		want   bool
+	}{
+		{name: "email terdaftar", email: "admin@mail.com", want: true},
+		{name: "huruf besar tetap cocok", email: "Ops@Mail.com", want: true},
+		{name: "email tidak terdaftar", email: "buyer@mail.com", want: false},
+		{name: "email kosong", email: "", want: false},
+		{name: "tanpa email", email: nil, want: false},
+		{name: "entry ADMIN_EMAILS kosong", email: "", admins: []string{""}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			if tt.email != nil {
+				c.Set("userEmail", tt.email)
+			}
+			list := admins
+			if tt.admins != nil {
+				list = tt.admins
+			}
+			assert.Equal(t, tt.want, IsAdmin(c, list))
+		})
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{name: "admin diteruskan", email: "admin@mail.com", wantCode: http.StatusOK},
+		{name: "bukan admin ditolak", email: "buyer@mail.com", wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmail := func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set("userEmail", tt.email)
+					return next(c)
+				}
+			}
+			admin := AdminOnly([]string{"admin@mail.com"})
+			rec := serve(func(next echo.HandlerFunc) echo.HandlerFunc { return setEmail(admin(next)) }, httptest.NewRequest(http.MethodPost, "/products", nil))
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLimit menolak body lebih dari limit byte dengan 413 sebelum
+// diteruskan ke service. Body tanpa Content-Length (chunked) dipotong di
+// limit sehingga upstream menerima body tidak lengkap dan menolaknya.
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+					"message": "Request body too large",
+				})
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
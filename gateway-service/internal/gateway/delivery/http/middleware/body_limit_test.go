@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "di bawah limit", body: strings.Repeat("a", 8), wantCode: http.StatusOK},
+		{name: "tepat di limit", body: strings.Repeat("a", 16), wantCode: http.StatusOK},
+		{name: "melebihi limit", body: strings.Repeat("a", 17), wantCode: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/products/import", strings.NewReader(tt.body))
+			rec := serve(BodyLimit(16), req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestBodyLimit_ChunkedBodyTruncated(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/products/import", strings.NewReader(strings.Repeat("a", 32)))
+	req.ContentLength = -1
+	e := echo.New()
+	c := e.NewContext(req, httptest.NewRecorder())
+	var readErr error
+	err := BodyLimit(16)(func(c echo.Context) error {
+		_, readErr = io.ReadAll(c.Request().Body)
+		return nil
+	})(c)
+	assert.NoError(t, err)
+	var maxErr *http.MaxBytesError
+	assert.ErrorAs(t, readErr, &maxErr)
+}
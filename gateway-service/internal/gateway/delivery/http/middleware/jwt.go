@@ -4,46 +4,54 @@ import (
 	"net/http"
 	"strings"
 
-	"gateway-service/config"
-
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-// JWTMiddleware untuk validasi token dan ekstrak email dari JWT
-func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{
-				"message": "Missing Authorization header",
-			})
-		}
+// JWT validasi token dengan secret dari config dan ekstrak email dari JWT
+func JWT(secret string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"message": "Missing Authorization header",
+				})
+			}
 
-		// Ambil token dari "Bearer <token>"
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
+			// Ambil token dari "Bearer <token>"
+			tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Pastikan pakai metode HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, echo.ErrUnauthorized
+			claims, err := parseToken(secret, tokenString)
+			// ticket stream tidak boleh dipakai sebagai access token
+			if err != nil || claims["purpose"] != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"message": "Invalid or expired token",
+				})
 			}
-			return []byte(config.JWTSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{
-				"message": "Invalid or expired token",
-			})
-		}
 
-		// Ambil data dari claims (email, user_id, dll)
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			// Ambil data dari claims (email, user_id, dll)
 			if email, ok := claims["email"].(string); ok {
 				c.Set("userEmail", email)
 			}
+
+			return next(c)
 		}
+	}
+}
 
-		return next(c)
+// Parse dan verifikasi token HMAC, hasilnya claims
+func parseToken(secret, tokenString string, opts ...jwt.ParserOption) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Pastikan pakai metode HMAC
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, echo.ErrUnauthorized
+		}
+		return []byte(secret), nil
+	}, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return claims, nil
 }
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "rahasia"
+
+func signToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// jalankan middleware di depan handler yang mengembalikan email dari context
+func serve(mw echo.MiddlewareFunc, req *http.Request) *httptest.ResponseRecorder {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := mw(func(c echo.Context) error {
+		email, _ := c.Get("userEmail").(string)
+		return c.String(http.StatusOK, email)
+	})
+	if err := handler(c); err != nil {
+		e.HTTPErrorHandler(err, c)
+	}
+	return rec
+}
+
+func TestJWT(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		header   string
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "access token valid",
+			header:   "Bearer " + signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "exp": now.Add(time.Hour).Unix()}),
+			wantCode: http.StatusOK,
+			wantBody: "buyer@mail.com",
+		},
+		{
+			name:     "tanpa header",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "access token lewat query ditolak",
+			query:    "?access_token=" + signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "exp": now.Add(time.Hour).Unix()}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "ticket stream tidak bisa jadi access token",
+			header:   "Bearer " + signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "purpose": "stream", "exp": now.Add(time.Hour).Unix()}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "token kedaluwarsa",
+			header:   "Bearer " + signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "exp": now.Add(-time.Minute).Unix()}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "secret berbeda",
+			header:   "Bearer " + signToken(t, "lain", jwt.MapClaims{"email": "buyer@mail.com", "exp": now.Add(time.Hour).Unix()}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "metode bukan HMAC",
+			header:   "Bearer " + noneToken(t),
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/transactions"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := serve(JWT(testSecret), req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func noneToken(t *testing.T) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"email": "admin@mail.com"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
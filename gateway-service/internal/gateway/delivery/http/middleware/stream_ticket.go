@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// Masa berlaku ticket stream, cukup untuk membuka EventSource
+const StreamTicketTTL = 30 * time.Second
+
+// Batas reconnect EventSource dengan ticket yang sama. Reconnect membawa
+// header Last-Event-ID, ticket yang sudah lewat TTL masih diterima selama
+// belum lewat window ini. Setelah itu client minta ticket baru.
+const StreamTicketResumeWindow = 12 * time.Hour
+
+// claim purpose yang membedakan ticket stream dari access token
+const streamTicketPurpose = "stream"
+
+var ErrInvalidStreamTicket = errors.New("invalid or expired stream ticket")
+
+// Buat ticket stream untuk email: JWT singkat dengan purpose "stream".
+// EventSource di browser tidak bisa kirim header, jadi ticket ini yang
+// dikirim lewat query, bukan access token.
+func IssueStreamTicket(secret, email string, now time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email":   email,
+		"purpose": streamTicketPurpose,
+		"iat":     now.Unix(),
+		"exp":     now.Add(StreamTicketTTL).Unix(),
+	})
+	return token.SignedString([]byte(secret))
+}
+
+// Validasi ticket stream, hasilnya email pemilik ticket. leeway memberi
+// tambahan waktu setelah exp untuk reconnect.
+func parseStreamTicket(secret, ticket string, leeway time.Duration) (string, error) {
+	claims, err := parseToken(secret, ticket, jwt.WithLeeway(leeway))
+	if err != nil || claims["purpose"] != streamTicketPurpose {
+		return "", ErrInvalidStreamTicket
+	}
+	// ticket selalu punya exp, tanpa exp jwt.Parse tetap meloloskan
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return "", ErrInvalidStreamTicket
+	}
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return "", ErrInvalidStreamTicket
+	}
+	return email, nil
+}
+
+// StreamTicket untuk endpoint SSE: terima query ticket dari IssueStreamTicket,
+// tanpa ticket jatuh ke validasi header Authorization biasa. TTL ticket hanya
+// berlaku untuk koneksi pertama, reconnect dengan Last-Event-ID boleh
+// memakai ticket yang sama sampai StreamTicketResumeWindow.
+func StreamTicket(secret string) echo.MiddlewareFunc {
+	jwtAuth := JWT(secret)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := jwtAuth(next)
+		return func(c echo.Context) error {
+			ticket := c.QueryParam("ticket")
+			if ticket == "" {
+				return withJWT(c)
+			}
+
+			var leeway time.Duration
+			if c.Request().Header.Get("Last-Event-ID") != "" {
+				leeway = StreamTicketResumeWindow
+			}
+			email, err := parseStreamTicket(secret, ticket, leeway)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"message": err.Error(),
+				})
+			}
+			c.Set("userEmail", email)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTicket(t *testing.T) {
+	now := time.Now()
+	issue := func(email string, at time.Time) string {
+		ticket, err := IssueStreamTicket(testSecret, email, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ticket
+	}
+	valid := issue("buyer@mail.com", now)
+
+	tests := []struct {
+		name     string
+		ticket   string
+		header   string
+		resumeID string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "ticket valid",
+			ticket:   valid,
+			wantCode: http.StatusOK,
+			wantBody: "buyer@mail.com",
+		},
+		{
+			name:     "ticket kedaluwarsa",
+			ticket:   issue("buyer@mail.com", now.Add(-StreamTicketTTL-time.Second)),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "reconnect dengan ticket kedaluwarsa",
+			ticket:   issue("buyer@mail.com", now.Add(-StreamTicketTTL-time.Minute)),
+			resumeID: "42",
+			wantCode: http.StatusOK,
+			wantBody: "buyer@mail.com",
+		},
+		{
+			name:     "reconnect lewat resume window",
+			ticket:   issue("buyer@mail.com", now.Add(-StreamTicketTTL-StreamTicketResumeWindow-time.Minute)),
+			resumeID: "42",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "ticket diubah",
+			ticket:   valid[:len(valid)-2] + "xx",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "access token bukan ticket",
+			ticket:   signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "exp": now.Add(time.Hour).Unix()}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "ticket tanpa exp",
+			ticket:   signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "purpose": "stream"}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "ticket tanpa email",
+			ticket:   signToken(t, testSecret, jwt.MapClaims{"purpose": "stream", "exp": now.Add(time.Minute).Unix()}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "tanpa ticket pakai header",
+			header:   "Bearer " + signToken(t, testSecret, jwt.MapClaims{"email": "buyer@mail.com", "exp": now.Add(time.Hour).Unix()}),
+			wantCode: http.StatusOK,
+			wantBody: "buyer@mail.com",
+		},
+		{
+			name:     "tanpa ticket dan header",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/payments/watch"
+			if tt.ticket != "" {
+				target += "?ticket=" + tt.ticket
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.resumeID != "" {
+				req.Header.Set("Last-Event-ID", tt.resumeID)
+			}
+			rec := serve(StreamTicket(testSecret), req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package http
+
+import "gateway-service/proto"
+
+// Nominal uang di request/response REST, minor unit (sen) + kode ISO-4217
+type Money struct {
+	MinorUnits int64  `json:"minor_units"`
+	Currency   string `json:"currency"`
+}
+
+func (m Money) toPB() *proto.Money {
+	return &proto.Money{MinorUnits: m.MinorUnits, Currency: m.Currency}
+}
+
+func fromMoneyPB(m *proto.Money) Money {
+	return Money{MinorUnits: m.GetMinorUnits(), Currency: m.GetCurrency()}
+}
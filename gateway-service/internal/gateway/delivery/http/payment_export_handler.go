@@ -0,0 +1,224 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway-service/internal/gateway/delivery/http/middleware"
+	"gateway-service/proto"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Format export yang didukung
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatCSV    = "csv"
+)
+
+var csvHeader = []string{"id", "email", "amount_minor_units", "currency", "status", "created_at"}
+
+// ExportPaymentsHandler men-download payment sebagai NDJSON atau CSV.
+// Data diteruskan per baris dari stream gRPC tanpa ditampung di memori.
+// User biasa hanya mendapat payment miliknya (lihat paymentEmail).
+func (h *GatewayHandler) ExportPaymentsHandler(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = exportFormatNDJSON
+	}
+	if format != exportFormatNDJSON && format != exportFormatCSV {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "format must be ndjson or csv"})
+	}
+
+	email, ok := h.paymentEmail(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	filter, err := parsePaymentFilter(c, email)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+	}
+
+	// Pakai context request supaya stream ikut berhenti saat client disconnect
+	ctx := c.Request().Context()
+	client := h.clients.Payment
+
+	stream, err := client.StreamPayments(ctx, &proto.StreamPaymentsRequest{Filter: filter})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to export payments", "error": err.Error()})
+	}
+
+	// Ambil data pertama sebelum kirim header, supaya error dari payment
+	// service masih bisa dikembalikan sebagai JSON
+	payment, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to export payments", "error": err.Error()})
+	}
+
+	res := c.Response()
+	writer := newPaymentRowWriter(format, res)
+	res.Header().Set(echo.HeaderContentType, writer.contentType())
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="payments.`+format+`"`)
+	res.WriteHeader(http.StatusOK)
+
+	if err := writer.writeHeader(); err != nil {
+		return nil
+	}
+
+	for payment != nil {
+		if err := writer.write(payment); err != nil {
+			slog.WarnContext(c.Request().Context(), "export payments: write failed", "error", err)
+			return nil
+		}
+		res.Flush()
+
+		payment, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Header sudah terkirim, cukup log dan putus response
+			slog.ErrorContext(c.Request().Context(), "export payments: stream failed", "error", err)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Email filter payment: selalu email dari JWT, kecuali admin yang boleh
+// memakai query email (kosong = semua user)
+func (h *GatewayHandler) paymentEmail(c echo.Context) (string, bool) {
+	if middleware.IsAdmin(c, h.cfg.AdminEmails) {
+		return c.QueryParam("email"), true
+	}
+	email, ok := c.Get("userEmail").(string)
+	return email, ok && email != ""
+}
+
+// parsePaymentFilter membaca filter dari query string (status, from, to),
+// email dari paymentEmail. from/to menerima RFC3339 atau tanggal YYYY-MM-DD.
+func parsePaymentFilter(c echo.Context, email string) (*proto.PaymentFilter, error) {
+	filter := &proto.PaymentFilter{
+		Email:  email,
+		Status: c.QueryParam("status"),
+	}
+
+	from, err := parseFilterTime(c.QueryParam("from"))
+	if err != nil {
+		return nil, errors.New("invalid from: use RFC3339 or YYYY-MM-DD")
+	}
+	to, err := parseFilterTime(c.QueryParam("to"))
+	if err != nil {
+		return nil, errors.New("invalid to: use RFC3339 or YYYY-MM-DD")
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+
+	if !from.IsZero() {
+		filter.CreatedFrom = timestamppb.New(from)
+	}
+	if !to.IsZero() {
+		filter.CreatedTo = timestamppb.New(to)
+	}
+
+	return filter, nil
+}
+
+func parseFilterTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// paymentRowWriter menulis satu payment per baris ke response
+type paymentRowWriter interface {
+	contentType() string
+	writeHeader() error
+	write(p *proto.Payment) error
+}
+
+func newPaymentRowWriter(format string, w io.Writer) paymentRowWriter {
+	if format == exportFormatCSV {
+		return &csvPaymentWriter{w: csv.NewWriter(w)}
+	}
+	return &ndjsonPaymentWriter{enc: json.NewEncoder(w)}
+}
+
+// Baris export, created_at dalam RFC3339
+type paymentRow struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Amount    Money  `json:"amount"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+func toPaymentRow(p *proto.Payment) paymentRow {
+	row := paymentRow{
+		ID:     p.GetId(),
+		Email:  p.GetEmail(),
+		Amount: fromMoneyPB(p.GetAmount()),
+		Status: p.GetStatus(),
+	}
+	if p.GetCreatedAt() != nil {
+		row.CreatedAt = p.GetCreatedAt().AsTime().Format(time.RFC3339)
+	}
+	return row
+}
+
+type ndjsonPaymentWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonPaymentWriter) contentType() string { return "application/x-ndjson" }
+
+func (w *ndjsonPaymentWriter) writeHeader() error { return nil }
+
+func (w *ndjsonPaymentWriter) write(p *proto.Payment) error {
+	return w.enc.Encode(toPaymentRow(p))
+}
+
+type csvPaymentWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvPaymentWriter) contentType() string { return "text/csv" }
+
+func (w *csvPaymentWriter) writeHeader() error {
+	return w.flush(csvHeader)
+}
+
+func (w *csvPaymentWriter) write(p *proto.Payment) error {
+	row := toPaymentRow(p)
+	return w.flush([]string{
+		row.ID,
+		row.Email,
+		strconv.FormatInt(row.Amount.MinorUnits, 10),
+		row.Amount.Currency,
+		row.Status,
+		row.CreatedAt,
+	})
+}
+
+// Tulis satu record lalu flush buffer csv ke response
+func (w *csvPaymentWriter) flush(record []string) error {
+	if err := w.w.Write(record); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
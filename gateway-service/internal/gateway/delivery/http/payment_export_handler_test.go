@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gateway-service/config"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentEmail(t *testing.T) {
+	h := NewGatewayHandler(&config.Config{AdminEmails: []string{"admin@mail.com"}}, nil, nil)
+	tests := []struct {
+		name      string
+		userEmail string
+		query     string
+		wantEmail string
+		wantOK    bool
+	}{
+		{name: "user biasa selalu email sendiri", userEmail: "buyer@mail.com", query: "?email=other@mail.com", wantEmail: "buyer@mail.com", wantOK: true},
+		{name: "admin memilih email", userEmail: "admin@mail.com", query: "?email=other@mail.com", wantEmail: "other@mail.com", wantOK: true},
+		{name: "admin tanpa email berarti semua", userEmail: "admin@mail.com", wantEmail: "", wantOK: true},
+		{name: "tanpa email di token", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/payments"+tt.query, nil), httptest.NewRecorder())
+			if tt.userEmail != "" {
+				c.Set("userEmail", tt.userEmail)
+			}
+			email, ok := h.paymentEmail(c)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantEmail, email)
+		})
+	}
+}
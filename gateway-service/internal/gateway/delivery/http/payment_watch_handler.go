@@ -0,0 +1,171 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gateway-service/internal/gateway/delivery/http/middleware"
+	"gateway-service/proto"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Interval heartbeat SSE supaya koneksi tidak diputus proxy saat idle
+const sseHeartbeatInterval = 15 * time.Second
+
+// Saran jeda reconnect untuk EventSource di browser (ms)
+const sseRetryMillis = 3000
+
+// Ditutup saat server mulai shutdown. Stream SSE diakhiri supaya Shutdown
+// tidak menunggu sampai deadline, client reconnect dengan Last-Event-ID.
+var sseShutdown = make(chan struct{})
+
+func closeSSEOnShutdown(e *echo.Echo) {
+	var once sync.Once
+	e.Server.RegisterOnShutdown(func() {
+		once.Do(func() { close(sseShutdown) })
+	})
+}
+
+// StreamTicketHandler membuat ticket singkat untuk membuka /payments/watch
+// dari EventSource (query ticket), supaya access token tidak masuk URL.
+// expires_in hanya untuk koneksi pertama, reconnect otomatis EventSource
+// (dengan Last-Event-ID) diterima sampai resume_window. Jika stream ditolak
+// 401, client minta ticket baru lalu buka EventSource lagi.
+func (h *GatewayHandler) StreamTicketHandler(c echo.Context) error {
+	email, ok := c.Get("userEmail").(string)
+	if !ok || email == "" {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	ticket, err := middleware.IssueStreamTicket(h.cfg.JWTSecret.Value(), email, time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to issue stream ticket"})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"ticket":        ticket,
+		"expires_in":    int(middleware.StreamTicketTTL.Seconds()),
+		"resume_window": int(middleware.StreamTicketResumeWindow.Seconds()),
+	})
+}
+
+// WatchPaymentsHandler meneruskan stream WatchPayments sebagai Server-Sent Events.
+// Filter lewat query payment_id, user biasa hanya menerima event payment
+// miliknya, admin boleh memilih email lewat query. Resume memakai header
+// Last-Event-ID (dikirim otomatis oleh EventSource) atau query last_event_id.
+func (h *GatewayHandler) WatchPaymentsHandler(c echo.Context) error {
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "invalid last event id"})
+	}
+
+	email, ok := h.paymentEmail(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	ctx := c.Request().Context()
+	client := h.clients.Payment
+
+	stream, err := client.WatchPayments(ctx, &proto.WatchPaymentsRequest{
+		PaymentId:   c.QueryParam("payment_id"),
+		Email:       email,
+		LastEventId: lastEventID,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Failed to watch payments", "error": err.Error()})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(res, "retry: %d\n\n", sseRetryMillis)
+	res.Flush()
+
+	// Recv blocking, jadi dibaca di goroutine terpisah supaya heartbeat tetap jalan
+	events := make(chan *proto.PaymentEvent)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sseShutdown:
+			return nil
+		case err := <-errs:
+			// stream putus, client akan reconnect dengan Last-Event-ID
+			if ctx.Err() == nil {
+				slog.WarnContext(c.Request().Context(), "watch payments: stream closed", "error", err)
+			}
+			return nil
+		case event := <-events:
+			if err := writeSSEEvent(res, event); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// Ambil last event id dari header Last-Event-ID atau query last_event_id
+func parseLastEventID(c echo.Context) (uint64, error) {
+	value := c.Request().Header.Get("Last-Event-ID")
+	if value == "" {
+		value = c.QueryParam("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// Tulis satu event dengan format SSE (id, event, data)
+func writeSSEEvent(w http.ResponseWriter, event *proto.PaymentEvent) error {
+	data := map[string]any{
+		"id":      event.GetId(),
+		"type":    event.GetType(),
+		"payment": toPaymentRow(event.GetPayment()),
+	}
+	if event.GetOccurredAt() != nil {
+		data["occurred_at"] = event.GetOccurredAt().AsTime().Format(time.RFC3339Nano)
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.GetId(), event.GetType(), body)
+	return err
+}
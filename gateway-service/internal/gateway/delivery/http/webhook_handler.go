@@ -0,0 +1,152 @@
+package http
+
+import (
+	"net/http"
+
+	"gateway-service/proto"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type RegisterWebhookInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+}
+
+// Client ID webhook = email dari JWT, jadi tiap user hanya melihat endpoint miliknya
+func webhookClientID(c echo.Context) (string, bool) {
+	email, ok := c.Get("userEmail").(string)
+	return email, ok && email != ""
+}
+
+// Mapping status gRPC dari webhook service ke HTTP status
+func webhookErrorResponse(c echo.Context, message string, err error) error {
+	code := http.StatusInternalServerError
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.FailedPrecondition:
+		code = http.StatusConflict
+	}
+	return c.JSON(code, echo.Map{"message": message, "error": status.Convert(err).Message()})
+}
+
+func (h *GatewayHandler) RegisterWebhookHandler(c echo.Context) error {
+	clientID, ok := webhookClientID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	var input RegisterWebhookInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Invalid input format"})
+	}
+
+	client := h.clients.Webhook
+	ctx := c.Request().Context()
+
+	res, err := client.RegisterEndpoint(ctx, &proto.RegisterEndpointRequest{
+		ClientId:   clientID,
+		Url:        input.URL,
+		EventTypes: input.EventTypes,
+	})
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to register webhook", err)
+	}
+
+	// secret hanya dikembalikan sekali di sini
+	return c.JSON(http.StatusCreated, res)
+}
+
+func (h *GatewayHandler) ListWebhooksHandler(c echo.Context) error {
+	clientID, ok := webhookClientID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	client := h.clients.Webhook
+	ctx := c.Request().Context()
+
+	res, err := client.ListEndpoints(ctx, &proto.ListEndpointsRequest{ClientId: clientID})
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to fetch webhooks", err)
+	}
+
+	return c.JSON(http.StatusOK, res.Endpoints)
+}
+
+func (h *GatewayHandler) DeleteWebhookHandler(c echo.Context) error {
+	clientID, ok := webhookClientID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	client := h.clients.Webhook
+	ctx := c.Request().Context()
+
+	res, err := client.DeleteEndpoint(ctx, &proto.DeleteEndpointRequest{ClientId: clientID, Id: c.Param("id")})
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to delete webhook", err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *GatewayHandler) ListWebhookDeliveriesHandler(c echo.Context) error {
+	clientID, ok := webhookClientID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	client := h.clients.Webhook
+	ctx := c.Request().Context()
+
+	res, err := client.ListDeliveries(ctx, &proto.ListDeliveriesRequest{
+		ClientId:   clientID,
+		EndpointId: c.QueryParam("endpoint_id"),
+		Status:     c.QueryParam("status"),
+	})
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to fetch webhook deliveries", err)
+	}
+
+	return c.JSON(http.StatusOK, res.Deliveries)
+}
+
+func (h *GatewayHandler) ListWebhookAttemptsHandler(c echo.Context) error {
+	clientID, ok := webhookClientID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	client := h.clients.Webhook
+	ctx := c.Request().Context()
+
+	res, err := client.ListDeliveryAttempts(ctx, &proto.ListDeliveryAttemptsRequest{ClientId: clientID, DeliveryId: c.Param("id")})
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to fetch delivery attempts", err)
+	}
+
+	return c.JSON(http.StatusOK, res.Attempts)
+}
+
+func (h *GatewayHandler) RedeliverWebhookHandler(c echo.Context) error {
+	clientID, ok := webhookClientID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Missing user email in token"})
+	}
+
+	client := h.clients.Webhook
+	ctx := c.Request().Context()
+
+	res, err := client.Redeliver(ctx, &proto.RedeliverRequest{ClientId: clientID, DeliveryId: c.Param("id")})
+	if err != nil {
+		return webhookErrorResponse(c, "Failed to redeliver webhook", err)
+	}
+
+	return c.JSON(http.StatusAccepted, res)
+}
@@ -1,50 +1,79 @@
 package infra
 
 import (
-	"log"
-	"sync"
+	"errors"
+	"fmt"
+	"log/slog"
 
 	"gateway-service/config"
 	"gateway-service/proto"
+	"shared/logging"
+	"shared/metrics"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
-var (
-	authClient    proto.AuthServiceClient
-	paymentClient proto.PaymentServiceClient
-	once          sync.Once
-)
+// GRPCClients berisi client gRPC ke auth dan payment
+type GRPCClients struct {
+	Auth    proto.AuthServiceClient
+	Payment proto.PaymentServiceClient
+	// Webhook service di-host oleh payment-service, pakai koneksi yang sama
+	Webhook proto.WebhookServiceClient
+
+	authConn    *grpc.ClientConn
+	paymentConn *grpc.ClientConn
+}
+
+// NewGRPCClients initializes the gRPC clients for auth and payment services
+func NewGRPCClients(cfg *config.Config) (*GRPCClients, error) {
+	// Connect to Auth Service
+	authConn, err := grpc.Dial(cfg.AuthServiceURL, dialOptions("auth")...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to auth-service: %w", err)
+	}
+	slog.Info("connected to auth-service", "addr", cfg.AuthServiceURL)
+
+	// Connect to Payment Service
+	paymentConn, err := grpc.Dial(cfg.PaymentServiceGRPC, dialOptions("payment")...)
+	if err != nil {
+		authConn.Close()
+		return nil, fmt.Errorf("connect to payment-service: %w", err)
+	}
+	slog.Info("connected to payment-service", "addr", cfg.PaymentServiceGRPC)
+
+	return &GRPCClients{
+		Auth:        proto.NewAuthServiceClient(authConn),
+		Payment:     proto.NewPaymentServiceClient(paymentConn),
+		Webhook:     proto.NewWebhookServiceClient(paymentConn),
+		authConn:    authConn,
+		paymentConn: paymentConn,
+	}, nil
+}
+
+// Option dial bersama, request ID dan trace context dari context ikut ke
+// metadata gRPC, call tercatat di metric upstream per backend
+func dialOptions(backend string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor, metrics.UnaryClientInterceptor(backend)),
+		grpc.WithChainStreamInterceptor(logging.StreamClientInterceptor, metrics.StreamClientInterceptor(backend)),
+	}
+}
 
-// InitGRPCClients initializes the gRPC clients for auth and payment services
-func InitGRPCClients() {
-	once.Do(func() {
-		// Connect to Auth Service
-		authConn, err := grpc.Dial(config.AuthServiceURL, grpc.WithInsecure())
-		if err != nil {
-			log.Fatalf("failed to connect to auth-service: %v", err)
-		}
-		authClient = proto.NewAuthServiceClient(authConn)
-		log.Println("Connected to auth-service")
-
-		// Connect to Payment Service
-		// paymentConn, err := grpc.Dial(config.PaymentServiceURL, grpc.WithInsecure())
-		paymentConn, err := grpc.Dial(config.PaymentServiceGRPC, grpc.WithInsecure())
-
-		if err != nil {
-			log.Fatalf("failed to connect to payment-service: %v", err)
-		}
-		paymentClient = proto.NewPaymentServiceClient(paymentConn)
-		log.Println("Connected to payment-service")
-	})
+// Close menutup koneksi auth dan payment, stream yang masih berjalan
+// (watch payment) ikut berhenti
+func (c *GRPCClients) Close() error {
+	return errors.Join(c.authConn.Close(), c.paymentConn.Close())
 }
 
-// GetAuthClient returns the initialized auth gRPC client
-func GetAuthClient() proto.AuthServiceClient {
-	return authClient
+// AuthConn koneksi auth, dipakai health check grpc.health.v1
+func (c *GRPCClients) AuthConn() *grpc.ClientConn {
+	return c.authConn
 }
 
-// GetPaymentClient returns the initialized payment gRPC client
-func GetPaymentClient() proto.PaymentServiceClient {
-	return paymentClient
+// PaymentConn koneksi payment, dipakai health check grpc.health.v1
+func (c *GRPCClients) PaymentConn() *grpc.ClientConn {
+	return c.paymentConn
 }
@@ -1,15 +1,97 @@
 package infra
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway-service/config"
+	"shared/metrics"
 
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// client ke backend service, traceparent ikut dikirim dan tiap call
+// tercatat sebagai span client
+var httpClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+var tracer = otel.Tracer("gateway-service/internal/gateway/infra")
+
+// span untuk satu request yang diteruskan ke backend
+func startForwardSpan(c echo.Context, name, targetURL string) (context.Context, trace.Span) {
+	return tracer.Start(c.Request().Context(), name, trace.WithAttributes(
+		attribute.String("gateway.target_url", targetURL),
+		attribute.String("http.request.method", c.Request().Method),
+	))
+}
+
+// Forwarder meneruskan request REST ke backend service
+type Forwarder struct {
+	backends []backend
+}
+
+// prefix URL backend untuk label metric
+type backend struct {
+	name string
+	url  string
+}
+
+func NewForwarder(cfg *config.Config) *Forwarder {
+	return &Forwarder{backends: []backend{
+		{name: "shopping", url: cfg.ShoppingServiceURL},
+		{name: "payment", url: cfg.PaymentServiceURL},
+		{name: "payment", url: cfg.PaymentAdminURL},
+		{name: "auth", url: cfg.AuthAdminURL},
+	}}
+}
+
+// metric upstream per backend; untuk stream durasi sampai header diterima
+func (f *Forwarder) observeUpstream(targetURL string, resp *http.Response, err error, start time.Time) {
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.ObserveUpstream(f.backendName(targetURL), status, start)
+}
+
+// nama backend dari prefix URL tujuan, sesuai config
+func (f *Forwarder) backendName(targetURL string) string {
+	for _, b := range f.backends {
+		if strings.HasPrefix(targetURL, b.url) {
+			return b.name
+		}
+	}
+	return "unknown"
+}
+
+// status upstream ke span, error kalau backend tidak bisa dihubungi atau 5xx
+func endForwardSpan(span trace.Span, resp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+}
+
 // ForwardRequest mengirim request ke backend service, lalu kembalikan responsenya ke client
-func ForwardRequest(c echo.Context, targetURL string) error {
-	req, err := http.NewRequest(c.Request().Method, targetURL, c.Request().Body)
+func (f *Forwarder) ForwardRequest(c echo.Context, targetURL string) error {
+	ctx, span := startForwardSpan(c, "ForwardRequest", targetURL)
+	defer span.End()
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, c.Request().Method, targetURL, c.Request().Body)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create request"})
 	}
@@ -19,13 +101,68 @@ func ForwardRequest(c echo.Context, targetURL string) error {
 		req.Header[k] = v
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
+	endForwardSpan(span, resp, err)
+	f.observeUpstream(targetURL, resp, err, start)
 	if err != nil {
 		return c.JSON(http.StatusBadGateway, map[string]string{"message": "Service unreachable"})
 	}
 	defer resp.Body.Close()
 
+	// ETag dibutuhkan client untuk If-Match saat update
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		c.Response().Header().Set("ETag", etag)
+	}
+
 	body, _ := io.ReadAll(resp.Body)
 	return c.Blob(resp.StatusCode, resp.Header.Get("Content-Type"), body)
 }
+
+// StreamRequest sama dengan ForwardRequest, tapi response diteruskan ke
+// client sambil dibaca (untuk download/export besar) tanpa ditampung
+// di memori
+func (f *Forwarder) StreamRequest(c echo.Context, targetURL string) error {
+	ctx, span := startForwardSpan(c, "StreamRequest", targetURL)
+	defer span.End()
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, c.Request().Method, targetURL, c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create request"})
+	}
+
+	for k, v := range c.Request().Header {
+		req.Header[k] = v
+	}
+
+	resp, err := httpClient.Do(req)
+	endForwardSpan(span, resp, err)
+	f.observeUpstream(targetURL, resp, err, start)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, map[string]string{"message": "Service unreachable"})
+	}
+	defer resp.Body.Close()
+
+	res := c.Response()
+	for _, k := range []string{echo.HeaderContentType, echo.HeaderContentDisposition} {
+		if v := resp.Header.Get(k); v != "" {
+			res.Header().Set(k, v)
+		}
+	}
+	res.WriteHeader(resp.StatusCode)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := res.Write(buf[:n]); werr != nil {
+				return nil
+			}
+			res.Flush()
+		}
+		if err != nil {
+			// header sudah terkirim, error upstream hanya memutus response
+			return nil
+		}
+	}
+}
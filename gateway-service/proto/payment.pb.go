@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,20 +22,82 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Nominal uang dalam minor unit (misalnya sen) dan kode ISO-4217
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinorUnits    int64                  `protobuf:"varint,1,opt,name=minor_units,json=minorUnits,proto3" json:"minor_units,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_proto_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetMinorUnits() int64 {
+	if x != nil {
+		return x.MinorUnits
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
 // Data payment yang akan dipakai sebagai response
 type Payment struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
-	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email       string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Amount      *Money                 `protobuf:"bytes,9,opt,name=amount,proto3" json:"amount,omitempty"`
+	Status      string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Method      string                 `protobuf:"bytes,6,opt,name=method,proto3" json:"method,omitempty"`
+	Provider    string                 `protobuf:"bytes,7,opt,name=provider,proto3" json:"provider,omitempty"`
+	ProviderRef string                 `protobuf:"bytes,8,opt,name=provider_ref,json=providerRef,proto3" json:"provider_ref,omitempty"`
+	// diisi kalau payment di-soft delete
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	DeletedBy string                 `protobuf:"bytes,11,opt,name=deleted_by,json=deletedBy,proto3" json:"deleted_by,omitempty"`
+	// refund terlama dulu
+	Refunds       []*Refund `protobuf:"bytes,12,rep,name=refunds,proto3" json:"refunds,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Payment) Reset() {
 	*x = Payment{}
-	mi := &file_proto_payment_proto_msgTypes[0]
+	mi := &file_proto_payment_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -46,7 +109,7 @@ func (x *Payment) String() string {
 func (*Payment) ProtoMessage() {}
 
 func (x *Payment) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_proto_msgTypes[0]
+	mi := &file_proto_payment_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,7 +122,7 @@ func (x *Payment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Payment.ProtoReflect.Descriptor instead.
 func (*Payment) Descriptor() ([]byte, []int) {
-	return file_proto_payment_proto_rawDescGZIP(), []int{0}
+	return file_proto_payment_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *Payment) GetId() string {
@@ -76,11 +139,11 @@ func (x *Payment) GetEmail() string {
 	return ""
 }
 
-func (x *Payment) GetAmount() float64 {
+func (x *Payment) GetAmount() *Money {
 	if x != nil {
 		return x.Amount
 	}
-	return 0
+	return nil
 }
 
 func (x *Payment) GetStatus() string {
@@ -90,81 +153,82 @@ func (x *Payment) GetStatus() string {
 	return ""
 }
 
-// Digunakan saat membuat payment
-type AddPaymentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
-	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Payment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (x *AddPaymentRequest) Reset() {
-	*x = AddPaymentRequest{}
-	mi := &file_proto_payment_proto_msgTypes[1]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Payment) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
 }
 
-func (x *AddPaymentRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Payment) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
 }
 
-func (*AddPaymentRequest) ProtoMessage() {}
-
-func (x *AddPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_proto_msgTypes[1]
+func (x *Payment) GetProviderRef() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.ProviderRef
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use AddPaymentRequest.ProtoReflect.Descriptor instead.
-func (*AddPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_proto_rawDescGZIP(), []int{1}
+func (x *Payment) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
 }
 
-func (x *AddPaymentRequest) GetEmail() string {
+func (x *Payment) GetDeletedBy() string {
 	if x != nil {
-		return x.Email
+		return x.DeletedBy
 	}
 	return ""
 }
 
-func (x *AddPaymentRequest) GetAmount() float64 {
+func (x *Payment) GetRefunds() []*Refund {
 	if x != nil {
-		return x.Amount
+		return x.Refunds
 	}
-	return 0
+	return nil
 }
 
-// Digunakan untuk ambil/hapus payment by ID
-type GetPaymentByIDRequest struct {
+// Satu refund payment, status pending sampai provider selesai memproses
+type Refund struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Amount        *Money                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Reference     string                 `protobuf:"bytes,4,opt,name=reference,proto3" json:"reference,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetPaymentByIDRequest) Reset() {
-	*x = GetPaymentByIDRequest{}
+func (x *Refund) Reset() {
+	*x = Refund{}
 	mi := &file_proto_payment_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetPaymentByIDRequest) String() string {
+func (x *Refund) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetPaymentByIDRequest) ProtoMessage() {}
+func (*Refund) ProtoMessage() {}
 
-func (x *GetPaymentByIDRequest) ProtoReflect() protoreflect.Message {
+func (x *Refund) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_payment_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -176,39 +240,82 @@ func (x *GetPaymentByIDRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetPaymentByIDRequest.ProtoReflect.Descriptor instead.
-func (*GetPaymentByIDRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Refund.ProtoReflect.Descriptor instead.
+func (*Refund) Descriptor() ([]byte, []int) {
 	return file_proto_payment_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *GetPaymentByIDRequest) GetId() string {
+func (x *Refund) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-type DeletePaymentByIDRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+func (x *Refund) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *Refund) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Refund) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *Refund) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Refund) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// Digunakan saat membuat payment
+type AddPaymentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	// currency kosong dianggap IDR
+	Amount *Money `protobuf:"bytes,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// metode pembayaran (card, bank_transfer, ewallet), default card
+	Method string `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	// token kartu, hanya diteruskan ke provider
+	CardToken     string `protobuf:"bytes,5,opt,name=card_token,json=cardToken,proto3" json:"card_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeletePaymentByIDRequest) Reset() {
-	*x = DeletePaymentByIDRequest{}
+func (x *AddPaymentRequest) Reset() {
+	*x = AddPaymentRequest{}
 	mi := &file_proto_payment_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeletePaymentByIDRequest) String() string {
+func (x *AddPaymentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeletePaymentByIDRequest) ProtoMessage() {}
+func (*AddPaymentRequest) ProtoMessage() {}
 
-func (x *DeletePaymentByIDRequest) ProtoReflect() protoreflect.Message {
+func (x *AddPaymentRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_payment_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -220,39 +327,72 @@ func (x *DeletePaymentByIDRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeletePaymentByIDRequest.ProtoReflect.Descriptor instead.
-func (*DeletePaymentByIDRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddPaymentRequest.ProtoReflect.Descriptor instead.
+func (*AddPaymentRequest) Descriptor() ([]byte, []int) {
 	return file_proto_payment_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *DeletePaymentByIDRequest) GetId() string {
+func (x *AddPaymentRequest) GetEmail() string {
 	if x != nil {
-		return x.Id
+		return x.Email
 	}
 	return ""
 }
 
-// Digunakan untuk ambil semua data
-type GetAllPaymentsRequest struct {
+func (x *AddPaymentRequest) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *AddPaymentRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AddPaymentRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AddPaymentRequest) GetCardToken() string {
+	if x != nil {
+		return x.CardToken
+	}
+	return ""
+}
+
+// Refund penuh atau parsial, amount kosong berarti sisa nominal.
+// reference wajib diisi, reference yang sama tidak di-refund dua kali.
+type RefundPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Amount        *Money                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Reference     string                 `protobuf:"bytes,4,opt,name=reference,proto3" json:"reference,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAllPaymentsRequest) Reset() {
-	*x = GetAllPaymentsRequest{}
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
 	mi := &file_proto_payment_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAllPaymentsRequest) String() string {
+func (x *RefundPaymentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAllPaymentsRequest) ProtoMessage() {}
+func (*RefundPaymentRequest) ProtoMessage() {}
 
-func (x *GetAllPaymentsRequest) ProtoReflect() protoreflect.Message {
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_payment_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -264,32 +404,61 @@ func (x *GetAllPaymentsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAllPaymentsRequest.ProtoReflect.Descriptor instead.
-func (*GetAllPaymentsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
 	return file_proto_payment_proto_rawDescGZIP(), []int{4}
 }
 
-type GetAllPaymentsResponse struct {
+func (x *RefundPaymentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *RefundPaymentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+type RefundPaymentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Payments      []*Payment             `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	Refund        *Refund                `protobuf:"bytes,2,opt,name=refund,proto3" json:"refund,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAllPaymentsResponse) Reset() {
-	*x = GetAllPaymentsResponse{}
+func (x *RefundPaymentResponse) Reset() {
+	*x = RefundPaymentResponse{}
 	mi := &file_proto_payment_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAllPaymentsResponse) String() string {
+func (x *RefundPaymentResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAllPaymentsResponse) ProtoMessage() {}
+func (*RefundPaymentResponse) ProtoMessage() {}
 
-func (x *GetAllPaymentsResponse) ProtoReflect() protoreflect.Message {
+func (x *RefundPaymentResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_payment_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -301,44 +470,927 @@ func (x *GetAllPaymentsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAllPaymentsResponse.ProtoReflect.Descriptor instead.
-func (*GetAllPaymentsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RefundPaymentResponse.ProtoReflect.Descriptor instead.
+func (*RefundPaymentResponse) Descriptor() ([]byte, []int) {
 	return file_proto_payment_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetAllPaymentsResponse) GetPayments() []*Payment {
+func (x *RefundPaymentResponse) GetPayment() *Payment {
 	if x != nil {
-		return x.Payments
+		return x.Payment
 	}
 	return nil
 }
 
-var File_proto_payment_proto protoreflect.FileDescriptor
+func (x *RefundPaymentResponse) GetRefund() *Refund {
+	if x != nil {
+		return x.Refund
+	}
+	return nil
+}
 
-const file_proto_payment_proto_rawDesc = "" +
-	"\n" +
-	"\x13proto/payment.proto\x12\apayment\"_\n" +
-	"\aPayment\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05email\x18\x02 \x01(\tR\x05email\x12\x16\n" +
-	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\"A\n" +
-	"\x11AddPaymentRequest\x12\x14\n" +
-	"\x05email\x18\x01 \x01(\tR\x05email\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x01R\x06amount\"'\n" +
-	"\x15GetPaymentByIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"*\n" +
-	"\x18DeletePaymentByIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
-	"\x15GetAllPaymentsRequest\"F\n" +
-	"\x16GetAllPaymentsResponse\x12,\n" +
-	"\bpayments\x18\x01 \x03(\v2\x10.payment.PaymentR\bpayments2\xad\x02\n" +
+// Digunakan untuk ambil/hapus payment by ID
+type GetPaymentByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaymentByIDRequest) Reset() {
+	*x = GetPaymentByIDRequest{}
+	mi := &file_proto_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentByIDRequest) ProtoMessage() {}
+
+func (x *GetPaymentByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentByIDRequest.ProtoReflect.Descriptor instead.
+func (*GetPaymentByIDRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPaymentByIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeletePaymentByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePaymentByIDRequest) Reset() {
+	*x = DeletePaymentByIDRequest{}
+	mi := &file_proto_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePaymentByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePaymentByIDRequest) ProtoMessage() {}
+
+func (x *DeletePaymentByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePaymentByIDRequest.ProtoReflect.Descriptor instead.
+func (*DeletePaymentByIDRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeletePaymentByIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Filter yang dipakai list dan stream payment, field kosong diabaikan
+type PaymentFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedFrom   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentFilter) Reset() {
+	*x = PaymentFilter{}
+	mi := &file_proto_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentFilter) ProtoMessage() {}
+
+func (x *PaymentFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentFilter.ProtoReflect.Descriptor instead.
+func (*PaymentFilter) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PaymentFilter) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetCreatedFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return nil
+}
+
+func (x *PaymentFilter) GetCreatedTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return nil
+}
+
+// Digunakan untuk ambil semua data
+// page_size 0 berarti semua payment, page_token dari next_page_token
+// response sebelumnya
+type GetAllPaymentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *PaymentFilter         `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	PageSize      int64                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllPaymentsRequest) Reset() {
+	*x = GetAllPaymentsRequest{}
+	mi := &file_proto_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllPaymentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllPaymentsRequest) ProtoMessage() {}
+
+func (x *GetAllPaymentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllPaymentsRequest.ProtoReflect.Descriptor instead.
+func (*GetAllPaymentsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetAllPaymentsRequest) GetFilter() *PaymentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *GetAllPaymentsRequest) GetPageSize() int64 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetAllPaymentsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// next_page_token kosong kalau sudah halaman terakhir
+type GetAllPaymentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payments      []*Payment             `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllPaymentsResponse) Reset() {
+	*x = GetAllPaymentsResponse{}
+	mi := &file_proto_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllPaymentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllPaymentsResponse) ProtoMessage() {}
+
+func (x *GetAllPaymentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllPaymentsResponse.ProtoReflect.Descriptor instead.
+func (*GetAllPaymentsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetAllPaymentsResponse) GetPayments() []*Payment {
+	if x != nil {
+		return x.Payments
+	}
+	return nil
+}
+
+func (x *GetAllPaymentsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Digunakan untuk export payment secara streaming
+type StreamPaymentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *PaymentFilter         `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamPaymentsRequest) Reset() {
+	*x = StreamPaymentsRequest{}
+	mi := &file_proto_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamPaymentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamPaymentsRequest) ProtoMessage() {}
+
+func (x *StreamPaymentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamPaymentsRequest.ProtoReflect.Descriptor instead.
+func (*StreamPaymentsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StreamPaymentsRequest) GetFilter() *PaymentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+// Digunakan untuk watch perubahan payment, field kosong diabaikan.
+// last_event_id dipakai untuk resume setelah reconnect, 0 berarti hanya
+// event baru tanpa replay.
+type WatchPaymentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	LastEventId   uint64                 `protobuf:"varint,3,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchPaymentsRequest) Reset() {
+	*x = WatchPaymentsRequest{}
+	mi := &file_proto_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchPaymentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPaymentsRequest) ProtoMessage() {}
+
+func (x *WatchPaymentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPaymentsRequest.ProtoReflect.Descriptor instead.
+func (*WatchPaymentsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchPaymentsRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *WatchPaymentsRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *WatchPaymentsRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+// Event perubahan state payment
+type PaymentEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Payment       *Payment               `protobuf:"bytes,3,opt,name=payment,proto3" json:"payment,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentEvent) Reset() {
+	*x = PaymentEvent{}
+	mi := &file_proto_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentEvent) ProtoMessage() {}
+
+func (x *PaymentEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentEvent.ProtoReflect.Descriptor instead.
+func (*PaymentEvent) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PaymentEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PaymentEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PaymentEvent) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *PaymentEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+// Digunakan admin untuk melihat payment yang di-soft delete
+type ListDeletedPaymentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeletedPaymentsRequest) Reset() {
+	*x = ListDeletedPaymentsRequest{}
+	mi := &file_proto_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeletedPaymentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeletedPaymentsRequest) ProtoMessage() {}
+
+func (x *ListDeletedPaymentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeletedPaymentsRequest.ProtoReflect.Descriptor instead.
+func (*ListDeletedPaymentsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{14}
+}
+
+type RestorePaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestorePaymentRequest) Reset() {
+	*x = RestorePaymentRequest{}
+	mi := &file_proto_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestorePaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestorePaymentRequest) ProtoMessage() {}
+
+func (x *RestorePaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestorePaymentRequest.ProtoReflect.Descriptor instead.
+func (*RestorePaymentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RestorePaymentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Filter audit log, payment_id kosong berarti semua payment
+type ListAuditLogsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	// default 100, maksimal 500
+	Limit         int64 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditLogsRequest) Reset() {
+	*x = ListAuditLogsRequest{}
+	mi := &file_proto_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditLogsRequest) ProtoMessage() {}
+
+func (x *ListAuditLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditLogsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListAuditLogsRequest) GetPaymentId() string {
+	if x != nil {
+		return x.PaymentId
+	}
+	return ""
+}
+
+func (x *ListAuditLogsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Nilai field sebelum dan sesudah perubahan dalam JSON, kosong kalau tidak ada
+type AuditChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Before        string                 `protobuf:"bytes,1,opt,name=before,proto3" json:"before,omitempty"`
+	After         string                 `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditChange) Reset() {
+	*x = AuditChange{}
+	mi := &file_proto_payment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditChange) ProtoMessage() {}
+
+func (x *AuditChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditChange.ProtoReflect.Descriptor instead.
+func (*AuditChange) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AuditChange) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *AuditChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+// Satu perubahan payment (create, update, delete, restore)
+type AuditLog struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Id            string                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Resource      string                  `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	ResourceId    string                  `protobuf:"bytes,3,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	Action        string                  `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Actor         string                  `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	RequestId     string                  `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Changes       map[string]*AuditChange `protobuf:"bytes,7,rep,name=changes,proto3" json:"changes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAt     *timestamppb.Timestamp  `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditLog) Reset() {
+	*x = AuditLog{}
+	mi := &file_proto_payment_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditLog) ProtoMessage() {}
+
+func (x *AuditLog) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditLog.ProtoReflect.Descriptor instead.
+func (*AuditLog) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AuditLog) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AuditLog) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *AuditLog) GetResourceId() string {
+	if x != nil {
+		return x.ResourceId
+	}
+	return ""
+}
+
+func (x *AuditLog) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditLog) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditLog) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *AuditLog) GetChanges() map[string]*AuditChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *AuditLog) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListAuditLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Logs          []*AuditLog            `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditLogsResponse) Reset() {
+	*x = ListAuditLogsResponse{}
+	mi := &file_proto_payment_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditLogsResponse) ProtoMessage() {}
+
+func (x *ListAuditLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditLogsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditLogsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListAuditLogsResponse) GetLogs() []*AuditLog {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+var File_proto_payment_proto protoreflect.FileDescriptor
+
+const file_proto_payment_proto_rawDesc = "" +
+	"\n" +
+	"\x13proto/payment.proto\x12\apayment\x1a\x1fgoogle/protobuf/timestamp.proto\"D\n" +
+	"\x05Money\x12\x1f\n" +
+	"\vminor_units\x18\x01 \x01(\x03R\n" +
+	"minorUnits\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"\x8c\x03\n" +
+	"\aPayment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12&\n" +
+	"\x06amount\x18\t \x01(\v2\x0e.payment.MoneyR\x06amount\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06method\x18\x06 \x01(\tR\x06method\x12\x1a\n" +
+	"\bprovider\x18\a \x01(\tR\bprovider\x12!\n" +
+	"\fprovider_ref\x18\b \x01(\tR\vproviderRef\x129\n" +
+	"\n" +
+	"deleted_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_by\x18\v \x01(\tR\tdeletedBy\x12)\n" +
+	"\arefunds\x18\f \x03(\v2\x0f.payment.RefundR\arefundsJ\x04\b\x03\x10\x04\"\xc9\x01\n" +
+	"\x06Refund\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\x06amount\x18\x02 \x01(\v2\x0e.payment.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1c\n" +
+	"\treference\x18\x04 \x01(\tR\treference\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa6\x01\n" +
+	"\x11AddPaymentRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12&\n" +
+	"\x06amount\x18\x06 \x01(\v2\x0e.payment.MoneyR\x06amount\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x1d\n" +
+	"\n" +
+	"card_token\x18\x05 \x01(\tR\tcardTokenJ\x04\b\x02\x10\x03\"\x84\x01\n" +
+	"\x14RefundPaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\x06amount\x18\x02 \x01(\v2\x0e.payment.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1c\n" +
+	"\treference\x18\x04 \x01(\tR\treference\"l\n" +
+	"\x15RefundPaymentResponse\x12*\n" +
+	"\apayment\x18\x01 \x01(\v2\x10.payment.PaymentR\apayment\x12'\n" +
+	"\x06refund\x18\x02 \x01(\v2\x0f.payment.RefundR\x06refund\"'\n" +
+	"\x15GetPaymentByIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"*\n" +
+	"\x18DeletePaymentByIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xb7\x01\n" +
+	"\rPaymentFilter\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12=\n" +
+	"\fcreated_from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedFrom\x129\n" +
+	"\n" +
+	"created_to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedTo\"\x83\x01\n" +
+	"\x15GetAllPaymentsRequest\x12.\n" +
+	"\x06filter\x18\x01 \x01(\v2\x16.payment.PaymentFilterR\x06filter\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x03R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"n\n" +
+	"\x16GetAllPaymentsResponse\x12,\n" +
+	"\bpayments\x18\x01 \x03(\v2\x10.payment.PaymentR\bpayments\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"G\n" +
+	"\x15StreamPaymentsRequest\x12.\n" +
+	"\x06filter\x18\x01 \x01(\v2\x16.payment.PaymentFilterR\x06filter\"o\n" +
+	"\x14WatchPaymentsRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\"\n" +
+	"\rlast_event_id\x18\x03 \x01(\x04R\vlastEventId\"\x9b\x01\n" +
+	"\fPaymentEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12*\n" +
+	"\apayment\x18\x03 \x01(\v2\x10.payment.PaymentR\apayment\x12;\n" +
+	"\voccurred_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\x1c\n" +
+	"\x1aListDeletedPaymentsRequest\"'\n" +
+	"\x15RestorePaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"K\n" +
+	"\x14ListAuditLogsRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\";\n" +
+	"\vAuditChange\x12\x16\n" +
+	"\x06before\x18\x01 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x02 \x01(\tR\x05after\"\xeb\x02\n" +
+	"\bAuditLog\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x1f\n" +
+	"\vresource_id\x18\x03 \x01(\tR\n" +
+	"resourceId\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x14\n" +
+	"\x05actor\x18\x05 \x01(\tR\x05actor\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\x128\n" +
+	"\achanges\x18\a \x03(\v2\x1e.payment.AuditLog.ChangesEntryR\achanges\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1aP\n" +
+	"\fChangesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.payment.AuditChangeR\x05value:\x028\x01\">\n" +
+	"\x15ListAuditLogsResponse\x12%\n" +
+	"\x04logs\x18\x01 \x03(\v2\x11.payment.AuditLogR\x04logs2\xfd\x05\n" +
 	"\x0ePaymentService\x12:\n" +
 	"\n" +
 	"AddPayment\x12\x1a.payment.AddPaymentRequest\x1a\x10.payment.Payment\x12B\n" +
 	"\x0eGetPaymentByID\x12\x1e.payment.GetPaymentByIDRequest\x1a\x10.payment.Payment\x12H\n" +
-	"\x11DeletePaymentByID\x12!.payment.DeletePaymentByIDRequest\x1a\x10.payment.Payment\x12Q\n" +
-	"\x0eGetAllPayments\x12\x1e.payment.GetAllPaymentsRequest\x1a\x1f.payment.GetAllPaymentsResponseB\bZ\x06/protob\x06proto3"
+	"\x11DeletePaymentByID\x12!.payment.DeletePaymentByIDRequest\x1a\x10.payment.Payment\x12N\n" +
+	"\rRefundPayment\x12\x1d.payment.RefundPaymentRequest\x1a\x1e.payment.RefundPaymentResponse\x12Q\n" +
+	"\x0eGetAllPayments\x12\x1e.payment.GetAllPaymentsRequest\x1a\x1f.payment.GetAllPaymentsResponse\x12D\n" +
+	"\x0eStreamPayments\x12\x1e.payment.StreamPaymentsRequest\x1a\x10.payment.Payment0\x01\x12G\n" +
+	"\rWatchPayments\x12\x1d.payment.WatchPaymentsRequest\x1a\x15.payment.PaymentEvent0\x01\x12[\n" +
+	"\x13ListDeletedPayments\x12#.payment.ListDeletedPaymentsRequest\x1a\x1f.payment.GetAllPaymentsResponse\x12B\n" +
+	"\x0eRestorePayment\x12\x1e.payment.RestorePaymentRequest\x1a\x10.payment.Payment\x12N\n" +
+	"\rListAuditLogs\x12\x1d.payment.ListAuditLogsRequest\x1a\x1e.payment.ListAuditLogsResponseB\bZ\x06/protob\x06proto3"
 
 var (
 	file_proto_payment_proto_rawDescOnce sync.Once
@@ -352,30 +1404,78 @@ func file_proto_payment_proto_rawDescGZIP() []byte {
 	return file_proto_payment_proto_rawDescData
 }
 
-var file_proto_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_proto_payment_proto_goTypes = []any{
-	(*Payment)(nil),                  // 0: payment.Payment
-	(*AddPaymentRequest)(nil),        // 1: payment.AddPaymentRequest
-	(*GetPaymentByIDRequest)(nil),    // 2: payment.GetPaymentByIDRequest
-	(*DeletePaymentByIDRequest)(nil), // 3: payment.DeletePaymentByIDRequest
-	(*GetAllPaymentsRequest)(nil),    // 4: payment.GetAllPaymentsRequest
-	(*GetAllPaymentsResponse)(nil),   // 5: payment.GetAllPaymentsResponse
+	(*Money)(nil),                      // 0: payment.Money
+	(*Payment)(nil),                    // 1: payment.Payment
+	(*Refund)(nil),                     // 2: payment.Refund
+	(*AddPaymentRequest)(nil),          // 3: payment.AddPaymentRequest
+	(*RefundPaymentRequest)(nil),       // 4: payment.RefundPaymentRequest
+	(*RefundPaymentResponse)(nil),      // 5: payment.RefundPaymentResponse
+	(*GetPaymentByIDRequest)(nil),      // 6: payment.GetPaymentByIDRequest
+	(*DeletePaymentByIDRequest)(nil),   // 7: payment.DeletePaymentByIDRequest
+	(*PaymentFilter)(nil),              // 8: payment.PaymentFilter
+	(*GetAllPaymentsRequest)(nil),      // 9: payment.GetAllPaymentsRequest
+	(*GetAllPaymentsResponse)(nil),     // 10: payment.GetAllPaymentsResponse
+	(*StreamPaymentsRequest)(nil),      // 11: payment.StreamPaymentsRequest
+	(*WatchPaymentsRequest)(nil),       // 12: payment.WatchPaymentsRequest
+	(*PaymentEvent)(nil),               // 13: payment.PaymentEvent
+	(*ListDeletedPaymentsRequest)(nil), // 14: payment.ListDeletedPaymentsRequest
+	(*RestorePaymentRequest)(nil),      // 15: payment.RestorePaymentRequest
+	(*ListAuditLogsRequest)(nil),       // 16: payment.ListAuditLogsRequest
+	(*AuditChange)(nil),                // 17: payment.AuditChange
+	(*AuditLog)(nil),                   // 18: payment.AuditLog
+	(*ListAuditLogsResponse)(nil),      // 19: payment.ListAuditLogsResponse
+	nil,                                // 20: payment.AuditLog.ChangesEntry
+	(*timestamppb.Timestamp)(nil),      // 21: google.protobuf.Timestamp
 }
 var file_proto_payment_proto_depIdxs = []int32{
-	0, // 0: payment.GetAllPaymentsResponse.payments:type_name -> payment.Payment
-	1, // 1: payment.PaymentService.AddPayment:input_type -> payment.AddPaymentRequest
-	2, // 2: payment.PaymentService.GetPaymentByID:input_type -> payment.GetPaymentByIDRequest
-	3, // 3: payment.PaymentService.DeletePaymentByID:input_type -> payment.DeletePaymentByIDRequest
-	4, // 4: payment.PaymentService.GetAllPayments:input_type -> payment.GetAllPaymentsRequest
-	0, // 5: payment.PaymentService.AddPayment:output_type -> payment.Payment
-	0, // 6: payment.PaymentService.GetPaymentByID:output_type -> payment.Payment
-	0, // 7: payment.PaymentService.DeletePaymentByID:output_type -> payment.Payment
-	5, // 8: payment.PaymentService.GetAllPayments:output_type -> payment.GetAllPaymentsResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: payment.Payment.amount:type_name -> payment.Money
+	21, // 1: payment.Payment.created_at:type_name -> google.protobuf.Timestamp
+	21, // 2: payment.Payment.deleted_at:type_name -> google.protobuf.Timestamp
+	2,  // 3: payment.Payment.refunds:type_name -> payment.Refund
+	0,  // 4: payment.Refund.amount:type_name -> payment.Money
+	21, // 5: payment.Refund.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 6: payment.AddPaymentRequest.amount:type_name -> payment.Money
+	0,  // 7: payment.RefundPaymentRequest.amount:type_name -> payment.Money
+	1,  // 8: payment.RefundPaymentResponse.payment:type_name -> payment.Payment
+	2,  // 9: payment.RefundPaymentResponse.refund:type_name -> payment.Refund
+	21, // 10: payment.PaymentFilter.created_from:type_name -> google.protobuf.Timestamp
+	21, // 11: payment.PaymentFilter.created_to:type_name -> google.protobuf.Timestamp
+	8,  // 12: payment.GetAllPaymentsRequest.filter:type_name -> payment.PaymentFilter
+	1,  // 13: payment.GetAllPaymentsResponse.payments:type_name -> payment.Payment
+	8,  // 14: payment.StreamPaymentsRequest.filter:type_name -> payment.PaymentFilter
+	1,  // 15: payment.PaymentEvent.payment:type_name -> payment.Payment
+	21, // 16: payment.PaymentEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	20, // 17: payment.AuditLog.changes:type_name -> payment.AuditLog.ChangesEntry
+	21, // 18: payment.AuditLog.created_at:type_name -> google.protobuf.Timestamp
+	18, // 19: payment.ListAuditLogsResponse.logs:type_name -> payment.AuditLog
+	17, // 20: payment.AuditLog.ChangesEntry.value:type_name -> payment.AuditChange
+	3,  // 21: payment.PaymentService.AddPayment:input_type -> payment.AddPaymentRequest
+	6,  // 22: payment.PaymentService.GetPaymentByID:input_type -> payment.GetPaymentByIDRequest
+	7,  // 23: payment.PaymentService.DeletePaymentByID:input_type -> payment.DeletePaymentByIDRequest
+	4,  // 24: payment.PaymentService.RefundPayment:input_type -> payment.RefundPaymentRequest
+	9,  // 25: payment.PaymentService.GetAllPayments:input_type -> payment.GetAllPaymentsRequest
+	11, // 26: payment.PaymentService.StreamPayments:input_type -> payment.StreamPaymentsRequest
+	12, // 27: payment.PaymentService.WatchPayments:input_type -> payment.WatchPaymentsRequest
+	14, // 28: payment.PaymentService.ListDeletedPayments:input_type -> payment.ListDeletedPaymentsRequest
+	15, // 29: payment.PaymentService.RestorePayment:input_type -> payment.RestorePaymentRequest
+	16, // 30: payment.PaymentService.ListAuditLogs:input_type -> payment.ListAuditLogsRequest
+	1,  // 31: payment.PaymentService.AddPayment:output_type -> payment.Payment
+	1,  // 32: payment.PaymentService.GetPaymentByID:output_type -> payment.Payment
+	1,  // 33: payment.PaymentService.DeletePaymentByID:output_type -> payment.Payment
+	5,  // 34: payment.PaymentService.RefundPayment:output_type -> payment.RefundPaymentResponse
+	10, // 35: payment.PaymentService.GetAllPayments:output_type -> payment.GetAllPaymentsResponse
+	1,  // 36: payment.PaymentService.StreamPayments:output_type -> payment.Payment
+	13, // 37: payment.PaymentService.WatchPayments:output_type -> payment.PaymentEvent
+	10, // 38: payment.PaymentService.ListDeletedPayments:output_type -> payment.GetAllPaymentsResponse
+	1,  // 39: payment.PaymentService.RestorePayment:output_type -> payment.Payment
+	19, // 40: payment.PaymentService.ListAuditLogs:output_type -> payment.ListAuditLogsResponse
+	31, // [31:41] is the sub-list for method output_type
+	21, // [21:31] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_proto_payment_proto_init() }
@@ -389,7 +1489,7 @@ func file_proto_payment_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payment_proto_rawDesc), len(file_proto_payment_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,10 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PaymentService_AddPayment_FullMethodName        = "/payment.PaymentService/AddPayment"
-	PaymentService_GetPaymentByID_FullMethodName    = "/payment.PaymentService/GetPaymentByID"
-	PaymentService_DeletePaymentByID_FullMethodName = "/payment.PaymentService/DeletePaymentByID"
-	PaymentService_GetAllPayments_FullMethodName    = "/payment.PaymentService/GetAllPayments"
+	PaymentService_AddPayment_FullMethodName          = "/payment.PaymentService/AddPayment"
+	PaymentService_GetPaymentByID_FullMethodName      = "/payment.PaymentService/GetPaymentByID"
+	PaymentService_DeletePaymentByID_FullMethodName   = "/payment.PaymentService/DeletePaymentByID"
+	PaymentService_RefundPayment_FullMethodName       = "/payment.PaymentService/RefundPayment"
+	PaymentService_GetAllPayments_FullMethodName      = "/payment.PaymentService/GetAllPayments"
+	PaymentService_StreamPayments_FullMethodName      = "/payment.PaymentService/StreamPayments"
+	PaymentService_WatchPayments_FullMethodName       = "/payment.PaymentService/WatchPayments"
+	PaymentService_ListDeletedPayments_FullMethodName = "/payment.PaymentService/ListDeletedPayments"
+	PaymentService_RestorePayment_FullMethodName      = "/payment.PaymentService/RestorePayment"
+	PaymentService_ListAuditLogs_FullMethodName       = "/payment.PaymentService/ListAuditLogs"
 )
 
 // PaymentServiceClient is the client API for PaymentService service.
@@ -34,7 +40,13 @@ type PaymentServiceClient interface {
 	AddPayment(ctx context.Context, in *AddPaymentRequest, opts ...grpc.CallOption) (*Payment, error)
 	GetPaymentByID(ctx context.Context, in *GetPaymentByIDRequest, opts ...grpc.CallOption) (*Payment, error)
 	DeletePaymentByID(ctx context.Context, in *DeletePaymentByIDRequest, opts ...grpc.CallOption) (*Payment, error)
+	RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error)
 	GetAllPayments(ctx context.Context, in *GetAllPaymentsRequest, opts ...grpc.CallOption) (*GetAllPaymentsResponse, error)
+	StreamPayments(ctx context.Context, in *StreamPaymentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Payment], error)
+	WatchPayments(ctx context.Context, in *WatchPaymentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PaymentEvent], error)
+	ListDeletedPayments(ctx context.Context, in *ListDeletedPaymentsRequest, opts ...grpc.CallOption) (*GetAllPaymentsResponse, error)
+	RestorePayment(ctx context.Context, in *RestorePaymentRequest, opts ...grpc.CallOption) (*Payment, error)
+	ListAuditLogs(ctx context.Context, in *ListAuditLogsRequest, opts ...grpc.CallOption) (*ListAuditLogsResponse, error)
 }
 
 type paymentServiceClient struct {
@@ -75,6 +87,16 @@ func (c *paymentServiceClient) DeletePaymentByID(ctx context.Context, in *Delete
 	return out, nil
 }
 
+func (c *paymentServiceClient) RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefundPaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_RefundPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentServiceClient) GetAllPayments(ctx context.Context, in *GetAllPaymentsRequest, opts ...grpc.CallOption) (*GetAllPaymentsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAllPaymentsResponse)
@@ -85,6 +107,74 @@ func (c *paymentServiceClient) GetAllPayments(ctx context.Context, in *GetAllPay
 	return out, nil
 }
 
+func (c *paymentServiceClient) StreamPayments(ctx context.Context, in *StreamPaymentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Payment], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PaymentService_ServiceDesc.Streams[0], PaymentService_StreamPayments_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamPaymentsRequest, Payment]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_StreamPaymentsClient = grpc.ServerStreamingClient[Payment]
+
+func (c *paymentServiceClient) WatchPayments(ctx context.Context, in *WatchPaymentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PaymentEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PaymentService_ServiceDesc.Streams[1], PaymentService_WatchPayments_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchPaymentsRequest, PaymentEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_WatchPaymentsClient = grpc.ServerStreamingClient[PaymentEvent]
+
+func (c *paymentServiceClient) ListDeletedPayments(ctx context.Context, in *ListDeletedPaymentsRequest, opts ...grpc.CallOption) (*GetAllPaymentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAllPaymentsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListDeletedPayments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) RestorePayment(ctx context.Context, in *RestorePaymentRequest, opts ...grpc.CallOption) (*Payment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Payment)
+	err := c.cc.Invoke(ctx, PaymentService_RestorePayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListAuditLogs(ctx context.Context, in *ListAuditLogsRequest, opts ...grpc.CallOption) (*ListAuditLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuditLogsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListAuditLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServiceServer is the server API for PaymentService service.
 // All implementations must embed UnimplementedPaymentServiceServer
 // for forward compatibility.
@@ -94,7 +184,13 @@ type PaymentServiceServer interface {
 	AddPayment(context.Context, *AddPaymentRequest) (*Payment, error)
 	GetPaymentByID(context.Context, *GetPaymentByIDRequest) (*Payment, error)
 	DeletePaymentByID(context.Context, *DeletePaymentByIDRequest) (*Payment, error)
+	RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error)
 	GetAllPayments(context.Context, *GetAllPaymentsRequest) (*GetAllPaymentsResponse, error)
+	StreamPayments(*StreamPaymentsRequest, grpc.ServerStreamingServer[Payment]) error
+	WatchPayments(*WatchPaymentsRequest, grpc.ServerStreamingServer[PaymentEvent]) error
+	ListDeletedPayments(context.Context, *ListDeletedPaymentsRequest) (*GetAllPaymentsResponse, error)
+	RestorePayment(context.Context, *RestorePaymentRequest) (*Payment, error)
+	ListAuditLogs(context.Context, *ListAuditLogsRequest) (*ListAuditLogsResponse, error)
 	mustEmbedUnimplementedPaymentServiceServer()
 }
 
@@ -114,9 +210,27 @@ func (UnimplementedPaymentServiceServer) GetPaymentByID(context.Context, *GetPay
 func (UnimplementedPaymentServiceServer) DeletePaymentByID(context.Context, *DeletePaymentByIDRequest) (*Payment, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeletePaymentByID not implemented")
 }
+func (UnimplementedPaymentServiceServer) RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
 func (UnimplementedPaymentServiceServer) GetAllPayments(context.Context, *GetAllPaymentsRequest) (*GetAllPaymentsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllPayments not implemented")
 }
+func (UnimplementedPaymentServiceServer) StreamPayments(*StreamPaymentsRequest, grpc.ServerStreamingServer[Payment]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPayments not implemented")
+}
+func (UnimplementedPaymentServiceServer) WatchPayments(*WatchPaymentsRequest, grpc.ServerStreamingServer[PaymentEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPayments not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListDeletedPayments(context.Context, *ListDeletedPaymentsRequest) (*GetAllPaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedPayments not implemented")
+}
+func (UnimplementedPaymentServiceServer) RestorePayment(context.Context, *RestorePaymentRequest) (*Payment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestorePayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListAuditLogs(context.Context, *ListAuditLogsRequest) (*ListAuditLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditLogs not implemented")
+}
 func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
 func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
 
@@ -192,6 +306,24 @@ func _PaymentService_DeletePaymentByID_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RefundPayment(ctx, req.(*RefundPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentService_GetAllPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAllPaymentsRequest)
 	if err := dec(in); err != nil {
@@ -210,6 +342,82 @@ func _PaymentService_GetAllPayments_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_StreamPayments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamPaymentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaymentServiceServer).StreamPayments(m, &grpc.GenericServerStream[StreamPaymentsRequest, Payment]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_StreamPaymentsServer = grpc.ServerStreamingServer[Payment]
+
+func _PaymentService_WatchPayments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPaymentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaymentServiceServer).WatchPayments(m, &grpc.GenericServerStream[WatchPaymentsRequest, PaymentEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_WatchPaymentsServer = grpc.ServerStreamingServer[PaymentEvent]
+
+func _PaymentService_ListDeletedPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedPaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListDeletedPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListDeletedPayments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListDeletedPayments(ctx, req.(*ListDeletedPaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_RestorePayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestorePaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RestorePayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RestorePayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RestorePayment(ctx, req.(*RestorePaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListAuditLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListAuditLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListAuditLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListAuditLogs(ctx, req.(*ListAuditLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,11 +437,38 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeletePaymentByID",
 			Handler:    _PaymentService_DeletePaymentByID_Handler,
 		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _PaymentService_RefundPayment_Handler,
+		},
 		{
 			MethodName: "GetAllPayments",
 			Handler:    _PaymentService_GetAllPayments_Handler,
 		},
+		{
+			MethodName: "ListDeletedPayments",
+			Handler:    _PaymentService_ListDeletedPayments_Handler,
+		},
+		{
+			MethodName: "RestorePayment",
+			Handler:    _PaymentService_RestorePayment_Handler,
+		},
+		{
+			MethodName: "ListAuditLogs",
+			Handler:    _PaymentService_ListAuditLogs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPayments",
+			Handler:       _PaymentService_StreamPayments_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchPayments",
+			Handler:       _PaymentService_WatchPayments_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/payment.proto",
 }
@@ -1,10 +1,19 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
 	"payment-service/config"
+	"payment-service/internal/migration"
 	"payment-service/internal/payment/app"
 	grpcServer "payment-service/internal/payment/delivery/grpc"
+	"shared/health"
+	"shared/lifecycle"
+	"shared/logging"
+	"shared/metrics"
+	"shared/tracing"
 
 	_ "payment-service/docs" // untuk Swagger
 )
@@ -15,41 +24,74 @@ import (
 // @host localhost:8081
 // @BasePath /
 func main() {
-	// Load env dan koneksi MongoDB
-	config.LoadEnv()
-	config.ConnectDB()
+	// Config dari flag, env dan .env (opsional), semua error dilaporkan sekaligus
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logging.Fatal("failed to load config", "error", err)
+	}
+	logging.Setup("payment-service", cfg.LogLevel)
+	slog.Info("config loaded", "config", cfg)
 
-	// Jalankan cron job pembersih data lama
-	app.StartPaymentCleanupJob()
+	// tracing OpenTelemetry, exporter none/stdout/otlp
+	shutdownTracing, err := tracing.Setup(context.Background(), "payment-service", cfg.TracesExporter)
+	if err != nil {
+		logging.Fatal("failed to setup tracing", "error", err)
+	}
 
-	// Jalankan gRPC di background
-	go grpcServer.StartGRPCServer()
+	db, err := config.ConnectDB(context.Background(), cfg)
+	if err != nil {
+		logging.Fatal("failed to connect to MongoDB", "error", err)
+	}
+
+	// Migrasi amount float lama ke money (minor unit + currency)
+	if err := migration.Run(context.Background(), db); err != nil {
+		logging.Fatal("money migration failed", "error", err)
+	}
+
+	// Context lifecycle selesai saat shutdown dimulai, menghentikan cron,
+	// dispatcher webhook dan status grpc.health.v1
+	lm := lifecycle.NewManager(cfg.ShutdownTimeout)
 
-	// Blok utama agar aplikasi tidak langsung exit
-	fmt.Println("Payment Service is running with gRPC and cron job...")
+	// Jalankan cron job pembersih data lama
+	app.StartPaymentCleanupJob(lm.Context())
+
+	// readiness: Mongo, broker ditambahkan saat gRPC server dibuat
+	checker := health.NewChecker()
+	checker.Add("mongo", health.MongoCheck(db.Client()))
 
-	select {} // blok selamanya
+	// Jalankan gRPC di background
+	server := grpcServer.NewGRPCServer(lm.Context(), cfg, db, checker)
+	lm.Go("grpc", server.Serve)
 
-	// Init Echo
-	// app := echo.New()
-	// app.Use(middleware.Logger())
-	// app.Use(middleware.Recover())
+	// HTTP admin internal (level log, metrics, health), tidak diekspos lewat gateway publik
+	admin := newAdminServer(cfg.AdminPort, checker)
+	lm.Go("admin", admin.ListenAndServe)
 
-	// // Health Check
-	// app.GET("/", func(c echo.Context) error {
-	// 	return c.String(200, "Payment Service is running!")
-	// })
+	slog.Info("payment service is running with grpc and cron job")
 
-	// // Swagger
-	// app.GET("/swagger/*", echoSwagger.WrapHandler)
+	// urutan shutdown: selesaikan RPC yang berjalan, tutup admin, tutup
+	// broker (flush publish NATS), putus Mongo, lalu flush trace
+	lm.OnShutdown("grpc", lifecycle.StopGRPC(server.GRPC))
+	lm.OnShutdown("admin", admin.Shutdown)
+	lm.OnShutdown("broker", func(ctx context.Context) error { return server.Broker.Close() })
+	lm.OnShutdown("mongo", lifecycle.CloseMongo(db.Client()))
+	lm.OnShutdown("tracing", shutdownTracing)
 
-	// // Route utama (harusnya modular)
-	// app.POST("/payments", handler.CreatePayment)
+	// Blok utama sampai SIGTERM/SIGINT
+	if err := lm.Wait(); err != nil {
+		logging.Fatal("payment service stopped with error", "error", err)
+	}
+	slog.Info("payment service stopped")
+}
 
-	// // Port
-	// port := config.GetEnvOrDefault("PORT", "8081")
-	// fmt.Println("Server running at PORT:", port)
+// Server HTTP admin: GET/PUT /admin/log-level, GET /metrics, /healthz dan /readyz
+func newAdminServer(port string, checker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/admin/log-level", logging.LevelHandler())
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", health.LiveHandler())
+	mux.Handle("/readyz", health.ReadyHandler(checker))
 
-	// // Start
-	// app.Logger.Fatal(app.Start(":" + port))
+	slog.Info("admin server running", "port", port)
+	return &http.Server{Addr: ":" + port, Handler: mux}
 }
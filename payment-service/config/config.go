@@ -2,25 +2,98 @@ package config
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"os"
 	"time"
 
+	"payment-service/internal/provider"
+	"shared/envconfig"
+	"shared/events"
+	"shared/lifecycle"
+	"shared/metrics"
+	"shared/tracing"
+
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var DB *mongo.Database
+// Config payment-service, dibaca sekali di main lalu di-inject ke constructor
+type Config struct {
+	GRPCPort  string
+	AdminPort string
+
+	MongoURI      envconfig.Secret // bisa berisi user/password
+	MongoDatabase string
+
+	// provider pembayaran, simulator hanya kalau dipilih eksplisit
+	PaymentProvider string
+
+	EventBroker   string
+	NATSURL       envconfig.Secret // bisa berisi user:password@ atau token@
+	NATSCredsFile string
+	NATSCAFile    string
+
+	LogLevel       string
+	TracesExporter string
 
-func ConnectDB() {
-	clientOptions := options.Client().ApplyURI(GetEnv("MONGOURI"))
+	ShutdownTimeout time.Duration
+}
+
+// Load config dari flag (args tanpa nama program), env dan file .env
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func load(args []string, env func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{}
+	l := envconfig.NewLoader("payment-service")
+	l.Port(&cfg.GRPCPort, "GRPC_PORT", "50051", "port gRPC")
+	l.Port(&cfg.AdminPort, "ADMIN_PORT", "9091", "port HTTP admin (log level, metrics, health)")
+	l.Secret(&cfg.MongoURI, "MONGOURI", "", "URI MongoDB").Required()
+	l.String(&cfg.MongoDatabase, "MONGODB_NAME", "", "nama database MongoDB").Required()
+	l.OneOf(&cfg.PaymentProvider, "PAYMENT_PROVIDER", "", "provider pembayaran (simulator hanya untuk development)", provider.ProviderSimulator).Required()
+	l.OneOf(&cfg.EventBroker, "EVENT_BROKER", events.BrokerMemory, "broker event domain", events.BrokerMemory, events.BrokerNATS)
+	l.Secret(&cfg.NATSURL, "NATS_URL", "", "alamat NATS (tls:// untuk TLS), wajib kalau EVENT_BROKER=nats")
+	l.String(&cfg.NATSCredsFile, "NATS_CREDS_FILE", "", "file .creds NATS (JWT + nkey)")
+	l.String(&cfg.NATSCAFile, "NATS_CA_FILE", "", "CA tambahan untuk sertifikat server NATS")
+	l.LogLevel(&cfg.LogLevel, "LOG_LEVEL", "info", "level log awal")
+	l.OneOf(&cfg.TracesExporter, "OTEL_TRACES_EXPORTER", "none", "exporter trace", tracing.ExporterNone, tracing.ExporterStdout, "console", tracing.ExporterOTLP)
+	l.Duration(&cfg.ShutdownTimeout, "SHUTDOWN_TIMEOUT", lifecycle.DefaultTimeout.String(), "batas waktu graceful shutdown")
+
+	err := l.Load(args, env)
+	if cfg.EventBroker == events.BrokerNATS && cfg.NATSURL == "" {
+		err = errors.Join(err, errors.New("NATS_URL is required when EVENT_BROKER=nats"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NATSOptions koneksi broker NATS dari config
+func (c *Config) NATSOptions() events.NATSOptions {
+	return events.NATSOptions{
+		URL:       c.NATSURL.Value(),
+		Name:      "payment-service",
+		CredsFile: c.NATSCredsFile,
+		CAFile:    c.NATSCAFile,
+	}
+}
+
+// ConnectDB membuka koneksi MongoDB dengan span per command dan statistik
+// pool untuk /metrics
+func ConnectDB(ctx context.Context, cfg *Config) (*mongo.Database, error) {
+	clientOptions := options.Client().ApplyURI(cfg.MongoURI.Value()).
+		SetMonitor(tracing.MongoMonitor()).
+		SetPoolMonitor(metrics.MongoPoolMonitor())
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal("Failed to connect to MongoDB:", err)
+		return nil, err
 	}
-
-	DB = client.Database(GetEnv("MONGODB_NAME"))
+	return client.Database(cfg.MongoDatabase), nil
 }
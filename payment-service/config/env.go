@@ -1,32 +0,0 @@
-package config
-
-import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
-)
-
-func LoadEnv() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-}
-
-func GetEnv(key string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		log.Fatalf("Environment variable '%s' tidak ditemukan atau kosong", key)
-	}
-	return val
-}
-
-// Helper
-func GetEnvOrDefault(key, fallback string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		return fallback
-	}
-	return val
-}
@@ -0,0 +1,21 @@
+package migration
+
+import (
+	"context"
+
+	"shared/money"
+	"shared/moneymigration"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Field nominal yang dimigrasi di service ini
+var moneyFields = []moneymigration.Field{
+	{Collection: "payments", Field: "amount"},
+}
+
+// Jalankan semua migrasi money. Aman dipanggil setiap start karena hanya
+// dokumen yang field-nya masih berupa angka yang diubah.
+func Run(ctx context.Context, db *mongo.Database) error {
+	return moneymigration.Migrate(ctx, db, moneyFields, money.DefaultCurrency)
+}
@@ -3,9 +3,30 @@ package app
 import "errors"
 
 var (
-	ErrEmailEmpty     = errors.New("email is required")
-	ErrAmountInvalid  = errors.New("amount must be greater than zero")
-	ErrStatusEmpty    = errors.New("status is required")
-	ErrInsertFailed   = errors.New("failed to insert payment")
-	ErrInvalidPayload = errors.New("invalid request format")
+	ErrEmailEmpty      = errors.New("email is required")
+	ErrAmountInvalid   = errors.New("amount must be greater than zero")
+	ErrCurrencyInvalid = errors.New("currency must be a supported ISO-4217 code")
+	ErrStatusEmpty     = errors.New("status is required")
+	ErrInsertFailed    = errors.New("failed to insert payment")
+	ErrInvalidPayload  = errors.New("invalid request format")
+
+	ErrInvalidDateRange = errors.New("created_from must be before created_to")
+	ErrInvalidPageToken = errors.New("invalid page token")
+	ErrInvalidPageSize  = errors.New("page size must be between 0 and 500")
+	ErrWatchLagging     = errors.New("watcher fell behind, reconnect with the last event id")
+
+	ErrUnsupportedMethod = errors.New("unsupported payment method")
+	ErrPaymentDeclined   = errors.New("payment declined by provider")
+	ErrProviderTimeout   = errors.New("payment provider timed out")
+	ErrProviderFailed    = errors.New("payment provider error")
+
+	ErrPaymentNotFound    = errors.New("payment not found")
+	ErrRefundNotAllowed   = errors.New("payment cannot be refunded in its current status")
+	ErrRefundExceeded     = errors.New("refund amount exceeds remaining paid amount")
+	ErrRefundReference    = errors.New("refund reference is required")
+	ErrRefundConflict     = errors.New("payment was refunded concurrently, retry")
+	ErrRefundPending      = errors.New("refund with this reference is still pending")
+	ErrRefundRecordFailed = errors.New("refund processed but could not be recorded")
+
+	ErrPaymentNotDeleted = errors.New("payment not found or not deleted")
 )
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"errors"
+
+	"shared/money"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// metric bisnis payment, amount dalam major unit per currency
+var (
+	paymentsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payments_created_total",
+		Help: "Jumlah payment yang berhasil dibuat per metode.",
+	}, []string{"method"})
+
+	paymentsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payments_failed_total",
+		Help: "Jumlah payment gagal per metode dan alasan (declined, timeout, provider_error, insert_failed).",
+	}, []string{"method", "reason"})
+
+	paymentsFailedAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payments_failed_amount_total",
+		Help: "Total amount payment yang gagal.",
+	}, []string{"currency"})
+
+	paymentsRefundedAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payments_refunded_amount_total",
+		Help: "Total amount yang sudah di-refund.",
+	}, []string{"currency"})
+)
+
+// catat payment gagal, err hasil providerError atau ErrInsertFailed
+func observePaymentFailed(method string, amount money.Money, err error) {
+	reason := "provider_error"
+	switch {
+	case errors.Is(err, ErrPaymentDeclined):
+		reason = "declined"
+	case errors.Is(err, ErrProviderTimeout):
+		reason = "timeout"
+	case errors.Is(err, ErrInsertFailed):
+		reason = "insert_failed"
+	}
+	paymentsFailed.WithLabelValues(method, reason).Inc()
+	paymentsFailedAmount.WithLabelValues(amount.Currency).Add(amount.Major())
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"payment-service/internal/payment/domain"
+	"payment-service/internal/payment/infra"
+)
+
+// Default jumlah event terakhir yang di-replay saat resume
+const DefaultEventHistory = 1000
+
+// Buffer channel per subscriber. Subscriber yang tertinggal lebih dari ini
+// akan diputus supaya publisher tidak ikut tertahan.
+const subscriberBuffer = 64
+
+// PaymentEventBus adalah pub/sub in-process untuk event perubahan payment.
+// Event disimpan di store dengan sequence persisten supaya subscriber bisa
+// resume dari last event id setelah reconnect, termasuk setelah restart.
+type PaymentEventBus struct {
+	mu      sync.Mutex
+	store   infra.PaymentEventRepository
+	limit   int
+	subs    map[uint64]chan domain.PaymentEvent
+	nextSub uint64
+}
+
+// Inisialisasi event bus, history adalah jumlah maksimal event yang
+// di-replay saat subscribe
+func NewPaymentEventBus(history int, store infra.PaymentEventRepository) *PaymentEventBus {
+	if history <= 0 {
+		history = DefaultEventHistory
+	}
+	return &PaymentEventBus{
+		store: store,
+		limit: history,
+		subs:  make(map[uint64]chan domain.PaymentEvent),
+	}
+}
+
+// Simpan event lalu kirim ke semua subscriber. Event yang gagal disimpan
+// tidak dikirim supaya ID yang diterima client selalu bisa dipakai resume,
+// perubahan payment-nya sendiri tetap berhasil. Sequence diambil atomic di
+// store dan lock hanya dipegang saat fan-out, jadi publish paralel tidak
+// saling menunggu Mongo (urutan kirim bisa berbeda sedikit dari urutan ID).
+func (b *PaymentEventBus) Publish(ctx context.Context, eventType string, payment domain.Payment) {
+	event := domain.PaymentEvent{
+		Type:       eventType,
+		Payment:    payment,
+		OccurredAt: time.Now(),
+	}
+	if err := b.store.Append(context.WithoutCancel(ctx), &event); err != nil {
+		slog.ErrorContext(ctx, "failed to store payment event", "event_type", eventType, "payment_id", payment.ID.Hex(), "error", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			// subscriber terlalu lambat, putus supaya client reconnect dan resume
+			close(ch)
+			delete(b.subs, id)
+		}
+	}
+}
+
+// Subscribe mengembalikan event tersimpan dengan ID > afterID untuk
+// di-replay, channel untuk event berikutnya, dan fungsi untuk berhenti
+// subscribe. afterID 0 berarti mulai dari sekarang tanpa replay. Channel
+// ditutup jika subscriber tertinggal terlalu jauh.
+//
+// Channel didaftarkan sebelum replay dibaca supaya event di antaranya tidak
+// terlewat, akibatnya event yang ada di replay bisa muncul lagi di channel
+// dan harus dilewati pemanggil berdasarkan ID.
+func (b *PaymentEventBus) Subscribe(ctx context.Context, afterID uint64) ([]domain.PaymentEvent, <-chan domain.PaymentEvent, func(), error) {
+	b.mu.Lock()
+	id := b.nextSub
+	b.nextSub++
+	ch := make(chan domain.PaymentEvent, subscriberBuffer)
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[id]; ok {
+			close(ch)
+			delete(b.subs, id)
+		}
+	}
+
+	if afterID == 0 {
+		return nil, ch, cancel, nil
+	}
+
+	replay, err := b.store.FindAfter(ctx, afterID, b.limit)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	return replay, ch, cancel, nil
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"payment-service/internal/payment/domain"
+	"payment-service/internal/payment/infra"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// store yang selalu gagal menyimpan event
+type failingEventStore struct {
+	infra.PaymentEventRepository
+}
+
+func (failingEventStore) Append(ctx context.Context, event *domain.PaymentEvent) error {
+	return errors.New("mongo down")
+}
+
+func (failingEventStore) FindAfter(ctx context.Context, afterID uint64, limit int) ([]domain.PaymentEvent, error) {
+	return nil, nil
+}
+
+// store yang menahan Append pertama sampai release ditutup
+type blockingEventStore struct {
+	infra.PaymentEventRepository
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingEventStore) Append(ctx context.Context, event *domain.PaymentEvent) error {
+	first := false
+	s.once.Do(func() { first = true })
+	if first {
+		close(s.entered)
+		<-s.release
+	}
+	return s.PaymentEventRepository.Append(ctx, event)
+}
+
+func TestPaymentEventBus_PublishAndSubscribe(t *testing.T) {
+	bus := NewPaymentEventBus(10, infra.NewMemoryPaymentEventRepository())
+
+	replay, events, cancel, err := bus.Subscribe(context.TODO(), 0)
+	defer cancel()
+	assert.NoError(t, err)
+	assert.Empty(t, replay)
+
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{Email: "a@a.com"})
+
+	event := <-events
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, domain.PaymentEventCreated, event.Type)
+	assert.Equal(t, "a@a.com", event.Payment.Email)
+}
+
+func TestPaymentEventBus_ReplayAfterLastEventID(t *testing.T) {
+	bus := NewPaymentEventBus(10, infra.NewMemoryPaymentEventRepository())
+	for i := 0; i < 5; i++ {
+		bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{})
+	}
+
+	replay, _, cancel, err := bus.Subscribe(context.TODO(), 3)
+	defer cancel()
+
+	assert.NoError(t, err)
+	assert.Len(t, replay, 2)
+	assert.Equal(t, uint64(4), replay[0].ID)
+	assert.Equal(t, uint64(5), replay[1].ID)
+}
+
+func TestPaymentEventBus_HistoryLimit(t *testing.T) {
+	bus := NewPaymentEventBus(3, infra.NewMemoryPaymentEventRepository())
+	for i := 0; i < 5; i++ {
+		bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{})
+	}
+
+	replay, _, cancel, err := bus.Subscribe(context.TODO(), 1)
+	defer cancel()
+
+	assert.NoError(t, err)
+	assert.Len(t, replay, 3)
+	assert.Equal(t, uint64(3), replay[0].ID)
+}
+
+func TestPaymentEventBus_ZeroCursorStartsNow(t *testing.T) {
+	bus := NewPaymentEventBus(10, infra.NewMemoryPaymentEventRepository())
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{})
+
+	replay, events, cancel, err := bus.Subscribe(context.TODO(), 0)
+	defer cancel()
+	assert.NoError(t, err)
+	assert.Empty(t, replay)
+
+	bus.Publish(context.TODO(), domain.PaymentEventDeleted, domain.Payment{})
+	event := <-events
+	assert.Equal(t, uint64(2), event.ID)
+}
+
+func TestPaymentEventBus_PublishDoesNotWaitForOtherStoreWrites(t *testing.T) {
+	store := &blockingEventStore{PaymentEventRepository: infra.NewMemoryPaymentEventRepository(), entered: make(chan struct{}), release: make(chan struct{})}
+	bus := NewPaymentEventBus(10, store)
+
+	// publish pertama tertahan di store
+	blocked := make(chan struct{})
+	go func() {
+		bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{Email: "slow@a.com"})
+		close(blocked)
+	}()
+	<-store.entered
+
+	_, events, cancel, err := bus.Subscribe(context.TODO(), 0)
+	defer cancel()
+	assert.NoError(t, err)
+
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{Email: "fast@a.com"})
+	assert.Equal(t, "fast@a.com", (<-events).Payment.Email)
+
+	close(store.release)
+	<-blocked
+	assert.Equal(t, "slow@a.com", (<-events).Payment.Email)
+}
+
+func TestPaymentEventBus_ResumeAfterRestart(t *testing.T) {
+	store := infra.NewMemoryPaymentEventRepository()
+	before := NewPaymentEventBus(10, store)
+	before.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{})
+	before.Publish(context.TODO(), domain.PaymentEventDeleted, domain.Payment{})
+
+	// proses baru dengan store yang sama, sequence tidak mulai dari 0 lagi
+	after := NewPaymentEventBus(10, store)
+	after.Publish(context.TODO(), domain.PaymentEventRestored, domain.Payment{})
+
+	replay, _, cancel, err := after.Subscribe(context.TODO(), 1)
+	defer cancel()
+
+	assert.NoError(t, err)
+	if assert.Len(t, replay, 2) {
+		assert.Equal(t, uint64(2), replay[0].ID)
+		assert.Equal(t, uint64(3), replay[1].ID)
+		assert.Equal(t, domain.PaymentEventRestored, replay[1].Type)
+	}
+}
+
+func TestPaymentEventBus_StoreFailureSkipsFanOut(t *testing.T) {
+	bus := NewPaymentEventBus(10, failingEventStore{})
+
+	_, events, cancel, err := bus.Subscribe(context.TODO(), 0)
+	defer cancel()
+	assert.NoError(t, err)
+
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{})
+
+	assert.Empty(t, events)
+}
+
+func TestPaymentEventBus_DropsSlowSubscriber(t *testing.T) {
+	bus := NewPaymentEventBus(10, infra.NewMemoryPaymentEventRepository())
+
+	_, events, cancel, err := bus.Subscribe(context.TODO(), 0)
+	defer cancel()
+	assert.NoError(t, err)
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{})
+	}
+
+	count := 0
+	for range events {
+		count++
+	}
+	assert.Equal(t, subscriberBuffer, count)
+}
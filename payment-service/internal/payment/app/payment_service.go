@@ -2,8 +2,15 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"payment-service/internal/payment/domain"
 	"payment-service/internal/payment/infra"
+	"payment-service/internal/provider"
+	"shared/audit"
+	"shared/events"
+	"shared/money"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,17 +20,35 @@ type PaymentService interface {
 	CreatePayment(ctx context.Context, input domain.Payment) (domain.Payment, error)
 	GetPaymentByID(ctx context.Context, id string) (domain.Payment, error)
 	DeletePaymentByID(ctx context.Context, id string) (domain.Payment, error)
-	GetAllPayments(ctx context.Context) ([]domain.Payment, error)
+	GetAllPayments(ctx context.Context, filter domain.PaymentFilter) ([]domain.Payment, error)
+	StreamPayments(ctx context.Context, filter domain.PaymentFilter, fn func(domain.Payment) error) error
+	WatchPayments(ctx context.Context, filter domain.PaymentWatchFilter, lastEventID uint64, fn func(domain.PaymentEvent) error) error
+	RefundPayment(ctx context.Context, id string, req domain.RefundRequest) (domain.Payment, domain.Refund, error)
+	// payment yang di-soft delete dan pengembaliannya (admin)
+	GetDeletedPayments(ctx context.Context) ([]domain.Payment, error)
+	RestorePayment(ctx context.Context, id string) (domain.Payment, error)
+	// riwayat perubahan payment, paymentID kosong berarti semua payment
+	GetAuditLogs(ctx context.Context, paymentID string, limit int64) ([]audit.Entry, error)
 }
 
+// Nama resource payment di audit log
+const auditResource = "payment"
+
+// Nama service sebagai source di envelope event
+const eventSource = "payment-service"
+
 // Implementasi service
 type paymentService struct {
-	repo infra.PaymentRepository
+	repo      infra.PaymentRepository
+	bus       *PaymentEventBus
+	publisher events.Publisher
+	providers *provider.Registry
+	audit     audit.Log
 }
 
-// Inisialisasi service
-func NewPaymentService(repo infra.PaymentRepository) PaymentService {
-	return &paymentService{repo: repo}
+// Inisialisasi service. Actor perubahan diambil dari ctx (audit.WithActor).
+func NewPaymentService(repo infra.PaymentRepository, bus *PaymentEventBus, publisher events.Publisher, providers *provider.Registry, auditLog audit.Log) PaymentService {
+	return &paymentService{repo: repo, bus: bus, publisher: publisher, providers: providers, audit: auditLog}
 }
 
 func (s *paymentService) CreatePayment(ctx context.Context, input domain.Payment) (domain.Payment, error) {
@@ -31,37 +56,333 @@ func (s *paymentService) CreatePayment(ctx context.Context, input domain.Payment
 	if input.Email == "" {
 		return domain.Payment{}, ErrEmailEmpty
 	}
-	if input.Amount <= 0 {
+	if !input.Amount.IsPositive() {
 		return domain.Payment{}, ErrAmountInvalid
 	}
+	if input.Amount.Currency == "" {
+		input.Amount.Currency = money.DefaultCurrency
+	}
+	if err := input.Amount.Validate(); err != nil {
+		return domain.Payment{}, ErrCurrencyInvalid
+	}
 
-	// Set default status
-	input.Status = "paid"
+	// Pilih provider sesuai metode, default kartu
+	if input.Method == "" {
+		input.Method = provider.MethodCard
+	}
+	processor, err := s.providers.For(input.Method)
+	if err != nil {
+		return domain.Payment{}, ErrUnsupportedMethod
+	}
 
 	// Set ID & Timestamp
 	input.ID = primitive.NewObjectID()
 	input.CreatedAt = time.Now()
 
+	// Authorize lalu capture di provider
+	auth, err := processor.Authorize(ctx, provider.AuthorizeRequest{
+		PaymentID: input.ID.Hex(),
+		Email:     input.Email,
+		Amount:    input.Amount,
+		Method:    input.Method,
+		CardToken: input.CardToken,
+	})
+	if err != nil {
+		err = providerError(err)
+		observePaymentFailed(input.Method, input.Amount, err)
+		return domain.Payment{}, err
+	}
+
+	capture, err := processor.Capture(ctx, auth.Reference, input.Amount)
+	if err != nil {
+		// lepas dana yang sudah di-hold
+		if _, voidErr := processor.Void(ctx, auth.Reference); voidErr != nil {
+			slog.ErrorContext(ctx, "void after failed capture", "provider_ref", auth.Reference, "error", voidErr)
+		}
+		err = providerError(err)
+		observePaymentFailed(input.Method, input.Amount, err)
+		return domain.Payment{}, err
+	}
+
+	input.Status = domain.PaymentStatusPaid
+	input.Provider = processor.Name()
+	input.ProviderRef = capture.Reference
+	input.CardToken = ""
+
 	// Insert ke Mongo
-	_, err := s.repo.Insert(ctx, input)
+	_, err = s.repo.Insert(ctx, input)
 	if err != nil {
+		// dana sudah ter-capture tapi payment tidak tersimpan, kembalikan
+		if _, refundErr := processor.Refund(ctx, capture.Reference, input.Amount); refundErr != nil {
+			slog.ErrorContext(ctx, "refund after failed insert", "provider_ref", capture.Reference, "error", refundErr)
+		}
+		observePaymentFailed(input.Method, input.Amount, ErrInsertFailed)
 		return domain.Payment{}, ErrInsertFailed
 	}
+	paymentsCreated.WithLabelValues(input.Method).Inc()
+
+	s.record(ctx, input.ID.Hex(), audit.ActionCreate, nil, input)
+	s.bus.Publish(ctx, domain.PaymentEventCreated, input)
+	s.publish(ctx, events.TypePaymentCreated, events.VersionPaymentCreated, events.PaymentCreated{
+		PaymentID: input.ID.Hex(),
+		Email:     input.Email,
+		Amount:    input.Amount,
+		Status:    input.Status,
+	})
 
 	return input, nil
 }
 
+// Refund penuh atau parsial lewat provider yang memproses payment.
+// Refund disimpan pending dulu (dengan version check) sebelum provider
+// dipanggil, jadi dua refund bersamaan tidak bisa sama-sama lolos ke
+// provider. Refund dengan reference yang sudah berhasil mengembalikan
+// refund lama tanpa refund ulang, jadi pemanggil aman retry.
+func (s *paymentService) RefundPayment(ctx context.Context, id string, req domain.RefundRequest) (domain.Payment, domain.Refund, error) {
+	if req.Reference == "" {
+		return domain.Payment{}, domain.Refund{}, ErrRefundReference
+	}
+
+	payment, err := s.repo.FindByID(ctx, id)
+	if err != nil || payment.ID.IsZero() {
+		return domain.Payment{}, domain.Refund{}, ErrPaymentNotFound
+	}
+	if existing, ok := payment.RefundByReference(req.Reference); ok {
+		if existing.Pending() {
+			return domain.Payment{}, domain.Refund{}, ErrRefundPending
+		}
+		return payment, existing, nil
+	}
+	if payment.Status != domain.PaymentStatusPaid && payment.Status != domain.PaymentStatusPartiallyRefunded {
+		return domain.Payment{}, domain.Refund{}, ErrRefundNotAllowed
+	}
+
+	remaining, err := payment.Amount.Sub(payment.RefundedAmount())
+	if err != nil {
+		return domain.Payment{}, domain.Refund{}, ErrCurrencyInvalid
+	}
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if amount.Currency == "" {
+		amount.Currency = payment.Amount.Currency
+	}
+	if amount.Currency != payment.Amount.Currency {
+		return domain.Payment{}, domain.Refund{}, ErrCurrencyInvalid
+	}
+	if !amount.IsPositive() {
+		return domain.Payment{}, domain.Refund{}, ErrAmountInvalid
+	}
+	if amount.MinorUnits > remaining.MinorUnits {
+		return domain.Payment{}, domain.Refund{}, ErrRefundExceeded
+	}
+
+	processor, err := s.providers.For(payment.Method)
+	if err != nil {
+		return domain.Payment{}, domain.Refund{}, ErrUnsupportedMethod
+	}
+
+	refund := domain.Refund{
+		ID:        primitive.NewObjectID().Hex(),
+		Amount:    amount,
+		Reason:    req.Reason,
+		Reference: req.Reference,
+		Status:    domain.RefundStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if _, err := s.repo.ReserveRefund(ctx, id, refund, len(payment.Refunds)); err != nil {
+		if errors.Is(err, infra.ErrRefundConflict) {
+			return domain.Payment{}, domain.Refund{}, ErrRefundConflict
+		}
+		return domain.Payment{}, domain.Refund{}, ErrRefundRecordFailed
+	}
+
+	result, err := processor.Refund(ctx, payment.ProviderRef, amount)
+	if err != nil {
+		err = providerError(err)
+		// timeout: provider mungkin sudah memproses, refund tetap pending
+		// sampai dicocokkan manual supaya nominalnya tidak di-refund lagi
+		if errors.Is(err, ErrProviderTimeout) {
+			slog.ErrorContext(ctx, "refund left pending after provider timeout", "refund_id", refund.ID, "payment_id", id)
+			return domain.Payment{}, domain.Refund{}, err
+		}
+		if releaseErr := s.repo.ReleaseRefund(ctx, id, refund.ID); releaseErr != nil {
+			slog.ErrorContext(ctx, "failed to release pending refund", "refund_id", refund.ID, "payment_id", id, "error", releaseErr)
+		}
+		return domain.Payment{}, domain.Refund{}, err
+	}
+
+	updated, err := s.repo.CompleteRefund(ctx, id, refund.ID, result.Reference)
+	if err != nil {
+		// dana sudah dikembalikan provider, refund tetap pending dan perlu
+		// dicocokkan manual
+		slog.ErrorContext(ctx, "failed to record refund", "refund_id", refund.ID, "provider_ref", result.Reference, "payment_id", id, "error", err)
+		return domain.Payment{}, domain.Refund{}, ErrRefundRecordFailed
+	}
+	refund.Status = domain.RefundStatusSucceeded
+	refund.ProviderRef = result.Reference
+
+	paymentsRefundedAmount.WithLabelValues(amount.Currency).Add(amount.Major())
+	s.record(ctx, id, audit.ActionUpdate, payment, updated)
+	s.bus.Publish(ctx, domain.PaymentEventRefunded, updated)
+	s.publish(ctx, events.TypePaymentRefunded, events.VersionPaymentRefunded, events.PaymentRefunded{
+		PaymentID: id,
+		Email:     updated.Email,
+		Amount:    amount,
+		Reason:    req.Reason,
+	})
+
+	return updated, refund, nil
+}
+
 // Ambil payment by ID
 func (s *paymentService) GetPaymentByID(ctx context.Context, id string) (domain.Payment, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
-// Hapus payment by ID
+// Soft delete payment by ID, user dari ctx dicatat sebagai deleted_by
 func (s *paymentService) DeletePaymentByID(ctx context.Context, id string) (domain.Payment, error) {
-	return s.repo.DeleteByID(ctx, id)
+	deleted, err := s.repo.DeleteByID(ctx, id, audit.ActorFrom(ctx).Email)
+	if err != nil {
+		if errors.Is(err, infra.ErrPaymentNotFound) {
+			return domain.Payment{}, ErrPaymentNotFound
+		}
+		return domain.Payment{}, err
+	}
+
+	before := deleted
+	before.DeletedAt, before.DeletedBy = nil, ""
+	s.record(ctx, id, audit.ActionDelete, before, deleted)
+	s.bus.Publish(ctx, domain.PaymentEventDeleted, deleted)
+
+	return deleted, nil
+}
+
+// Ambil payment yang di-soft delete
+func (s *paymentService) GetDeletedPayments(ctx context.Context) ([]domain.Payment, error) {
+	return s.repo.FindDeleted(ctx)
+}
+
+// Kembalikan payment yang di-soft delete
+func (s *paymentService) RestorePayment(ctx context.Context, id string) (domain.Payment, error) {
+	deleted, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, infra.ErrPaymentNotFound) {
+			return domain.Payment{}, ErrPaymentNotDeleted
+		}
+		return domain.Payment{}, err
+	}
+
+	restored := deleted
+	restored.DeletedAt, restored.DeletedBy = nil, ""
+	s.record(ctx, id, audit.ActionRestore, deleted, restored)
+	s.bus.Publish(ctx, domain.PaymentEventRestored, restored)
+
+	return restored, nil
+}
+
+// Ambil audit log payment, terbaru dulu
+func (s *paymentService) GetAuditLogs(ctx context.Context, paymentID string, limit int64) ([]audit.Entry, error) {
+	return s.audit.List(ctx, audit.Filter{Resource: auditResource, ResourceID: paymentID, Limit: limit})
+}
+
+// Catat perubahan payment ke audit log dengan actor dari ctx
+func (s *paymentService) record(ctx context.Context, id, action string, before, after any) {
+	s.audit.Record(ctx, audit.NewEntry(auditResource, id, action, audit.ActorFrom(ctx), before, after))
+}
+
+// Ambil semua payment sesuai filter
+func (s *paymentService) GetAllPayments(ctx context.Context, filter domain.PaymentFilter) ([]domain.Payment, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAll(ctx, filter)
+}
+
+// Stream payment sesuai filter, fn dipanggil untuk tiap payment
+func (s *paymentService) StreamPayments(ctx context.Context, filter domain.PaymentFilter, fn func(domain.Payment) error) error {
+	if err := validateFilter(filter); err != nil {
+		return err
+	}
+	return s.repo.Stream(ctx, filter, fn)
+}
+
+// Terjemahkan error provider ke error service
+func providerError(err error) error {
+	var decline *provider.DeclineError
+	switch {
+	case errors.As(err, &decline):
+		return fmt.Errorf("%w: %s", ErrPaymentDeclined, decline.Code)
+	case errors.Is(err, provider.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ErrProviderTimeout
+	default:
+		return fmt.Errorf("%w: %v", ErrProviderFailed, err)
+	}
 }
 
-// Ambil semua payment
-func (s *paymentService) GetAllPayments(ctx context.Context) ([]domain.Payment, error) {
-	return s.repo.FindAll(ctx)
+func validateFilter(filter domain.PaymentFilter) error {
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return ErrInvalidDateRange
+	}
+	if filter.AfterID != "" && !primitive.IsValidObjectID(filter.AfterID) {
+		return ErrInvalidPageToken
+	}
+	if filter.Limit < 0 || filter.Limit > domain.MaxPageSize {
+		return ErrInvalidPageSize
+	}
+	return nil
+}
+
+// Kirim event payment yang cocok dengan filter ke fn sampai ctx selesai.
+// Event tersimpan setelah lastEventID dikirim lebih dulu, lastEventID 0
+// hanya menerima event baru.
+func (s *paymentService) WatchPayments(ctx context.Context, filter domain.PaymentWatchFilter, lastEventID uint64, fn func(domain.PaymentEvent) error) error {
+	replay, updates, cancel, err := s.bus.Subscribe(ctx, lastEventID)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// event replay bisa terkirim lagi lewat updates, lihat Subscribe
+	replayed := make(map[uint64]bool, len(replay))
+	for _, event := range replay {
+		replayed[event.ID] = true
+		if !filter.Match(event) {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-updates:
+			if !ok {
+				return ErrWatchLagging
+			}
+			if replayed[event.ID] || !filter.Match(event) {
+				continue
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Publish event domain ke broker. Gagal publish tidak membatalkan operasi
+// yang sudah tersimpan, cukup dicatat di log.
+func (s *paymentService) publish(ctx context.Context, eventType string, version int, payload any) {
+	env, err := events.NewEnvelope(eventType, version, eventSource, payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build event", "event_type", eventType, "error", err)
+		return
+	}
+	if err := events.PublishWithRetry(ctx, s.publisher, env, 3); err != nil {
+		slog.ErrorContext(ctx, "failed to publish event", "event_type", eventType, "event_id", env.ID, "error", err)
+	}
 }
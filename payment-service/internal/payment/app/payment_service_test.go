@@ -4,11 +4,18 @@ import (
 	"context"
 	"errors"
 	"payment-service/internal/payment/domain"
+	"payment-service/internal/payment/infra"
+	"payment-service/internal/provider"
+	"shared/audit"
+	"shared/events"
+	"shared/money"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -31,25 +38,62 @@ func (m *MockPaymentRepository) FindByID(ctx context.Context, id string) (domain
 	return args.Get(0).(domain.Payment), args.Error(1)
 }
 
-func (m *MockPaymentRepository) DeleteByID(ctx context.Context, id string) (domain.Payment, error) {
-	args := m.Called(ctx, id)
+func (m *MockPaymentRepository) DeleteByID(ctx context.Context, id string, deletedBy string) (domain.Payment, error) {
+	args := m.Called(ctx, id, deletedBy)
 	return args.Get(0).(domain.Payment), args.Error(1)
 }
 
-func (m *MockPaymentRepository) FindAll(ctx context.Context) ([]domain.Payment, error) {
+func (m *MockPaymentRepository) FindDeleted(ctx context.Context) ([]domain.Payment, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]domain.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) Restore(ctx context.Context, id string) (domain.Payment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) FindAll(ctx context.Context, filter domain.PaymentFilter) ([]domain.Payment, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) Stream(ctx context.Context, filter domain.PaymentFilter, fn func(domain.Payment) error) error {
+	args := m.Called(ctx, filter)
+	if payments, ok := args.Get(0).([]domain.Payment); ok {
+		for _, p := range payments {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockPaymentRepository) ReserveRefund(ctx context.Context, id string, refund domain.Refund, refundCount int) (domain.Payment, error) {
+	args := m.Called(ctx, id, refund, refundCount)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) CompleteRefund(ctx context.Context, id, refundID, providerRef string) (domain.Payment, error) {
+	args := m.Called(ctx, id, refundID, providerRef)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) ReleaseRefund(ctx context.Context, id, refundID string) error {
+	args := m.Called(ctx, id, refundID)
+	return args.Error(0)
+}
+
 // ===================== TESTS ========================
 
 func TestCreatePayment_Success(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
 
 	input := domain.Payment{
 		Email:  "user@example.com",
-		Amount: 100.0,
+		Amount: money.New(10000, "IDR"),
 	}
 
 	mockRepo.On("Insert", mock.Anything, mock.MatchedBy(func(p domain.Payment) bool {
@@ -69,9 +113,9 @@ func TestCreatePayment_Success(t *testing.T) {
 
 func TestCreatePayment_EmptyEmail(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
 
-	input := domain.Payment{Email: "", Amount: 100.0}
+	input := domain.Payment{Email: "", Amount: money.New(10000, "IDR")}
 	result, err := service.CreatePayment(context.TODO(), input)
 
 	assert.ErrorIs(t, err, ErrEmailEmpty)
@@ -80,9 +124,9 @@ func TestCreatePayment_EmptyEmail(t *testing.T) {
 
 func TestCreatePayment_InvalidAmount(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
 
-	input := domain.Payment{Email: "x@y.com", Amount: 0}
+	input := domain.Payment{Email: "x@y.com", Amount: money.New(0, "IDR")}
 	result, err := service.CreatePayment(context.TODO(), input)
 
 	assert.ErrorIs(t, err, ErrAmountInvalid)
@@ -91,9 +135,9 @@ func TestCreatePayment_InvalidAmount(t *testing.T) {
 
 func TestCreatePayment_InsertFailed(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
 
-	input := domain.Payment{Email: "user@example.com", Amount: 100.0}
+	input := domain.Payment{Email: "user@example.com", Amount: money.New(10000, "IDR")}
 	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(nil, errors.New("mongo error"))
 
 	result, err := service.CreatePayment(context.TODO(), input)
@@ -104,9 +148,9 @@ func TestCreatePayment_InsertFailed(t *testing.T) {
 
 func TestGetPaymentByID_Success(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
 
-	expected := domain.Payment{Email: "x@y.com", Amount: 42}
+	expected := domain.Payment{Email: "x@y.com", Amount: money.New(4200, "IDR")}
 	mockRepo.On("FindByID", mock.Anything, "abc123").Return(expected, nil)
 
 	result, err := service.GetPaymentByID(context.TODO(), "abc123")
@@ -118,31 +162,512 @@ func TestGetPaymentByID_Success(t *testing.T) {
 
 func TestDeletePaymentByID_Success(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	auditLog := audit.NewMemoryLog()
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), auditLog)
 
-	expected := domain.Payment{Email: "del@x.com", Amount: 500}
-	mockRepo.On("DeleteByID", mock.Anything, "del123").Return(expected, nil)
+	deletedAt := time.Now()
+	expected := domain.Payment{Email: "del@x.com", Amount: money.New(50000, "IDR"), DeletedAt: &deletedAt, DeletedBy: "admin@x.com"}
+	mockRepo.On("DeleteByID", mock.Anything, "del123", "admin@x.com").Return(expected, nil)
 
-	result, err := service.DeletePaymentByID(context.TODO(), "del123")
+	ctx := audit.WithActor(context.TODO(), audit.Actor{Email: "admin@x.com", RequestID: "req-1"})
+	result, err := service.DeletePaymentByID(ctx, "del123")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
+
+	entries, _ := auditLog.List(context.TODO(), audit.Filter{ResourceID: "del123"})
+	assert.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionDelete, entries[0].Action)
+	assert.Equal(t, "admin@x.com", entries[0].Actor)
+	assert.Equal(t, "req-1", entries[0].RequestID)
+	assert.Contains(t, entries[0].Changes, "deleted_by")
+	assert.NotContains(t, entries[0].Changes, "email")
+}
+
+func TestDeletePaymentByID_NotFound(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	mockRepo.On("DeleteByID", mock.Anything, "gone", "").Return(domain.Payment{}, infra.ErrPaymentNotFound)
+
+	_, err := service.DeletePaymentByID(context.TODO(), "gone")
+
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+}
+
+func TestRestorePayment_Success(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	auditLog := audit.NewMemoryLog()
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), auditLog)
+
+	deletedAt := time.Now()
+	deleted := domain.Payment{Email: "del@x.com", Amount: money.New(50000, "IDR"), DeletedAt: &deletedAt, DeletedBy: "admin@x.com"}
+	mockRepo.On("Restore", mock.Anything, "del123").Return(deleted, nil)
+
+	result, err := service.RestorePayment(audit.WithActor(context.TODO(), audit.Actor{Email: "admin@x.com"}), "del123")
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.DeletedAt)
+	assert.Empty(t, result.DeletedBy)
+
+	entries, _ := auditLog.List(context.TODO(), audit.Filter{ResourceID: "del123"})
+	assert.Len(t, entries, 1)
+	assert.Equal(t, audit.ActionRestore, entries[0].Action)
+	assert.Equal(t, "admin@x.com", entries[0].Changes["deleted_by"].Before)
 }
 
 func TestGetAllPayments_Success(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
-	service := NewPaymentService(mockRepo)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
 
 	expected := []domain.Payment{
-		{Email: "a@a.com", Amount: 1},
-		{Email: "b@b.com", Amount: 2},
+		{Email: "a@a.com", Amount: money.New(100, "IDR")},
+		{Email: "b@b.com", Amount: money.New(200, "IDR")},
 	}
-	mockRepo.On("FindAll", mock.Anything).Return(expected, nil)
+	mockRepo.On("FindAll", mock.Anything, domain.PaymentFilter{}).Return(expected, nil)
 
-	result, err := service.GetAllPayments(context.TODO())
+	result, err := service.GetAllPayments(context.TODO(), domain.PaymentFilter{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetAllPayments_InvalidPage(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	_, err := service.GetAllPayments(context.TODO(), domain.PaymentFilter{AfterID: "not-an-id"})
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+
+	_, err = service.GetAllPayments(context.TODO(), domain.PaymentFilter{Limit: domain.MaxPageSize + 1})
+	assert.ErrorIs(t, err, ErrInvalidPageSize)
+
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
+
+func TestGetAllPayments_InvalidDateRange(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	now := time.Now()
+	filter := domain.PaymentFilter{CreatedFrom: now, CreatedTo: now.Add(-time.Hour)}
+
+	result, err := service.GetAllPayments(context.TODO(), filter)
+
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything)
+}
+
+func TestStreamPayments_Success(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	filter := domain.PaymentFilter{Status: "paid"}
+	expected := []domain.Payment{
+		{Email: "a@a.com", Amount: money.New(100, "IDR"), Status: "paid"},
+		{Email: "b@b.com", Amount: money.New(200, "IDR"), Status: "paid"},
+	}
+	mockRepo.On("Stream", mock.Anything, filter).Return(expected, nil)
+
+	var received []domain.Payment
+	err := service.StreamPayments(context.TODO(), filter, func(p domain.Payment) error {
+		received = append(received, p)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, received)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStreamPayments_StopsOnCallbackError(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	expected := []domain.Payment{{Email: "a@a.com"}, {Email: "b@b.com"}}
+	mockRepo.On("Stream", mock.Anything, domain.PaymentFilter{}).Return(expected, nil)
+
+	sendErr := errors.New("client gone")
+	calls := 0
+	err := service.StreamPayments(context.TODO(), domain.PaymentFilter{}, func(p domain.Payment) error {
+		calls++
+		return sendErr
+	})
+
+	assert.ErrorIs(t, err, sendErr)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCreatePayment_PublishesEvent(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	bus := NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository())
+	service := NewPaymentService(mockRepo, bus, events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(&mongo.InsertOneResult{}, nil)
+
+	_, events, cancel, err := bus.Subscribe(context.TODO(), 0)
+	assert.NoError(t, err)
+	defer cancel()
+
+	created, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1000, "IDR")})
+	assert.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, domain.PaymentEventCreated, event.Type)
+	assert.Equal(t, created.ID, event.Payment.ID)
+}
+
+func TestWatchPayments_ReplayAndFilter(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	bus := NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository())
+	service := NewPaymentService(mockRepo, bus, events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{Email: "a@a.com"})
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{Email: "b@b.com"})
+	bus.Publish(context.TODO(), domain.PaymentEventDeleted, domain.Payment{Email: "a@a.com"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var received []domain.PaymentEvent
+	err := service.WatchPayments(ctx, domain.PaymentWatchFilter{Email: "a@a.com"}, 1, func(event domain.PaymentEvent) error {
+		received = append(received, event)
+		cancel()
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, received, 1)
+	assert.Equal(t, uint64(3), received[0].ID)
+	assert.Equal(t, domain.PaymentEventDeleted, received[0].Type)
+}
+
+func TestWatchPayments_ReceivesLiveEvents(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	bus := NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository())
+	service := NewPaymentService(mockRepo, bus, events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	received := make(chan domain.PaymentEvent, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- service.WatchPayments(ctx, domain.PaymentWatchFilter{}, 0, func(event domain.PaymentEvent) error {
+			received <- event
+			return errors.New("stop")
+		})
+	}()
+
+	// tunggu sampai watcher ter-subscribe sebelum publish
+	assert.Eventually(t, func() bool {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		return len(bus.subs) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	bus.Publish(context.TODO(), domain.PaymentEventCreated, domain.Payment{Email: "live@x.com"})
+
+	event := <-received
+	assert.Equal(t, "live@x.com", event.Payment.Email)
+	assert.EqualError(t, <-done, "stop")
+}
+
+func TestCreatePayment_PublishesDomainEvent(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	broker := events.NewMemoryBroker()
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), broker, provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(&mongo.InsertOneResult{}, nil)
+
+	created, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1000, "IDR")})
+	assert.NoError(t, err)
+
+	published := broker.Published()
+	assert.Len(t, published, 1)
+	assert.Equal(t, events.TypePaymentCreated, published[0].Type)
+	assert.Equal(t, events.VersionPaymentCreated, published[0].Version)
+
+	var payload events.PaymentCreated
+	assert.NoError(t, published[0].Decode(&payload))
+	assert.Equal(t, created.ID.Hex(), payload.PaymentID)
+	assert.Equal(t, money.New(1000, "IDR"), payload.Amount)
+}
+
+func TestCreatePayment_RecordsProvider(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	mockRepo.On("Insert", mock.Anything, mock.MatchedBy(func(p domain.Payment) bool {
+		return p.CardToken == "" && p.ProviderRef != ""
+	})).Return(&mongo.InsertOneResult{}, nil)
+
+	result, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1000, "IDR"), CardToken: provider.TokenSuccess})
+
+	assert.NoError(t, err)
+	assert.Equal(t, provider.MethodCard, result.Method)
+	assert.Equal(t, "simulator", result.Provider)
+	assert.Equal(t, "paid", result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePayment_Declined(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	result, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1000, "IDR"), CardToken: provider.TokenInsufficientFunds})
+
+	assert.ErrorIs(t, err, ErrPaymentDeclined)
+	assert.Contains(t, err.Error(), provider.DeclineInsufficientFunds)
+	assert.Equal(t, domain.Payment{}, result)
+	mockRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+func TestCreatePayment_ProviderTimeout(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	providers := provider.NewSimulatorRegistry(provider.SimulatorConfig{Timeout: time.Millisecond})
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), providers, audit.NewMemoryLog())
+
+	_, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1054, "IDR")})
+
+	assert.ErrorIs(t, err, ErrProviderTimeout)
+	mockRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+func TestCreatePayment_UnsupportedMethod(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	_, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1000, "IDR"), Method: "crypto"})
+
+	assert.ErrorIs(t, err, ErrUnsupportedMethod)
+}
+
+func TestCreatePayment_DefaultsCurrency(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(&mongo.InsertOneResult{}, nil)
+
+	result, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.Money{MinorUnits: 1000}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(1000, money.DefaultCurrency), result.Amount)
+}
+
+func TestCreatePayment_InvalidCurrency(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	_, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(1000, "XYZ")})
+
+	assert.ErrorIs(t, err, ErrCurrencyInvalid)
+	mockRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+// buat payment lewat simulator supaya provider punya capture yang bisa di-refund
+func createCapturedPayment(t *testing.T, service PaymentService, mockRepo *MockPaymentRepository, amount money.Money) domain.Payment {
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(&mongo.InsertOneResult{}, nil).Once()
+	payment, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: amount})
+	assert.NoError(t, err)
+	return payment
+}
+
+func TestRefundPayment_Partial(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	broker := events.NewMemoryBroker()
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), broker, provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	payment := createCapturedPayment(t, service, mockRepo, money.New(10000, "IDR"))
+	id := payment.ID.Hex()
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.MatchedBy(func(r domain.Refund) bool {
+		return r.Amount == money.New(4000, "IDR") && r.Reference == "ret-1" && r.Pending()
+	}), 0).Return(payment, nil)
+	mockRepo.On("CompleteRefund", mock.Anything, id, mock.Anything, mock.MatchedBy(func(ref string) bool { return ref != "" })).Return(payment, nil)
+
+	_, refund, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{
+		Amount:    money.New(4000, "IDR"),
+		Reason:    "damaged",
+		Reference: "ret-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(4000, "IDR"), refund.Amount)
+	assert.Equal(t, domain.RefundStatusSucceeded, refund.Status)
+	mockRepo.AssertExpectations(t)
+
+	published := broker.Published()
+	assert.Equal(t, events.TypePaymentRefunded, published[len(published)-1].Type)
+}
+
+func TestRefundPayment_FullRemainingByDefault(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	payment := createCapturedPayment(t, service, mockRepo, money.New(10000, "IDR"))
+	payment.Status = domain.PaymentStatusPartiallyRefunded
+	payment.Refunds = []domain.Refund{{ID: "r1", Amount: money.New(3000, "IDR"), Reference: "ret-1"}}
+	id := payment.ID.Hex()
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.MatchedBy(func(r domain.Refund) bool {
+		return r.Amount == money.New(7000, "IDR")
+	}), 1).Return(payment, nil)
+	mockRepo.On("CompleteRefund", mock.Anything, id, mock.Anything, mock.Anything).Return(payment, nil)
+
+	_, refund, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{Reference: "ret-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(7000, "IDR"), refund.Amount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefundPayment_Exceeded(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	payment := domain.Payment{ID: primitive.NewObjectID(), Amount: money.New(10000, "IDR"), Status: domain.PaymentStatusPaid, Method: provider.MethodCard}
+	mockRepo.On("FindByID", mock.Anything, payment.ID.Hex()).Return(payment, nil)
+
+	_, _, err := service.RefundPayment(context.TODO(), payment.ID.Hex(), domain.RefundRequest{
+		Amount:    money.New(10001, "IDR"),
+		Reference: "ret-1",
+	})
+
+	assert.ErrorIs(t, err, ErrRefundExceeded)
+	mockRepo.AssertNotCalled(t, "ReserveRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_SameReferenceIsIdempotent(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	existing := domain.Refund{ID: "r1", Amount: money.New(10000, "IDR"), Reference: "ret-1"}
+	payment := domain.Payment{ID: primitive.NewObjectID(), Amount: money.New(10000, "IDR"), Status: domain.PaymentStatusRefunded, Refunds: []domain.Refund{existing}}
+	mockRepo.On("FindByID", mock.Anything, payment.ID.Hex()).Return(payment, nil)
+
+	_, refund, err := service.RefundPayment(context.TODO(), payment.ID.Hex(), domain.RefundRequest{Reference: "ret-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, existing, refund)
+	mockRepo.AssertNotCalled(t, "ReserveRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_PendingReference(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	pending := domain.Refund{ID: "r1", Amount: money.New(10000, "IDR"), Reference: "ret-1", Status: domain.RefundStatusPending}
+	payment := domain.Payment{ID: primitive.NewObjectID(), Amount: money.New(10000, "IDR"), Status: domain.PaymentStatusPaid, Refunds: []domain.Refund{pending}}
+	mockRepo.On("FindByID", mock.Anything, payment.ID.Hex()).Return(payment, nil)
+
+	_, _, err := service.RefundPayment(context.TODO(), payment.ID.Hex(), domain.RefundRequest{Reference: "ret-1"})
+
+	assert.ErrorIs(t, err, ErrRefundPending)
+	mockRepo.AssertNotCalled(t, "ReserveRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_ConflictSkipsProvider(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	payment := createCapturedPayment(t, service, mockRepo, money.New(10000, "IDR"))
+	id := payment.ID.Hex()
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.Anything, 0).Return(domain.Payment{}, infra.ErrRefundConflict)
+
+	_, _, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{Reference: "ret-1"})
+	assert.ErrorIs(t, err, ErrRefundConflict)
+	mockRepo.AssertNotCalled(t, "CompleteRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// provider belum dipanggil, seluruh nominal masih bisa di-refund
+	mockRepo.ExpectedCalls = nil
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.Anything, 0).Return(payment, nil)
+	mockRepo.On("CompleteRefund", mock.Anything, id, mock.Anything, mock.Anything).Return(payment, nil)
+
+	_, refund, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{Reference: "ret-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(10000, "IDR"), refund.Amount)
+}
+
+func TestRefundPayment_ProviderFailureReleasesPending(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	// reference tidak dikenal simulator, refund ditolak provider
+	payment := domain.Payment{ID: primitive.NewObjectID(), Amount: money.New(10000, "IDR"), Status: domain.PaymentStatusPaid, Method: provider.MethodCard, ProviderRef: "unknown"}
+	id := payment.ID.Hex()
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	var reserved domain.Refund
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.Anything, 0).Run(func(args mock.Arguments) {
+		reserved = args.Get(2).(domain.Refund)
+	}).Return(payment, nil)
+	mockRepo.On("ReleaseRefund", mock.Anything, id, mock.Anything).Return(nil)
+
+	_, _, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{Reference: "ret-1"})
+
+	assert.ErrorIs(t, err, ErrProviderFailed)
+	mockRepo.AssertCalled(t, "ReleaseRefund", mock.Anything, id, reserved.ID)
+	mockRepo.AssertNotCalled(t, "CompleteRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_RecordFailureKeepsPending(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	payment := createCapturedPayment(t, service, mockRepo, money.New(10000, "IDR"))
+	id := payment.ID.Hex()
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.Anything, 0).Return(payment, nil)
+	mockRepo.On("CompleteRefund", mock.Anything, id, mock.Anything, mock.Anything).Return(domain.Payment{}, errors.New("mongo down"))
+
+	_, _, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{Reference: "ret-1"})
+
+	assert.ErrorIs(t, err, ErrRefundRecordFailed)
+	mockRepo.AssertNotCalled(t, "ReleaseRefund", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefundPayment_NotFound(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+
+	mockRepo.On("FindByID", mock.Anything, "missing").Return(domain.Payment{}, nil)
+
+	_, _, err := service.RefundPayment(context.TODO(), "missing", domain.RefundRequest{Reference: "ret-1"})
+
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+}
+
+func TestCreatePayment_DeclinedCountsFailure(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+	failed := testutil.ToFloat64(paymentsFailed.WithLabelValues(provider.MethodCard, "declined"))
+	amount := testutil.ToFloat64(paymentsFailedAmount.WithLabelValues("IDR"))
+
+	_, err := service.CreatePayment(context.TODO(), domain.Payment{Email: "user@example.com", Amount: money.New(2500, "IDR"), CardToken: provider.TokenInsufficientFunds})
+
+	assert.ErrorIs(t, err, ErrPaymentDeclined)
+	assert.Equal(t, failed+1, testutil.ToFloat64(paymentsFailed.WithLabelValues(provider.MethodCard, "declined")))
+	assert.Equal(t, amount+25, testutil.ToFloat64(paymentsFailedAmount.WithLabelValues("IDR")))
+}
+
+func TestRefundPayment_CountsRefundedAmount(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	service := NewPaymentService(mockRepo, NewPaymentEventBus(0, infra.NewMemoryPaymentEventRepository()), events.NewMemoryBroker(), provider.NewSimulatorRegistry(provider.DefaultSimulatorConfig), audit.NewMemoryLog())
+	created := testutil.ToFloat64(paymentsCreated.WithLabelValues(provider.MethodCard))
+
+	payment := createCapturedPayment(t, service, mockRepo, money.New(10000, "IDR"))
+	id := payment.ID.Hex()
+	mockRepo.On("FindByID", mock.Anything, id).Return(payment, nil)
+	mockRepo.On("ReserveRefund", mock.Anything, id, mock.Anything, 0).Return(payment, nil)
+	mockRepo.On("CompleteRefund", mock.Anything, id, mock.Anything, mock.Anything).Return(payment, nil)
+	refunded := testutil.ToFloat64(paymentsRefundedAmount.WithLabelValues("IDR"))
+
+	_, _, err := service.RefundPayment(context.TODO(), id, domain.RefundRequest{Amount: money.New(4000, "IDR"), Reference: "ret-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, created+1, testutil.ToFloat64(paymentsCreated.WithLabelValues(provider.MethodCard)))
+	assert.Equal(t, refunded+40, testutil.ToFloat64(paymentsRefundedAmount.WithLabelValues("IDR")))
+}
@@ -1,20 +1,33 @@
 package app
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"time"
+
+	"shared/metrics"
 )
 
-// Cron job: cetak/bersihkan payment yang lebih dari 5 menit (simulasi)
-func StartPaymentCleanupJob() {
+// Cron job: cetak/bersihkan payment yang lebih dari 5 menit (simulasi),
+// berhenti saat ctx selesai
+func StartPaymentCleanupJob(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Minute) // tiap 1 menit
 	go func() {
-		for range ticker.C {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			start := time.Now()
 			err := cleanOldPayments()
+			metrics.ObserveJob("payment_cleanup", start, err)
 			if err != nil {
-				fmt.Println("Cleanup error:", err)
+				slog.Error("payment cleanup failed", "job", "payment_cleanup", "error", err)
 			} else {
-				fmt.Println("Cleanup done at", time.Now())
+				slog.Debug("payment cleanup done", "job", "payment_cleanup")
 			}
 		}
 	}()
@@ -23,7 +36,7 @@ func StartPaymentCleanupJob() {
 // Fungsi dummy: bisa diganti logic delete Mongo
 func cleanOldPayments() error {
 	// Simulasi: hapus data lebih dari 5 menit (dummy log)
-	fmt.Println("Simulating cleanup: deleting payments older than 5 minutes...")
+	slog.Debug("simulating cleanup of payments older than 5 minutes", "job", "payment_cleanup")
 	// Implementasi real: tambahkan query delete by CreatedAt < now - 5 minutes
 	return nil
 }
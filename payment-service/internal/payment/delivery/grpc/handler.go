@@ -2,9 +2,17 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"payment-service/internal/payment/app"
 	"payment-service/internal/payment/delivery/grpc/paymentpb"
 	"payment-service/internal/payment/domain"
+	"shared/audit"
+	"shared/money"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Handler gRPC
@@ -16,21 +24,18 @@ type PaymentHandler struct {
 // Tambah payment baru
 func (h *PaymentHandler) AddPayment(ctx context.Context, req *paymentpb.AddPaymentRequest) (*paymentpb.Payment, error) {
 	input := domain.Payment{
-		Email:  req.GetEmail(),
-		Amount: req.GetAmount(),
+		Email:     req.GetEmail(),
+		Amount:    toMoney(req.GetAmount()),
+		Method:    req.GetMethod(),
+		CardToken: req.GetCardToken(),
 	}
 
 	result, err := h.Service.CreatePayment(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, createPaymentError(err)
 	}
 
-	return &paymentpb.Payment{
-		Id:     result.ID.Hex(),
-		Email:  result.Email,
-		Amount: result.Amount,
-		Status: result.Status,
-	}, nil
+	return toPaymentPB(result), nil
 }
 
 // Ambil payment by ID
@@ -40,48 +45,274 @@ func (h *PaymentHandler) GetPaymentByID(ctx context.Context, req *paymentpb.GetP
 		return nil, err
 	}
 	if result.ID.IsZero() {
-		return nil, nil
+		return nil, status.Error(codes.NotFound, app.ErrPaymentNotFound.Error())
 	}
 
-	return &paymentpb.Payment{
-		Id:     result.ID.Hex(),
-		Email:  result.Email,
-		Amount: result.Amount,
-		Status: result.Status,
-	}, nil
+	return toPaymentPB(result), nil
 }
 
-// Hapus payment by ID
+// Soft delete payment by ID
 func (h *PaymentHandler) DeletePaymentByID(ctx context.Context, req *paymentpb.DeletePaymentByIDRequest) (*paymentpb.Payment, error) {
 	result, err := h.Service.DeletePaymentByID(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, app.ErrPaymentNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return toPaymentPB(result), nil
+}
+
+// Refund penuh atau parsial, reference yang sama aman dikirim ulang
+func (h *PaymentHandler) RefundPayment(ctx context.Context, req *paymentpb.RefundPaymentRequest) (*paymentpb.RefundPaymentResponse, error) {
+	input := domain.RefundRequest{
+		Amount:    toMoney(req.GetAmount()),
+		Reason:    req.GetReason(),
+		Reference: req.GetReference(),
+	}
+
+	payment, refund, err := h.Service.RefundPayment(ctx, req.GetId(), input)
+	if err != nil {
+		return nil, refundPaymentError(err)
+	}
+
+	return &paymentpb.RefundPaymentResponse{Payment: toPaymentPB(payment), Refund: toRefundPB(refund)}, nil
+}
+
+// Ambil payment yang di-soft delete
+func (h *PaymentHandler) ListDeletedPayments(ctx context.Context, req *paymentpb.ListDeletedPaymentsRequest) (*paymentpb.GetAllPaymentsResponse, error) {
+	data, err := h.Service.GetDeletedPayments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*paymentpb.Payment, 0, len(data))
+	for _, p := range data {
+		payments = append(payments, toPaymentPB(p))
+	}
+
+	return &paymentpb.GetAllPaymentsResponse{Payments: payments}, nil
+}
+
+// Kembalikan payment yang di-soft delete
+func (h *PaymentHandler) RestorePayment(ctx context.Context, req *paymentpb.RestorePaymentRequest) (*paymentpb.Payment, error) {
+	result, err := h.Service.RestorePayment(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, app.ErrPaymentNotDeleted) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	return toPaymentPB(result), nil
+}
+
+// Ambil audit log payment, terbaru dulu
+func (h *PaymentHandler) ListAuditLogs(ctx context.Context, req *paymentpb.ListAuditLogsRequest) (*paymentpb.ListAuditLogsResponse, error) {
+	entries, err := h.Service.GetAuditLogs(ctx, req.GetPaymentId(), req.GetLimit())
 	if err != nil {
 		return nil, err
 	}
 
-	return &paymentpb.Payment{
-		Id:     result.ID.Hex(),
-		Email:  result.Email,
-		Amount: result.Amount,
-		Status: result.Status,
-	}, nil
+	logs := make([]*paymentpb.AuditLog, 0, len(entries))
+	for _, e := range entries {
+		logs = append(logs, toAuditLogPB(e))
+	}
+
+	return &paymentpb.ListAuditLogsResponse{Logs: logs}, nil
 }
 
 // Ambil semua payment
-func (h *PaymentHandler) GetAllPayments(ctx context.Context, _ *paymentpb.GetAllPaymentsRequest) (*paymentpb.GetAllPaymentsResponse, error) {
-	data, err := h.Service.GetAllPayments(ctx)
+func (h *PaymentHandler) GetAllPayments(ctx context.Context, req *paymentpb.GetAllPaymentsRequest) (*paymentpb.GetAllPaymentsResponse, error) {
+	filter := toPaymentFilter(req.GetFilter())
+	filter.AfterID = req.GetPageToken()
+	filter.Limit = req.GetPageSize()
+
+	data, err := h.Service.GetAllPayments(ctx, filter)
 	if err != nil {
+		if errors.Is(err, app.ErrInvalidDateRange) || errors.Is(err, app.ErrInvalidPageToken) || errors.Is(err, app.ErrInvalidPageSize) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, err
 	}
 
 	var payments []*paymentpb.Payment
 	for _, p := range data {
-		payments = append(payments, &paymentpb.Payment{
-			Id:     p.ID.Hex(),
-			Email:  p.Email,
-			Amount: p.Amount,
-			Status: p.Status,
+		payments = append(payments, toPaymentPB(p))
+	}
+
+	return &paymentpb.GetAllPaymentsResponse{Payments: payments, NextPageToken: nextPageToken(data, filter.Limit)}, nil
+}
+
+// Stream payment satu per satu. Send akan blocking saat flow control gRPC
+// penuh, sehingga pembacaan cursor di repository ikut tertahan.
+func (h *PaymentHandler) StreamPayments(req *paymentpb.StreamPaymentsRequest, stream paymentpb.PaymentService_StreamPaymentsServer) error {
+	return h.Service.StreamPayments(stream.Context(), toPaymentFilter(req.GetFilter()), func(p domain.Payment) error {
+		return stream.Send(toPaymentPB(p))
+	})
+}
+
+// Kirim event perubahan payment sampai client menutup stream
+func (h *PaymentHandler) WatchPayments(req *paymentpb.WatchPaymentsRequest, stream paymentpb.PaymentService_WatchPaymentsServer) error {
+	filter := domain.PaymentWatchFilter{
+		PaymentID: req.GetPaymentId(),
+		Email:     req.GetEmail(),
+	}
+
+	return h.Service.WatchPayments(stream.Context(), filter, req.GetLastEventId(), func(event domain.PaymentEvent) error {
+		return stream.Send(&paymentpb.PaymentEvent{
+			Id:         event.ID,
+			Type:       event.Type,
+			Payment:    toPaymentPB(event.Payment),
+			OccurredAt: timestamppb.New(event.OccurredAt),
 		})
+	})
+}
+
+// Konversi domain.Payment ke message proto
+func toPaymentPB(p domain.Payment) *paymentpb.Payment {
+	result := &paymentpb.Payment{
+		Id:     p.ID.Hex(),
+		Email:  p.Email,
+		Amount: toMoneyPB(p.Amount),
+		Status: p.Status,
+
+		Method:      p.Method,
+		Provider:    p.Provider,
+		ProviderRef: p.ProviderRef,
 	}
+	if !p.CreatedAt.IsZero() {
+		result.CreatedAt = timestamppb.New(p.CreatedAt)
+	}
+	if p.DeletedAt != nil {
+		result.DeletedAt = timestamppb.New(*p.DeletedAt)
+		result.DeletedBy = p.DeletedBy
+	}
+	for _, r := range p.Refunds {
+		result.Refunds = append(result.Refunds, toRefundPB(r))
+	}
+	return result
+}
 
-	return &paymentpb.GetAllPaymentsResponse{Payments: payments}, nil
+// Konversi domain.Refund ke message proto, refund lama tanpa status
+// dikirim sebagai succeeded
+func toRefundPB(r domain.Refund) *paymentpb.Refund {
+	refundStatus := r.Status
+	if refundStatus == "" {
+		refundStatus = domain.RefundStatusSucceeded
+	}
+	result := &paymentpb.Refund{
+		Id:        r.ID,
+		Amount:    toMoneyPB(r.Amount),
+		Reason:    r.Reason,
+		Reference: r.Reference,
+		Status:    refundStatus,
+	}
+	if !r.CreatedAt.IsZero() {
+		result.CreatedAt = timestamppb.New(r.CreatedAt)
+	}
+	return result
+}
+
+// Konversi audit.Entry ke message proto, nilai before/after dikirim sebagai JSON
+func toAuditLogPB(e audit.Entry) *paymentpb.AuditLog {
+	changes := make(map[string]*paymentpb.AuditChange, len(e.Changes))
+	for field, c := range e.Changes {
+		changes[field] = &paymentpb.AuditChange{Before: toJSON(c.Before), After: toJSON(c.After)}
+	}
+	return &paymentpb.AuditLog{
+		Id:         e.ID.Hex(),
+		Resource:   e.Resource,
+		ResourceId: e.ResourceID,
+		Action:     e.Action,
+		Actor:      e.Actor,
+		RequestId:  e.RequestID,
+		Changes:    changes,
+		CreatedAt:  timestamppb.New(e.CreatedAt),
+	}
+}
+
+func toJSON(v any) string {
+	if v == nil {
+		return ""
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func toMoneyPB(m money.Money) *paymentpb.Money {
+	return &paymentpb.Money{MinorUnits: m.MinorUnits, Currency: m.Currency}
+}
+
+func toMoney(m *paymentpb.Money) money.Money {
+	return money.New(m.GetMinorUnits(), m.GetCurrency())
+}
+
+// Konversi filter proto ke domain.PaymentFilter
+// ID payment terakhir kalau halaman penuh, kosong kalau sudah habis
+func nextPageToken(payments []domain.Payment, limit int64) string {
+	if limit == 0 || int64(len(payments)) < limit {
+		return ""
+	}
+	return payments[len(payments)-1].ID.Hex()
+}
+
+func toPaymentFilter(f *paymentpb.PaymentFilter) domain.PaymentFilter {
+	filter := domain.PaymentFilter{
+		Email:  f.GetEmail(),
+		Status: f.GetStatus(),
+	}
+	if f.GetCreatedFrom() != nil {
+		filter.CreatedFrom = f.GetCreatedFrom().AsTime()
+	}
+	if f.GetCreatedTo() != nil {
+		filter.CreatedTo = f.GetCreatedTo().AsTime()
+	}
+	return filter
+}
+
+// Mapping error CreatePayment ke status gRPC supaya gateway bisa
+// membedakan input salah, ditolak provider dan provider timeout
+func createPaymentError(err error) error {
+	switch {
+	case errors.Is(err, app.ErrEmailEmpty), errors.Is(err, app.ErrAmountInvalid),
+		errors.Is(err, app.ErrCurrencyInvalid), errors.Is(err, app.ErrUnsupportedMethod):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, app.ErrPaymentDeclined):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, app.ErrProviderTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, app.ErrProviderFailed):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Mapping error RefundPayment ke status gRPC. Aborted berarti aman diulang
+// dengan reference yang sama.
+func refundPaymentError(err error) error {
+	switch {
+	case errors.Is(err, app.ErrPaymentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, app.ErrRefundReference), errors.Is(err, app.ErrAmountInvalid),
+		errors.Is(err, app.ErrCurrencyInvalid), errors.Is(err, app.ErrRefundExceeded):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, app.ErrRefundNotAllowed), errors.Is(err, app.ErrUnsupportedMethod):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, app.ErrRefundConflict), errors.Is(err, app.ErrRefundPending):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, app.ErrPaymentDeclined):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, app.ErrProviderTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, app.ErrProviderFailed):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
 }
@@ -2,14 +2,23 @@ package grpc
 
 import (
 	"context"
+	"fmt"
+	"payment-service/internal/payment/app"
 	"payment-service/internal/payment/delivery/grpc/paymentpb"
 	"payment-service/internal/payment/domain"
+	"shared/audit"
+	"shared/money"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Mock service
@@ -29,10 +38,70 @@ func (m *MockPaymentService) DeletePaymentByID(ctx context.Context, id string) (
 	args := m.Called(ctx, id)
 	return args.Get(0).(domain.Payment), args.Error(1)
 }
-func (m *MockPaymentService) GetAllPayments(ctx context.Context) ([]domain.Payment, error) {
+func (m *MockPaymentService) GetAllPayments(ctx context.Context, filter domain.PaymentFilter) ([]domain.Payment, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+func (m *MockPaymentService) StreamPayments(ctx context.Context, filter domain.PaymentFilter, fn func(domain.Payment) error) error {
+	args := m.Called(ctx, filter)
+	for _, p := range args.Get(0).([]domain.Payment) {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+func (m *MockPaymentService) RefundPayment(ctx context.Context, id string, req domain.RefundRequest) (domain.Payment, domain.Refund, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(domain.Payment), args.Get(1).(domain.Refund), args.Error(2)
+}
+func (m *MockPaymentService) GetDeletedPayments(ctx context.Context) ([]domain.Payment, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]domain.Payment), args.Error(1)
 }
+func (m *MockPaymentService) RestorePayment(ctx context.Context, id string) (domain.Payment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Payment), args.Error(1)
+}
+func (m *MockPaymentService) GetAuditLogs(ctx context.Context, paymentID string, limit int64) ([]audit.Entry, error) {
+	args := m.Called(ctx, paymentID, limit)
+	return args.Get(0).([]audit.Entry), args.Error(1)
+}
+func (m *MockPaymentService) WatchPayments(ctx context.Context, filter domain.PaymentWatchFilter, lastEventID uint64, fn func(domain.PaymentEvent) error) error {
+	args := m.Called(ctx, filter, lastEventID)
+	for _, event := range args.Get(0).([]domain.PaymentEvent) {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// Fake stream server untuk StreamPayments
+type fakePaymentStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*paymentpb.Payment
+}
+
+func (f *fakePaymentStream) Context() context.Context { return f.ctx }
+func (f *fakePaymentStream) Send(p *paymentpb.Payment) error {
+	f.sent = append(f.sent, p)
+	return nil
+}
+
+// Fake stream server untuk WatchPayments
+type fakeEventStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*paymentpb.PaymentEvent
+}
+
+func (f *fakeEventStream) Context() context.Context { return f.ctx }
+func (f *fakeEventStream) Send(e *paymentpb.PaymentEvent) error {
+	f.sent = append(f.sent, e)
+	return nil
+}
 
 //  TESTS
 
@@ -40,12 +109,12 @@ func TestAddPayment_Success(t *testing.T) {
 	mockSvc := new(MockPaymentService)
 	handler := &PaymentHandler{Service: mockSvc}
 
-	input := &paymentpb.AddPaymentRequest{Email: "user@example.com", Amount: 100}
+	input := &paymentpb.AddPaymentRequest{Email: "user@example.com", Amount: &paymentpb.Money{MinorUnits: 10000, Currency: "IDR"}}
 	fakeID := primitive.NewObjectID()
 	expected := domain.Payment{
 		ID:        fakeID,
 		Email:     "user@example.com",
-		Amount:    100,
+		Amount:    money.New(10000, "IDR"),
 		Status:    "paid",
 		CreatedAt: time.Now(),
 	}
@@ -55,7 +124,7 @@ func TestAddPayment_Success(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected.Email, resp.Email)
-	assert.Equal(t, expected.Amount, resp.Amount)
+	assert.Equal(t, expected.Amount.MinorUnits, resp.Amount.GetMinorUnits())
 	assert.Equal(t, expected.Status, resp.Status)
 	assert.Equal(t, expected.ID.Hex(), resp.Id)
 	mockSvc.AssertExpectations(t)
@@ -66,7 +135,7 @@ func TestGetPaymentByID_Success(t *testing.T) {
 	handler := &PaymentHandler{Service: mockSvc}
 
 	fakeID := primitive.NewObjectID()
-	expected := domain.Payment{ID: fakeID, Email: "x@y.com", Amount: 55.5, Status: "paid"}
+	expected := domain.Payment{ID: fakeID, Email: "x@y.com", Amount: money.New(5550, "IDR"), Status: "paid"}
 	mockSvc.On("GetPaymentByID", mock.Anything, "abc123").Return(expected, nil)
 
 	resp, err := handler.GetPaymentByID(context.TODO(), &paymentpb.GetPaymentByIDRequest{Id: "abc123"})
@@ -74,16 +143,28 @@ func TestGetPaymentByID_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expected.ID.Hex(), resp.Id)
 	assert.Equal(t, expected.Email, resp.Email)
-	assert.Equal(t, expected.Amount, resp.Amount)
+	assert.Equal(t, expected.Amount.MinorUnits, resp.Amount.GetMinorUnits())
 	assert.Equal(t, expected.Status, resp.Status)
 }
 
+func TestGetPaymentByID_NotFound(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	mockSvc.On("GetPaymentByID", mock.Anything, "missing").Return(domain.Payment{}, nil)
+
+	resp, err := handler.GetPaymentByID(context.TODO(), &paymentpb.GetPaymentByIDRequest{Id: "missing"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestDeletePaymentByID_Success(t *testing.T) {
 	mockSvc := new(MockPaymentService)
 	handler := &PaymentHandler{Service: mockSvc}
 
 	fakeID := primitive.NewObjectID()
-	expected := domain.Payment{ID: fakeID, Email: "del@x.com", Amount: 99, Status: "deleted"}
+	expected := domain.Payment{ID: fakeID, Email: "del@x.com", Amount: money.New(9900, "IDR"), Status: "deleted"}
 	mockSvc.On("DeletePaymentByID", mock.Anything, "del123").Return(expected, nil)
 
 	resp, err := handler.DeletePaymentByID(context.TODO(), &paymentpb.DeletePaymentByIDRequest{Id: "del123"})
@@ -91,7 +172,7 @@ func TestDeletePaymentByID_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expected.ID.Hex(), resp.Id)
 	assert.Equal(t, expected.Email, resp.Email)
-	assert.Equal(t, expected.Amount, resp.Amount)
+	assert.Equal(t, expected.Amount.MinorUnits, resp.Amount.GetMinorUnits())
 	assert.Equal(t, expected.Status, resp.Status)
 }
 
@@ -101,15 +182,217 @@ func TestGetAllPayments_Success(t *testing.T) {
 
 	fakeID := primitive.NewObjectID()
 	expected := []domain.Payment{
-		{ID: fakeID, Email: "a@a.com", Amount: 1, Status: "paid"},
+		{ID: fakeID, Email: "a@a.com", Amount: money.New(100, "IDR"), Status: "paid"},
 	}
-	mockSvc.On("GetAllPayments", mock.Anything).Return(expected, nil)
+	mockSvc.On("GetAllPayments", mock.Anything, domain.PaymentFilter{}).Return(expected, nil)
 
 	resp, err := handler.GetAllPayments(context.TODO(), &paymentpb.GetAllPaymentsRequest{})
 
 	assert.NoError(t, err)
 	assert.Len(t, resp.Payments, 1)
 	assert.Equal(t, "a@a.com", resp.Payments[0].Email)
-	assert.Equal(t, float64(1), resp.Payments[0].Amount)
+	assert.Equal(t, int64(100), resp.Payments[0].Amount.GetMinorUnits())
 	assert.Equal(t, "paid", resp.Payments[0].Status)
 }
+
+func TestGetAllPayments_WithFilter(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedFilter := domain.PaymentFilter{Email: "a@a.com", Status: "paid", CreatedFrom: from}
+	mockSvc.On("GetAllPayments", mock.Anything, expectedFilter).Return([]domain.Payment{}, nil)
+
+	_, err := handler.GetAllPayments(context.TODO(), &paymentpb.GetAllPaymentsRequest{
+		Filter: &paymentpb.PaymentFilter{Email: "a@a.com", Status: "paid", CreatedFrom: timestamppb.New(from)},
+	})
+
+	assert.NoError(t, err)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestGetAllPayments_Paging(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	after := primitive.NewObjectID()
+	page := []domain.Payment{
+		{ID: primitive.NewObjectID(), Email: "a@a.com", Amount: money.New(100, "IDR"), Status: "paid"},
+		{ID: primitive.NewObjectID(), Email: "b@b.com", Amount: money.New(200, "IDR"), Status: "paid"},
+	}
+	mockSvc.On("GetAllPayments", mock.Anything, domain.PaymentFilter{AfterID: after.Hex(), Limit: 2}).Return(page, nil)
+	mockSvc.On("GetAllPayments", mock.Anything, domain.PaymentFilter{AfterID: page[1].ID.Hex(), Limit: 2}).Return(page[:1], nil)
+
+	resp, err := handler.GetAllPayments(context.TODO(), &paymentpb.GetAllPaymentsRequest{PageSize: 2, PageToken: after.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Payments, 2)
+	assert.Equal(t, page[1].ID.Hex(), resp.NextPageToken)
+
+	// halaman tidak penuh berarti halaman terakhir
+	resp, err = handler.GetAllPayments(context.TODO(), &paymentpb.GetAllPaymentsRequest{PageSize: 2, PageToken: resp.NextPageToken})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.NextPageToken)
+}
+
+func TestGetAllPayments_InvalidPageToken(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	mockSvc.On("GetAllPayments", mock.Anything, domain.PaymentFilter{AfterID: "bad", Limit: 10}).Return([]domain.Payment(nil), app.ErrInvalidPageToken)
+
+	_, err := handler.GetAllPayments(context.TODO(), &paymentpb.GetAllPaymentsRequest{PageSize: 10, PageToken: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamPayments_Success(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := []domain.Payment{
+		{ID: primitive.NewObjectID(), Email: "a@a.com", Amount: money.New(100, "IDR"), Status: "paid", CreatedAt: createdAt},
+		{ID: primitive.NewObjectID(), Email: "b@b.com", Amount: money.New(200, "IDR"), Status: "paid", CreatedAt: createdAt},
+	}
+	mockSvc.On("StreamPayments", mock.Anything, domain.PaymentFilter{Status: "paid"}).Return(expected, nil)
+
+	stream := &fakePaymentStream{ctx: context.TODO()}
+	err := handler.StreamPayments(&paymentpb.StreamPaymentsRequest{
+		Filter: &paymentpb.PaymentFilter{Status: "paid"},
+	}, stream)
+
+	assert.NoError(t, err)
+	assert.Len(t, stream.sent, 2)
+	assert.Equal(t, expected[0].ID.Hex(), stream.sent[0].Id)
+	assert.Equal(t, "b@b.com", stream.sent[1].Email)
+	assert.Equal(t, createdAt, stream.sent[0].CreatedAt.AsTime())
+	mockSvc.AssertExpectations(t)
+}
+
+func TestWatchPayments_Success(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	payment := domain.Payment{ID: primitive.NewObjectID(), Email: "a@a.com", Amount: money.New(1000, "IDR"), Status: "paid"}
+	events := []domain.PaymentEvent{
+		{ID: 7, Type: domain.PaymentEventCreated, Payment: payment, OccurredAt: time.Now()},
+	}
+	filter := domain.PaymentWatchFilter{PaymentID: payment.ID.Hex()}
+	mockSvc.On("WatchPayments", mock.Anything, filter, uint64(6)).Return(events, nil)
+
+	stream := &fakeEventStream{ctx: context.TODO()}
+	err := handler.WatchPayments(&paymentpb.WatchPaymentsRequest{
+		PaymentId:   payment.ID.Hex(),
+		LastEventId: 6,
+	}, stream)
+
+	assert.NoError(t, err)
+	assert.Len(t, stream.sent, 1)
+	assert.Equal(t, uint64(7), stream.sent[0].Id)
+	assert.Equal(t, domain.PaymentEventCreated, stream.sent[0].Type)
+	assert.Equal(t, payment.ID.Hex(), stream.sent[0].Payment.Id)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestAddPayment_ErrorCodes(t *testing.T) {
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{app.ErrAmountInvalid, codes.InvalidArgument},
+		{app.ErrUnsupportedMethod, codes.InvalidArgument},
+		{fmt.Errorf("%w: card_declined", app.ErrPaymentDeclined), codes.FailedPrecondition},
+		{app.ErrProviderTimeout, codes.DeadlineExceeded},
+		{app.ErrInsertFailed, codes.Internal},
+	}
+
+	for _, tc := range cases {
+		mockSvc := new(MockPaymentService)
+		handler := &PaymentHandler{Service: mockSvc}
+		mockSvc.On("CreatePayment", mock.Anything, mock.Anything).Return(domain.Payment{}, tc.err)
+
+		_, err := handler.AddPayment(context.TODO(), &paymentpb.AddPaymentRequest{Email: "a@a.com", Amount: &paymentpb.Money{MinorUnits: 100}, CardToken: "tok_decline"})
+
+		assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+	}
+}
+
+func TestRefundPayment_Success(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	refund := domain.Refund{ID: "r1", Amount: money.New(4000, "IDR"), Reference: "return-1", Status: domain.RefundStatusSucceeded}
+	payment := domain.Payment{ID: primitive.NewObjectID(), Amount: money.New(10000, "IDR"), Status: domain.PaymentStatusPartiallyRefunded, Refunds: []domain.Refund{refund}}
+	mockSvc.On("RefundPayment", mock.Anything, payment.ID.Hex(), domain.RefundRequest{
+		Amount:    money.New(4000, "IDR"),
+		Reason:    "damaged",
+		Reference: "return-1",
+	}).Return(payment, refund, nil)
+
+	resp, err := handler.RefundPayment(context.TODO(), &paymentpb.RefundPaymentRequest{
+		Id:        payment.ID.Hex(),
+		Amount:    &paymentpb.Money{MinorUnits: 4000, Currency: "IDR"},
+		Reason:    "damaged",
+		Reference: "return-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", resp.Refund.Id)
+	assert.Equal(t, domain.PaymentStatusPartiallyRefunded, resp.Payment.Status)
+	assert.Len(t, resp.Payment.Refunds, 1)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestRefundPayment_ErrorCodes(t *testing.T) {
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{app.ErrPaymentNotFound, codes.NotFound},
+		{app.ErrRefundExceeded, codes.InvalidArgument},
+		{app.ErrRefundNotAllowed, codes.FailedPrecondition},
+		{app.ErrRefundConflict, codes.Aborted},
+		{app.ErrRefundPending, codes.Aborted},
+		{app.ErrProviderTimeout, codes.DeadlineExceeded},
+		{app.ErrRefundRecordFailed, codes.Internal},
+	}
+
+	for _, tc := range cases {
+		mockSvc := new(MockPaymentService)
+		handler := &PaymentHandler{Service: mockSvc}
+		mockSvc.On("RefundPayment", mock.Anything, mock.Anything, mock.Anything).Return(domain.Payment{}, domain.Refund{}, tc.err)
+
+		_, err := handler.RefundPayment(context.TODO(), &paymentpb.RefundPaymentRequest{Id: "p1", Reference: "return-1"})
+
+		assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+	}
+}
+
+func TestAuditUnaryInterceptor_SetsActor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs(MetadataUserEmail, "admin@x.com", MetadataRequestID, "req-1"))
+
+	var got audit.Actor
+	_, err := AuditUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		got = audit.ActorFrom(ctx)
+		return nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, audit.Actor{Email: "admin@x.com", RequestID: "req-1"}, got)
+}
+
+func TestListAuditLogs_EncodesChangesAsJSON(t *testing.T) {
+	mockSvc := new(MockPaymentService)
+	handler := &PaymentHandler{Service: mockSvc}
+
+	entry := audit.NewEntry("payment", "p1", audit.ActionUpdate, audit.Actor{Email: "admin@x.com"},
+		domain.Payment{Status: "paid"}, domain.Payment{Status: "refunded"})
+	mockSvc.On("GetAuditLogs", mock.Anything, "p1", int64(10)).Return([]audit.Entry{entry}, nil)
+
+	resp, err := handler.ListAuditLogs(context.TODO(), &paymentpb.ListAuditLogsRequest{PaymentId: "p1", Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Logs, 1)
+	assert.Equal(t, "admin@x.com", resp.Logs[0].Actor)
+	assert.Equal(t, `"paid"`, resp.Logs[0].Changes["status"].Before)
+	assert.Equal(t, `"refunded"`, resp.Logs[0].Changes["status"].After)
+}
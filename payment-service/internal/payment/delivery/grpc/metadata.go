@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+
+	"shared/audit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadata yang dikirim gateway, dipakai sebagai actor di audit log
+const (
+	MetadataUserEmail = "x-user-email"
+	MetadataRequestID = "x-request-id"
+)
+
+// Interceptor unary yang menaruh user dan request ID dari metadata ke ctx
+func AuditUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withActor(ctx), req)
+}
+
+func withActor(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return audit.WithActor(ctx, audit.Actor{
+		Email:     first(md.Get(MetadataUserEmail)),
+		RequestID: first(md.Get(MetadataRequestID)),
+	})
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
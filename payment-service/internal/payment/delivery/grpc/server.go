@@ -1,47 +1,110 @@
 package grpc
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
 	"net"
-	"os"
+	"time"
 
+	"payment-service/config"
 	"payment-service/internal/payment/app"
 	"payment-service/internal/payment/delivery/grpc/paymentpb"
 	"payment-service/internal/payment/infra"
+	"payment-service/internal/provider"
+	webhookapp "payment-service/internal/webhook/app"
+	webhookgrpc "payment-service/internal/webhook/delivery/grpc"
+	"payment-service/internal/webhook/delivery/grpc/webhookpb"
+	webhookinfra "payment-service/internal/webhook/infra"
+	"shared/audit"
+	"shared/events"
+	"shared/health"
+	"shared/logging"
+	"shared/metrics"
 
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
-// Jalankan gRPC server
-func StartGRPCServer() {
-	// Ambil port dari env
-	grpcPort := os.Getenv("GRPC_PORT")
-	if grpcPort == "" {
-		grpcPort = "50051"
-	}
+// Server gRPC payment beserta broker yang ditutup saat shutdown
+type Server struct {
+	GRPC   *grpc.Server
+	Broker events.Broker
+	lis    net.Listener
+}
 
+// Siapkan gRPC server, broker ikut dicek di readiness checker. Dispatcher
+// webhook dan status grpc.health.v1 berjalan sampai ctx selesai.
+func NewGRPCServer(ctx context.Context, cfg *config.Config, db *mongo.Database, checker *health.Checker) *Server {
 	// Buka koneksi TCP
-	lis, err := net.Listen("tcp", ":"+grpcPort)
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		logging.Fatal("failed to listen", "port", cfg.GRPCPort, "error", err)
 	}
 
 	// Inisialisasi gRPC server
-	grpcServer := grpc.NewServer()
+	// span dari trace context metadata, request ID & log per call dulu,
+	// metric per method, lalu actor audit dari metadata
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor, metrics.UnaryServerInterceptor, AuditUnaryInterceptor),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor, metrics.StreamServerInterceptor),
+	)
+
+	// Broker event domain (memory atau nats)
+	broker, err := events.Open(cfg.EventBroker, cfg.NATSOptions())
+	if err != nil {
+		logging.Fatal("failed to open event broker", "error", err)
+	}
+	checker.Add("broker", func(ctx context.Context) error { return broker.Ping() })
 
 	// Setup service dan handler
-	repo := infra.NewPaymentRepository()
-	service := app.NewPaymentService(repo)
+	repo := infra.NewPaymentRepository(db)
+	eventRepo := infra.NewPaymentEventRepository(db)
+	if err := eventRepo.EnsureIndexes(context.Background()); err != nil {
+		logging.Fatal("failed to create payment event indexes", "error", err)
+	}
+	bus := app.NewPaymentEventBus(app.DefaultEventHistory, eventRepo)
+	// Provider per metode pembayaran sesuai PAYMENT_PROVIDER
+	providers, err := provider.Open(cfg.PaymentProvider, provider.DefaultSimulatorConfig)
+	if err != nil {
+		logging.Fatal("failed to open payment provider", "error", err)
+	}
+	if cfg.PaymentProvider == provider.ProviderSimulator {
+		slog.Warn("payment provider simulator enabled, payments are not charged")
+	}
+	service := app.NewPaymentService(repo, bus, broker, providers, audit.NewMongoLog(db))
 	handler := &PaymentHandler{Service: service}
 
+	// Setup webhook: fan-out dari broker dan dispatcher antrian Mongo
+	webhookRepo := webhookinfra.NewWebhookRepository(db)
+	if err := webhookRepo.EnsureIndexes(context.Background()); err != nil {
+		logging.Fatal("failed to create webhook indexes", "error", err)
+	}
+	webhookService := webhookapp.NewWebhookService(webhookRepo, net.DefaultResolver)
+
+	dedupe, err := events.NewMongoDeduper(db, 7*24*time.Hour)
+	if err != nil {
+		logging.Fatal("failed to init event deduper", "error", err)
+	}
+	if err := webhookapp.SubscribeWebhookEvents(broker, dedupe, webhookService); err != nil {
+		logging.Fatal("failed to subscribe webhook events", "error", err)
+	}
+
+	dispatcher := webhookapp.NewDispatcher(webhookRepo, webhookinfra.NewHTTPWebhookSender(), webhookapp.DefaultDispatcherConfig)
+	dispatcher.Start(ctx)
+
 	// Daftarkan handler ke gRPC
 	paymentpb.RegisterPaymentServiceServer(grpcServer, handler)
+	webhookpb.RegisterWebhookServiceServer(grpcServer, &webhookgrpc.WebhookHandler{Service: webhookService})
+	// grpc.health.v1 untuk probe dan gateway
+	health.RegisterGRPC(ctx, grpcServer, checker, health.DefaultGRPCInterval)
 
-	fmt.Println("gRPC server running at port:", grpcPort)
+	return &Server{GRPC: grpcServer, Broker: broker, lis: lis}
+}
 
-	// Mulai server
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
-	}
+// Mulai melayani request, return nil setelah GracefulStop/Stop
+func (s *Server) Serve() error {
+	slog.Info("grpc server running", "addr", s.lis.Addr().String())
+	return s.GRPC.Serve(s.lis)
 }
@@ -1,16 +1,25 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
+
 	"payment-service/internal/payment/app"
 	"payment-service/internal/payment/domain"
-	"payment-service/internal/payment/infra"
 
 	"github.com/labstack/echo/v4"
 )
 
-// Inisialisasi service payment
-var paymentService = app.NewPaymentService(infra.NewPaymentRepository())
+// Handler REST payment, service di-inject dari main
+type PaymentHandler struct {
+	Service app.PaymentService
+}
+
+func NewPaymentHandler(service app.PaymentService) *PaymentHandler {
+	return &PaymentHandler{Service: service}
+}
 
 // CreatePayment godoc
 // @Summary Create a new payment
@@ -23,7 +32,7 @@ var paymentService = app.NewPaymentService(infra.NewPaymentRepository())
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /payments [post]
-func CreatePayment(c echo.Context) error {
+func (h *PaymentHandler) CreatePayment(c echo.Context) error {
 	var payload domain.Payment
 
 	// Ambil request body dan bind ke struct
@@ -32,7 +41,7 @@ func CreatePayment(c echo.Context) error {
 	}
 
 	// Panggil service untuk validasi dan simpan ke DB
-	result, err := paymentService.CreatePayment(c.Request().Context(), payload)
+	result, err := h.Service.CreatePayment(c.Request().Context(), payload)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
 	}
@@ -40,3 +49,102 @@ func CreatePayment(c echo.Context) error {
 	// Kirim response sukses
 	return c.JSON(http.StatusCreated, result)
 }
+
+// RefundPayment godoc
+// @Summary Refund a payment
+// @Description Refund penuh atau parsial. Amount kosong berarti sisa nominal, reference yang sama tidak di-refund dua kali
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param refund body domain.RefundRequest true "Refund Payload"
+// @Success 201 {object} domain.Refund
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /payments/{id}/refunds [post]
+func (h *PaymentHandler) RefundPayment(c echo.Context) error {
+	var payload domain.RefundRequest
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"message": app.ErrInvalidPayload.Error()})
+	}
+
+	_, refund, err := h.Service.RefundPayment(c.Request().Context(), c.Param("id"), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrPaymentNotFound):
+			return c.JSON(http.StatusNotFound, echo.Map{"message": err.Error()})
+		case errors.Is(err, app.ErrRefundNotAllowed), errors.Is(err, app.ErrRefundConflict), errors.Is(err, app.ErrRefundPending):
+			return c.JSON(http.StatusConflict, echo.Map{"message": err.Error()})
+		case errors.Is(err, app.ErrProviderFailed), errors.Is(err, app.ErrProviderTimeout), errors.Is(err, app.ErrRefundRecordFailed):
+			return c.JSON(http.StatusBadGateway, echo.Map{"message": err.Error()})
+		default:
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, refund)
+}
+
+// halaman list payment, next_after kosong kalau sudah halaman terakhir
+type paymentPage struct {
+	Data      []domain.Payment `json:"data"`
+	NextAfter string           `json:"next_after,omitempty"`
+}
+
+// ListPayments godoc
+// @Summary List payments per halaman
+// @Description Payment diurutkan by ID. Isi after dengan next_after dari halaman sebelumnya untuk halaman berikutnya
+// @Tags payments
+// @Produce json
+// @Param status query string false "Status payment"
+// @Param created_from query string false "RFC3339, inklusif"
+// @Param created_to query string false "RFC3339, eksklusif"
+// @Param after query string false "ID payment terakhir halaman sebelumnya"
+// @Param limit query int false "Jumlah per halaman (default 100, maks 500)"
+// @Success 200 {object} paymentPage
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /payments [get]
+func (h *PaymentHandler) ListPayments(c echo.Context) error {
+	filter := domain.PaymentFilter{
+		Email:   c.QueryParam("email"),
+		Status:  c.QueryParam("status"),
+		AfterID: c.QueryParam("after"),
+		Limit:   100,
+	}
+	for param, target := range map[string]*time.Time{"created_from": &filter.CreatedFrom, "created_to": &filter.CreatedTo} {
+		if v := c.QueryParam(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, echo.Map{"message": param + " must be RFC3339"})
+			}
+			*target = t
+		}
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit <= 0 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": app.ErrInvalidPageSize.Error()})
+		}
+		filter.Limit = limit
+	}
+
+	payments, err := h.Service.GetAllPayments(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidDateRange) || errors.Is(err, app.ErrInvalidPageToken) || errors.Is(err, app.ErrInvalidPageSize) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"message": err.Error()})
+	}
+
+	page := paymentPage{Data: payments}
+	if page.Data == nil {
+		page.Data = []domain.Payment{}
+	}
+	if int64(len(payments)) == filter.Limit {
+		page.NextAfter = payments[len(payments)-1].ID.Hex()
+	}
+	return c.JSON(http.StatusOK, page)
+}
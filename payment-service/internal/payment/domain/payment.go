@@ -3,13 +3,140 @@ package domain
 import (
 	"time"
 
+	"shared/money"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Payment struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Email     string             `bson:"email" json:"email"`
-	Amount    float64            `bson:"amount" json:"amount"`
+	Amount    money.Money        `bson:"amount" json:"amount"`
 	Status    string             `bson:"status" json:"status"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+
+	// Metode pembayaran dan referensi di provider yang memproses
+	Method      string `bson:"method" json:"method"`
+	Provider    string `bson:"provider,omitempty" json:"provider,omitempty"`
+	ProviderRef string `bson:"provider_ref,omitempty" json:"provider_ref,omitempty"`
+
+	// Diisi saat payment dihapus (soft delete)
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+
+	// Refund yang sudah diproses, terlama dulu
+	Refunds []Refund `bson:"refunds,omitempty" json:"refunds,omitempty"`
+
+	// Token kartu hanya diteruskan ke provider, tidak disimpan
+	CardToken string `bson:"-" json:"card_token,omitempty"`
+}
+
+// Status payment
+const (
+	PaymentStatusPaid              = "paid"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+)
+
+// Satu refund (penuh atau parsial) atas payment. Reference berasal dari
+// pemanggil (misalnya ID retur di shopping) dan unik per payment, refund
+// dengan reference yang sama tidak diproses dua kali.
+type Refund struct {
+	ID          string      `bson:"id" json:"id"`
+	Amount      money.Money `bson:"amount" json:"amount"`
+	Reason      string      `bson:"reason,omitempty" json:"reason,omitempty"`
+	Reference   string      `bson:"reference" json:"reference"`
+	Status      string      `bson:"status,omitempty" json:"status,omitempty"`
+	ProviderRef string      `bson:"provider_ref,omitempty" json:"provider_ref,omitempty"`
+	CreatedAt   time.Time   `bson:"created_at" json:"created_at"`
+}
+
+// Status refund. Refund disimpan pending sebelum dikirim ke provider dan
+// jadi succeeded setelah provider berhasil. Refund lama tanpa status
+// dianggap succeeded.
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+)
+
+// Refund masih menunggu hasil provider
+func (r Refund) Pending() bool {
+	return r.Status == RefundStatusPending
+}
+
+// Input refund, Amount kosong berarti sisa nominal yang belum di-refund
+type RefundRequest struct {
+	Amount    money.Money `json:"amount"`
+	Reason    string      `json:"reason"`
+	Reference string      `json:"reference"`
+}
+
+// Total nominal yang sudah di-refund, termasuk refund pending supaya
+// nominal yang sedang diproses tidak bisa di-refund lagi
+func (p Payment) RefundedAmount() money.Money {
+	total := money.New(0, p.Amount.Currency)
+	for _, r := range p.Refunds {
+		total.MinorUnits += r.Amount.MinorUnits
+	}
+	return total
+}
+
+// Cari refund by reference
+func (p Payment) RefundByReference(reference string) (Refund, bool) {
+	for _, r := range p.Refunds {
+		if r.Reference == reference {
+			return r, true
+		}
+	}
+	return Refund{}, false
+}
+
+// Filter untuk list dan stream payment, field kosong diabaikan
+type PaymentFilter struct {
+	Email       string
+	Status      string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Paging by _id: hanya payment setelah AfterID, maksimal Limit
+	// (0 berarti semua). Dipakai list, stream tidak memakai Limit.
+	AfterID string
+	Limit   int64
+}
+
+// Batas Limit di PaymentFilter
+const MaxPageSize = 500
+
+// Jenis event perubahan payment
+const (
+	PaymentEventCreated  = "payment.created"
+	PaymentEventDeleted  = "payment.deleted"
+	PaymentEventRefunded = "payment.refunded"
+	PaymentEventRestored = "payment.restored"
+)
+
+// Event perubahan state payment. ID dari sequence yang disimpan di Mongo,
+// naik berurutan lintas restart dan dipakai client untuk resume (last
+// event id).
+type PaymentEvent struct {
+	ID         uint64    `bson:"_id"`
+	Type       string    `bson:"type"`
+	Payment    Payment   `bson:"payment"`
+	OccurredAt time.Time `bson:"occurred_at"`
+}
+
+// Filter untuk watch payment, field kosong diabaikan
+type PaymentWatchFilter struct {
+	PaymentID string
+	Email     string
+}
+
+// Cek apakah event cocok dengan filter
+func (f PaymentWatchFilter) Match(event PaymentEvent) bool {
+	if f.PaymentID != "" && event.Payment.ID.Hex() != f.PaymentID {
+		return false
+	}
+	if f.Email != "" && event.Payment.Email != f.Email {
+		return false
+	}
+	return true
 }
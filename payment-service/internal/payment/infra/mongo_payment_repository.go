@@ -3,32 +3,59 @@ package infra
 import (
 	"context"
 	"errors"
-	"payment-service/config"
 	"payment-service/internal/payment/domain"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Jumlah dokumen per batch cursor saat streaming
+const streamBatchSize = 100
+
 // Interface repository
 type PaymentRepository interface {
 	Insert(ctx context.Context, payment domain.Payment) (*mongo.InsertOneResult, error)
 	FindByID(ctx context.Context, id string) (domain.Payment, error)
-	DeleteByID(ctx context.Context, id string) (domain.Payment, error)
-	FindAll(ctx context.Context) ([]domain.Payment, error)
+	// soft delete, payment yang dihapus tidak ikut query lain selain FindDeleted
+	DeleteByID(ctx context.Context, id string, deletedBy string) (domain.Payment, error)
+	FindDeleted(ctx context.Context) ([]domain.Payment, error)
+	Restore(ctx context.Context, id string) (domain.Payment, error)
+	FindAll(ctx context.Context, filter domain.PaymentFilter) ([]domain.Payment, error)
+	Stream(ctx context.Context, filter domain.PaymentFilter, fn func(domain.Payment) error) error
+	// Simpan refund pending kalau jumlah refund tersimpan masih refundCount
+	// (version check), dipanggil sebelum refund dikirim ke provider
+	ReserveRefund(ctx context.Context, id string, refund domain.Refund, refundCount int) (domain.Payment, error)
+	// Tandai refund pending berhasil dan hitung ulang status payment,
+	// kembalikan payment setelah update
+	CompleteRefund(ctx context.Context, id, refundID, providerRef string) (domain.Payment, error)
+	// Hapus refund pending yang ditolak provider
+	ReleaseRefund(ctx context.Context, id, refundID string) error
 }
 
+// Payment sudah berubah (refund lain masuk lebih dulu) sejak dibaca
+var ErrRefundConflict = errors.New("payment was refunded concurrently")
+
+// Refund tidak ada atau sudah tidak pending
+var ErrRefundNotPending = errors.New("refund not found or not pending")
+
+// Payment tidak ada atau (untuk Restore) tidak sedang dihapus
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// payment yang di-soft delete tidak ikut query biasa
+var notDeleted = bson.M{"$exists": false}
+
 // Implementasi repository
 type paymentRepository struct {
 	collection *mongo.Collection
 }
 
 // Inisialisasi repository
-func NewPaymentRepository() PaymentRepository {
+func NewPaymentRepository(db *mongo.Database) PaymentRepository {
 	return &paymentRepository{
-		collection: config.DB.Collection("payments"),
+		collection: db.Collection("payments"),
 	}
 }
 
@@ -51,7 +78,7 @@ func (r *paymentRepository) FindByID(ctx context.Context, id string) (domain.Pay
 	}
 
 	var result domain.Payment
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&result)
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID, "deleted_at": notDeleted}).Decode(&result)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return domain.Payment{}, nil
@@ -62,8 +89,8 @@ func (r *paymentRepository) FindByID(ctx context.Context, id string) (domain.Pay
 	return result, nil
 }
 
-// Hapus data berdasarkan ID
-func (r *paymentRepository) DeleteByID(ctx context.Context, id string) (domain.Payment, error) {
+// Soft delete: dokumen tetap ada dengan deleted_at dan deleted_by
+func (r *paymentRepository) DeleteByID(ctx context.Context, id string, deletedBy string) (domain.Payment, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -72,29 +99,77 @@ func (r *paymentRepository) DeleteByID(ctx context.Context, id string) (domain.P
 		return domain.Payment{}, err
 	}
 
-	var deleted domain.Payment
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&deleted)
+	var deleted domain.Payment
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objID, "deleted_at": notDeleted}, update, opts).Decode(&deleted)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Payment{}, ErrPaymentNotFound
+		}
 		return domain.Payment{}, err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return deleted, nil
+}
+
+// Payment yang di-soft delete, terbaru dihapus dulu
+func (r *paymentRepository) FindDeleted(ctx context.Context) ([]domain.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []domain.Payment{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Kembalikan payment yang di-soft delete. Hasilnya dokumen sebelum
+// dikembalikan supaya caller masih tahu kapan dan oleh siapa dihapus.
+func (r *paymentRepository) Restore(ctx context.Context, id string) (domain.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return domain.Payment{}, err
 	}
 
-	deleted.Status = "deleted"
+	update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
 
-	return deleted, nil
+	var restored domain.Payment
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objID, "deleted_at": bson.M{"$exists": true}}, update, opts).Decode(&restored)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Payment{}, ErrPaymentNotFound
+		}
+		return domain.Payment{}, err
+	}
+
+	return restored, nil
 }
 
-// Ambil semua data
-func (r *paymentRepository) FindAll(ctx context.Context) ([]domain.Payment, error) {
+// Ambil semua data sesuai filter
+func (r *paymentRepository) FindAll(ctx context.Context, filter domain.PaymentFilter) ([]domain.Payment, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, buildPaymentFilter(filter), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -111,3 +186,165 @@ func (r *paymentRepository) FindAll(ctx context.Context) ([]domain.Payment, erro
 
 	return results, nil
 }
+
+// Iterasi payment satu per satu dari cursor. fn dipanggil per dokumen dan
+// cursor baru lanjut setelah fn selesai, jadi consumer yang lambat ikut
+// menahan pembacaan dari Mongo. Tidak pakai timeout karena export bisa lama,
+// batasnya mengikuti ctx dari caller.
+func (r *paymentRepository) Stream(ctx context.Context, filter domain.PaymentFilter, fn func(domain.Payment) error) error {
+	opts := options.Find().
+		SetBatchSize(streamBatchSize).
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, buildPaymentFilter(filter), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var p domain.Payment
+		if err := cursor.Decode(&p); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// Simpan refund pending. Filter refundCount memastikan tidak ada refund
+// lain yang masuk sejak payment dibaca, reference yang sama ditolak.
+func (r *paymentRepository) ReserveRefund(ctx context.Context, id string, refund domain.Refund, refundCount int) (domain.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	filter := bson.M{
+		"_id":               objID,
+		"deleted_at":        notDeleted,
+		"refunds.reference": bson.M{"$ne": refund.Reference},
+		"$expr": bson.M{"$eq": bson.A{
+			bson.M{"$size": bson.M{"$ifNull": bson.A{"$refunds", bson.A{}}}},
+			refundCount,
+		}},
+	}
+	update := bson.M{"$push": bson.M{"refunds": refund}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated domain.Payment
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Payment{}, ErrRefundConflict
+		}
+		return domain.Payment{}, err
+	}
+	return updated, nil
+}
+
+// Tandai refund succeeded lalu set status payment dari total refund yang
+// sudah berhasil, dalam satu update supaya refund yang selesai bersamaan
+// tidak saling menimpa status
+func (r *paymentRepository) CompleteRefund(ctx context.Context, id, refundID, providerRef string) (domain.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	filter := bson.M{
+		"_id":     objID,
+		"refunds": bson.M{"$elemMatch": bson.M{"id": refundID, "status": domain.RefundStatusPending}},
+	}
+	// refund lama tanpa status dianggap succeeded
+	succeeded := bson.M{"$filter": bson.M{
+		"input": "$refunds",
+		"cond": bson.M{"$eq": bson.A{
+			bson.M{"$ifNull": bson.A{"$$this.status", domain.RefundStatusSucceeded}},
+			domain.RefundStatusSucceeded,
+		}},
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"refunds": bson.M{"$map": bson.M{
+			"input": "$refunds",
+			"as":    "r",
+			"in": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$$r.id", refundID}},
+				bson.M{"$mergeObjects": bson.A{"$$r", bson.M{"status": domain.RefundStatusSucceeded, "provider_ref": providerRef}}},
+				"$$r",
+			}},
+		}}}}},
+		{{Key: "$set", Value: bson.M{"status": bson.M{"$cond": bson.A{
+			bson.M{"$gte": bson.A{
+				bson.M{"$sum": bson.M{"$map": bson.M{"input": succeeded, "in": "$$this.amount.minor_units"}}},
+				"$amount.minor_units",
+			}},
+			domain.PaymentStatusRefunded,
+			domain.PaymentStatusPartiallyRefunded,
+		}}}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updated domain.Payment
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Payment{}, ErrRefundNotPending
+		}
+		return domain.Payment{}, err
+	}
+	return updated, nil
+}
+
+// Hapus refund pending, nominalnya kembali bisa di-refund
+func (r *paymentRepository) ReleaseRefund(ctx context.Context, id, refundID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": objID},
+		bson.M{"$pull": bson.M{"refunds": bson.M{"id": refundID, "status": domain.RefundStatusPending}}},
+	)
+	return err
+}
+
+// Susun query Mongo dari filter
+func buildPaymentFilter(filter domain.PaymentFilter) bson.M {
+	query := bson.M{"deleted_at": notDeleted}
+	if filter.Email != "" {
+		query["email"] = filter.Email
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if afterID, err := primitive.ObjectIDFromHex(filter.AfterID); err == nil {
+		query["_id"] = bson.M{"$gt": afterID}
+	}
+
+	createdAt := bson.M{}
+	if !filter.CreatedFrom.IsZero() {
+		createdAt["$gte"] = filter.CreatedFrom
+	}
+	if !filter.CreatedTo.IsZero() {
+		createdAt["$lt"] = filter.CreatedTo
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	return query
+}
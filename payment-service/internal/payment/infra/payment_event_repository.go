@@ -0,0 +1,137 @@
+package infra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"payment-service/internal/payment/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Event watch disimpan selama ini, cukup untuk client yang reconnect
+const paymentEventRetention = 24 * time.Hour
+
+// Penyimpanan event watch payment. ID event diambil dari sequence yang
+// persisten, jadi last event id client tetap berlaku setelah restart.
+type PaymentEventRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// isi event.ID dengan sequence berikutnya lalu simpan event
+	Append(ctx context.Context, event *domain.PaymentEvent) error
+	// event dengan ID > afterID urut ID, maksimal limit event terakhir
+	FindAfter(ctx context.Context, afterID uint64, limit int) ([]domain.PaymentEvent, error)
+}
+
+type paymentEventRepository struct {
+	events   *mongo.Collection
+	counters *mongo.Collection
+}
+
+// Inisialisasi repository event, sequence di collection "counters"
+func NewPaymentEventRepository(db *mongo.Database) PaymentEventRepository {
+	return &paymentEventRepository{
+		events:   db.Collection("payment_events"),
+		counters: db.Collection("counters"),
+	}
+}
+
+// Index TTL supaya event lama terhapus otomatis
+func (r *paymentEventRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.events.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "occurred_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(paymentEventRetention.Seconds())),
+	})
+	return err
+}
+
+// Ambil sequence berikutnya secara atomic ($inc upsert) lalu simpan event
+func (r *paymentEventRepository) Append(ctx context.Context, event *domain.PaymentEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.counters.FindOneAndUpdate(ctx,
+		bson.M{"_id": "payment_events"},
+		bson.M{"$inc": bson.M{"seq": int64(1)}},
+		opts,
+	).Decode(&counter)
+	if err != nil {
+		return err
+	}
+
+	event.ID = uint64(counter.Seq)
+	_, err = r.events.InsertOne(ctx, event)
+	return err
+}
+
+// Ambil event setelah afterID. Kalau lebih dari limit, yang diambil limit
+// event terakhir supaya client yang tertinggal jauh tetap dapat event baru.
+func (r *paymentEventRepository) FindAfter(ctx context.Context, afterID uint64, limit int) ([]domain.PaymentEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.events.Find(ctx, bson.M{"_id": bson.M{"$gt": int64(afterID)}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []domain.PaymentEvent
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	// dibaca terbaru dulu, dikembalikan terlama dulu
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+// Penyimpanan event di memory untuk test dan pemakaian tanpa Mongo
+type memoryPaymentEventRepository struct {
+	mu     sync.Mutex
+	seq    uint64
+	events []domain.PaymentEvent
+}
+
+func NewMemoryPaymentEventRepository() PaymentEventRepository {
+	return &memoryPaymentEventRepository{}
+}
+
+func (r *memoryPaymentEventRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryPaymentEventRepository) Append(ctx context.Context, event *domain.PaymentEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	event.ID = r.seq
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryPaymentEventRepository) FindAfter(ctx context.Context, afterID uint64, limit int) ([]domain.PaymentEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []domain.PaymentEvent
+	for _, event := range r.events {
+		if event.ID > afterID {
+			result = append(result, event)
+		}
+	}
+	if len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
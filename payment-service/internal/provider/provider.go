@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"shared/money"
+)
+
+// Metode pembayaran yang bisa didaftarkan ke registry
+const (
+	MethodCard         = "card"
+	MethodBankTransfer = "bank_transfer"
+	MethodEWallet      = "ewallet"
+)
+
+// Status hasil operasi di provider
+const (
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusVoided     = "voided"
+)
+
+var (
+	ErrDeclined          = errors.New("payment declined")
+	ErrTimeout           = errors.New("payment provider timed out")
+	ErrUnknownMethod     = errors.New("no provider registered for payment method")
+	ErrReferenceNotFound = errors.New("provider reference not found")
+	ErrInvalidState      = errors.New("operation not allowed in current state")
+	ErrAmountExceeded    = errors.New("amount exceeds remaining balance")
+)
+
+// Error penolakan dari provider, Code mengikuti decline code processor.
+// errors.Is(err, ErrDeclined) bernilai true.
+type DeclineError struct {
+	Code string
+}
+
+func (e *DeclineError) Error() string {
+	return "payment declined: " + e.Code
+}
+
+func (e *DeclineError) Unwrap() error {
+	return ErrDeclined
+}
+
+// Data yang dikirim saat authorize
+type AuthorizeRequest struct {
+	PaymentID string
+	Email     string
+	Amount    money.Money
+	Method    string
+	CardToken string
+}
+
+// Hasil satu operasi di provider. Reference dipakai untuk operasi
+// berikutnya (capture/void memakai reference authorize).
+type Result struct {
+	Provider  string
+	Reference string
+	Status    string
+	Amount    money.Money
+}
+
+// PaymentProvider adalah adapter ke satu processor pembayaran
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	Capture(ctx context.Context, reference string, amount money.Money) (Result, error)
+	Refund(ctx context.Context, reference string, amount money.Money) (Result, error)
+	Void(ctx context.Context, reference string) (Result, error)
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry memilih provider berdasarkan metode pembayaran
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]PaymentProvider
+}
+
+// Nama provider untuk PAYMENT_PROVIDER
+const ProviderSimulator = simulatorName
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]PaymentProvider)}
+}
+
+// Registry dengan simulator untuk semua metode bawaan, dipakai
+// selama belum ada processor sungguhan
+func NewSimulatorRegistry(cfg SimulatorConfig) *Registry {
+	simulator := NewSimulator(cfg)
+	registry := NewRegistry()
+	for _, method := range []string{MethodCard, MethodBankTransfer, MethodEWallet} {
+		registry.Register(method, simulator)
+	}
+	return registry
+}
+
+// Buka registry sesuai PAYMENT_PROVIDER. Tidak ada default: simulator
+// menyetujui pembayaran tanpa memindahkan dana, jadi harus dipilih eksplisit.
+// simCfg hanya dipakai untuk simulator.
+func Open(kind string, simCfg SimulatorConfig) (*Registry, error) {
+	switch kind {
+	case ProviderSimulator:
+		return NewSimulatorRegistry(simCfg), nil
+	case "":
+		return nil, errors.New("payment provider is not configured")
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", kind)
+	}
+}
+
+// Daftarkan provider untuk metode, menimpa yang lama kalau ada
+func (r *Registry) Register(method string, provider PaymentProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[method] = provider
+}
+
+// Ambil provider untuk metode
+func (r *Registry) For(method string) (PaymentProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMethod, method)
+	}
+	return provider, nil
+}
+
+// Daftar metode yang terdaftar, urut abjad
+func (r *Registry) Methods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	methods := make([]string, 0, len(r.providers))
+	for method := range r.providers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
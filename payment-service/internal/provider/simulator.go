@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"shared/money"
+)
+
+// Card token untuk memilih skenario simulator
+const (
+	TokenSuccess           = "tok_success"
+	TokenDecline           = "tok_decline"
+	TokenInsufficientFunds = "tok_insufficient_funds"
+	TokenTimeout           = "tok_timeout"
+	TokenLateSuccess       = "tok_late_success"
+)
+
+// Decline code yang dikembalikan simulator
+const (
+	DeclineCardDeclined      = "card_declined"
+	DeclineInsufficientFunds = "insufficient_funds"
+)
+
+// Skenario juga bisa dipilih lewat dua digit terakhir minor unit amount,
+// misalnya IDR 100.51 selalu ditolak
+const (
+	CentsDecline           = 51
+	CentsInsufficientFunds = 52
+	CentsTimeout           = 54
+	CentsLateSuccess       = 55
+)
+
+type scenario int
+
+const (
+	scenarioSuccess scenario = iota
+	scenarioDecline
+	scenarioInsufficientFunds
+	scenarioTimeout
+	scenarioLateSuccess
+)
+
+type SimulatorConfig struct {
+	// Lama skenario timeout menggantung sebelum mengembalikan ErrTimeout
+	Timeout time.Duration
+	// Jeda sebelum skenario late success berhasil
+	LateDelay time.Duration
+}
+
+var DefaultSimulatorConfig = SimulatorConfig{
+	Timeout:   30 * time.Second,
+	LateDelay: 3 * time.Second,
+}
+
+const simulatorName = "simulator"
+
+// State satu authorization di simulator
+type simPayment struct {
+	status     string
+	currency   string
+	authorized int64
+	captured   int64
+	refunded   int64
+}
+
+// Simulator adalah provider lokal yang deterministik. Hasil authorize
+// ditentukan oleh card token atau sen amount, sehingga jalur gagal bisa
+// dites tanpa processor eksternal. Capture, refund dan void mengikuti
+// state authorization yang disimpan di memory.
+type Simulator struct {
+	cfg SimulatorConfig
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*simPayment
+	refunds  map[string]string // reference refund -> reference authorize
+}
+
+func NewSimulator(cfg SimulatorConfig) *Simulator {
+	return &Simulator{
+		cfg:      cfg,
+		payments: make(map[string]*simPayment),
+		refunds:  make(map[string]string),
+	}
+}
+
+func (s *Simulator) Name() string {
+	return simulatorName
+}
+
+func (s *Simulator) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	switch scenarioFor(req) {
+	case scenarioDecline:
+		return Result{}, &DeclineError{Code: DeclineCardDeclined}
+	case scenarioInsufficientFunds:
+		return Result{}, &DeclineError{Code: DeclineInsufficientFunds}
+	case scenarioTimeout:
+		// tidak pernah berhasil, tunggu sampai batas waktu
+		s.wait(ctx, s.cfg.Timeout)
+		return Result{}, ErrTimeout
+	case scenarioLateSuccess:
+		if !s.wait(ctx, s.cfg.LateDelay) {
+			return Result{}, ErrTimeout
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reference := s.nextReference("auth")
+	s.payments[reference] = &simPayment{
+		status:     StatusAuthorized,
+		currency:   req.Amount.Currency,
+		authorized: req.Amount.MinorUnits,
+	}
+
+	return s.result(reference, StatusAuthorized, req.Amount), nil
+}
+
+func (s *Simulator) Capture(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payment, ok := s.payments[reference]
+	if !ok {
+		return Result{}, ErrReferenceNotFound
+	}
+	if payment.status != StatusAuthorized {
+		return Result{}, fmt.Errorf("%w: capture %s payment", ErrInvalidState, payment.status)
+	}
+	if amount.Currency != payment.currency {
+		return Result{}, money.ErrCurrencyMismatch
+	}
+	if amount.MinorUnits > payment.authorized {
+		return Result{}, ErrAmountExceeded
+	}
+
+	payment.status = StatusCaptured
+	payment.captured = amount.MinorUnits
+
+	return s.result(reference, StatusCaptured, amount), nil
+}
+
+// Refund bisa parsial, reference boleh reference authorize atau refund sebelumnya
+func (s *Simulator) Refund(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if original, ok := s.refunds[reference]; ok {
+		reference = original
+	}
+	payment, ok := s.payments[reference]
+	if !ok {
+		return Result{}, ErrReferenceNotFound
+	}
+	if payment.status != StatusCaptured && payment.status != StatusRefunded {
+		return Result{}, fmt.Errorf("%w: refund %s payment", ErrInvalidState, payment.status)
+	}
+	if amount.Currency != payment.currency {
+		return Result{}, money.ErrCurrencyMismatch
+	}
+	if !amount.IsPositive() || payment.refunded+amount.MinorUnits > payment.captured {
+		return Result{}, ErrAmountExceeded
+	}
+
+	payment.refunded += amount.MinorUnits
+	if payment.refunded == payment.captured {
+		payment.status = StatusRefunded
+	}
+
+	refundRef := s.nextReference("rf")
+	s.refunds[refundRef] = reference
+
+	return s.result(refundRef, StatusRefunded, amount), nil
+}
+
+func (s *Simulator) Void(ctx context.Context, reference string) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payment, ok := s.payments[reference]
+	if !ok {
+		return Result{}, ErrReferenceNotFound
+	}
+	if payment.status != StatusAuthorized {
+		return Result{}, fmt.Errorf("%w: void %s payment", ErrInvalidState, payment.status)
+	}
+
+	payment.status = StatusVoided
+
+	return s.result(reference, StatusVoided, money.New(payment.authorized, payment.currency)), nil
+}
+
+// Tunggu d atau sampai ctx selesai. true kalau d habis lebih dulu.
+func (s *Simulator) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Reference berurutan supaya hasil simulator bisa diprediksi di test
+func (s *Simulator) nextReference(kind string) string {
+	s.seq++
+	return fmt.Sprintf("sim_%s_%06d", kind, s.seq)
+}
+
+func (s *Simulator) result(reference, status string, amount money.Money) Result {
+	return Result{Provider: simulatorName, Reference: reference, Status: status, Amount: amount}
+}
+
+// Card token diutamakan, kalau tidak dikenal pakai minor unit amount
+func scenarioFor(req AuthorizeRequest) scenario {
+	switch req.CardToken {
+	case TokenSuccess:
+		return scenarioSuccess
+	case TokenDecline:
+		return scenarioDecline
+	case TokenInsufficientFunds:
+		return scenarioInsufficientFunds
+	case TokenTimeout:
+		return scenarioTimeout
+	case TokenLateSuccess:
+		return scenarioLateSuccess
+	}
+
+	switch req.Amount.MinorUnits % 100 {
+	case CentsDecline:
+		return scenarioDecline
+	case CentsInsufficientFunds:
+		return scenarioInsufficientFunds
+	case CentsTimeout:
+		return scenarioTimeout
+	case CentsLateSuccess:
+		return scenarioLateSuccess
+	}
+	return scenarioSuccess
+}
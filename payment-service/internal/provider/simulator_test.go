@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"shared/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSimulator() *Simulator {
+	return NewSimulator(SimulatorConfig{Timeout: 20 * time.Millisecond, LateDelay: 5 * time.Millisecond})
+}
+
+func TestSimulator_AuthorizeCaptureRefund(t *testing.T) {
+	sim := newTestSimulator()
+	ctx := context.Background()
+
+	auth, err := sim.Authorize(ctx, AuthorizeRequest{Amount: money.New(10000, "IDR"), CardToken: TokenSuccess})
+	assert.NoError(t, err)
+	assert.Equal(t, "sim_auth_000001", auth.Reference)
+	assert.Equal(t, StatusAuthorized, auth.Status)
+
+	capture, err := sim.Capture(ctx, auth.Reference, money.New(10000, "IDR"))
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCaptured, capture.Status)
+
+	refund, err := sim.Refund(ctx, auth.Reference, money.New(4000, "IDR"))
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(4000, "IDR"), refund.Amount)
+
+	_, err = sim.Refund(ctx, auth.Reference, money.New(7000, "IDR"))
+	assert.ErrorIs(t, err, ErrAmountExceeded)
+
+	_, err = sim.Refund(ctx, refund.Reference, money.New(6000, "IDR"))
+	assert.NoError(t, err)
+
+	_, err = sim.Void(ctx, auth.Reference)
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestSimulator_Void(t *testing.T) {
+	sim := newTestSimulator()
+	ctx := context.Background()
+
+	auth, _ := sim.Authorize(ctx, AuthorizeRequest{Amount: money.New(1000, "IDR")})
+	voided, err := sim.Void(ctx, auth.Reference)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusVoided, voided.Status)
+
+	_, err = sim.Capture(ctx, auth.Reference, money.New(1000, "IDR"))
+	assert.ErrorIs(t, err, ErrInvalidState)
+
+	_, err = sim.Void(ctx, "sim_auth_999999")
+	assert.ErrorIs(t, err, ErrReferenceNotFound)
+}
+
+func TestSimulator_DeclineByTokenAndAmount(t *testing.T) {
+	sim := newTestSimulator()
+
+	_, err := sim.Authorize(context.Background(), AuthorizeRequest{Amount: money.New(1000, "IDR"), CardToken: TokenDecline})
+	var decline *DeclineError
+	assert.True(t, errors.As(err, &decline))
+	assert.Equal(t, DeclineCardDeclined, decline.Code)
+	assert.ErrorIs(t, err, ErrDeclined)
+
+	_, err = sim.Authorize(context.Background(), AuthorizeRequest{Amount: money.New(1052, "IDR")})
+	assert.True(t, errors.As(err, &decline))
+	assert.Equal(t, DeclineInsufficientFunds, decline.Code)
+
+	// token success menang atas sen amount
+	_, err = sim.Authorize(context.Background(), AuthorizeRequest{Amount: money.New(1051, "IDR"), CardToken: TokenSuccess})
+	assert.NoError(t, err)
+}
+
+func TestSimulator_Timeout(t *testing.T) {
+	sim := newTestSimulator()
+
+	start := time.Now()
+	_, err := sim.Authorize(context.Background(), AuthorizeRequest{Amount: money.New(1000, "IDR"), CardToken: TokenTimeout})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// ctx yang lebih pendek memotong penantian
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = NewSimulator(SimulatorConfig{Timeout: time.Hour}).Authorize(ctx, AuthorizeRequest{Amount: money.New(1054, "IDR")})
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestSimulator_LateSuccess(t *testing.T) {
+	sim := newTestSimulator()
+
+	auth, err := sim.Authorize(context.Background(), AuthorizeRequest{Amount: money.New(1000, "IDR"), CardToken: TokenLateSuccess})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAuthorized, auth.Status)
+
+	// caller yang tidak sabar mendapat timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = NewSimulator(SimulatorConfig{LateDelay: time.Hour}).Authorize(ctx, AuthorizeRequest{Amount: money.New(1055, "IDR")})
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestRegistry_For(t *testing.T) {
+	registry := NewSimulatorRegistry(DefaultSimulatorConfig)
+
+	p, err := registry.For(MethodCard)
+	assert.NoError(t, err)
+	assert.Equal(t, "simulator", p.Name())
+
+	_, err = registry.For("crypto")
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+	assert.Equal(t, []string{MethodBankTransfer, MethodCard, MethodEWallet}, registry.Methods())
+}
+
+func TestOpen(t *testing.T) {
+	registry, err := Open(ProviderSimulator, DefaultSimulatorConfig)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{MethodBankTransfer, MethodCard, MethodEWallet}, registry.Methods())
+	}
+
+	// tanpa PAYMENT_PROVIDER tidak jatuh ke simulator
+	_, err = Open("", DefaultSimulatorConfig)
+	assert.Error(t, err)
+	_, err = Open("stripe", DefaultSimulatorConfig)
+	assert.ErrorContains(t, err, "unknown payment provider")
+}
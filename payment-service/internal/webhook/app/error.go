@@ -0,0 +1,16 @@
+package app
+
+import "errors"
+
+var (
+	ErrClientIDEmpty      = errors.New("client id is required")
+	ErrInvalidURL         = errors.New("url must be an absolute http or https url")
+	ErrUnresolvableURL    = errors.New("url host cannot be resolved")
+	ErrForbiddenURL       = errors.New("url must resolve to a public address")
+	ErrEventTypesEmpty    = errors.New("at least one event type is required")
+	ErrUnknownEventType   = errors.New("unsupported event type")
+	ErrEndpointNotFound   = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound   = errors.New("webhook delivery not found")
+	ErrDeliveryInProgress = errors.New("webhook delivery is currently being sent")
+	ErrInvalidID          = errors.New("invalid id")
+)
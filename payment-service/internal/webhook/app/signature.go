@@ -0,0 +1,32 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Header yang dikirim bersama setiap webhook
+const (
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// Sign menghitung signature HMAC-SHA256 dari "<timestamp>.<body>" dengan
+// secret endpoint. Partner memverifikasi dengan menghitung ulang nilai ini
+// dan menolak timestamp yang terlalu lama untuk mencegah replay.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify mengecek signature dengan perbandingan constant-time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
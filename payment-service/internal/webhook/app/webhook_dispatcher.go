@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"payment-service/internal/webhook/domain"
+	"payment-service/internal/webhook/infra"
+	"shared/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Pengaturan dispatcher webhook
+type DispatcherConfig struct {
+	Interval    time.Duration // jeda polling antrian
+	BatchSize   int           // maksimal delivery per tick
+	MaxAttempts int           // setelah ini delivery jadi dead letter
+	BaseBackoff time.Duration // jeda retry pertama, dobel tiap percobaan
+	MaxBackoff  time.Duration
+	Lease       time.Duration // lama delivery dikunci saat dikirim
+}
+
+// Default dispatcher: retry 30s, 1m, 2m, ... maksimal 1 jam, 8 percobaan
+var DefaultDispatcherConfig = DispatcherConfig{
+	Interval:    5 * time.Second,
+	BatchSize:   50,
+	MaxAttempts: 8,
+	BaseBackoff: 30 * time.Second,
+	MaxBackoff:  time.Hour,
+	Lease:       time.Minute,
+}
+
+// Dispatcher mengambil delivery yang jatuh tempo dari antrian Mongo dan
+// mengirimnya ke endpoint partner
+type Dispatcher struct {
+	repo   infra.WebhookRepository
+	sender infra.WebhookSender
+	cfg    DispatcherConfig
+	now    func() time.Time
+}
+
+// Inisialisasi dispatcher
+func NewDispatcher(repo infra.WebhookRepository, sender infra.WebhookSender, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{repo: repo, sender: sender, cfg: cfg, now: time.Now}
+}
+
+// Jalankan dispatcher di background, berhenti saat ctx selesai
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := d.RunOnce(ctx); err != nil {
+					slog.ErrorContext(ctx, "webhook dispatch failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Proses delivery yang jatuh tempo, maksimal BatchSize. Return jumlah
+// delivery yang diproses.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	processed := 0
+	for processed < d.cfg.BatchSize {
+		delivery, ok, err := d.repo.ClaimDueDelivery(ctx, d.now(), d.cfg.Lease)
+		if err != nil {
+			return processed, err
+		}
+		if !ok {
+			return processed, nil
+		}
+
+		if err := d.deliver(ctx, delivery); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// Kirim satu delivery lalu simpan hasil dan log percobaannya
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.Delivery) error {
+	endpoint, err := d.repo.FindEndpointByID(ctx, delivery.EndpointID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	// endpoint yang sudah dihapus tidak perlu di-retry
+	disabled := err != nil || !endpoint.Active
+
+	started := d.now()
+	var statusCode int
+	var sendErr error
+	if disabled {
+		sendErr = errors.New("endpoint disabled")
+	} else {
+		statusCode, sendErr = d.send(ctx, endpoint, delivery, started)
+	}
+	finished := d.now()
+
+	attempt := domain.DeliveryAttempt{
+		ID:          primitive.NewObjectID(),
+		DeliveryID:  delivery.ID,
+		AttemptNo:   delivery.Attempts + 1,
+		StatusCode:  statusCode,
+		DurationMs:  finished.Sub(started).Milliseconds(),
+		AttemptedAt: started,
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	if err := d.repo.InsertAttempt(ctx, attempt); err != nil {
+		slog.ErrorContext(ctx, "failed to log webhook attempt", "delivery_id", delivery.ID.Hex(), "error", err)
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = attempt.Error
+	delivery.LockedUntil = time.Time{}
+	delivery.UpdatedAt = finished
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.DeliverySucceeded
+	// alamat non-publik tidak akan berubah dengan retry
+	case disabled || errors.Is(sendErr, infra.ErrForbiddenAddress) || delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = domain.DeliveryDead
+	default:
+		delivery.Status = domain.DeliveryPending
+		delivery.NextAttemptAt = finished.Add(d.backoff(delivery.Attempts))
+	}
+
+	return d.repo.UpdateDelivery(ctx, delivery)
+}
+
+// Kirim payload dengan header signature. Status non-2xx dianggap gagal.
+func (d *Dispatcher) send(ctx context.Context, endpoint domain.Endpoint, delivery domain.Delivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := now.Unix()
+	headers := map[string]string{
+		HeaderWebhookID:        delivery.ID.Hex(),
+		HeaderWebhookEvent:     delivery.EventType,
+		HeaderWebhookTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderWebhookSignature: Sign(endpoint.Secret, timestamp, body),
+	}
+
+	statusCode, err := d.sender.Send(ctx, endpoint.URL, headers, body)
+	if err != nil {
+		return statusCode, err
+	}
+	if statusCode < 200 || statusCode > 299 {
+		return statusCode, fmt.Errorf("endpoint responded with status %d", statusCode)
+	}
+	return statusCode, nil
+}
+
+// Exponential backoff dengan jitter +-20%
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.BaseBackoff
+	for i := 1; i < attempts && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.cfg.MaxBackoff {
+		wait = d.cfg.MaxBackoff
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(wait)/5*2+1)) - wait/5
+	return wait + jitter
+}
+
+// Subscribe ke event payment dan transaksi di broker untuk fan-out webhook.
+// Handler dibungkus Dedupe supaya event yang terkirim ulang tidak diproses dua kali.
+func SubscribeWebhookEvents(broker events.Broker, dedupe events.Deduper, service WebhookService) error {
+	handler := events.Dedupe(dedupe, "webhook-fanout", service.HandleEvent)
+	for _, subject := range []string{"payment.>", "transaction.>"} {
+		if _, err := broker.Subscribe(subject, "webhook-fanout", handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Payload webhook adalah envelope event apa adanya
+func envelopeJSON(env events.Envelope) (string, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"payment-service/internal/webhook/domain"
+	"payment-service/internal/webhook/infra"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Fake sender yang mencatat request terakhir
+type fakeSender struct {
+	status  int
+	err     error
+	headers map[string]string
+	body    []byte
+}
+
+func (f *fakeSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	f.headers = headers
+	f.body = body
+	return f.status, f.err
+}
+
+func newTestDispatcher(repo *MockWebhookRepository, sender *fakeSender, now time.Time) *Dispatcher {
+	cfg := DefaultDispatcherConfig
+	cfg.BatchSize = 1
+	d := NewDispatcher(repo, sender, cfg)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func setupClaim(repo *MockWebhookRepository, delivery domain.Delivery, endpoint domain.Endpoint) {
+	repo.On("ClaimDueDelivery", mock.Anything, mock.Anything, mock.Anything).Return(delivery, true, nil).Once()
+	repo.On("FindEndpointByID", mock.Anything, endpoint.ID).Return(endpoint, nil)
+	repo.On("InsertAttempt", mock.Anything, mock.AnythingOfType("domain.DeliveryAttempt")).Return(nil)
+}
+
+func TestDispatcher_SuccessSignsPayload(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeSender{status: 200}
+	now := time.Unix(1700000000, 0)
+
+	endpoint := domain.Endpoint{ID: primitive.NewObjectID(), URL: "https://x.example", Secret: "s3cret", Active: true}
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), EndpointID: endpoint.ID, Payload: `{"id":"e1"}`, EventType: "payment.created"}
+	setupClaim(repo, delivery, endpoint)
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.Delivery) bool {
+		return d.Status == domain.DeliverySucceeded && d.Attempts == 1 && d.LastStatusCode == 200
+	})).Return(nil)
+
+	n, err := newTestDispatcher(repo, sender, now).RunOnce(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), sender.headers[HeaderWebhookTimestamp])
+	assert.True(t, Verify("s3cret", now.Unix(), sender.body, sender.headers[HeaderWebhookSignature]))
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_FailureSchedulesRetryWithBackoff(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeSender{status: 500}
+	now := time.Unix(1700000000, 0)
+
+	endpoint := domain.Endpoint{ID: primitive.NewObjectID(), Active: true}
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), EndpointID: endpoint.ID, Attempts: 2}
+	setupClaim(repo, delivery, endpoint)
+
+	var updated domain.Delivery
+	repo.On("UpdateDelivery", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = args.Get(1).(domain.Delivery)
+	}).Return(nil)
+
+	_, err := newTestDispatcher(repo, sender, now).RunOnce(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliveryPending, updated.Status)
+	assert.Equal(t, 3, updated.Attempts)
+	// percobaan ke-3: 30s * 4 = 2m, jitter +-20%
+	wait := updated.NextAttemptAt.Sub(now)
+	assert.GreaterOrEqual(t, wait, 96*time.Second)
+	assert.LessOrEqual(t, wait, 144*time.Second)
+}
+
+func TestDispatcher_DeadLetterAfterMaxAttempts(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeSender{err: errors.New("connection refused")}
+
+	endpoint := domain.Endpoint{ID: primitive.NewObjectID(), Active: true}
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), EndpointID: endpoint.ID, Attempts: DefaultDispatcherConfig.MaxAttempts - 1}
+	setupClaim(repo, delivery, endpoint)
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.Delivery) bool {
+		return d.Status == domain.DeliveryDead && d.LastError == "connection refused"
+	})).Return(nil)
+
+	_, err := newTestDispatcher(repo, sender, time.Now()).RunOnce(context.TODO())
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_DisabledEndpointIsDeadLettered(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeSender{status: 200}
+
+	endpoint := domain.Endpoint{ID: primitive.NewObjectID(), Active: false}
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), EndpointID: endpoint.ID}
+	setupClaim(repo, delivery, endpoint)
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.Delivery) bool {
+		return d.Status == domain.DeliveryDead
+	})).Return(nil)
+
+	_, err := newTestDispatcher(repo, sender, time.Now()).RunOnce(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Nil(t, sender.body)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_ForbiddenAddressIsDeadLettered(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeSender{err: fmt.Errorf("dial tcp 10.0.0.5:443: %w", infra.ErrForbiddenAddress)}
+
+	endpoint := domain.Endpoint{ID: primitive.NewObjectID(), Active: true}
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), EndpointID: endpoint.ID}
+	setupClaim(repo, delivery, endpoint)
+	repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.Delivery) bool {
+		return d.Status == domain.DeliveryDead && d.Attempts == 1
+	})).Return(nil)
+
+	_, err := newTestDispatcher(repo, sender, time.Now()).RunOnce(context.TODO())
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"payment-service/internal/webhook/domain"
+	"payment-service/internal/webhook/infra"
+	"shared/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Tipe event yang boleh di-subscribe partner
+var SupportedEventTypes = []string{
+	events.TypePaymentCreated,
+	events.TypePaymentRefunded,
+	events.TypeTransactionCreated,
+	events.TypeTransactionStatusChanged,
+}
+
+// Batas jumlah delivery per request list
+const maxDeliveryList = 100
+
+type WebhookService interface {
+	RegisterEndpoint(ctx context.Context, clientID, rawURL string, eventTypes []string) (domain.Endpoint, error)
+	ListEndpoints(ctx context.Context, clientID string) ([]domain.Endpoint, error)
+	DeleteEndpoint(ctx context.Context, clientID, id string) (domain.Endpoint, error)
+	ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.Delivery, error)
+	ListAttempts(ctx context.Context, clientID, deliveryID string) ([]domain.DeliveryAttempt, error)
+	Redeliver(ctx context.Context, clientID, deliveryID string) (domain.Delivery, error)
+
+	// HandleEvent mengantrikan event ke semua endpoint pemiliknya yang subscribe
+	HandleEvent(ctx context.Context, env events.Envelope) error
+}
+
+type webhookService struct {
+	repo     infra.WebhookRepository
+	resolver infra.HostResolver
+}
+
+// Inisialisasi service, resolver dipakai mengecek host URL endpoint
+func NewWebhookService(repo infra.WebhookRepository, resolver infra.HostResolver) WebhookService {
+	return &webhookService{repo: repo, resolver: resolver}
+}
+
+// Daftarkan endpoint baru. Secret hanya dikembalikan di sini.
+func (s *webhookService) RegisterEndpoint(ctx context.Context, clientID, rawURL string, eventTypes []string) (domain.Endpoint, error) {
+	if clientID == "" {
+		return domain.Endpoint{}, ErrClientIDEmpty
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.Endpoint{}, ErrInvalidURL
+	}
+	// host harus resolve ke alamat publik, dicek ulang saat delivery
+	if err := infra.CheckPublicHost(ctx, s.resolver, u.Hostname()); err != nil {
+		if errors.Is(err, infra.ErrForbiddenAddress) {
+			return domain.Endpoint{}, ErrForbiddenURL
+		}
+		return domain.Endpoint{}, ErrUnresolvableURL
+	}
+
+	if len(eventTypes) == 0 {
+		return domain.Endpoint{}, ErrEventTypesEmpty
+	}
+	for _, t := range eventTypes {
+		if !isSupportedEventType(t) {
+			return domain.Endpoint{}, ErrUnknownEventType
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return domain.Endpoint{}, err
+	}
+
+	endpoint := domain.Endpoint{
+		ID:         primitive.NewObjectID(),
+		ClientID:   clientID,
+		URL:        u.String(),
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.repo.InsertEndpoint(ctx, endpoint); err != nil {
+		return domain.Endpoint{}, err
+	}
+
+	return endpoint, nil
+}
+
+// Ambil endpoint aktif milik client
+func (s *webhookService) ListEndpoints(ctx context.Context, clientID string) ([]domain.Endpoint, error) {
+	if clientID == "" {
+		return nil, ErrClientIDEmpty
+	}
+	return s.repo.FindEndpointsByClient(ctx, clientID)
+}
+
+// Nonaktifkan endpoint milik client
+func (s *webhookService) DeleteEndpoint(ctx context.Context, clientID, id string) (domain.Endpoint, error) {
+	endpoint, err := s.ownedEndpoint(ctx, clientID, id)
+	if err != nil {
+		return domain.Endpoint{}, err
+	}
+
+	if err := s.repo.DeactivateEndpoint(ctx, endpoint.ID); err != nil {
+		return domain.Endpoint{}, err
+	}
+
+	endpoint.Active = false
+	return endpoint, nil
+}
+
+// Ambil delivery milik client
+func (s *webhookService) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.Delivery, error) {
+	if filter.ClientID == "" {
+		return nil, ErrClientIDEmpty
+	}
+	if filter.EndpointID != "" && !primitive.IsValidObjectID(filter.EndpointID) {
+		return nil, ErrInvalidID
+	}
+	return s.repo.FindDeliveries(ctx, filter, maxDeliveryList)
+}
+
+// Ambil log percobaan satu delivery
+func (s *webhookService) ListAttempts(ctx context.Context, clientID, deliveryID string) ([]domain.DeliveryAttempt, error) {
+	delivery, err := s.ownedDelivery(ctx, clientID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindAttempts(ctx, delivery.ID)
+}
+
+// Kirim ulang delivery secara manual, termasuk yang sudah dead
+func (s *webhookService) Redeliver(ctx context.Context, clientID, deliveryID string) (domain.Delivery, error) {
+	delivery, err := s.ownedDelivery(ctx, clientID, deliveryID)
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+
+	reset, err := s.repo.ResetDelivery(ctx, delivery.ID, time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Delivery{}, ErrDeliveryInProgress
+	}
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	return reset, nil
+}
+
+// Fan-out event ke endpoint. Event hanya dikirim ke client pemilik data
+// (email di payload), dan unique index (endpoint_id, event_id) membuat
+// pemanggilan ulang untuk event yang sama aman.
+func (s *webhookService) HandleEvent(ctx context.Context, env events.Envelope) error {
+	if !isSupportedEventType(env.Type) {
+		return nil
+	}
+
+	var owner struct {
+		Email string `json:"email"`
+	}
+	if err := env.Decode(&owner); err != nil || owner.Email == "" {
+		return nil
+	}
+
+	endpoints, err := s.repo.FindSubscribedEndpoints(ctx, owner.Email, env.Type)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := envelopeJSON(env)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		_, err := s.repo.InsertDelivery(ctx, domain.Delivery{
+			ID:            primitive.NewObjectID(),
+			EndpointID:    endpoint.ID,
+			ClientID:      endpoint.ClientID,
+			EventID:       env.ID,
+			EventType:     env.Type,
+			Payload:       payload,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *webhookService) ownedEndpoint(ctx context.Context, clientID, id string) (domain.Endpoint, error) {
+	if clientID == "" {
+		return domain.Endpoint{}, ErrClientIDEmpty
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Endpoint{}, ErrInvalidID
+	}
+
+	endpoint, err := s.repo.FindEndpointByID(ctx, objID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && (endpoint.ClientID != clientID || !endpoint.Active)) {
+		return domain.Endpoint{}, ErrEndpointNotFound
+	}
+	return endpoint, err
+}
+
+func (s *webhookService) ownedDelivery(ctx context.Context, clientID, id string) (domain.Delivery, error) {
+	if clientID == "" {
+		return domain.Delivery{}, ErrClientIDEmpty
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Delivery{}, ErrInvalidID
+	}
+
+	delivery, err := s.repo.FindDeliveryByID(ctx, objID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && delivery.ClientID != clientID) {
+		return domain.Delivery{}, ErrDeliveryNotFound
+	}
+	return delivery, err
+}
+
+func isSupportedEventType(eventType string) bool {
+	for _, t := range SupportedEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Secret acak 32 byte dalam hex
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"payment-service/internal/webhook/domain"
+	"shared/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Mock repository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) EnsureIndexes(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+func (m *MockWebhookRepository) InsertEndpoint(ctx context.Context, endpoint domain.Endpoint) error {
+	return m.Called(ctx, endpoint).Error(0)
+}
+func (m *MockWebhookRepository) FindEndpointByID(ctx context.Context, id primitive.ObjectID) (domain.Endpoint, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Endpoint), args.Error(1)
+}
+func (m *MockWebhookRepository) FindEndpointsByClient(ctx context.Context, clientID string) ([]domain.Endpoint, error) {
+	args := m.Called(ctx, clientID)
+	return args.Get(0).([]domain.Endpoint), args.Error(1)
+}
+func (m *MockWebhookRepository) FindSubscribedEndpoints(ctx context.Context, clientID, eventType string) ([]domain.Endpoint, error) {
+	args := m.Called(ctx, clientID, eventType)
+	return args.Get(0).([]domain.Endpoint), args.Error(1)
+}
+func (m *MockWebhookRepository) DeactivateEndpoint(ctx context.Context, id primitive.ObjectID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *MockWebhookRepository) InsertDelivery(ctx context.Context, delivery domain.Delivery) (bool, error) {
+	args := m.Called(ctx, delivery)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockWebhookRepository) FindDeliveryByID(ctx context.Context, id primitive.ObjectID) (domain.Delivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Delivery), args.Error(1)
+}
+func (m *MockWebhookRepository) FindDeliveries(ctx context.Context, filter domain.DeliveryFilter, limit int64) ([]domain.Delivery, error) {
+	args := m.Called(ctx, filter, limit)
+	return args.Get(0).([]domain.Delivery), args.Error(1)
+}
+func (m *MockWebhookRepository) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (domain.Delivery, bool, error) {
+	args := m.Called(ctx, now, lease)
+	return args.Get(0).(domain.Delivery), args.Bool(1), args.Error(2)
+}
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery domain.Delivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+func (m *MockWebhookRepository) ResetDelivery(ctx context.Context, id primitive.ObjectID, now time.Time) (domain.Delivery, error) {
+	args := m.Called(ctx, id, now)
+	return args.Get(0).(domain.Delivery), args.Error(1)
+}
+func (m *MockWebhookRepository) InsertAttempt(ctx context.Context, attempt domain.DeliveryAttempt) error {
+	return m.Called(ctx, attempt).Error(0)
+}
+func (m *MockWebhookRepository) FindAttempts(ctx context.Context, deliveryID primitive.ObjectID) ([]domain.DeliveryAttempt, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).([]domain.DeliveryAttempt), args.Error(1)
+}
+
+// resolver tetap per host, host lain gagal resolve
+type fakeResolver map[string]string
+
+func (r fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ip, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+}
+
+var publicResolver = fakeResolver{
+	"partner.example": "93.184.216.34",
+	"x.example":       "93.184.216.35",
+	"127.0.0.1":       "127.0.0.1",
+	"internal.corp":   "10.0.0.5",
+	"metadata.cloud":  "169.254.169.254",
+	"::1":             "::1",
+}
+
+// ===================== TESTS ========================
+
+func TestRegisterEndpoint_Success(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, publicResolver)
+
+	mockRepo.On("InsertEndpoint", mock.Anything, mock.AnythingOfType("domain.Endpoint")).Return(nil)
+
+	endpoint, err := service.RegisterEndpoint(context.TODO(), "partner@x.com", "https://partner.example/hook", []string{events.TypePaymentCreated})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "partner@x.com", endpoint.ClientID)
+	assert.True(t, endpoint.Active)
+	assert.NotEmpty(t, endpoint.Secret)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegisterEndpoint_Validation(t *testing.T) {
+	service := NewWebhookService(new(MockWebhookRepository), publicResolver)
+
+	_, err := service.RegisterEndpoint(context.TODO(), "", "https://x.example", []string{events.TypePaymentCreated})
+	assert.ErrorIs(t, err, ErrClientIDEmpty)
+
+	_, err = service.RegisterEndpoint(context.TODO(), "c", "ftp://x.example", []string{events.TypePaymentCreated})
+	assert.ErrorIs(t, err, ErrInvalidURL)
+
+	_, err = service.RegisterEndpoint(context.TODO(), "c", "https://x.example", nil)
+	assert.ErrorIs(t, err, ErrEventTypesEmpty)
+
+	_, err = service.RegisterEndpoint(context.TODO(), "c", "https://x.example", []string{events.TypeProductStockChanged})
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+}
+
+func TestRegisterEndpoint_RejectsNonPublicHosts(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, publicResolver)
+
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{url: "http://127.0.0.1:8081/hook", want: ErrForbiddenURL},
+		{url: "http://[::1]/hook", want: ErrForbiddenURL},
+		{url: "https://internal.corp/hook", want: ErrForbiddenURL},
+		{url: "http://metadata.cloud/latest", want: ErrForbiddenURL},
+		{url: "https://unknown.example/hook", want: ErrUnresolvableURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := service.RegisterEndpoint(context.TODO(), "c", tt.url, []string{events.TypePaymentCreated})
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+	mockRepo.AssertNotCalled(t, "InsertEndpoint", mock.Anything, mock.Anything)
+}
+
+func TestHandleEvent_QueuesForOwnerEndpoints(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, publicResolver)
+
+	endpoint := domain.Endpoint{ID: primitive.NewObjectID(), ClientID: "owner@x.com", Active: true}
+	mockRepo.On("FindSubscribedEndpoints", mock.Anything, "owner@x.com", events.TypePaymentCreated).Return([]domain.Endpoint{endpoint}, nil)
+	mockRepo.On("InsertDelivery", mock.Anything, mock.MatchedBy(func(d domain.Delivery) bool {
+		return d.EndpointID == endpoint.ID && d.Status == domain.DeliveryPending && d.EventType == events.TypePaymentCreated
+	})).Return(true, nil)
+
+	env, _ := events.NewEnvelope(events.TypePaymentCreated, events.VersionPaymentCreated, "test", events.PaymentCreated{Email: "owner@x.com"})
+	err := service.HandleEvent(context.TODO(), env)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestHandleEvent_IgnoresUnsupportedTypes(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, publicResolver)
+
+	env, _ := events.NewEnvelope(events.TypeProductStockChanged, events.VersionProductStockChanged, "test", events.ProductStockChanged{})
+	err := service.HandleEvent(context.TODO(), env)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "FindSubscribedEndpoints", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedeliver_OtherClientNotFound(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, publicResolver)
+
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), ClientID: "owner@x.com"}
+	mockRepo.On("FindDeliveryByID", mock.Anything, delivery.ID).Return(delivery, nil)
+
+	_, err := service.Redeliver(context.TODO(), "intruder@x.com", delivery.ID.Hex())
+
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+	mockRepo.AssertNotCalled(t, "ResetDelivery", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedeliver_InProgress(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, publicResolver)
+
+	delivery := domain.Delivery{ID: primitive.NewObjectID(), ClientID: "owner@x.com", Status: domain.DeliveryInProgress}
+	mockRepo.On("FindDeliveryByID", mock.Anything, delivery.ID).Return(delivery, nil)
+	mockRepo.On("ResetDelivery", mock.Anything, delivery.ID, mock.Anything).Return(domain.Delivery{}, mongo.ErrNoDocuments)
+
+	_, err := service.Redeliver(context.TODO(), "owner@x.com", delivery.ID.Hex())
+
+	assert.ErrorIs(t, err, ErrDeliveryInProgress)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	assert.True(t, Verify("secret", 1700000000, body, signature))
+	assert.False(t, Verify("other", 1700000000, body, signature))
+	assert.False(t, Verify("secret", 1700000001, body, signature))
+	assert.Equal(t, "v1=", signature[:3])
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"payment-service/internal/webhook/app"
+	"payment-service/internal/webhook/delivery/grpc/webhookpb"
+	"payment-service/internal/webhook/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Handler gRPC webhook
+type WebhookHandler struct {
+	webhookpb.UnimplementedWebhookServiceServer
+	Service app.WebhookService
+}
+
+// Daftarkan endpoint webhook
+func (h *WebhookHandler) RegisterEndpoint(ctx context.Context, req *webhookpb.RegisterEndpointRequest) (*webhookpb.WebhookEndpoint, error) {
+	endpoint, err := h.Service.RegisterEndpoint(ctx, req.GetClientId(), req.GetUrl(), req.GetEventTypes())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	result := toEndpointPB(endpoint)
+	result.Secret = endpoint.Secret
+	return result, nil
+}
+
+// Ambil endpoint milik client
+func (h *WebhookHandler) ListEndpoints(ctx context.Context, req *webhookpb.ListEndpointsRequest) (*webhookpb.ListEndpointsResponse, error) {
+	endpoints, err := h.Service.ListEndpoints(ctx, req.GetClientId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	res := &webhookpb.ListEndpointsResponse{}
+	for _, e := range endpoints {
+		res.Endpoints = append(res.Endpoints, toEndpointPB(e))
+	}
+	return res, nil
+}
+
+// Hapus (nonaktifkan) endpoint
+func (h *WebhookHandler) DeleteEndpoint(ctx context.Context, req *webhookpb.DeleteEndpointRequest) (*webhookpb.WebhookEndpoint, error) {
+	endpoint, err := h.Service.DeleteEndpoint(ctx, req.GetClientId(), req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toEndpointPB(endpoint), nil
+}
+
+// Ambil delivery milik client
+func (h *WebhookHandler) ListDeliveries(ctx context.Context, req *webhookpb.ListDeliveriesRequest) (*webhookpb.ListDeliveriesResponse, error) {
+	deliveries, err := h.Service.ListDeliveries(ctx, domain.DeliveryFilter{
+		ClientID:   req.GetClientId(),
+		EndpointID: req.GetEndpointId(),
+		Status:     req.GetStatus(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	res := &webhookpb.ListDeliveriesResponse{}
+	for _, d := range deliveries {
+		res.Deliveries = append(res.Deliveries, toDeliveryPB(d))
+	}
+	return res, nil
+}
+
+// Ambil log percobaan satu delivery
+func (h *WebhookHandler) ListDeliveryAttempts(ctx context.Context, req *webhookpb.ListDeliveryAttemptsRequest) (*webhookpb.ListDeliveryAttemptsResponse, error) {
+	attempts, err := h.Service.ListAttempts(ctx, req.GetClientId(), req.GetDeliveryId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	res := &webhookpb.ListDeliveryAttemptsResponse{}
+	for _, a := range attempts {
+		res.Attempts = append(res.Attempts, &webhookpb.WebhookDeliveryAttempt{
+			Id:          a.ID.Hex(),
+			AttemptNo:   int32(a.AttemptNo),
+			StatusCode:  int32(a.StatusCode),
+			Error:       a.Error,
+			DurationMs:  a.DurationMs,
+			AttemptedAt: timestamppb.New(a.AttemptedAt),
+		})
+	}
+	return res, nil
+}
+
+// Kirim ulang delivery secara manual
+func (h *WebhookHandler) Redeliver(ctx context.Context, req *webhookpb.RedeliverRequest) (*webhookpb.WebhookDelivery, error) {
+	delivery, err := h.Service.Redeliver(ctx, req.GetClientId(), req.GetDeliveryId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toDeliveryPB(delivery), nil
+}
+
+func toEndpointPB(e domain.Endpoint) *webhookpb.WebhookEndpoint {
+	return &webhookpb.WebhookEndpoint{
+		Id:         e.ID.Hex(),
+		ClientId:   e.ClientID,
+		Url:        e.URL,
+		EventTypes: e.EventTypes,
+		Active:     e.Active,
+		CreatedAt:  timestamppb.New(e.CreatedAt),
+	}
+}
+
+func toDeliveryPB(d domain.Delivery) *webhookpb.WebhookDelivery {
+	return &webhookpb.WebhookDelivery{
+		Id:             d.ID.Hex(),
+		EndpointId:     d.EndpointID.Hex(),
+		EventId:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       int32(d.Attempts),
+		NextAttemptAt:  timestamppb.New(d.NextAttemptAt),
+		LastStatusCode: int32(d.LastStatusCode),
+		LastError:      d.LastError,
+		CreatedAt:      timestamppb.New(d.CreatedAt),
+		UpdatedAt:      timestamppb.New(d.UpdatedAt),
+	}
+}
+
+// Petakan error service ke status gRPC supaya gateway bisa memilih HTTP code
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, app.ErrClientIDEmpty),
+		errors.Is(err, app.ErrInvalidURL),
+		errors.Is(err, app.ErrUnresolvableURL),
+		errors.Is(err, app.ErrForbiddenURL),
+		errors.Is(err, app.ErrEventTypesEmpty),
+		errors.Is(err, app.ErrUnknownEventType),
+		errors.Is(err, app.ErrInvalidID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, app.ErrEndpointNotFound), errors.Is(err, app.ErrDeliveryNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, app.ErrDeliveryInProgress):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
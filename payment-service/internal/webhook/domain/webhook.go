@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status delivery webhook
+const (
+	DeliveryPending    = "pending"
+	DeliveryInProgress = "delivering"
+	DeliverySucceeded  = "succeeded"
+	DeliveryDead       = "dead"
+)
+
+// Endpoint webhook milik satu API client
+type Endpoint struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClientID   string             `bson:"client_id" json:"client_id"`
+	URL        string             `bson:"url" json:"url"`
+	EventTypes []string           `bson:"event_types" json:"event_types"`
+	Secret     string             `bson:"secret" json:"-"`
+	Active     bool               `bson:"active" json:"active"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Delivery adalah satu event yang harus dikirim ke satu endpoint.
+// Collection delivery sekaligus menjadi antrian persisten, delivery dengan
+// status dead adalah dead letter.
+type Delivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EndpointID     primitive.ObjectID `bson:"endpoint_id" json:"endpoint_id"`
+	ClientID       string             `bson:"client_id" json:"client_id"`
+	EventID        string             `bson:"event_id" json:"event_id"`
+	EventType      string             `bson:"event_type" json:"event_type"`
+	Payload        string             `bson:"payload" json:"payload"`
+	Status         string             `bson:"status" json:"status"`
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil    time.Time          `bson:"locked_until,omitempty" json:"-"`
+	LastStatusCode int                `bson:"last_status_code,omitempty" json:"last_status_code,omitempty"`
+	LastError      string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Log satu kali percobaan pengiriman
+type DeliveryAttempt struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeliveryID  primitive.ObjectID `bson:"delivery_id" json:"delivery_id"`
+	AttemptNo   int                `bson:"attempt_no" json:"attempt_no"`
+	StatusCode  int                `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs  int64              `bson:"duration_ms" json:"duration_ms"`
+	AttemptedAt time.Time          `bson:"attempted_at" json:"attempted_at"`
+}
+
+// Filter list delivery, field kosong diabaikan
+type DeliveryFilter struct {
+	ClientID   string
+	EndpointID string
+	Status     string
+}
@@ -0,0 +1,61 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Tujuan webhook yang bukan alamat publik (loopback, jaringan privat,
+// link-local, dll) ditolak supaya endpoint tidak dipakai untuk SSRF
+var ErrForbiddenAddress = errors.New("webhook url must resolve to a public address")
+
+// Shared address space (CGNAT) 100.64.0.0/10, tidak tercakup net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Resolver DNS, net.DefaultResolver memenuhi interface ini
+type HostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Cek apakah ip boleh dituju webhook
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// Resolve host lalu pastikan semua alamatnya publik. Error resolve
+// dikembalikan apa adanya, alamat non-publik jadi ErrForbiddenAddress.
+func CheckPublicHost(ctx context.Context, resolver HostResolver, host string) error {
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// Control untuk net.Dialer: dicek tepat sebelum connect ke alamat hasil
+// resolve, jadi DNS yang berubah setelah registrasi (rebinding) tetap ditolak
+func publicAddressControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
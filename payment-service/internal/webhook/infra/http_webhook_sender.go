@@ -0,0 +1,64 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Batas waktu satu request webhook ke endpoint partner
+const webhookRequestTimeout = 10 * time.Second
+
+// Interface pengirim webhook, dipisah supaya bisa di-mock di test
+type WebhookSender interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+type httpWebhookSender struct {
+	client *http.Client
+}
+
+// Inisialisasi pengirim webhook via HTTP POST. Koneksi hanya ke alamat
+// publik (dicek saat dial), tanpa proxy dan tanpa mengikuti redirect.
+func NewHTTPWebhookSender() WebhookSender {
+	dialer := &net.Dialer{Timeout: webhookRequestTimeout, Control: publicAddressControl}
+	return &httpWebhookSender{client: &http.Client{
+		Timeout: webhookRequestTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookRequestTimeout,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Kirim POST dan kembalikan status code. Status non-2xx bukan error di
+// level ini, keputusan retry ada di dispatcher.
+func (s *httpWebhookSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// buang body supaya koneksi bisa dipakai ulang
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:2800:220:1::", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.1.2.3", want: false},
+		{ip: "172.16.0.1", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "fd00::1", want: false},
+		{ip: "100.64.0.1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "::ffff:127.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPublicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestHTTPWebhookSender_RejectsLoopbackAtDial(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPWebhookSender().Send(context.TODO(), server.URL, nil, []byte(`{}`))
+
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+	assert.False(t, called)
+}
@@ -0,0 +1,293 @@
+package infra
+
+import (
+	"context"
+	"payment-service/internal/webhook/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Interface repository webhook
+type WebhookRepository interface {
+	EnsureIndexes(ctx context.Context) error
+
+	InsertEndpoint(ctx context.Context, endpoint domain.Endpoint) error
+	FindEndpointByID(ctx context.Context, id primitive.ObjectID) (domain.Endpoint, error)
+	FindEndpointsByClient(ctx context.Context, clientID string) ([]domain.Endpoint, error)
+	FindSubscribedEndpoints(ctx context.Context, clientID, eventType string) ([]domain.Endpoint, error)
+	DeactivateEndpoint(ctx context.Context, id primitive.ObjectID) error
+
+	InsertDelivery(ctx context.Context, delivery domain.Delivery) (bool, error)
+	FindDeliveryByID(ctx context.Context, id primitive.ObjectID) (domain.Delivery, error)
+	FindDeliveries(ctx context.Context, filter domain.DeliveryFilter, limit int64) ([]domain.Delivery, error)
+	ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (domain.Delivery, bool, error)
+	UpdateDelivery(ctx context.Context, delivery domain.Delivery) error
+	ResetDelivery(ctx context.Context, id primitive.ObjectID, now time.Time) (domain.Delivery, error)
+
+	InsertAttempt(ctx context.Context, attempt domain.DeliveryAttempt) error
+	FindAttempts(ctx context.Context, deliveryID primitive.ObjectID) ([]domain.DeliveryAttempt, error)
+}
+
+// Implementasi repository
+type webhookRepository struct {
+	endpoints  *mongo.Collection
+	deliveries *mongo.Collection
+	attempts   *mongo.Collection
+}
+
+// Inisialisasi repository
+func NewWebhookRepository(db *mongo.Database) WebhookRepository {
+	return &webhookRepository{
+		endpoints:  db.Collection("webhook_endpoints"),
+		deliveries: db.Collection("webhook_deliveries"),
+		attempts:   db.Collection("webhook_attempts"),
+	}
+}
+
+// Buat index yang dibutuhkan query dispatcher dan fan-out
+func (r *webhookRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.endpoints.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "client_id", Value: 1}, {Key: "event_types", Value: 1}, {Key: "active", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.deliveries.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// satu event hanya boleh masuk antrian sekali per endpoint
+		{
+			Keys:    bson.D{{Key: "endpoint_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "client_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.attempts.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "delivery_id", Value: 1}, {Key: "attempt_no", Value: 1}},
+	})
+	return err
+}
+
+// Simpan endpoint baru
+func (r *webhookRepository) InsertEndpoint(ctx context.Context, endpoint domain.Endpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.endpoints.InsertOne(ctx, endpoint)
+	return err
+}
+
+// Ambil endpoint berdasarkan ID
+func (r *webhookRepository) FindEndpointByID(ctx context.Context, id primitive.ObjectID) (domain.Endpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var endpoint domain.Endpoint
+	err := r.endpoints.FindOne(ctx, bson.M{"_id": id}).Decode(&endpoint)
+	return endpoint, err
+}
+
+// Ambil endpoint aktif milik client
+func (r *webhookRepository) FindEndpointsByClient(ctx context.Context, clientID string) ([]domain.Endpoint, error) {
+	return r.findEndpoints(ctx, bson.M{"client_id": clientID, "active": true})
+}
+
+// Ambil endpoint aktif milik client yang subscribe ke tipe event
+func (r *webhookRepository) FindSubscribedEndpoints(ctx context.Context, clientID, eventType string) ([]domain.Endpoint, error) {
+	return r.findEndpoints(ctx, bson.M{"client_id": clientID, "event_types": eventType, "active": true})
+}
+
+func (r *webhookRepository) findEndpoints(ctx context.Context, filter bson.M) ([]domain.Endpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.endpoints.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.Endpoint
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Nonaktifkan endpoint, riwayat delivery tetap disimpan
+func (r *webhookRepository) DeactivateEndpoint(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.endpoints.UpdateByID(ctx, id, bson.M{"$set": bson.M{"active": false}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Masukkan delivery ke antrian. Return false jika event yang sama sudah
+// pernah diantrikan ke endpoint ini.
+func (r *webhookRepository) InsertDelivery(ctx context.Context, delivery domain.Delivery) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.deliveries.InsertOne(ctx, delivery)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Ambil delivery berdasarkan ID
+func (r *webhookRepository) FindDeliveryByID(ctx context.Context, id primitive.ObjectID) (domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var delivery domain.Delivery
+	err := r.deliveries.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery)
+	return delivery, err
+}
+
+// Ambil delivery terbaru sesuai filter
+func (r *webhookRepository) FindDeliveries(ctx context.Context, filter domain.DeliveryFilter, limit int64) ([]domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := bson.M{"client_id": filter.ClientID}
+	if filter.EndpointID != "" {
+		endpointID, err := primitive.ObjectIDFromHex(filter.EndpointID)
+		if err != nil {
+			return nil, err
+		}
+		query["endpoint_id"] = endpointID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := r.deliveries.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.Delivery
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Ambil satu delivery yang sudah jatuh tempo dan kunci selama lease secara
+// atomik, supaya beberapa dispatcher tidak mengirim delivery yang sama.
+// Delivery "delivering" yang lease-nya habis (dispatcher crash) ikut diambil.
+func (r *webhookRepository) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (domain.Delivery, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": domain.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": domain.DeliveryInProgress, "locked_until": bson.M{"$lt": now}},
+	}}
+	update := bson.M{"$set": bson.M{
+		"status":       domain.DeliveryInProgress,
+		"locked_until": now.Add(lease),
+		"updated_at":   now,
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery domain.Delivery
+	err := r.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return domain.Delivery{}, false, nil
+	}
+	if err != nil {
+		return domain.Delivery{}, false, err
+	}
+	return delivery, true, nil
+}
+
+// Simpan hasil percobaan pengiriman
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery domain.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.deliveries.UpdateByID(ctx, delivery.ID, bson.M{"$set": bson.M{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"locked_until":     delivery.LockedUntil,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"updated_at":       delivery.UpdatedAt,
+	}})
+	return err
+}
+
+// Kembalikan delivery ke antrian untuk dikirim ulang dari awal.
+// Delivery yang sedang dikirim tidak disentuh.
+func (r *webhookRepository) ResetDelivery(ctx context.Context, id primitive.ObjectID, now time.Time) (domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "status": bson.M{"$ne": domain.DeliveryInProgress}}
+	update := bson.M{"$set": bson.M{
+		"status":          domain.DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"updated_at":      now,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var delivery domain.Delivery
+	err := r.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	return delivery, err
+}
+
+// Simpan log percobaan pengiriman
+func (r *webhookRepository) InsertAttempt(ctx context.Context, attempt domain.DeliveryAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.attempts.InsertOne(ctx, attempt)
+	return err
+}
+
+// Ambil log percobaan untuk satu delivery
+func (r *webhookRepository) FindAttempts(ctx context.Context, deliveryID primitive.ObjectID) ([]domain.DeliveryAttempt, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.attempts.Find(ctx, bson.M{"delivery_id": deliveryID}, options.Find().SetSort(bson.D{{Key: "attempted_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []domain.DeliveryAttempt
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
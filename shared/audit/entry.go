@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// aksi yang dicatat di audit log
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// user dan request yang melakukan perubahan
+type Actor struct {
+	Email     string
+	RequestID string
+}
+
+// actor untuk perubahan dari proses internal (cron, job), misalnya System("import")
+func System(name string) Actor {
+	return Actor{Email: "system:" + name}
+}
+
+// nilai field sebelum dan sesudah perubahan, kosong untuk field baru/terhapus
+type Change struct {
+	Before any `bson:"before,omitempty" json:"before,omitempty"`
+	After  any `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// satu baris audit log, hanya ditambah dan tidak pernah diubah
+type Entry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Resource   string             `bson:"resource" json:"resource"`
+	ResourceID string             `bson:"resource_id" json:"resource_id"`
+	Action     string             `bson:"action" json:"action"`
+	Actor      string             `bson:"actor" json:"actor"`
+	RequestID  string             `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	// field (key JSON) yang berubah
+	Changes   map[string]Change `bson:"changes,omitempty" json:"changes,omitempty"`
+	CreatedAt time.Time         `bson:"created_at" json:"created_at"`
+}
+
+// Buat entry dengan diff before/after. before nil untuk create, after nil
+// untuk hard delete.
+func NewEntry(resource, resourceID, action string, actor Actor, before, after any) Entry {
+	return Entry{
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     action,
+		Actor:      actor.Email,
+		RequestID:  actor.RequestID,
+		Changes:    Diff(before, after),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// Bandingkan dua nilai per key JSON level atas, hanya key yang berbeda
+// yang dikembalikan. Field dengan tag json:"-" tidak pernah masuk diff.
+func Diff(before, after any) map[string]Change {
+	b := toFields(before)
+	a := toFields(after)
+
+	changes := map[string]Change{}
+	for k, v := range b {
+		if w, ok := a[k]; !ok || !reflect.DeepEqual(v, w) {
+			changes[k] = Change{Before: v, After: a[k]}
+		}
+	}
+	for k, w := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = Change{After: w}
+		}
+	}
+	return changes
+}
+
+func toFields(v any) map[string]any {
+	fields := map[string]any{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return fields
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(raw, &fields)
+	return fields
+}
+
+type actorKey struct{}
+
+// simpan actor di context, dipakai service yang menerima ctx
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actor dari context, kosong kalau tidak ada
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Name   string `json:"name"`
+	Stock  int    `json:"stock"`
+	Secret string `json:"-"`
+}
+
+func TestDiff_OnlyChangedFields(t *testing.T) {
+	changes := Diff(sample{Name: "a", Stock: 1, Secret: "x"}, sample{Name: "a", Stock: 2, Secret: "y"})
+
+	assert.Equal(t, map[string]Change{"stock": {Before: float64(1), After: float64(2)}}, changes)
+}
+
+func TestDiff_CreateHasNoBefore(t *testing.T) {
+	changes := Diff(nil, &sample{Name: "a"})
+
+	assert.Equal(t, Change{After: "a"}, changes["name"])
+	assert.Len(t, changes, 2)
+}
+
+func TestDiff_NilPointerIsEmpty(t *testing.T) {
+	var before *sample
+	changes := Diff(before, sample{Name: "a"})
+
+	assert.Nil(t, changes["name"].Before)
+}
+
+func TestMemoryLog_ListNewestFirstWithFilter(t *testing.T) {
+	log := NewMemoryLog()
+	log.Record(context.TODO(), NewEntry("payment", "p1", ActionCreate, Actor{Email: "a@x.com"}, nil, sample{}))
+	log.Record(context.TODO(), NewEntry("payment", "p2", ActionCreate, Actor{Email: "a@x.com"}, nil, sample{}))
+	log.Record(context.TODO(), NewEntry("payment", "p1", ActionDelete, Actor{Email: "b@x.com"}, sample{}, nil))
+
+	entries, err := log.List(context.TODO(), Filter{ResourceID: "p1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, ActionDelete, entries[0].Action)
+	assert.Equal(t, ActionCreate, entries[1].Action)
+}
+
+func TestActorFrom_EmptyWithoutActor(t *testing.T) {
+	assert.Equal(t, Actor{}, ActorFrom(context.TODO()))
+	assert.Equal(t, Actor{Email: "a@x.com"}, ActorFrom(WithActor(context.TODO(), Actor{Email: "a@x.com"})))
+}
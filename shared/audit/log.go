@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// batas jumlah entry per List
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 500
+)
+
+// filter audit log, field kosong diabaikan
+type Filter struct {
+	Resource   string
+	ResourceID string
+	Actor      string
+	RequestID  string
+	Limit      int64
+}
+
+// Audit log. Gagal mencatat tidak membatalkan perubahan yang sudah
+// tersimpan, cukup dicatat di log aplikasi.
+type Log interface {
+	Record(ctx context.Context, entry Entry)
+	// entry terbaru dulu
+	List(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// audit log di collection MongoDB
+type MongoLog struct {
+	col *mongo.Collection
+}
+
+// Inisialisasi audit log di collection "audit_logs"
+func NewMongoLog(db *mongo.Database) *MongoLog {
+	col := db.Collection("audit_logs")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "resource", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+	})
+	if err != nil {
+		slog.Error("failed to create audit_logs index", "error", err)
+	}
+
+	return &MongoLog{col: col}
+}
+
+func (l *MongoLog) Record(ctx context.Context, entry Entry) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if _, err := l.col.InsertOne(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit entry", "action", entry.Action, "resource", entry.Resource, "resource_id", entry.ResourceID, "actor", entry.Actor, "error", err)
+	}
+}
+
+func (l *MongoLog) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if filter.Resource != "" {
+		query["resource"] = filter.Resource
+	}
+	if filter.ResourceID != "" {
+		query["resource_id"] = filter.ResourceID
+	}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.RequestID != "" {
+		query["request_id"] = filter.RequestID
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(listLimit(filter.Limit))
+	cursor, err := l.col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []Entry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// MemoryLog menyimpan entry di memori, dipakai untuk test dan development
+type MemoryLog struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{}
+}
+
+func (l *MemoryLog) Record(ctx context.Context, entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *MemoryLog) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := []Entry{}
+	for i := len(l.entries) - 1; i >= 0 && int64(len(entries)) < listLimit(filter.Limit); i-- {
+		e := l.entries[i]
+		if (filter.Resource == "" || e.Resource == filter.Resource) &&
+			(filter.ResourceID == "" || e.ResourceID == filter.ResourceID) &&
+			(filter.Actor == "" || e.Actor == filter.Actor) &&
+			(filter.RequestID == "" || e.RequestID == filter.RequestID) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func listLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		return MaxListLimit
+	}
+	return limit
+}
@@ -0,0 +1,217 @@
+// Package envconfig membaca config service dari flag, env dan file .env
+// dengan validasi per field. Dipakai config/ di setiap service.
+package envconfig
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// file .env yang dibaca kalau ada, tidak wajib (container cukup env asli)
+const defaultEnvFile = ".env"
+
+// Field satu field config. Nilai diambil dari flag, env, file lalu default,
+// yang pertama tidak kosong dipakai.
+type Field struct {
+	key      string
+	aliases  []string // nama env lama yang masih dibaca
+	def      string
+	usage    string
+	required bool
+	set      func(value string) error
+}
+
+// Loader mengumpulkan field config lalu membaca semuanya sekaligus, semua
+// masalah dilaporkan bersama
+type Loader struct {
+	name   string
+	fields []*Field
+}
+
+func NewLoader(name string) *Loader {
+	return &Loader{name: name}
+}
+
+func (l *Loader) add(f *Field) *Field {
+	l.fields = append(l.fields, f)
+	return f
+}
+
+func (l *Loader) String(p *string, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		*p = v
+		return nil
+	}})
+}
+
+func (l *Loader) Secret(p *Secret, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		*p = Secret(v)
+		return nil
+	}})
+}
+
+func (l *Loader) Duration(p *time.Duration, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		if d <= 0 {
+			return fmt.Errorf("duration must be positive, got %s", v)
+		}
+		*p = d
+		return nil
+	}})
+}
+
+// daftar dipisah koma, item kosong dibuang
+func (l *Loader) List(p *[]string, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		*p = nil
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+		return nil
+	}})
+}
+
+// LOG_LEVEL: debug, info, warn, error
+func (l *Loader) LogLevel(p *string, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("invalid log level %q", v)
+		}
+		*p = strings.ToLower(v)
+		return nil
+	}})
+}
+
+// port TCP, boleh dengan ":" di depan (format lama GATEWAY_PORT)
+func (l *Loader) Port(p *string, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		port, err := strconv.Atoi(strings.TrimPrefix(v, ":"))
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", v)
+		}
+		*p = strconv.Itoa(port)
+		return nil
+	}})
+}
+
+// URL absolut http/https, tanpa "/" di akhir
+func (l *Loader) URL(p *string, key, def, usage string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid URL %q, want http(s)://host", v)
+		}
+		*p = strings.TrimSuffix(v, "/")
+		return nil
+	}})
+}
+
+// nilai harus salah satu dari choices
+func (l *Loader) OneOf(p *string, key, def, usage string, choices ...string) *Field {
+	return l.add(&Field{key: key, def: def, usage: usage, set: func(v string) error {
+		for _, choice := range choices {
+			if v == choice {
+				*p = v
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q, want one of %s", v, strings.Join(choices, ", "))
+	}})
+}
+
+func (f *Field) Required() *Field {
+	f.required = true
+	return f
+}
+
+func (f *Field) Alias(keys ...string) *Field {
+	f.aliases = append(f.aliases, keys...)
+	return f
+}
+
+// nama flag dari key env: JWT_SECRET -> -jwt-secret
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// Load membaca semua field. Urutan sumber: flag, env, file (-config, CONFIG_FILE,
+// atau .env kalau ada), default. env diisi os.LookupEnv, test memakai map
+// sendiri supaya bisa paralel.
+func (l *Loader) Load(args []string, env func(string) (string, bool)) error {
+	flags := flag.NewFlagSet(l.name, flag.ContinueOnError)
+	configFile := flags.String("config", "", "file env tambahan (default CONFIG_FILE atau .env kalau ada)")
+	flagValues := make(map[string]*string, len(l.fields))
+	for _, f := range l.fields {
+		flagValues[f.key] = flags.String(flagName(f.key), "", f.usage+" (env "+f.key+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	path, explicit := *configFile, true
+	if path == "" {
+		path, explicit = env("CONFIG_FILE")
+	}
+	if path == "" {
+		path, explicit = defaultEnvFile, false
+	}
+	file, err := godotenv.Read(path)
+	if err != nil {
+		if explicit || !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	lookup := func(f *Field) string {
+		if v := *flagValues[f.key]; v != "" {
+			return v
+		}
+		keys := append([]string{f.key}, f.aliases...)
+		for _, key := range keys {
+			if v, _ := env(key); v != "" {
+				return v
+			}
+		}
+		for _, key := range keys {
+			if v := file[key]; v != "" {
+				return v
+			}
+		}
+		return f.def
+	}
+
+	var errs []error
+	for _, f := range l.fields {
+		value := lookup(f)
+		if value == "" {
+			if f.required {
+				errs = append(errs, fmt.Errorf("%s is required", f.key))
+			}
+			continue
+		}
+		if err := f.set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,32 @@
+package envconfig
+
+import "log/slog"
+
+const redacted = "[REDACTED]"
+
+// Secret nilai rahasia (JWT secret, URI dengan password). Tidak pernah
+// tercetak utuh lewat fmt, slog maupun JSON, ambil nilai asli lewat Value.
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return `"` + s.String() + `"`
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
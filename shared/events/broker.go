@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"shared/logging"
+)
+
+// Jenis broker yang didukung
+const (
+	BrokerMemory = "memory"
+	BrokerNATS   = "nats"
+)
+
+// Handler memproses satu event. Error berarti event belum berhasil diproses
+// dan boleh dikirim ulang (at-least-once), jadi handler harus idempotent
+// atau dibungkus Dedupe.
+type Handler func(ctx context.Context, env Envelope) error
+
+// Subscription aktif, panggil Unsubscribe untuk berhenti
+type Subscription interface {
+	Unsubscribe() error
+}
+
+// Publisher mengirim event ke broker
+type Publisher interface {
+	Publish(ctx context.Context, env Envelope) error
+}
+
+// Broker adalah abstraksi message broker. Subject memakai format NATS
+// (token dipisah titik, "*" satu token, ">" sisa token).
+type Broker interface {
+	Publisher
+	Subscribe(subject, queue string, handler Handler) (Subscription, error)
+	// error kalau broker sudah ditutup atau koneksi sedang putus,
+	// dipakai readiness check
+	Ping() error
+	Close() error
+}
+
+// Buka broker sesuai jenis. opts hanya dipakai untuk NATS.
+func Open(kind string, opts NATSOptions) (Broker, error) {
+	switch kind {
+	case "", BrokerMemory:
+		return NewMemoryBroker(), nil
+	case BrokerNATS:
+		return DialNATS(opts)
+	default:
+		return nil, fmt.Errorf("unknown event broker %q", kind)
+	}
+}
+
+// Berapa kali handler dicoba ulang sebelum event dianggap gagal
+const handlerAttempts = 3
+
+// Jalankan handler dengan retry sederhana. Dipakai broker memory dan
+// consumer NATS tanpa queue, yang tidak punya redelivery dari server.
+func deliver(ctx context.Context, handler Handler, env Envelope) {
+	ctx = logging.WithRequestID(ctx, env.RequestID)
+	var err error
+	for attempt := 1; attempt <= handlerAttempts; attempt++ {
+		if err = handler(ctx, env); err == nil {
+			return
+		}
+		if attempt < handlerAttempts {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+	}
+	slog.ErrorContext(ctx, "event handler failed", "event_type", env.Type, "event_id", env.ID, "attempts", handlerAttempts, "error", err)
+}
+
+// Publish dengan retry. Envelope (dan ID-nya) sama di setiap percobaan,
+// sehingga duplikat di sisi consumer bisa di-dedupe.
+func PublishWithRetry(ctx context.Context, pub Publisher, env Envelope, attempts int) error {
+	if env.RequestID == "" {
+		env.RequestID = logging.RequestID(ctx)
+	}
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = pub.Publish(ctx, env); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 200 * time.Millisecond):
+		}
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Deduper mencatat event yang sudah diproses consumer
+type Deduper interface {
+	// Seen true jika event dengan id ini sudah pernah sukses diproses
+	Seen(ctx context.Context, consumer, id string) (bool, error)
+	// Mark menandai event sudah sukses diproses
+	Mark(ctx context.Context, consumer, id string) error
+}
+
+// Dedupe membungkus handler supaya event yang sama (id sama) hanya
+// diproses sekali per consumer. Event baru ditandai setelah handler sukses,
+// jadi kalau handler gagal event tetap bisa diproses ulang.
+func Dedupe(store Deduper, consumer string, handler Handler) Handler {
+	return func(ctx context.Context, env Envelope) error {
+		seen, err := store.Seen(ctx, consumer, env.ID)
+		if err != nil {
+			return err
+		}
+		if seen {
+			return nil
+		}
+
+		if err := handler(ctx, env); err != nil {
+			return err
+		}
+
+		return store.Mark(ctx, consumer, env.ID)
+	}
+}
+
+// MemoryDeduper menyimpan id event di memori dengan TTL, untuk test dan
+// consumer yang cukup dedupe selama proses hidup.
+type MemoryDeduper struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[string]time.Time
+}
+
+// Inisialisasi deduper in-memory
+func NewMemoryDeduper(ttl time.Duration) *MemoryDeduper {
+	return &MemoryDeduper{ttl: ttl, seen: make(map[string]time.Time)}
+}
+
+func (d *MemoryDeduper) Seen(ctx context.Context, consumer, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	at, ok := d.seen[consumer+"/"+id]
+	if !ok {
+		return false, nil
+	}
+	if d.ttl > 0 && time.Since(at) > d.ttl {
+		delete(d.seen, consumer+"/"+id)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (d *MemoryDeduper) Mark(ctx context.Context, consumer, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.seen[consumer+"/"+id] = now
+
+	// bersihkan entry kadaluarsa sesekali supaya map tidak terus membesar
+	if d.ttl > 0 && len(d.seen)%1000 == 0 {
+		for key, at := range d.seen {
+			if now.Sub(at) > d.ttl {
+				delete(d.seen, key)
+			}
+		}
+	}
+	return nil
+}
+
+// MongoDeduper menyimpan id event di collection Mongo, sehingga dedupe
+// tetap berlaku setelah restart. Dokumen dihapus otomatis oleh TTL index.
+type MongoDeduper struct {
+	collection *mongo.Collection
+}
+
+// Inisialisasi deduper Mongo dan buat TTL index di processed_at
+func NewMongoDeduper(db *mongo.Database, ttl time.Duration) (*MongoDeduper, error) {
+	collection := db.Collection("processed_events")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "processed_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoDeduper{collection: collection}, nil
+}
+
+func (d *MongoDeduper) Seen(ctx context.Context, consumer, id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	count, err := d.collection.CountDocuments(ctx, bson.M{"_id": consumer + "/" + id})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d *MongoDeduper) Mark(ctx context.Context, consumer, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := d.collection.InsertOne(ctx, bson.M{
+		"_id":          consumer + "/" + id,
+		"consumer":     consumer,
+		"event_id":     id,
+		"processed_at": time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tipe event domain. Nilai ini juga dipakai sebagai subject di broker.
+const (
+	TypePaymentCreated           = "payment.created"
+	TypePaymentRefunded          = "payment.refunded"
+	TypeTransactionCreated       = "transaction.created"
+	TypeProductStockChanged      = "product.stock_changed"
+	TypeOrderCreated             = "order.created"
+	TypeProductStockLow          = "product.stock_low"
+	TypeTransactionStatusChanged = "transaction.status_changed"
+	TypeReconciliationMismatch   = "reconciliation.mismatch_found"
+)
+
+// Versi schema payload per tipe event. Naikkan versi jika ada perubahan
+// payload yang tidak backward compatible.
+const (
+	VersionPaymentCreated           = 2
+	VersionPaymentRefunded          = 2
+	VersionTransactionCreated       = 2
+	VersionProductStockChanged      = 1
+	VersionOrderCreated             = 1
+	VersionProductStockLow          = 1
+	VersionTransactionStatusChanged = 1
+	VersionReconciliationMismatch   = 1
+)
+
+// Envelope membungkus payload event dengan metadata yang sama untuk semua tipe.
+// ID unik per event dan tetap sama saat publish di-retry, sehingga consumer
+// bisa dedupe.
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+	// request yang memicu event, kosong untuk event dari job background
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Buat envelope baru dari payload
+func NewEnvelope(eventType string, version int, source string, payload any) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		Version:    version,
+		Source:     source,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}, nil
+}
+
+// Decode payload ke struct tujuan
+func (e Envelope) Decode(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// Payload payment.created v2 (v2: amount jadi money dalam minor unit)
+type PaymentCreated struct {
+	PaymentID string      `json:"payment_id"`
+	Email     string      `json:"email"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+}
+
+// Payload payment.refunded v2 (v2: amount jadi money dalam minor unit)
+type PaymentRefunded struct {
+	PaymentID string      `json:"payment_id"`
+	Email     string      `json:"email"`
+	Amount    money.Money `json:"amount"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// Payload transaction.created v2 (v2: total jadi money dalam minor unit)
+type TransactionCreated struct {
+	TransactionID string      `json:"transaction_id"`
+	PaymentID     string      `json:"payment_id"`
+	ProductID     string      `json:"product_id"`
+	Email         string      `json:"email"`
+	Quantity      int         `json:"quantity"`
+	Total         money.Money `json:"total"`
+	Status        string      `json:"status"`
+}
+
+// Payload transaction.status_changed v1, dikirim di setiap perpindahan
+// status transaksi. Carrier/TrackingNumber diisi saat dikirim (shipped).
+type TransactionStatusChanged struct {
+	TransactionID  string    `json:"transaction_id"`
+	Email          string    `json:"email"`
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	Actor          string    `json:"actor"`
+	Note           string    `json:"note,omitempty"`
+	Carrier        string    `json:"carrier,omitempty"`
+	TrackingNumber string    `json:"tracking_number,omitempty"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+// Payload product.stock_changed v1
+type ProductStockChanged struct {
+	ProductID string `json:"product_id"`
+	OldStock  int    `json:"old_stock"`
+	NewStock  int    `json:"new_stock"`
+}
+
+// Payload product.stock_low v1, dikirim saat stok tersedia turun
+// sampai batas low stock product
+type ProductStockLow struct {
+	ProductID string `json:"product_id"`
+	Available int    `json:"available"`
+	Threshold int    `json:"threshold"`
+}
+
+// Item di payload order.created
+type OrderCreatedItem struct {
+	ProductID string      `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+}
+
+// Payload order.created v1
+type OrderCreated struct {
+	OrderID   string             `json:"order_id"`
+	PaymentID string             `json:"payment_id"`
+	Email     string             `json:"email"`
+	Items     []OrderCreatedItem `json:"items"`
+	Total     money.Money        `json:"total"`
+}
+
+// Payload reconciliation.mismatch_found v1, ringkasan report rekonsiliasi
+// payment yang menemukan selisih. Detail ada di report ReportID.
+type ReconciliationMismatch struct {
+	ReportID       string         `json:"report_id"`
+	Transactions   int            `json:"transactions"`
+	Payments       int            `json:"payments"`
+	Mismatches     int            `json:"mismatches"`
+	MismatchByKind map[string]int `json:"mismatch_by_kind"`
+	FinishedAt     time.Time      `json:"finished_at"`
+}
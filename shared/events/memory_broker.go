@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrBrokerClosed = errors.New("event broker closed")
+
+// MemoryBroker adalah broker in-process, dipakai untuk test dan development.
+// Handler dipanggil langsung di dalam Publish sehingga hasilnya deterministik.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	subs      map[int]*memorySubscription
+	nextID    int
+	queueNext map[string]int
+	published []Envelope
+	closed    bool
+}
+
+type memorySubscription struct {
+	broker  *MemoryBroker
+	id      int
+	subject string
+	queue   string
+	handler Handler
+}
+
+// Inisialisasi broker in-memory
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs:      make(map[int]*memorySubscription),
+		queueNext: make(map[string]int),
+	}
+}
+
+// Publish event ke semua subscription yang cocok. Untuk subscription dengan
+// queue yang sama, hanya satu yang menerima (round robin).
+func (b *MemoryBroker) Publish(ctx context.Context, env Envelope) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBrokerClosed
+	}
+	b.published = append(b.published, env)
+
+	var targets []*memorySubscription
+	queues := make(map[string][]*memorySubscription)
+	for id := 0; id < b.nextID; id++ {
+		sub, ok := b.subs[id]
+		if !ok || !matchSubject(sub.subject, env.Type) {
+			continue
+		}
+		if sub.queue == "" {
+			targets = append(targets, sub)
+			continue
+		}
+		key := sub.subject + "|" + sub.queue
+		queues[key] = append(queues[key], sub)
+	}
+	for key, members := range queues {
+		idx := b.queueNext[key] % len(members)
+		b.queueNext[key]++
+		targets = append(targets, members[idx])
+	}
+	b.mu.Unlock()
+
+	for _, sub := range targets {
+		deliver(ctx, sub.handler, env)
+	}
+	return nil
+}
+
+// Subscribe ke subject, queue kosong berarti semua subscriber menerima
+func (b *MemoryBroker) Subscribe(subject, queue string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	sub := &memorySubscription{
+		broker:  b,
+		id:      b.nextID,
+		subject: subject,
+		queue:   queue,
+		handler: handler,
+	}
+	b.subs[sub.id] = sub
+	b.nextID++
+
+	return sub, nil
+}
+
+// Published mengembalikan salinan semua event yang pernah di-publish
+func (b *MemoryBroker) Published() []Envelope {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Envelope(nil), b.published...)
+}
+
+// Broker memory selalu siap selama belum ditutup
+func (b *MemoryBroker) Ping() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+	return nil
+}
+
+// Tutup broker, publish/subscribe berikutnya akan gagal
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.subs = make(map[int]*memorySubscription)
+	return nil
+}
+
+func (s *memorySubscription) Unsubscribe() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	delete(s.broker.subs, s.id)
+	return nil
+}
@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSubject(t *testing.T) {
+	assert.True(t, matchSubject("payment.created", "payment.created"))
+	assert.True(t, matchSubject("payment.*", "payment.created"))
+	assert.True(t, matchSubject(">", "payment.created"))
+	assert.True(t, matchSubject("payment.>", "payment.created"))
+	assert.False(t, matchSubject("payment.*", "product.stock_changed"))
+	assert.False(t, matchSubject("payment", "payment.created"))
+	assert.False(t, matchSubject("payment.created.extra", "payment.created"))
+}
+
+func TestMemoryBroker_FanOutAndWildcard(t *testing.T) {
+	broker := NewMemoryBroker()
+
+	var exact, wildcard, other int
+	broker.Subscribe(TypePaymentCreated, "", func(ctx context.Context, env Envelope) error { exact++; return nil })
+	broker.Subscribe("payment.*", "", func(ctx context.Context, env Envelope) error { wildcard++; return nil })
+	broker.Subscribe(TypeProductStockChanged, "", func(ctx context.Context, env Envelope) error { other++; return nil })
+
+	env, err := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{PaymentID: "p1"})
+	assert.NoError(t, err)
+	assert.NoError(t, broker.Publish(context.TODO(), env))
+
+	assert.Equal(t, 1, exact)
+	assert.Equal(t, 1, wildcard)
+	assert.Equal(t, 0, other)
+	assert.Len(t, broker.Published(), 1)
+}
+
+func TestMemoryBroker_QueueGroup(t *testing.T) {
+	broker := NewMemoryBroker()
+
+	var a, b int
+	broker.Subscribe(TypePaymentCreated, "workers", func(ctx context.Context, env Envelope) error { a++; return nil })
+	broker.Subscribe(TypePaymentCreated, "workers", func(ctx context.Context, env Envelope) error { b++; return nil })
+
+	for i := 0; i < 4; i++ {
+		env, _ := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{})
+		broker.Publish(context.TODO(), env)
+	}
+
+	assert.Equal(t, 2, a)
+	assert.Equal(t, 2, b)
+}
+
+func TestMemoryBroker_RetriesFailedHandler(t *testing.T) {
+	broker := NewMemoryBroker()
+
+	calls := 0
+	broker.Subscribe(TypePaymentCreated, "", func(ctx context.Context, env Envelope) error {
+		calls++
+		if calls < 2 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+
+	env, _ := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{})
+	broker.Publish(context.TODO(), env)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestMemoryBroker_Unsubscribe(t *testing.T) {
+	broker := NewMemoryBroker()
+
+	calls := 0
+	sub, _ := broker.Subscribe(TypePaymentCreated, "", func(ctx context.Context, env Envelope) error { calls++; return nil })
+	sub.Unsubscribe()
+
+	env, _ := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{})
+	broker.Publish(context.TODO(), env)
+
+	assert.Equal(t, 0, calls)
+}
+
+func TestDedupe_SkipsDuplicates(t *testing.T) {
+	store := NewMemoryDeduper(0)
+
+	calls := 0
+	handler := Dedupe(store, "test-consumer", func(ctx context.Context, env Envelope) error {
+		calls++
+		return nil
+	})
+
+	env, _ := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{})
+	assert.NoError(t, handler(context.TODO(), env))
+	assert.NoError(t, handler(context.TODO(), env))
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestDedupe_RetriesAfterFailure(t *testing.T) {
+	store := NewMemoryDeduper(0)
+
+	calls := 0
+	handler := Dedupe(store, "test-consumer", func(ctx context.Context, env Envelope) error {
+		calls++
+		if calls == 1 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+
+	env, _ := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{})
+	assert.Error(t, handler(context.TODO(), env))
+	assert.NoError(t, handler(context.TODO(), env))
+	assert.NoError(t, handler(context.TODO(), env))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestEnvelope_Decode(t *testing.T) {
+	env, err := NewEnvelope(TypeProductStockChanged, VersionProductStockChanged, "shopping-service", ProductStockChanged{
+		ProductID: "p1",
+		OldStock:  5,
+		NewStock:  3,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, env.ID)
+
+	var payload ProductStockChanged
+	assert.NoError(t, env.Decode(&payload))
+	assert.Equal(t, 3, payload.NewStock)
+}
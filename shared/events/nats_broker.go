@@ -0,0 +1,302 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"shared/logging"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// stream JetStream yang menyimpan semua event domain
+	natsStream = "EVENTS"
+	// event disimpan selama ini, cukup untuk consumer yang mati beberapa hari
+	natsStreamMaxAge = 7 * 24 * time.Hour
+	// publish ulang dengan Nats-Msg-Id sama di dalam window ini dibuang server
+	natsDuplicateWindow = 2 * time.Minute
+
+	natsDialTimeout  = 5 * time.Second
+	natsDrainTimeout = 10 * time.Second
+	natsSetupTimeout = 10 * time.Second
+
+	// batas pengiriman per event sebelum dianggap gagal permanen
+	natsMaxDeliver = 5
+	// waktu handler memproses event sebelum server mengirim ulang
+	natsAckWait = 30 * time.Second
+)
+
+// subject yang disimpan stream, satu per prefix tipe event
+var natsStreamSubjects = []string{"payment.>", "transaction.>", "product.>", "order.>", "reconciliation.>"}
+
+var ErrNotConnected = errors.New("event broker not connected")
+
+// NATSOptions koneksi ke NATS. URL boleh berisi user:password@ atau token@,
+// skema tls:// mewajibkan TLS.
+type NATSOptions struct {
+	URL string
+	// nama koneksi, juga prefix nama durable consumer
+	Name string
+	// file .creds (JWT + nkey) untuk server dengan decentralized auth
+	CredsFile string
+	// CA tambahan untuk verifikasi sertifikat server
+	CAFile string
+}
+
+// NATSBroker memakai JetStream. Event disimpan di stream EVENTS, publish
+// menunggu ack server dan Nats-Msg-Id = ID envelope sehingga retry publish
+// tidak menggandakan event. Subscribe dengan queue memakai durable consumer:
+// event yang gagal diproses (atau belum di-ack saat service mati) dikirim
+// ulang, jadi delivery at-least-once dan handler harus idempotent (Dedupe).
+type NATSBroker struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	name   string
+	closed chan struct{}
+
+	mu      sync.Mutex
+	subs    []*natsSubscription
+	closing bool
+}
+
+type natsSubscription struct {
+	broker  *NATSBroker
+	consume jetstream.ConsumeContext
+	once    sync.Once
+}
+
+// Koneksi ke server NATS lalu buat/perbarui stream EVENTS
+func DialNATS(opts NATSOptions) (*NATSBroker, error) {
+	if opts.URL == "" {
+		return nil, errors.New("NATS url is required")
+	}
+
+	b := &NATSBroker{name: opts.Name, closed: make(chan struct{})}
+	natsOpts := []nats.Option{
+		nats.Name(opts.Name),
+		nats.Timeout(natsDialTimeout),
+		nats.DrainTimeout(natsDrainTimeout),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				slog.Warn("nats connection lost", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			slog.Info("nats reconnected", "url", conn.ConnectedUrlRedacted())
+		}),
+		nats.ClosedHandler(func(*nats.Conn) { close(b.closed) }),
+	}
+	if opts.CredsFile != "" {
+		natsOpts = append(natsOpts, nats.UserCredentials(opts.CredsFile))
+	}
+	if opts.CAFile != "" {
+		natsOpts = append(natsOpts, nats.RootCAs(opts.CAFile))
+	}
+
+	conn, err := nats.Connect(opts.URL, natsOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("connect nats: %w", err)
+	}
+	b.conn = conn
+
+	b.js, err = jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+	_, err = b.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       natsStream,
+		Subjects:   natsStreamSubjects,
+		Storage:    jetstream.FileStorage,
+		MaxAge:     natsStreamMaxAge,
+		Duplicates: natsDuplicateWindow,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("create stream %s: %w", natsStream, err)
+	}
+
+	return b, nil
+}
+
+// Publish event dan tunggu ack JetStream
+func (b *NATSBroker) Publish(ctx context.Context, env Envelope) error {
+	if b.isClosing() {
+		return ErrBrokerClosed
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	_, err = b.js.Publish(ctx, env.Type, data, jetstream.WithMsgID(env.ID))
+	return err
+}
+
+// Subscribe ke subject. Dengan queue: durable consumer bersama (satu event
+// ke satu instance, resume setelah restart). Tanpa queue: consumer sementara
+// yang hanya menerima event baru selama proses hidup.
+func (b *NATSBroker) Subscribe(subject, queue string, handler Handler) (Subscription, error) {
+	if b.isClosing() {
+		return nil, ErrBrokerClosed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+
+	var consumer jetstream.Consumer
+	var err error
+	if queue == "" {
+		consumer, err = b.js.OrderedConsumer(ctx, natsStream, jetstream.OrderedConsumerConfig{
+			FilterSubjects: []string{subject},
+			DeliverPolicy:  jetstream.DeliverNewPolicy,
+		})
+	} else {
+		consumer, err = b.js.CreateOrUpdateConsumer(ctx, natsStream, jetstream.ConsumerConfig{
+			Durable:       durableName(b.name, queue, subject),
+			FilterSubject: subject,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       natsAckWait,
+			MaxDeliver:    natsMaxDeliver,
+			DeliverPolicy: jetstream.DeliverAllPolicy,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create consumer %s: %w", subject, err)
+	}
+
+	durable := queue != ""
+	consume, err := consumer.Consume(func(msg jetstream.Msg) {
+		handleNATSMessage(msg, handler, durable)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &natsSubscription{broker: b, consume: consume}
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+	return sub, nil
+}
+
+// Nama durable consumer: service, queue dan subject (tanpa "." "*" ">")
+func durableName(name, queue, subject string) string {
+	subject = strings.NewReplacer(".", "_", "*", "any", ">", "all").Replace(subject)
+	return strings.Join([]string{name, queue, subject}, "_")
+}
+
+// Proses satu pesan JetStream. Durable: ack kalau sukses, nak dengan jeda
+// kalau gagal supaya dikirim ulang, term kalau batas pengiriman habis atau
+// pesan rusak. Ordered consumer tidak memakai ack, handler di-retry lokal.
+func handleNATSMessage(msg jetstream.Msg, handler Handler, durable bool) {
+	var env Envelope
+	if err := json.Unmarshal(msg.Data(), &env); err != nil {
+		slog.Warn("drop invalid event message", "subject", msg.Subject(), "error", err)
+		if durable {
+			msg.Term()
+		}
+		return
+	}
+
+	if !durable {
+		deliver(context.Background(), handler, env)
+		return
+	}
+
+	ctx := logging.WithRequestID(context.Background(), env.RequestID)
+	err := handler(ctx, env)
+	if err == nil {
+		msg.Ack()
+		return
+	}
+
+	attempt := uint64(1)
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		attempt = meta.NumDelivered
+	}
+	if attempt >= natsMaxDeliver {
+		slog.ErrorContext(ctx, "event handler failed", "event_type", env.Type, "event_id", env.ID, "attempts", attempt, "error", err)
+		msg.Term()
+		return
+	}
+	slog.WarnContext(ctx, "event handler failed, will redeliver", "event_type", env.Type, "event_id", env.ID, "attempt", attempt, "error", err)
+	msg.NakWithDelay(time.Duration(attempt) * time.Second)
+}
+
+// Siap kalau koneksi NATS sedang tersambung
+func (b *NATSBroker) Ping() error {
+	if b.isClosing() {
+		return ErrBrokerClosed
+	}
+	if b.conn.Status() != nats.CONNECTED {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Hentikan consumer lalu drain koneksi: publish yang masih di buffer
+// dikirim dulu sebelum koneksi ditutup
+func (b *NATSBroker) Close() error {
+	b.mu.Lock()
+	if b.closing {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closing = true
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.stop()
+	}
+	if err := b.conn.Drain(); err != nil {
+		b.conn.Close()
+		return err
+	}
+	select {
+	case <-b.closed:
+	case <-time.After(natsDrainTimeout):
+		b.conn.Close()
+	}
+	return nil
+}
+
+func (b *NATSBroker) isClosing() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closing
+}
+
+func (s *natsSubscription) stop() {
+	s.once.Do(s.consume.Stop)
+}
+
+// Berhenti menerima event. Durable consumer tetap ada di server, event
+// baru menunggu sampai ada subscriber lagi.
+func (s *natsSubscription) Unsubscribe() error {
+	s.stop()
+
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	for i, sub := range s.broker.subs {
+		if sub == s {
+			s.broker.subs = append(s.broker.subs[:i], s.broker.subs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
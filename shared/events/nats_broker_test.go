@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"shared/money"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMsg mencatat ack/nak/term yang dipanggil handler
+type fakeMsg struct {
+	jetstream.Msg
+	data      []byte
+	delivered uint64
+
+	acked   bool
+	termed  bool
+	nakWait time.Duration
+}
+
+func (m *fakeMsg) Data() []byte    { return m.data }
+func (m *fakeMsg) Subject() string { return TypePaymentCreated }
+func (m *fakeMsg) Ack() error      { m.acked = true; return nil }
+func (m *fakeMsg) Term() error     { m.termed = true; return nil }
+func (m *fakeMsg) NakWithDelay(d time.Duration) error {
+	m.nakWait = d
+	return nil
+}
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.delivered}, nil
+}
+
+func newFakeMsg(t *testing.T, delivered uint64) *fakeMsg {
+	env, err := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{PaymentID: "p1", Amount: money.New(1000, "IDR")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.RequestID = "req-1"
+	data, _ := json.Marshal(env)
+	return &fakeMsg{data: data, delivered: delivered}
+}
+
+func TestHandleNATSMessage_Durable(t *testing.T) {
+	failing := errors.New("mongo down")
+
+	tests := []struct {
+		name       string
+		delivered  uint64
+		handlerErr error
+		wantAck    bool
+		wantTerm   bool
+		wantNak    time.Duration
+	}{
+		{name: "sukses di-ack", delivered: 1, wantAck: true},
+		{name: "gagal dikirim ulang dengan jeda", delivered: 2, handlerErr: failing, wantNak: 2 * time.Second},
+		{name: "batas pengiriman habis", delivered: natsMaxDeliver, handlerErr: failing, wantTerm: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := newFakeMsg(t, tt.delivered)
+			var got Envelope
+			handleNATSMessage(msg, func(ctx context.Context, env Envelope) error {
+				got = env
+				return tt.handlerErr
+			}, true)
+
+			assert.Equal(t, "req-1", got.RequestID)
+			assert.Equal(t, tt.wantAck, msg.acked)
+			assert.Equal(t, tt.wantTerm, msg.termed)
+			assert.Equal(t, tt.wantNak, msg.nakWait)
+		})
+	}
+}
+
+func TestHandleNATSMessage_InvalidPayloadTerminated(t *testing.T) {
+	msg := &fakeMsg{data: []byte("not json"), delivered: 1}
+	called := false
+	handleNATSMessage(msg, func(ctx context.Context, env Envelope) error {
+		called = true
+		return nil
+	}, true)
+
+	assert.False(t, called)
+	assert.True(t, msg.termed)
+}
+
+func TestHandleNATSMessage_OrderedRetriesLocally(t *testing.T) {
+	msg := newFakeMsg(t, 1)
+	calls := 0
+	handleNATSMessage(msg, func(ctx context.Context, env Envelope) error {
+		calls++
+		if calls < 2 {
+			return errors.New("sementara")
+		}
+		return nil
+	}, false)
+
+	assert.Equal(t, 2, calls)
+	assert.False(t, msg.acked)
+}
+
+func TestDurableName(t *testing.T) {
+	assert.Equal(t, "payment-service_webhook-fanout_payment_created", durableName("payment-service", "webhook-fanout", "payment.created"))
+	assert.Equal(t, "shopping-service_invoice_transaction_all", durableName("shopping-service", "invoice", "transaction.>"))
+	assert.Equal(t, "svc_q_order_any", durableName("svc", "q", "order.*"))
+}
+
+func TestDialNATS_Errors(t *testing.T) {
+	_, err := DialNATS(NATSOptions{})
+	assert.Error(t, err)
+
+	// tidak ada server di port ini
+	_, err = DialNATS(NATSOptions{URL: "nats://127.0.0.1:1", Name: "test"})
+	assert.ErrorContains(t, err, "connect nats")
+}
+
+func TestNATSBroker_ClosedBrokerRejectsPublish(t *testing.T) {
+	broker := &NATSBroker{closing: true}
+
+	env, _ := NewEnvelope(TypePaymentCreated, VersionPaymentCreated, "test", PaymentCreated{})
+	assert.ErrorIs(t, broker.Publish(context.TODO(), env), ErrBrokerClosed)
+	assert.ErrorIs(t, broker.Ping(), ErrBrokerClosed)
+	_, err := broker.Subscribe(TypePaymentCreated, "q", nil)
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+	assert.NoError(t, broker.Close())
+}
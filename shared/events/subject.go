@@ -0,0 +1,24 @@
+package events
+
+import "strings"
+
+// Cocokkan subject dengan pattern format NATS.
+// "*" cocok dengan satu token, ">" cocok dengan satu atau lebih token sisa.
+func matchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+
+	return len(patternTokens) == len(subjectTokens)
+}
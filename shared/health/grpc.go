@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// interval update status grpc.health.v1 dari checker
+const DefaultGRPCInterval = 5 * time.Second
+
+// RegisterGRPC memasang service standar grpc.health.v1 di server. Status
+// keseluruhan (service "") diperbarui dari checker tiap interval sampai
+// ctx selesai, lalu diset NOT_SERVING.
+func RegisterGRPC(ctx context.Context, server *grpc.Server, checker *Checker, interval time.Duration) *health.Server {
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(server, hs)
+
+	update := func() {
+		status := healthpb.HealthCheckResponse_SERVING
+		if report := checker.Run(ctx); report.Status != StatusOK {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			slog.WarnContext(ctx, "service not ready", "checks", report.Checks)
+		}
+		hs.SetServingStatus("", status)
+	}
+
+	update()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				hs.Shutdown()
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	}()
+	return hs
+}
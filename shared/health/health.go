@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// status check dan laporan
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// batas waktu satu check, supaya probe tidak menggantung
+const DefaultTimeout = 2 * time.Second
+
+// Check memeriksa satu dependency, error berarti service belum siap
+type Check func(ctx context.Context) error
+
+// hasil satu check
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// laporan readiness satu service
+type Report struct {
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker menjalankan semua check readiness secara paralel
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+func NewChecker() *Checker {
+	return &Checker{timeout: DefaultTimeout}
+}
+
+// Tambah check dengan nama dependency (mongo, broker, shopping, ...)
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Jalankan semua check, status ok hanya kalau semua check ok
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := nc.check(ctx)
+			result := Result{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[nc.name] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}(nc)
+	}
+	wg.Wait()
+	return report
+}
+
+// LiveHandler untuk /healthz: proses hidup dan bisa melayani HTTP,
+// tanpa cek dependency supaya service tidak di-restart saat Mongo down
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusOK})
+	})
+}
+
+// ReadyHandler untuk /readyz: 200 kalau semua dependency siap, 503 kalau
+// tidak, body berisi hasil tiap check
+func ReadyHandler(checker *Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Run(r.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
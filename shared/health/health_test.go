@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestChecker_Run(t *testing.T) {
+	checker := NewChecker()
+	checker.Add("mongo", func(ctx context.Context) error { return nil })
+	assert.Equal(t, StatusOK, checker.Run(context.Background()).Status)
+
+	checker.Add("broker", func(ctx context.Context) error { return errors.New("not connected") })
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["mongo"].Status)
+	assert.Equal(t, StatusFail, report.Checks["broker"].Status)
+	assert.Equal(t, "not connected", report.Checks["broker"].Error)
+}
+
+func TestChecker_RunTimeout(t *testing.T) {
+	checker := &Checker{timeout: 50 * time.Millisecond}
+	checker.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestReadyHandler(t *testing.T) {
+	ready := true
+	checker := NewChecker()
+	checker.Add("mongo", func(ctx context.Context) error {
+		if !ready {
+			return errors.New("server selection timeout")
+		}
+		return nil
+	})
+	handler := ReadyHandler(checker)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	ready = false
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, "server selection timeout", report.Checks["mongo"].Error)
+
+	// liveness tidak ikut dependency
+	rec = httptest.NewRecorder()
+	LiveHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRegisterGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	server := grpc.NewServer()
+
+	var ready atomic.Bool
+	checker := NewChecker()
+	checker.Add("mongo", func(ctx context.Context) error {
+		if !ready.Load() {
+			return errors.New("no reachable servers")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	RegisterGRPC(ctx, server, checker, 10*time.Millisecond)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.Status)
+	}
+
+	// status ikut berubah di update berikutnya
+	ready.Store(true)
+	assert.Eventually(t, func() bool {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err == nil && res.Status == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+}
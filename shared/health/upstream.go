@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCCheck memanggil grpc.health.v1 Check di upstream, siap kalau SERVING
+func GRPCCheck(conn grpc.ClientConnInterface) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", res.Status)
+		}
+		return nil
+	}
+}
+
+// HTTPCheck memanggil endpoint readiness upstream, siap kalau 2xx
+func HTTPCheck(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		_, err := FetchReport(ctx, client, url)
+		return err
+	}
+}
+
+// FetchReport mengambil laporan readiness upstream. Error kalau upstream
+// tidak bisa dihubungi atau tidak siap; laporan tetap dikembalikan kalau
+// body bisa dibaca.
+func FetchReport(ctx context.Context, client *http.Client, url string) (Report, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Report{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Report{}, err
+	}
+	defer resp.Body.Close()
+
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		report = Report{Status: StatusFail}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return report, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return report, nil
+}
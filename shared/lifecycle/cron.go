@@ -0,0 +1,26 @@
+package lifecycle
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+)
+
+// StopCron menghentikan semua scheduler cron lalu menunggu job yang sedang
+// berjalan selesai
+func StopCron(schedulers ...*cron.Cron) Hook {
+	return func(ctx context.Context) error {
+		running := make([]context.Context, 0, len(schedulers))
+		for _, c := range schedulers {
+			running = append(running, c.Stop())
+		}
+		for _, done := range running {
+			select {
+			case <-done.Done():
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+}
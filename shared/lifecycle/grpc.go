@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// StopGRPC menunggu RPC yang berjalan selesai (GracefulStop). Kalau
+// deadline habis, misalnya stream watch yang tidak ditutup client, server
+// dihentikan paksa.
+func StopGRPC(server *grpc.Server) Hook {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			server.Stop()
+			return ctx.Err()
+		}
+	}
+}
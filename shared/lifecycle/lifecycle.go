@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// batas waktu seluruh proses shutdown, di bawah stop_grace_period docker
+const DefaultTimeout = 20 * time.Second
+
+// Hook satu langkah shutdown, ctx berakhir saat deadline shutdown habis
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Manager menunggu SIGTERM/SIGINT (atau server yang berhenti karena error)
+// lalu menjalankan hook shutdown berurutan sesuai urutan daftar dengan satu
+// deadline bersama
+type Manager struct {
+	timeout time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	errs    chan error
+
+	mu    sync.Mutex
+	hooks []namedHook
+	once  sync.Once
+	err   error
+}
+
+func NewManager(timeout time.Duration) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		errs:    make(chan error, 1),
+	}
+}
+
+// Context selesai begitu shutdown dimulai, dipakai loop background (cron,
+// dispatcher, health) supaya berhenti sebelum dependency ditutup
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Daftarkan langkah shutdown, dijalankan sesuai urutan pendaftaran
+func (m *Manager) OnShutdown(name string, hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+// Go menjalankan server (Serve/Start) di background. Server yang berhenti
+// dengan error memicu shutdown; http.ErrServerClosed karena Shutdown
+// dianggap normal.
+func (m *Manager) Go(name string, run func() error) {
+	go func() {
+		if err := run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case m.errs <- fmt.Errorf("%s: %w", name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// Wait blok sampai sinyal berhenti atau ada server yang gagal, lalu
+// menjalankan Shutdown. Error server dan error langkah shutdown
+// dikembalikan supaya main bisa exit non-zero.
+func (m *Manager) Wait() error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	var cause error
+	select {
+	case s := <-sig:
+		slog.Info("shutdown signal received", "signal", s.String())
+	case cause = <-m.errs:
+		slog.Error("server stopped, shutting down", "error", cause)
+	case <-m.ctx.Done():
+	}
+	return errors.Join(cause, m.Shutdown())
+}
+
+// Shutdown membatalkan Context lalu menjalankan semua hook berurutan.
+// Langkah yang gagal dicatat dan langkah berikutnya tetap dijalankan,
+// supaya koneksi Mongo dan trace tetap ditutup. Aman dipanggil berulang.
+func (m *Manager) Shutdown() error {
+	m.once.Do(func() {
+		m.cancel()
+
+		m.mu.Lock()
+		hooks := append([]namedHook(nil), m.hooks...)
+		m.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+
+		var errs []error
+		for _, h := range hooks {
+			start := time.Now()
+			if err := h.hook(ctx); err != nil {
+				slog.Error("shutdown step failed", "step", h.name, "error", err)
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+				continue
+			}
+			slog.Info("shutdown step done", "step", h.name, "duration_ms", time.Since(start).Milliseconds())
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
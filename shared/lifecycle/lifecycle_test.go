@@ -0,0 +1,110 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestManager_ShutdownRunsHooksInOrder(t *testing.T) {
+	m := NewManager(time.Second)
+
+	var steps []string
+	m.OnShutdown("grpc", func(ctx context.Context) error {
+		// loop background sudah diberhentikan sebelum hook pertama
+		assert.Error(t, m.Context().Err())
+		steps = append(steps, "grpc")
+		return nil
+	})
+	m.OnShutdown("broker", func(ctx context.Context) error {
+		steps = append(steps, "broker")
+		return errors.New("flush failed")
+	})
+	m.OnShutdown("mongo", func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		steps = append(steps, "mongo")
+		return nil
+	})
+
+	err := m.Shutdown()
+	assert.ErrorContains(t, err, "broker: flush failed")
+	assert.Equal(t, []string{"grpc", "broker", "mongo"}, steps)
+
+	// panggilan kedua tidak menjalankan hook lagi
+	assert.Equal(t, err, m.Shutdown())
+	assert.Len(t, steps, 3)
+}
+
+func TestManager_WaitOnServerError(t *testing.T) {
+	m := NewManager(time.Second)
+	stopped := false
+	m.OnShutdown("http", func(ctx context.Context) error {
+		stopped = true
+		return nil
+	})
+
+	m.Go("admin", func() error { return errors.New("address already in use") })
+
+	err := m.Wait()
+	assert.ErrorContains(t, err, "admin: address already in use")
+	assert.True(t, stopped)
+}
+
+func TestManager_WaitOnSignal(t *testing.T) {
+	m := NewManager(time.Second)
+	srv := &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	m.Go("admin", srv.ListenAndServe)
+	m.OnShutdown("admin", srv.Shutdown)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	}()
+
+	// http.ErrServerClosed setelah Shutdown bukan error
+	assert.NoError(t, m.Wait())
+}
+
+func TestStopGRPC_ForcesStopAfterDeadline(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	// stream Watch tidak pernah selesai sendiri, GracefulStop akan menunggu
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = stream.Recv()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, StopGRPC(server)(ctx), context.DeadlineExceeded)
+	assert.NoError(t, <-served)
+
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
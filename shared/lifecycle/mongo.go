@@ -0,0 +1,15 @@
+package lifecycle
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CloseMongo memutus koneksi Mongo, koneksi yang masih dipakai ditutup
+// paksa saat deadline habis
+func CloseMongo(client *mongo.Client) Hook {
+	return func(ctx context.Context) error {
+		return client.Disconnect(ctx)
+	}
+}
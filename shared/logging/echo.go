@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware echo yang menaruh request ID ke context, header request
+// (supaya ikut diteruskan ke service lain) dan header response, lalu
+// mencatat satu log per request. trustHeader false berarti request ID
+// selalu dibuat baru (dipakai gateway), true berarti request ID dari
+// header dipakai kalau valid.
+func Middleware(trustHeader bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(HeaderRequestID)
+			if !trustHeader || !ValidRequestID(id) {
+				id = NewRequestID()
+			}
+			req.Header.Set(HeaderRequestID, id)
+			c.Response().Header().Set(HeaderRequestID, id)
+			ctx := WithRequestID(req.Context(), id)
+			c.SetRequest(req.WithContext(ctx))
+
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			res := c.Response()
+			level := slog.LevelInfo
+			if res.Status >= 500 {
+				level = slog.LevelError
+			}
+			slog.Log(ctx, level, "http request",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"route", c.Path(),
+				"status", res.Status,
+				"bytes_out", res.Size,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"remote_ip", c.RealIP(),
+			)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor server unary: request ID dari metadata (dibuat baru kalau
+// tidak ada) ke context, lalu satu log per call
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = incomingRequestID(ctx)
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// Interceptor server stream, sama dengan UnaryServerInterceptor
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := incomingRequestID(ss.Context())
+	start := time.Now()
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// Interceptor client unary: request ID dari context dikirim sebagai
+// metadata kalau pemanggil belum mengisinya
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// Interceptor client stream, sama dengan UnaryClientInterceptor
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+}
+
+func incomingRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataRequestID); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !ValidRequestID(id) {
+		id = NewRequestID()
+	}
+	return WithRequestID(ctx, id)
+}
+
+func outgoingRequestID(ctx context.Context) context.Context {
+	id := RequestID(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(MetadataRequestID)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataRequestID, id)
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		switch code {
+		case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+			level = slog.LevelError
+		}
+	}
+	attrs := []any{"method", method, "code", code.String(), "duration_ms", time.Since(start).Milliseconds()}
+	if err != nil {
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+	slog.Log(ctx, level, "grpc call", attrs...)
+}
+
+// ServerStream dengan context yang sudah berisi request ID
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
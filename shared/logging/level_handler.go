@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// body GET/PUT level log
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler melayani GET (level aktif) dan PUT {"level":"debug"}
+// untuk mengubah level log tanpa restart
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body levelBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Level == "" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"message": "level is required"})
+				return
+			}
+			before := Level()
+			if err := SetLevel(body.Level); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"message": "level must be debug, info, warn or error"})
+				return
+			}
+			slog.InfoContext(r.Context(), "log level changed", "from", before, "to", Level())
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, levelBody{Level: Level()})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,71 @@
+// Package logging menyiapkan log terstruktur (JSON, log/slog) untuk service.
+// Setiap baris log berisi nama service dan request ID dari context kalau
+// ada, level log bisa diubah saat runtime lewat LevelHandler.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level aktif, dipakai bersama semua logger
+var level = new(slog.LevelVar)
+
+// Setup mengganti logger default slog dengan JSON ke stdout. Log dari
+// package log standar ikut diteruskan ke logger ini di level info.
+// levelName kosong berarti info, level tidak dikenal juga jatuh ke info.
+func Setup(service, levelName string) {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(contextHandler{handler}).With("service", service))
+
+	if err := SetLevel(levelName); err != nil {
+		slog.Warn("invalid LOG_LEVEL, using info", "level", levelName)
+	}
+}
+
+// Level aktif dalam huruf kecil (debug, info, warn, error)
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// Ubah level aktif. name kosong berarti info.
+func SetLevel(name string) error {
+	if name == "" {
+		level.Set(slog.LevelInfo)
+		return nil
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// Fatal mencatat error lalu keluar, pengganti log.Fatalf
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// contextHandler menambahkan request ID dari context ke setiap record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestContextHandler_AddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(contextHandler{slog.NewJSONHandler(&buf, nil)}).With("service", "test")
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "hello")
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "test", line["service"])
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, ValidRequestID("0b6e4b1c-9f2a:retry.1"))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID("bad id\n"))
+	assert.False(t, ValidRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+	assert.Len(t, NewRequestID(), 32)
+}
+
+func TestUnaryServerInterceptor_RequestIDFromMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataRequestID, "req-1"))
+
+	var got string
+	_, err := UnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Call"}, func(ctx context.Context, req any) (any, error) {
+		got = RequestID(ctx)
+		return nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", got)
+}
+
+func TestUnaryServerInterceptor_GeneratesRequestID(t *testing.T) {
+	var got string
+	UnaryServerInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Call"}, func(ctx context.Context, req any) (any, error) {
+		got = RequestID(ctx)
+		return nil, nil
+	})
+
+	assert.True(t, ValidRequestID(got))
+}
+
+func TestOutgoingRequestID_KeepsExistingMetadata(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "from-ctx")
+	md, _ := metadata.FromOutgoingContext(outgoingRequestID(ctx))
+	assert.Equal(t, []string{"from-ctx"}, md.Get(MetadataRequestID))
+
+	ctx = metadata.AppendToOutgoingContext(ctx, MetadataRequestID, "explicit")
+	md, _ = metadata.FromOutgoingContext(outgoingRequestID(ctx))
+	assert.Equal(t, []string{"explicit"}, md.Get(MetadataRequestID))
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLevel("info")
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+	assert.Equal(t, "debug", Level())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"loud"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "debug", Level())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log-level", nil))
+	assert.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+}
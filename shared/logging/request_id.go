@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Request ID dibuat di gateway lalu diteruskan lewat header HTTP dan
+// metadata gRPC
+const (
+	HeaderRequestID   = "X-Request-ID"
+	MetadataRequestID = "x-request-id"
+)
+
+// panjang maksimal request ID yang diterima dari service lain
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// Simpan request ID di context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Request ID dari context, kosong kalau tidak ada
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Buat request ID baru (32 karakter hex)
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Request ID dari luar hanya dipakai kalau pendek dan berisi huruf, angka,
+// "-", "_", "." atau ":" supaya aman ditulis ke log dan header
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
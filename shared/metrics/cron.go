@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	jobDuration = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cron_job_last_duration_seconds",
+		Help: "Durasi run terakhir cron job.",
+	}, []string{"job"})
+
+	jobSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cron_job_last_success",
+		Help: "1 kalau run terakhir cron job berhasil, 0 kalau gagal.",
+	}, []string{"job"})
+
+	jobLastRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cron_job_last_run_timestamp_seconds",
+		Help: "Waktu (unix) run terakhir cron job selesai.",
+	}, []string{"job"})
+
+	jobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cron_job_runs_total",
+		Help: "Jumlah run cron job per hasil (success, failure).",
+	}, []string{"job", "result"})
+)
+
+// ObserveJob mencatat hasil satu run cron job yang mulai di start
+func ObserveJob(job string, start time.Time, err error) {
+	now := time.Now()
+	jobDuration.WithLabelValues(job).Set(now.Sub(start).Seconds())
+	jobLastRun.WithLabelValues(job).Set(float64(now.Unix()))
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+		jobSuccess.WithLabelValues(job).Set(0)
+	} else {
+		jobSuccess.WithLabelValues(job).Set(1)
+	}
+	jobRuns.WithLabelValues(job, result).Inc()
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Jumlah call gRPC yang selesai per method dan status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Durasi call gRPC per method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// Interceptor server unary untuk metric rate, error dan durasi per method
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+// Interceptor server stream, durasi dihitung sampai stream selesai
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeCall(info.FullMethod, start, err)
+	return err
+}
+
+func observeCall(method string, start time.Time, err error) {
+	grpcHandled.WithLabelValues(method, status.Code(err).String()).Inc()
+	grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,14 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler endpoint /metrics format Prometheus. Registry default sudah
+// berisi metric runtime Go dan proses; metric lain didaftarkan lewat
+// promauto di package masing-masing.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
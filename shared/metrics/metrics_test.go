@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/event"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestObserveJob(t *testing.T) {
+	ObserveJob("test_job", time.Now().Add(-2*time.Second), nil)
+	assert.Equal(t, float64(1), testutil.ToFloat64(jobSuccess.WithLabelValues("test_job")))
+	assert.GreaterOrEqual(t, testutil.ToFloat64(jobDuration.WithLabelValues("test_job")), float64(2))
+
+	ObserveJob("test_job", time.Now(), errors.New("boom"))
+	assert.Equal(t, float64(0), testutil.ToFloat64(jobSuccess.WithLabelValues("test_job")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(jobRuns.WithLabelValues("test_job", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(jobRuns.WithLabelValues("test_job", "failure")))
+}
+
+func TestMongoPoolMonitor(t *testing.T) {
+	monitor := MongoPoolMonitor()
+	address := "mongo-test:27017"
+
+	for _, typ := range []string{event.ConnectionCreated, event.ConnectionCreated, event.GetSucceeded, event.GetSucceeded, event.ConnectionReturned, event.ConnectionClosed} {
+		monitor.Event(&event.PoolEvent{Type: typ, Address: address})
+	}
+	monitor.Event(&event.PoolEvent{Type: event.GetFailed, Address: address, Reason: event.ReasonTimedOut})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(mongoOpen.WithLabelValues(address)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(mongoInUse.WithLabelValues(address)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(mongoCheckoutFailures.WithLabelValues(address, event.ReasonTimedOut)))
+}
+
+func TestUnaryServerInterceptor_CountsCode(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Fail"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}
+
+	_, err := UnaryServerInterceptor(context.Background(), nil, info, handler)
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, float64(1), testutil.ToFloat64(grpcHandled.WithLabelValues(info.FullMethod, codes.NotFound.String())))
+}
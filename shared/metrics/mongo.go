@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var (
+	mongoOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mongo_pool_open_connections",
+		Help: "Jumlah koneksi terbuka di pool MongoDB per server.",
+	}, []string{"address"})
+
+	mongoInUse = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mongo_pool_in_use_connections",
+		Help: "Jumlah koneksi pool MongoDB yang sedang dipakai per server.",
+	}, []string{"address"})
+
+	mongoCheckoutFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_pool_checkout_failures_total",
+		Help: "Jumlah gagal mengambil koneksi dari pool MongoDB per alasan.",
+	}, []string{"address", "reason"})
+)
+
+// MongoPoolMonitor mencatat statistik pool koneksi, dipasang lewat
+// options.Client().SetPoolMonitor
+func MongoPoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				mongoOpen.WithLabelValues(evt.Address).Inc()
+			case event.ConnectionClosed:
+				mongoOpen.WithLabelValues(evt.Address).Dec()
+			case event.GetSucceeded:
+				mongoInUse.WithLabelValues(evt.Address).Inc()
+			case event.ConnectionReturned:
+				mongoInUse.WithLabelValues(evt.Address).Dec()
+			case event.GetFailed:
+				mongoCheckoutFailures.WithLabelValues(evt.Address, evt.Reason).Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	upstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_upstream_requests_total",
+		Help: "Jumlah call gateway ke backend per status (HTTP status, gRPC code, atau error).",
+	}, []string{"backend", "status"})
+
+	upstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gateway_upstream_request_duration_seconds",
+		Help:    "Durasi call gateway ke backend.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"backend"})
+)
+
+// ObserveUpstream mencatat satu call ke backend yang mulai di start.
+// status "error" dipakai kalau backend tidak bisa dihubungi.
+func ObserveUpstream(backend, status string, start time.Time) {
+	upstreamRequests.WithLabelValues(backend, status).Inc()
+	upstreamDuration.WithLabelValues(backend).Observe(time.Since(start).Seconds())
+}
+
+// Interceptor client unary untuk metric upstream gRPC ke backend
+func UnaryClientInterceptor(backend string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		ObserveUpstream(backend, status.Code(err).String(), start)
+		return err
+	}
+}
+
+// Interceptor client stream, hanya membuka stream yang dihitung karena
+// stream (export, watch) bisa berjalan lama
+func StreamClientInterceptor(backend string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		ObserveUpstream(backend, status.Code(err).String(), start)
+		return stream, err
+	}
+}
@@ -0,0 +1,272 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Mata uang default untuk data lama yang belum punya currency
+const DefaultCurrency = "IDR"
+
+var (
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid money amount")
+	ErrInvalidWeights   = errors.New("allocation weights must be positive")
+)
+
+// Jumlah digit minor unit per ISO-4217. Currency yang tidak ada di sini
+// dianggap tidak valid.
+var exponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+// Money menyimpan nominal dalam minor unit (misalnya sen) sebagai integer
+// supaya penjumlahan tidak kehilangan presisi seperti float64.
+type Money struct {
+	MinorUnits int64  `bson:"minor_units" json:"minor_units"`
+	Currency   string `bson:"currency" json:"currency"`
+}
+
+func New(minorUnits int64, currency string) Money {
+	return Money{MinorUnits: minorUnits, Currency: currency}
+}
+
+// Cek kode currency dikenal
+func ValidCurrency(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+// Jumlah digit minor unit untuk currency, -1 kalau tidak dikenal
+func Exponent(currency string) int {
+	exp, ok := exponents[currency]
+	if !ok {
+		return -1
+	}
+	return exp
+}
+
+// Parse nominal major unit dari string desimal, misalnya "10.005".
+// Digit lebih dari exponent dibulatkan half away from zero.
+func Parse(amount, currency string) (Money, error) {
+	exp := Exponent(currency)
+	if exp < 0 {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	// pisahkan digit yang disimpan dan digit pertama yang dibuang
+	roundUp := false
+	if len(frac) > exp {
+		roundUp = frac[exp] >= '5'
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if roundUp {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{MinorUnits: minor, Currency: currency}, nil
+}
+
+// Konversi dari float major unit, hanya untuk data lama. Float diformat
+// ke representasi desimal terpendek dulu supaya 10.005 tidak jadi 10.00.
+func FromMajor(amount float64, currency string) (Money, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Money{}, ErrInvalidAmount
+	}
+	return Parse(strconv.FormatFloat(amount, 'f', -1, 64), currency)
+}
+
+// Cek currency valid
+func (m Money) Validate() error {
+	if !ValidCurrency(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, m.Currency)
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.MinorUnits == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.MinorUnits > 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{MinorUnits: m.MinorUnits + other.MinorUnits, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{MinorUnits: m.MinorUnits - other.MinorUnits, Currency: m.Currency}, nil
+}
+
+// Kalikan dengan quantity, hasil tetap exact
+func (m Money) Mul(quantity int64) Money {
+	return Money{MinorUnits: m.MinorUnits * quantity, Currency: m.Currency}
+}
+
+// Kalikan dengan rasio basis point (1/10000), misalnya tarif pajak 11% = 1100.
+// Dibulatkan half away from zero ke minor unit terdekat.
+func (m Money) MulBasisPoints(bp int64) Money {
+	return Money{MinorUnits: divRound(m.MinorUnits*bp, 10000), Currency: m.Currency}
+}
+
+// Bandingkan nominal: -1, 0 atau 1. Currency harus sama.
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.MinorUnits < other.MinorUnits:
+		return -1, nil
+	case m.MinorUnits > other.MinorUnits:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Bagi nominal sesuai bobot. Sisa pembulatan dibagikan satu minor unit
+// ke bagian pertama, sehingga jumlah semua bagian selalu sama dengan m.
+func (m Money) Allocate(weights ...int64) ([]Money, error) {
+	var total int64
+	for _, w := range weights {
+		if w <= 0 {
+			return nil, ErrInvalidWeights
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, ErrInvalidWeights
+	}
+
+	parts := make([]Money, len(weights))
+	remainder := m.MinorUnits
+	for i, w := range weights {
+		share := m.MinorUnits * w / total
+		parts[i] = Money{MinorUnits: share, Currency: m.Currency}
+		remainder -= share
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		parts[i].MinorUnits += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+// Nominal dalam major unit, hanya untuk tampilan dan laporan
+func (m Money) Major() float64 {
+	exp := Exponent(m.Currency)
+	if exp <= 0 {
+		return float64(m.MinorUnits)
+	}
+	return float64(m.MinorUnits) / math.Pow10(exp)
+}
+
+// Format "IDR 10000.50"
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// Nominal major unit sebagai string desimal tanpa currency, misalnya "10000.50"
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	if exp <= 0 {
+		return strconv.FormatInt(m.MinorUnits, 10)
+	}
+
+	sign := ""
+	units := m.MinorUnits
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	digits := fmt.Sprintf("%0*d", exp+1, units)
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Jumlahkan beberapa nominal dengan currency yang sama
+func Sum(currency string, items ...Money) (Money, error) {
+	total := Money{Currency: currency}
+	for _, item := range items {
+		var err error
+		if total, err = total.Add(item); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Pembagian integer dengan pembulatan half away from zero
+func divRound(n, d int64) int64 {
+	q, r := n/d, n%d
+	if 2*abs(r) >= abs(d) {
+		if (n < 0) != (d < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
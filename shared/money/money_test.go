@@ -0,0 +1,98 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Rounding(t *testing.T) {
+	cases := []struct {
+		in       string
+		currency string
+		minor    int64
+	}{
+		{"10", "IDR", 1000},
+		{"10.5", "IDR", 1050},
+		{"10.005", "IDR", 1001},
+		{"10.004", "IDR", 1000},
+		{"-10.005", "IDR", -1001},
+		{".5", "USD", 50},
+		{"1500.4", "JPY", 1500},
+		{"1500.5", "JPY", 1501},
+		{"1.2345", "KWD", 1235},
+	}
+
+	for _, tc := range cases {
+		m, err := Parse(tc.in, tc.currency)
+		assert.NoError(t, err, tc.in)
+		assert.Equal(t, tc.minor, m.MinorUnits, tc.in)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse("10", "XXX")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+
+	_, err = Parse("1e3", "IDR")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("abc", "IDR")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestFromMajor(t *testing.T) {
+	// 1.005 di float sebenarnya 1.00499999..., tapi representasi terpendeknya "1.005"
+	m, err := FromMajor(1.005, "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), m.MinorUnits)
+
+	m, err = FromMajor(0.1+0.2, "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), m.MinorUnits)
+}
+
+func TestAddSubCurrencyMismatch(t *testing.T) {
+	a := New(1000, "IDR")
+
+	sum, err := a.Add(New(250, "IDR"))
+	assert.NoError(t, err)
+	assert.Equal(t, New(1250, "IDR"), sum)
+
+	diff, err := a.Sub(New(250, "IDR"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(750), diff.MinorUnits)
+
+	_, err = a.Add(New(1, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMulBasisPoints(t *testing.T) {
+	// 11% dari 10.05 = 1.1055 -> 1.11
+	assert.Equal(t, int64(111), New(1005, "IDR").MulBasisPoints(1100).MinorUnits)
+	// 11% dari 10.00 = 1.10
+	assert.Equal(t, int64(110), New(1000, "IDR").MulBasisPoints(1100).MinorUnits)
+	assert.Equal(t, int64(-111), New(-1005, "IDR").MulBasisPoints(1100).MinorUnits)
+}
+
+func TestAllocate_SumsToTotal(t *testing.T) {
+	parts, err := New(1000, "IDR").Allocate(1, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Money{New(334, "IDR"), New(333, "IDR"), New(333, "IDR")}, parts)
+
+	parts, err = New(-1001, "IDR").Allocate(1, 1)
+	assert.NoError(t, err)
+	total, _ := Sum("IDR", parts...)
+	assert.Equal(t, int64(-1001), total.MinorUnits)
+
+	_, err = New(1000, "IDR").Allocate(1, 0)
+	assert.ErrorIs(t, err, ErrInvalidWeights)
+}
+
+func TestStringAndMajor(t *testing.T) {
+	assert.Equal(t, "IDR 10.05", New(1005, "IDR").String())
+	assert.Equal(t, "IDR -0.05", New(-5, "IDR").String())
+	assert.Equal(t, "JPY 1500", New(1500, "JPY").String())
+	assert.Equal(t, "1.235", New(1235, "KWD").Decimal())
+	assert.Equal(t, 10.05, New(1005, "IDR").Major())
+}
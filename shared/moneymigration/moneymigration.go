@@ -0,0 +1,148 @@
+// Package moneymigration mengubah field nominal format lama (angka major
+// unit) menjadi money.
+package moneymigration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"shared/money"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Ukuran batch bulk write saat migrasi
+const batchSize = 500
+
+// Tipe BSON nominal format lama (sebelum money)
+var legacyNumberTypes = bson.A{"double", "int", "long", "decimal"}
+
+// Field nominal yang dimigrasi, daftarnya milik masing-masing service
+type Field struct {
+	Collection string
+	Field      string
+}
+
+// Migrasi semua field ke money dengan currency. Aman dipanggil setiap start
+// karena hanya dokumen yang field-nya masih berupa angka yang diubah.
+func Migrate(ctx context.Context, db *mongo.Database, fields []Field, currency string) error {
+	for _, f := range fields {
+		migrated, err := MigrateMoneyField(ctx, db.Collection(f.Collection), f.Field, currency)
+		if err != nil {
+			return fmt.Errorf("migrate %s.%s: %w", f.Collection, f.Field, err)
+		}
+		if migrated > 0 {
+			slog.InfoContext(ctx, "migrated values to money", "collection", f.Collection, "field", f.Field, "count", migrated)
+		}
+	}
+	return nil
+}
+
+// Ubah field angka major unit (float) jadi dokumen {minor_units, currency}.
+// Pembulatan memakai aturan money.Parse (half away from zero).
+func MigrateMoneyField(ctx context.Context, coll *mongo.Collection, field, currency string) (int, error) {
+	filter := bson.M{field: bson.M{"$type": legacyNumberTypes}}
+	opts := options.Find().SetProjection(bson.M{field: 1}).SetBatchSize(batchSize)
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	models := make([]mongo.WriteModel, 0, batchSize)
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		res, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+		migrated += int(res.ModifiedCount)
+		models = models[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return migrated, err
+		}
+
+		major, ok := legacyAmount(doc[field])
+		if !ok {
+			continue
+		}
+		value, err := money.Parse(major, currency)
+		if err != nil {
+			return migrated, fmt.Errorf("document %v: %w", doc["_id"], err)
+		}
+
+		// filter tipe diulang supaya dokumen yang sudah diubah proses lain tidak tertimpa
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc["_id"], field: bson.M{"$type": legacyNumberTypes}}).
+			SetUpdate(bson.M{"$set": bson.M{field: value}}))
+
+		if len(models) == batchSize {
+			if err := flush(); err != nil {
+				return migrated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return migrated, err
+	}
+
+	return migrated, flush()
+}
+
+// Nilai field angka lama sebagai string desimal. Float diformat ke
+// representasi terpendek supaya 10.005 tidak jadi 10.00499...
+func legacyAmount(v any) (string, bool) {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	case int32:
+		return strconv.FormatInt(int64(n), 10), true
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	case primitive.Decimal128:
+		return decimalString(n), true
+	}
+	return "", false
+}
+
+// Decimal128 sebagai string desimal tanpa eksponen. String() bisa
+// menghasilkan notasi eksponen (1E+3) yang tidak diterima money.Parse,
+// jadi dihitung dari koefisien dan eksponennya.
+func decimalString(d primitive.Decimal128) string {
+	coef, exp, err := d.BigInt()
+	if err != nil {
+		// NaN/Infinity, biar ditolak money.Parse beserta ID dokumennya
+		return d.String()
+	}
+	if exp >= 0 {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+		return coef.Mul(coef, scale).String()
+	}
+
+	digits := new(big.Int).Abs(coef).String()
+	places := -exp
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	s := digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+	if coef.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
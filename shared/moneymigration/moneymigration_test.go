@@ -0,0 +1,66 @@
+package moneymigration
+
+import (
+	"testing"
+
+	"shared/money"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLegacyAmount(t *testing.T) {
+	dec, _ := primitive.ParseDecimal128("10.005")
+	cases := []struct {
+		in    any
+		minor int64
+	}{
+		{10.005, 1001},
+		{int32(150), 15000},
+		{int64(7), 700},
+		{dec, 1001},
+	}
+
+	for _, tc := range cases {
+		major, ok := legacyAmount(tc.in)
+		assert.True(t, ok)
+		m, err := money.Parse(major, money.DefaultCurrency)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.minor, m.MinorUnits)
+	}
+
+	// sudah money atau bukan angka
+	_, ok := legacyAmount(map[string]any{"minor_units": 1})
+	assert.False(t, ok)
+	_, ok = legacyAmount("10")
+	assert.False(t, ok)
+}
+
+func TestLegacyAmount_Decimal128ExponentForm(t *testing.T) {
+	cases := []struct {
+		in    string
+		major string
+		minor int64
+	}{
+		{in: "1E+3", major: "1000", minor: 100000},
+		{in: "1.50E+2", major: "150", minor: 15000},
+		{in: "12345E-2", major: "123.45", minor: 12345},
+		{in: "5E-3", major: "0.005", minor: 1},
+		{in: "-2.5", major: "-2.5", minor: -250},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			dec, err := primitive.ParseDecimal128(tc.in)
+			if !assert.NoError(t, err) {
+				return
+			}
+			major, ok := legacyAmount(dec)
+			assert.True(t, ok)
+			assert.Equal(t, tc.major, major)
+			m, err := money.Parse(major, money.DefaultCurrency)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.minor, m.MinorUnits)
+		})
+	}
+}
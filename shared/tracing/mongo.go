@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const mongoTracerName = "go.mongodb.org/mongo-driver"
+
+// MongoMonitor membuat span client untuk setiap command MongoDB, dipasang
+// lewat options.Client().SetMonitor. Span jadi child dari span di context
+// operasi (misalnya handler gRPC atau HTTP).
+func MongoMonitor() *event.CommandMonitor {
+	var spans sync.Map // request ID command -> trace.Span
+
+	end := func(requestID int64, failure string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if failure != "" {
+			span.RecordError(errors.New(failure))
+			span.SetStatus(codes.Error, failure)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			name := evt.CommandName
+			if collection != "" {
+				name = collection + "." + evt.CommandName
+			}
+			_, span := otel.Tracer(mongoTracerName).Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBNamespace(evt.DatabaseName),
+					semconv.DBOperationName(evt.CommandName),
+					attribute.String("db.collection.name", collection),
+				),
+			)
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			end(evt.RequestID, "")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			end(evt.RequestID, evt.Failure)
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// pilihan exporter trace (env OTEL_TRACES_EXPORTER)
+const (
+	ExporterNone   = "none"   // span tidak diekspor, trace context tetap diteruskan
+	ExporterStdout = "stdout" // satu JSON per span ke stdout, untuk coba tanpa collector
+	ExporterOTLP   = "otlp"   // OTLP gRPC, endpoint dari OTEL_EXPORTER_OTLP_ENDPOINT
+)
+
+// Setup memasang TracerProvider global dengan exporter terpilih dan
+// propagator W3C (traceparent + baggage). Fungsi yang dikembalikan
+// mengirim span tersisa, dipanggil sebelum service berhenti.
+func Setup(ctx context.Context, service, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout, "console":
+		spanExporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		spanExporter, err = otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(service),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// provider global dengan recorder, dikembalikan setelah test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "test", "jaeger")
+	assert.Error(t, err)
+}
+
+func TestSetup_NoneKeepsPropagation(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "test", ExporterNone)
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	fields := otel.GetTextMapPropagator().Fields()
+	assert.Contains(t, fields, "traceparent")
+	assert.Contains(t, fields, "baggage")
+}
+
+func TestMongoMonitor_SpanPerCommand(t *testing.T) {
+	recorder := recordSpans(t)
+	monitor := MongoMonitor()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	command, _ := bson.Marshal(bson.D{{Key: "find", Value: "payments"}})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "payment_db", CommandName: "find", RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "payment_db", CommandName: "find", RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 2}, Failure: "timeout"})
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "payments.find", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "timeout", spans[1].Status().Description)
+}
+
+func TestMongoMonitor_UnknownRequestIgnored(t *testing.T) {
+	recorder := recordSpans(t)
+
+	MongoMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 99}})
+
+	assert.Empty(t, recorder.Ended())
+}
@@ -1,12 +1,24 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"os"
 	"shopping-service/config"
 
+	"shared/audit"
+	"shared/events"
+	"shared/health"
+	"shared/lifecycle"
+	"shared/logging"
+	"shared/metrics"
+	"shared/tracing"
+	"shopping-service/internal/migration"
+	"shopping-service/internal/pricing"
 	"shopping-service/internal/shopping/app"
 	"shopping-service/internal/shopping/delivery/http"
 	"shopping-service/internal/shopping/infra"
+	"shopping-service/proto"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -22,35 +34,167 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
-	// load env & koneksi MongoDB
-	config.LoadEnv()
-	config.ConnectDB()
+	// config dari flag, env dan .env (opsional), semua error dilaporkan sekaligus
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logging.Fatal("failed to load config", "error", err)
+	}
+	logging.Setup("shopping-service", cfg.LogLevel)
+	slog.Info("config loaded", "config", cfg)
+
+	// tracing OpenTelemetry, exporter none/stdout/otlp
+	shutdownTracing, err := tracing.Setup(context.Background(), "shopping-service", cfg.TracesExporter)
+	if err != nil {
+		logging.Fatal("failed to setup tracing", "error", err)
+	}
+
+	// koneksi MongoDB
+	db, err := config.ConnectDB(context.Background(), cfg)
+	if err != nil {
+		logging.Fatal("failed to connect to MongoDB", "error", err)
+	}
+
+	// migrasi price/total float lama ke money (minor unit + currency) dan status transaksi lama
+	if err := migration.Run(context.Background(), db); err != nil {
+		logging.Fatal("money migration failed", "error", err)
+	}
 
 	// init Echo
 	e := echo.New()
-	e.Use(middleware.Logger())
+	e.HideBanner = true
+	e.HidePort = true
+	// span per request dengan parent dari traceparent gateway
+	e.Use(tracing.Middleware())
+	// request ID dari gateway (atau baru) dan log JSON per request
+	e.Use(logging.Middleware(true))
+	// metric rate, error dan durasi per route
+	e.Use(metrics.Middleware())
 	e.Use(middleware.Recover())
 
 	// swagger
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	// init product
-	productRepo := infra.NewProductRepo()
-	productService := app.NewProductService(productRepo)
+	// Prometheus
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	// broker event domain (memory atau nats)
+	broker, err := events.Open(cfg.EventBroker, cfg.NATSOptions())
+	if err != nil {
+		logging.Fatal("failed to open event broker", "error", err)
+	}
+
+	// koneksi gRPC Payment Service untuk bayar, refund dan list payment
+	paymentConn, err := config.DialPayment(cfg)
+	if err != nil {
+		logging.Fatal("failed to connect payment service", "error", err)
+	}
+
+	// health: /healthz proses hidup, /readyz Mongo, broker dan Payment
+	// Service (grpc.health.v1) siap
+	checker := health.NewChecker()
+	checker.Add("mongo", health.MongoCheck(db.Client()))
+	checker.Add("broker", func(ctx context.Context) error { return broker.Ping() })
+	checker.Add("payment", health.GRPCCheck(paymentConn))
+	e.GET("/healthz", echo.WrapHandler(health.LiveHandler()))
+	e.GET("/readyz", echo.WrapHandler(health.ReadyHandler(checker)))
+
+	// audit log perubahan product dan transaksi
+	auditLog := audit.NewMongoLog(db)
+
+	// init product & inventory
+	productRepo := infra.NewProductRepo(db)
+	inventoryService := app.NewInventoryService(infra.NewInventoryRepo(db), productRepo, broker, cfg.ReservationTTL)
+	http.InventoryRoute(e, http.NewInventoryHandler(inventoryService))
+	categoryService := app.NewCategoryService(infra.NewCategoryRepo(db))
+	http.CategoryRoute(e, http.NewCategoryHandler(categoryService))
+	productService := app.NewProductService(productRepo, categoryService, inventoryService, auditLog)
 	productHandler := http.NewProductHandler(productService)
 	http.ProductRoute(e, productHandler)
+	productImportService := app.NewProductImportService(infra.NewImportJobRepo(db), productService)
+	// job import yang terputus karena proses sebelumnya mati
+	if err := productImportService.FailInterrupted(); err != nil {
+		logging.Fatal("failed to mark interrupted import jobs", "error", err)
+	}
+	http.ProductImportRoute(e, http.NewProductImportHandler(productImportService, productService))
+
+	// aturan pajak & ongkir dari file config, bisa diubah tanpa ubah kode
+	pricingRules, err := pricing.LoadRules(cfg.PricingConfig)
+	if err != nil {
+		logging.Fatal("failed to load pricing rules", "error", err)
+	}
+	pricingPipeline, err := pricingRules.Build()
+	if err != nil {
+		logging.Fatal("failed to build pricing pipeline", "error", err)
+	}
+	slog.Info("pricing pipeline loaded", "calculators", pricingPipeline.Calculators())
+
+	// init promo
+	promotionService := app.NewPromotionService(infra.NewPromotionRepo(db))
+	http.PromotionRoute(e, http.NewPromotionHandler(promotionService))
+
+	// client Payment Service untuk bayar, refund dan list payment
+	paymentClient := app.NewPaymentClient(proto.NewPaymentServiceClient(paymentConn))
 
 	// init transaction
-	transactionRepo := infra.NewTransactionRepo()
-	transactionService := app.NewTransactionService(transactionRepo)
+	transactionRepo := infra.NewTransactionRepo(db)
+	transactionService := app.NewTransactionService(transactionRepo, productRepo, inventoryService, promotionService, pricingPipeline, broker, auditLog, paymentClient)
 	transactionHandler := http.NewTransactionHandler(&transactionService)
 	http.TransactionRoute(e, transactionHandler)
 
+	// init retur, refund lewat Payment Service
+	returnService := app.NewReturnService(infra.NewReturnRepo(db), transactionRepo, transactionService, inventoryService, paymentClient)
+	http.ReturnRoute(e, http.NewReturnHandler(returnService))
+
+	// endpoint admin: data yang dihapus, restore dan audit log
+	http.AdminRoute(e, http.NewAdminHandler(productService, transactionService, auditLog))
+
+	// init cart & order
+	cartRepo := infra.NewCartRepo(db)
+	cartService := app.NewCartService(cartRepo, productRepo)
+	orderRepo := infra.NewOrderRepo(db)
+	orderService := app.NewOrderService(orderRepo, cartRepo, productRepo, inventoryService, promotionService, pricingPipeline, broker, paymentClient)
+	orderHandler := http.NewOrderHandler(orderService)
+	http.CartRoute(e, http.NewCartHandler(cartService), orderHandler)
+	http.OrderRoute(e, orderHandler)
+
+	// invoice transaksi & order yang dibayar dan statement bulanan customer
+	invoiceService := app.NewInvoiceService(infra.NewInvoiceRepo(db), transactionRepo, orderRepo, productRepo, paymentClient)
+	http.InvoiceRoute(e, http.NewInvoiceHandler(invoiceService))
+	if err := app.SubscribeInvoiceEvents(broker, invoiceService); err != nil {
+		logging.Fatal("failed to subscribe invoice events", "error", err)
+	}
+
+	// rekonsiliasi transaksi & order dengan Payment Service
+	reconciliationService := app.NewReconciliationService(infra.NewReconciliationRepo(db), transactionRepo, orderRepo, broker, paymentClient)
+	http.ReconciliationRoute(e, http.NewReconciliationHandler(reconciliationService))
+
+	// laporan penjualan (aggregation transaksi & order)
+	http.AnalyticsRoute(e, http.NewAnalyticsHandler(app.NewAnalyticsService(infra.NewAnalyticsRepo(db))))
+
 	// Jalankan cron job transaksi
-	app.StartTransactionCron(transactionRepo)
+	transactionCron := app.StartTransactionCron(transactionRepo)
+	inventoryCron := app.StartInventoryCron(inventoryService)
+	reconciliationCron := app.StartReconciliationCron(reconciliationService)
 
 	// start server
-	port := config.GetEnv("PORT")
-	log.Println("running at port:", port)
-	e.Logger.Fatal(e.Start(":" + port))
+	lm := lifecycle.NewManager(cfg.ShutdownTimeout)
+	port := cfg.Port
+	lm.Go("http", func() error { return e.Start(":" + port) })
+	slog.Info("shopping service running", "port", port)
+
+	// urutan shutdown: selesaikan request yang berjalan, hentikan job
+	// import dan cron, tutup broker (flush publish NATS) dan koneksi payment,
+	// putus Mongo, lalu flush trace
+	lm.OnShutdown("http", e.Shutdown)
+	lm.OnShutdown("import", productImportService.Shutdown)
+	lm.OnShutdown("cron", lifecycle.StopCron(transactionCron, inventoryCron, reconciliationCron))
+	lm.OnShutdown("broker", func(ctx context.Context) error { return broker.Close() })
+	lm.OnShutdown("payment", func(ctx context.Context) error { return paymentConn.Close() })
+	lm.OnShutdown("mongo", lifecycle.CloseMongo(db.Client()))
+	lm.OnShutdown("tracing", shutdownTracing)
+
+	if err := lm.Wait(); err != nil {
+		logging.Fatal("shopping service stopped with error", "error", err)
+	}
+	slog.Info("shopping service stopped")
 }
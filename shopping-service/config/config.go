@@ -2,30 +2,123 @@ package config
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"os"
 	"time"
 
+	"shared/envconfig"
+	"shared/events"
+	"shared/lifecycle"
+	"shared/logging"
+	"shared/metrics"
+	"shared/tracing"
+	"shopping-service/internal/shopping/app"
+
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
-var DB *mongo.Database // Variabel global untuk akses database
+// Config shopping-service, dibaca sekali di main lalu di-inject ke constructor
+type Config struct {
+	Port string
+
+	MongoURI      envconfig.Secret // bisa berisi user/password
+	MongoDatabase string
+
+	// alamat gRPC Payment Service (bayar, refund, list payment)
+	PaymentGRPCAddr string
+
+	EventBroker   string
+	NATSURL       envconfig.Secret // bisa berisi user:password@ atau token@
+	NATSCredsFile string
+	NATSCAFile    string
+
+	PricingConfig  string
+	ReservationTTL time.Duration
+
+	LogLevel       string
+	TracesExporter string
 
-// ConnectDB digunakan untuk koneksi ke MongoDB
-func ConnectDB() {
-	// Membuat opsi koneksi dari MONGOURI (diambil dari .env)
-	clientOptions := options.Client().ApplyURI(GetEnv("MONGOURI"))
+	ShutdownTimeout time.Duration
+}
+
+// Load config dari flag (args tanpa nama program), env dan file .env
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
 
-	// Menentukan batas waktu koneksi 10 detik
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func load(args []string, env func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{}
+	l := envconfig.NewLoader("shopping-service")
+	l.Port(&cfg.Port, "PORT", "", "port HTTP").Required()
+	l.Secret(&cfg.MongoURI, "MONGOURI", "", "URI MongoDB").Required()
+	l.String(&cfg.MongoDatabase, "MONGODB_NAME", "", "nama database MongoDB").Required()
+	l.String(&cfg.PaymentGRPCAddr, "PAYMENT_SERVICE_GRPC_ADDR", "localhost:50051", "alamat gRPC payment-service")
+	l.OneOf(&cfg.EventBroker, "EVENT_BROKER", events.BrokerMemory, "broker event domain", events.BrokerMemory, events.BrokerNATS)
+	l.Secret(&cfg.NATSURL, "NATS_URL", "", "alamat NATS (tls:// untuk TLS), wajib kalau EVENT_BROKER=nats")
+	l.String(&cfg.NATSCredsFile, "NATS_CREDS_FILE", "", "file .creds NATS (JWT + nkey)")
+	l.String(&cfg.NATSCAFile, "NATS_CA_FILE", "", "CA tambahan untuk sertifikat server NATS")
+	l.String(&cfg.PricingConfig, "PRICING_CONFIG", "config/pricing.json", "file aturan pajak & ongkir")
+	l.Duration(&cfg.ReservationTTL, "RESERVATION_TTL", app.DefaultReservationTTL.String(), "lama reservasi stok checkout")
+	l.LogLevel(&cfg.LogLevel, "LOG_LEVEL", "info", "level log awal")
+	l.OneOf(&cfg.TracesExporter, "OTEL_TRACES_EXPORTER", "none", "exporter trace", tracing.ExporterNone, tracing.ExporterStdout, "console", tracing.ExporterOTLP)
+	l.Duration(&cfg.ShutdownTimeout, "SHUTDOWN_TIMEOUT", lifecycle.DefaultTimeout.String(), "batas waktu graceful shutdown")
+
+	err := l.Load(args, env)
+	if cfg.EventBroker == events.BrokerNATS && cfg.NATSURL == "" {
+		err = errors.Join(err, errors.New("NATS_URL is required when EVENT_BROKER=nats"))
+	}
+	if cfg.PricingConfig != "" {
+		if _, statErr := os.Stat(cfg.PricingConfig); statErr != nil {
+			err = errors.Join(err, fmt.Errorf("PRICING_CONFIG: %w", statErr))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NATSOptions koneksi broker NATS dari config
+func (c *Config) NATSOptions() events.NATSOptions {
+	return events.NATSOptions{
+		URL:       c.NATSURL.Value(),
+		Name:      "shopping-service",
+		CredsFile: c.NATSCredsFile,
+		CAFile:    c.NATSCAFile,
+	}
+}
+
+// DialPayment membuka koneksi gRPC ke Payment Service. Koneksi dibuat lazy,
+// request ID dan trace context dari ctx ikut ke metadata dan tiap call
+// tercatat di metric upstream.
+func DialPayment(cfg *Config) (*grpc.ClientConn, error) {
+	return grpc.NewClient(cfg.PaymentGRPCAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor, metrics.UnaryClientInterceptor("payment")),
+		grpc.WithChainStreamInterceptor(logging.StreamClientInterceptor, metrics.StreamClientInterceptor("payment")),
+	)
+}
+
+// ConnectDB membuka koneksi MongoDB dengan span per command dan statistik
+// pool untuk /metrics
+func ConnectDB(ctx context.Context, cfg *Config) (*mongo.Database, error) {
+	clientOptions := options.Client().ApplyURI(cfg.MongoURI.Value()).
+		SetMonitor(tracing.MongoMonitor()).
+		SetPoolMonitor(metrics.MongoPoolMonitor())
+
+	// batas waktu koneksi 10 detik
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	// Melakukan koneksi ke MongoDB
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal("Gagal konek ke MongoDB:", err)
+		return nil, err
 	}
-
-	// Mengatur database sesuai nama dari ENV
-	DB = client.Database(GetEnv("MONGODB_NAME"))
+	return client.Database(cfg.MongoDatabase), nil
 }
@@ -0,0 +1,35 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"shared/money"
+	"shared/moneymigration"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Field nominal yang dimigrasi di service ini
+var moneyFields = []moneymigration.Field{
+	{Collection: "products", Field: "price"},
+	{Collection: "transactions", Field: "total"},
+}
+
+// Jalankan semua migrasi money dan status transaksi. Aman dipanggil setiap
+// start karena hanya dokumen yang masih format lama yang diubah.
+func Run(ctx context.Context, db *mongo.Database) error {
+	if err := moneymigration.Migrate(ctx, db, moneyFields, money.DefaultCurrency); err != nil {
+		return err
+	}
+
+	migrated, err := MigrateTransactionStatus(ctx, db.Collection("transactions"))
+	if err != nil {
+		return fmt.Errorf("migrate transactions.status: %w", err)
+	}
+	if migrated > 0 {
+		slog.InfoContext(ctx, "migrated transaction status success to paid", "count", migrated)
+	}
+	return nil
+}
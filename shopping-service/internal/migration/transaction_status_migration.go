@@ -0,0 +1,21 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Status transaksi lama "success" jadi "paid" sesuai workflow status.
+// Aman dipanggil setiap start.
+func MigrateTransactionStatus(ctx context.Context, coll *mongo.Collection) (int64, error) {
+	result, err := coll.UpdateMany(ctx,
+		bson.M{"status": "success"},
+		bson.M{"$set": bson.M{"status": "paid"}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
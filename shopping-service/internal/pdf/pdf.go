@@ -0,0 +1,116 @@
+// Package pdf menulis dokumen PDF teks sederhana tanpa dependency luar.
+// Teks ditulis dengan font Courier (monospace) supaya kolom yang disusun
+// dari template teks tetap rata, halaman baru dibuat otomatis.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ukuran halaman A4 dan tata letak dalam point (1/72 inch)
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 50
+	fontSize     = 9
+	lineHeight   = 12
+	linesPerPage = (pageHeight - 2*margin) / lineHeight
+)
+
+// Document berisi baris teks yang akan dibagi ke beberapa halaman
+type Document struct {
+	lines []string
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// Tambah teks, dipecah per baris. Tab diganti spasi.
+func (d *Document) WriteText(text string) {
+	text = strings.ReplaceAll(strings.TrimRight(text, "\n"), "\t", "    ")
+	d.lines = append(d.lines, strings.Split(text, "\n")...)
+}
+
+// Tulis dokumen sebagai PDF 1.4
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	pages := d.pages()
+
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// 1 catalog, 2 pages, 3 font, lalu per halaman: page + content
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 5+i*2))
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) '\n", escape(line))
+		}
+		content.WriteString("ET")
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// bagi baris per halaman, dokumen kosong tetap punya satu halaman
+func (d *Document) pages() [][]string {
+	var pages [][]string
+	for start := 0; start < len(d.lines); start += linesPerPage {
+		end := min(start+linesPerPage, len(d.lines))
+		pages = append(pages, d.lines[start:end])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	return pages
+}
+
+// Escape string PDF. Karakter di luar Latin-1 diganti "?" karena font
+// standar hanya mendukung WinAnsiEncoding.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r > 255:
+			b.WriteByte('?')
+		case r > 126:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
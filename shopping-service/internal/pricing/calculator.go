@@ -0,0 +1,157 @@
+package pricing
+
+import (
+	"shared/money"
+)
+
+// Nama calculator yang bisa dipakai di config
+const (
+	CalculatorSubtotal = "subtotal"
+	CalculatorDiscount = "discount"
+	CalculatorTax      = "tax"
+	CalculatorShipping = "shipping"
+)
+
+// jumlah harga satuan * quantity semua baris
+type SubtotalCalculator struct{}
+
+func (SubtotalCalculator) Name() string { return CalculatorSubtotal }
+
+func (SubtotalCalculator) Calculate(q *Quote) error {
+	subtotal := money.New(0, q.Currency)
+	for _, line := range q.Lines {
+		var err error
+		if subtotal, err = subtotal.Add(line.UnitPrice.Mul(int64(line.Quantity))); err != nil {
+			return err
+		}
+	}
+	q.Breakdown.Subtotal = subtotal
+	return nil
+}
+
+// jumlah potongan per baris, tidak pernah melebihi subtotal
+type DiscountCalculator struct{}
+
+func (DiscountCalculator) Name() string { return CalculatorDiscount }
+
+func (DiscountCalculator) Calculate(q *Quote) error {
+	discount := money.New(0, q.Currency)
+	for _, line := range q.Lines {
+		if line.Discount.IsZero() {
+			continue
+		}
+		var err error
+		if discount, err = discount.Add(line.Discount); err != nil {
+			return err
+		}
+	}
+	if discount.MinorUnits > q.Breakdown.Subtotal.MinorUnits {
+		discount = q.Breakdown.Subtotal
+	}
+	q.Breakdown.Discount = discount
+	return nil
+}
+
+// Pajak per region dari tabel tarif. Dasar pengenaan pajak adalah subtotal
+// setelah discount ditambah shipping yang sudah dihitung, jadi shipping
+// ikut kena pajak hanya kalau calculator shipping dijalankan sebelum tax.
+type TaxCalculator struct {
+	DefaultRegion string
+	Rates         map[string]int64 // region -> basis point
+}
+
+func (TaxCalculator) Name() string { return CalculatorTax }
+
+func (c TaxCalculator) Calculate(q *Quote) error {
+	region := NormalizeRegion(q.Region)
+	if region == "" {
+		region = c.DefaultRegion
+	}
+	rate, ok := c.Rates[region]
+	if !ok {
+		return ErrUnknownRegion
+	}
+
+	base, err := q.Breakdown.Subtotal.Sub(q.Breakdown.Discount)
+	if err != nil {
+		return err
+	}
+	if base, err = base.Add(q.Breakdown.Shipping); err != nil {
+		return err
+	}
+
+	q.Breakdown.TaxRegion = region
+	q.Breakdown.TaxRate = rate
+	q.Breakdown.Tax = base.MulBasisPoints(rate)
+	return nil
+}
+
+// Jenis tarif shipping
+const (
+	ShippingFlat   = "flat"
+	ShippingWeight = "weight"
+)
+
+// Satu metode shipping. Flat memakai Amount, weight memakai Amount sebagai
+// biaya dasar ditambah PerKg untuk tiap kg (dibulatkan ke atas). FreeOver
+// membuat ongkir gratis kalau subtotal setelah discount mencapai nilainya.
+type ShippingRate struct {
+	Type     string      `json:"type"`
+	Amount   money.Money `json:"amount"`
+	PerKg    money.Money `json:"per_kg"`
+	FreeOver money.Money `json:"free_over"`
+}
+
+type ShippingCalculator struct {
+	DefaultMethod string
+	Methods       map[string]ShippingRate
+}
+
+func (ShippingCalculator) Name() string { return CalculatorShipping }
+
+func (c ShippingCalculator) Calculate(q *Quote) error {
+	method := NormalizeMethod(q.ShippingMethod)
+	if method == "" {
+		method = c.DefaultMethod
+	}
+	rate, ok := c.Methods[method]
+	if !ok {
+		return ErrUnknownShippingMethod
+	}
+
+	grams := 0
+	for _, line := range q.Lines {
+		grams += line.WeightGrams * line.Quantity
+	}
+	q.Breakdown.ShippingMethod = method
+	q.Breakdown.WeightGrams = grams
+
+	if !rate.FreeOver.IsZero() {
+		net, err := q.Breakdown.Subtotal.Sub(q.Breakdown.Discount)
+		if err != nil {
+			return err
+		}
+		cmp, err := net.Cmp(rate.FreeOver)
+		if err != nil {
+			return err
+		}
+		if cmp >= 0 {
+			q.Breakdown.Shipping = money.New(0, q.Currency)
+			return nil
+		}
+	}
+
+	shipping := rate.Amount
+	if rate.Type == ShippingWeight {
+		kg := int64((grams + 999) / 1000)
+		var err error
+		if shipping, err = shipping.Add(rate.PerKg.Mul(kg)); err != nil {
+			return err
+		}
+	}
+	if shipping.Currency != q.Currency {
+		return money.ErrCurrencyMismatch
+	}
+	q.Breakdown.Shipping = shipping
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package pricing menghitung rincian harga transaksi lewat pipeline
+// calculator yang berurutan: subtotal, discount, tax dan shipping. Urutan
+// dan aturan tiap calculator dibaca dari file config (lihat LoadRules).
+package pricing
+
+import (
+	"errors"
+	"fmt"
+
+	"shared/money"
+)
+
+var (
+	ErrEmptyQuote            = errors.New("pricing: quote has no lines")
+	ErrUnknownRegion         = errors.New("pricing: unknown tax region")
+	ErrUnknownShippingMethod = errors.New("pricing: unknown shipping method")
+	ErrUnknownCalculator     = errors.New("pricing: unknown calculator")
+	ErrInvalidRules          = errors.New("pricing: invalid rules")
+)
+
+// satu baris belanja yang dihargai
+type Line struct {
+	ProductID   string
+	UnitPrice   money.Money
+	Quantity    int
+	WeightGrams int         // berat per unit
+	Discount    money.Money // potongan promo untuk baris ini, boleh kosong
+}
+
+// Rincian harga yang disimpan di transaksi/order.
+// Total = Subtotal - Discount + Tax + Shipping.
+type Breakdown struct {
+	Subtotal money.Money `bson:"subtotal" json:"subtotal"`
+	Discount money.Money `bson:"discount" json:"discount"`
+	Tax      money.Money `bson:"tax" json:"tax"`
+	Shipping money.Money `bson:"shipping" json:"shipping"`
+	Total    money.Money `bson:"total" json:"total"`
+
+	TaxRegion      string `bson:"tax_region,omitempty" json:"tax_region,omitempty"`
+	TaxRate        int64  `bson:"tax_rate" json:"tax_rate"` // basis point, 1100 = 11%
+	ShippingMethod string `bson:"shipping_method,omitempty" json:"shipping_method,omitempty"`
+	WeightGrams    int    `bson:"weight_grams" json:"weight_grams"`
+}
+
+// input pipeline, Breakdown diisi bertahap oleh calculator
+type Quote struct {
+	Currency       string
+	Region         string // kosong berarti region default
+	ShippingMethod string // kosong berarti method default
+	Lines          []Line
+	Breakdown      Breakdown
+}
+
+// Calculator mengisi satu komponen Breakdown. Calculator boleh membaca
+// komponen yang sudah diisi calculator sebelumnya.
+type Calculator interface {
+	Name() string
+	Calculate(q *Quote) error
+}
+
+type Pipeline struct {
+	calculators []Calculator
+}
+
+func NewPipeline(calculators ...Calculator) *Pipeline {
+	return &Pipeline{calculators: calculators}
+}
+
+// nama calculator sesuai urutan eksekusi
+func (p *Pipeline) Calculators() []string {
+	names := make([]string, 0, len(p.calculators))
+	for _, c := range p.calculators {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Price menjalankan semua calculator lalu menghitung total
+func (p *Pipeline) Price(q Quote) (Breakdown, error) {
+	if len(q.Lines) == 0 {
+		return Breakdown{}, ErrEmptyQuote
+	}
+	if q.Currency == "" {
+		q.Currency = q.Lines[0].UnitPrice.Currency
+	}
+
+	zero := money.New(0, q.Currency)
+	q.Breakdown = Breakdown{Subtotal: zero, Discount: zero, Tax: zero, Shipping: zero}
+
+	for _, c := range p.calculators {
+		if err := c.Calculate(&q); err != nil {
+			return Breakdown{}, fmt.Errorf("%s: %w", c.Name(), err)
+		}
+	}
+
+	b := q.Breakdown
+	total, err := b.Subtotal.Sub(b.Discount)
+	if err == nil {
+		total, err = total.Add(b.Tax)
+	}
+	if err == nil {
+		total, err = total.Add(b.Shipping)
+	}
+	if err != nil {
+		return Breakdown{}, err
+	}
+	b.Total = total
+	return b, nil
+}
@@ -0,0 +1,118 @@
+package pricing
+
+import (
+	"testing"
+
+	"shared/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func idr(minor int64) money.Money {
+	return money.New(minor, "IDR")
+}
+
+// rules sama dengan config/pricing.json, order kosong berarti DefaultOrder
+func newTestPipeline(t *testing.T, order ...string) *Pipeline {
+	rules := &Rules{Order: order}
+	rules.Tax.DefaultRegion = "id"
+	rules.Tax.Rates = map[string]int64{"ID": 1100, "sg": 900}
+	rules.Shipping.DefaultMethod = "FLAT"
+	rules.Shipping.Methods = map[string]ShippingRate{
+		"flat":   {Type: ShippingFlat, Amount: idr(1500000), FreeOver: idr(50000000)},
+		"weight": {Type: ShippingWeight, Amount: idr(500000), PerKg: idr(800000)},
+		"pickup": {Type: ShippingFlat},
+	}
+	if err := rules.validate(); err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := rules.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pipeline
+}
+
+func TestPipeline_Price(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		quote Quote
+		want  Breakdown
+	}{
+		{
+			name:  "region dan method default",
+			quote: Quote{Lines: []Line{{UnitPrice: idr(10000000), Quantity: 2}}},
+			want: Breakdown{Subtotal: idr(20000000), Discount: idr(0), Tax: idr(2200000), Shipping: idr(1500000), Total: idr(23700000),
+				TaxRegion: "ID", TaxRate: 1100, ShippingMethod: "flat"},
+		},
+		{
+			name:  "discount mengurangi dasar pajak dan batas gratis ongkir",
+			quote: Quote{Lines: []Line{{UnitPrice: idr(30000000), Quantity: 2, Discount: idr(15000000)}}},
+			want: Breakdown{Subtotal: idr(60000000), Discount: idr(15000000), Tax: idr(4950000), Shipping: idr(1500000), Total: idr(51450000),
+				TaxRegion: "ID", TaxRate: 1100, ShippingMethod: "flat"},
+		},
+		{
+			name:  "gratis ongkir dihitung setelah discount",
+			quote: Quote{Lines: []Line{{UnitPrice: idr(30000000), Quantity: 2, Discount: idr(5000000)}}},
+			want: Breakdown{Subtotal: idr(60000000), Discount: idr(5000000), Tax: idr(6050000), Shipping: idr(0), Total: idr(61050000),
+				TaxRegion: "ID", TaxRate: 1100, ShippingMethod: "flat"},
+		},
+		{
+			name:  "berat dibulatkan ke atas per kg, kode tidak case sensitive",
+			quote: Quote{Region: "sg", ShippingMethod: " Weight ", Lines: []Line{{UnitPrice: idr(10000000), Quantity: 3, WeightGrams: 700}}},
+			want: Breakdown{Subtotal: idr(30000000), Discount: idr(0), Tax: idr(2700000), Shipping: idr(2900000), Total: idr(35600000),
+				TaxRegion: "SG", TaxRate: 900, ShippingMethod: "weight", WeightGrams: 2100},
+		},
+		{
+			name: "discount beberapa baris tidak melebihi subtotal",
+			quote: Quote{ShippingMethod: "pickup", Lines: []Line{
+				{UnitPrice: idr(1000000), Quantity: 1, Discount: idr(800000)},
+				{UnitPrice: idr(500000), Quantity: 1, Discount: idr(900000)},
+			}},
+			want: Breakdown{Subtotal: idr(1500000), Discount: idr(1500000), Tax: idr(0), Shipping: idr(0), Total: idr(0),
+				TaxRegion: "ID", TaxRate: 1100, ShippingMethod: "pickup"},
+		},
+		{
+			name:  "shipping sebelum tax ikut kena pajak",
+			order: []string{CalculatorSubtotal, CalculatorDiscount, CalculatorShipping, CalculatorTax},
+			quote: Quote{Lines: []Line{{UnitPrice: idr(10000000), Quantity: 2}}},
+			want: Breakdown{Subtotal: idr(20000000), Discount: idr(0), Tax: idr(2365000), Shipping: idr(1500000), Total: idr(23865000),
+				TaxRegion: "ID", TaxRate: 1100, ShippingMethod: "flat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestPipeline(t, tt.order...).Price(tt.quote)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPipeline_PriceErrors(t *testing.T) {
+	line := Line{UnitPrice: idr(10000000), Quantity: 1}
+	tests := []struct {
+		name  string
+		quote Quote
+		want  error
+	}{
+		{name: "tanpa baris", quote: Quote{}, want: ErrEmptyQuote},
+		{name: "region tidak dikenal", quote: Quote{Region: "JP", Lines: []Line{line}}, want: ErrUnknownRegion},
+		{name: "method tidak dikenal", quote: Quote{ShippingMethod: "drone", Lines: []Line{line}}, want: ErrUnknownShippingMethod},
+		{name: "ongkir beda currency", quote: Quote{Lines: []Line{{UnitPrice: money.New(1000, "USD"), Quantity: 1}}}, want: money.ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestPipeline(t).Price(tt.quote)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestRulesValidate_UnknownCalculator(t *testing.T) {
+	rules := &Rules{Order: []string{CalculatorSubtotal, "cashback"}}
+	assert.ErrorIs(t, rules.validate(), ErrUnknownCalculator)
+}
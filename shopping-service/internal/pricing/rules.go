@@ -0,0 +1,128 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"shared/money"
+)
+
+// urutan default kalau config tidak menyebut pipeline
+var DefaultOrder = []string{CalculatorSubtotal, CalculatorDiscount, CalculatorTax, CalculatorShipping}
+
+// Format file config pricing (JSON), contoh di config/pricing.json
+type Rules struct {
+	Order []string `json:"pipeline"`
+	Tax   struct {
+		DefaultRegion string           `json:"default_region"`
+		Rates         map[string]int64 `json:"rates"` // basis point
+	} `json:"tax"`
+	Shipping struct {
+		DefaultMethod string                  `json:"default_method"`
+		Methods       map[string]ShippingRate `json:"methods"`
+	} `json:"shipping"`
+}
+
+// baca dan validasi rules dari file
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (r *Rules) validate() error {
+	// kode region dan method tidak case sensitive, disimpan uppercase/lowercase
+	rates := make(map[string]int64, len(r.Tax.Rates))
+	for region, rate := range r.Tax.Rates {
+		if rate < 0 || rate > 10000 {
+			return fmt.Errorf("%w: tax rate %s must be 0-10000 basis points", ErrInvalidRules, region)
+		}
+		rates[NormalizeRegion(region)] = rate
+	}
+	r.Tax.Rates = rates
+	r.Tax.DefaultRegion = NormalizeRegion(r.Tax.DefaultRegion)
+
+	methods := make(map[string]ShippingRate, len(r.Shipping.Methods))
+	for name, rate := range r.Shipping.Methods {
+		if rate.Type != ShippingFlat && rate.Type != ShippingWeight {
+			return fmt.Errorf("%w: shipping %s type must be flat or weight", ErrInvalidRules, name)
+		}
+		// currency method: yang pertama disebut di config, default IDR
+		currency := ""
+		for _, m := range []money.Money{rate.Amount, rate.PerKg, rate.FreeOver} {
+			if m.Currency != "" && currency == "" {
+				currency = m.Currency
+			}
+			if m.Currency != "" && m.Currency != currency {
+				return fmt.Errorf("%w: shipping %s mixes currencies", ErrInvalidRules, name)
+			}
+			if m.IsZero() {
+				continue
+			}
+			if m.MinorUnits < 0 || m.Validate() != nil {
+				return fmt.Errorf("%w: shipping %s has invalid amount", ErrInvalidRules, name)
+			}
+		}
+		if currency == "" {
+			currency = money.DefaultCurrency
+		}
+		// nominal nol tanpa currency tetap memakai currency method
+		if rate.Amount.Currency == "" {
+			rate.Amount = money.New(rate.Amount.MinorUnits, currency)
+		}
+		if rate.PerKg.Currency == "" {
+			rate.PerKg = money.New(rate.PerKg.MinorUnits, currency)
+		}
+		methods[NormalizeMethod(name)] = rate
+	}
+	r.Shipping.Methods = methods
+	r.Shipping.DefaultMethod = NormalizeMethod(r.Shipping.DefaultMethod)
+
+	if len(r.Order) == 0 {
+		r.Order = DefaultOrder
+	}
+	_, err := r.Build()
+	return err
+}
+
+// susun pipeline sesuai urutan di rules
+func (r *Rules) Build() (*Pipeline, error) {
+	calculators := make([]Calculator, 0, len(r.Order))
+	for _, name := range r.Order {
+		switch name {
+		case CalculatorSubtotal:
+			calculators = append(calculators, SubtotalCalculator{})
+		case CalculatorDiscount:
+			calculators = append(calculators, DiscountCalculator{})
+		case CalculatorTax:
+			calculators = append(calculators, TaxCalculator{DefaultRegion: r.Tax.DefaultRegion, Rates: r.Tax.Rates})
+		case CalculatorShipping:
+			calculators = append(calculators, ShippingCalculator{DefaultMethod: r.Shipping.DefaultMethod, Methods: r.Shipping.Methods})
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownCalculator, name)
+		}
+	}
+	return NewPipeline(calculators...), nil
+}
+
+// region disimpan uppercase, misalnya "ID"
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+// method shipping disimpan lowercase, misalnya "flat"
+func NormalizeMethod(method string) string {
+	return strings.ToLower(strings.TrimSpace(method))
+}
@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"testing"
+
+	"shared/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesValidate_ShippingCurrency(t *testing.T) {
+	tests := []struct {
+		name string
+		rate ShippingRate
+		want string
+	}{
+		{
+			name: "nominal nol tetap memakai currency config",
+			rate: ShippingRate{Type: ShippingFlat, Amount: money.New(0, "SGD")},
+			want: "SGD",
+		},
+		{
+			name: "tanpa nominal dan per kg memakai default",
+			rate: ShippingRate{Type: ShippingFlat},
+			want: money.DefaultCurrency,
+		},
+		{
+			name: "currency dari per kg",
+			rate: ShippingRate{Type: ShippingWeight, PerKg: money.New(500, "USD")},
+			want: "USD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := &Rules{}
+			rules.Shipping.Methods = map[string]ShippingRate{"pickup": tt.rate}
+
+			if !assert.NoError(t, rules.validate()) {
+				return
+			}
+			rate := rules.Shipping.Methods["pickup"]
+			assert.Equal(t, tt.want, rate.Amount.Currency)
+			assert.Equal(t, tt.want, rate.PerKg.Currency)
+		})
+	}
+}
+
+func TestRulesValidate_RejectsMixedShippingCurrency(t *testing.T) {
+	rules := &Rules{}
+	rules.Shipping.Methods = map[string]ShippingRate{"weight": {
+		Type:   ShippingWeight,
+		Amount: money.New(500000, "IDR"),
+		PerKg:  money.New(500, "USD"),
+	}}
+
+	assert.ErrorIs(t, rules.validate(), ErrInvalidRules)
+}
+
+func TestShippingCalculator_ZeroAmountWeightMethod(t *testing.T) {
+	rules := &Rules{}
+	rules.Shipping.DefaultMethod = "free"
+	rules.Shipping.Methods = map[string]ShippingRate{"free": {Type: ShippingWeight}}
+	if !assert.NoError(t, rules.validate()) {
+		return
+	}
+
+	calc := ShippingCalculator{DefaultMethod: rules.Shipping.DefaultMethod, Methods: rules.Shipping.Methods}
+	q := &Quote{Currency: money.DefaultCurrency, Lines: []Line{{WeightGrams: 1500, Quantity: 1}}}
+	q.Breakdown.Subtotal = money.New(10000, money.DefaultCurrency)
+	q.Breakdown.Discount = money.New(0, money.DefaultCurrency)
+
+	assert.NoError(t, calc.Calculate(q))
+	assert.Equal(t, money.New(0, money.DefaultCurrency), q.Breakdown.Shipping)
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"math"
+	"strings"
+	"time"
+	// timezone laporan tetap valid di image tanpa zoneinfo
+	_ "time/tzdata"
+
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+// batas laporan penjualan
+const (
+	// rentang default kalau from tidak diisi
+	DefaultAnalyticsDays = 30
+	// rentang maksimal satu laporan
+	MaxAnalyticsDays   = 3 * 366
+	DefaultTopProducts = 10
+	MaxTopProducts     = 100
+)
+
+// Laporan penjualan dari aggregation Mongo atas transaksi dan order
+type AnalyticsService interface {
+	Revenue(filter domain.AnalyticsFilter, interval string) ([]domain.RevenuePoint, error)
+	TopProducts(filter domain.AnalyticsFilter, by string, limit int64) ([]domain.ProductSales, error)
+	Summary(filter domain.AnalyticsFilter) (*domain.SalesSummary, error)
+}
+
+type analyticsService struct {
+	repo infra.AnalyticsRepository
+}
+
+func NewAnalyticsService(repo infra.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{repo: repo}
+}
+
+// Susun filter dari parameter request. from dan to berupa tanggal
+// (2006-01-02) di timezone tz, to ikut dihitung sampai akhir hari.
+// Kosong berarti 30 hari terakhir sampai hari ini, timezone default UTC.
+func ParseAnalyticsFilter(from, to, tz, currency string, now time.Time) (domain.AnalyticsFilter, error) {
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return domain.AnalyticsFilter{}, ErrTimezoneInvalid
+	}
+
+	filter := domain.AnalyticsFilter{Timezone: loc.String(), Currency: strings.ToUpper(currency)}
+	if filter.Currency == "" {
+		filter.Currency = money.DefaultCurrency
+	}
+	if !money.ValidCurrency(filter.Currency) {
+		return domain.AnalyticsFilter{}, ErrCurrencyInvalid
+	}
+
+	today := now.In(loc)
+	filter.To = time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, loc)
+	if to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return domain.AnalyticsFilter{}, ErrAnalyticsDate
+		}
+		filter.To = day.AddDate(0, 0, 1)
+	}
+
+	filter.From = filter.To.AddDate(0, 0, -DefaultAnalyticsDays)
+	if from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return domain.AnalyticsFilter{}, ErrAnalyticsDate
+		}
+		filter.From = day
+	}
+
+	if !filter.From.Before(filter.To) || filter.To.Sub(filter.From) > MaxAnalyticsDays*24*time.Hour {
+		return domain.AnalyticsFilter{}, ErrAnalyticsRange
+	}
+	return filter, nil
+}
+
+// revenue dan jumlah order per hari, minggu (mulai Senin) atau bulan
+func (s *analyticsService) Revenue(filter domain.AnalyticsFilter, interval string) ([]domain.RevenuePoint, error) {
+	layout := "2006-01-02"
+	switch interval {
+	case domain.IntervalDay, domain.IntervalWeek:
+	case domain.IntervalMonth:
+		layout = "2006-01"
+	default:
+		return nil, ErrIntervalInvalid
+	}
+
+	points, err := s.repo.RevenueSeries(filter, interval)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+
+	loc, _ := time.LoadLocation(filter.Timezone)
+	for i := range points {
+		points[i].Start = points[i].Start.In(loc)
+		points[i].Period = points[i].Start.Format(layout)
+	}
+	return points, nil
+}
+
+// product terlaris by revenue atau unit terjual
+func (s *analyticsService) TopProducts(filter domain.AnalyticsFilter, by string, limit int64) ([]domain.ProductSales, error) {
+	if by == "" {
+		by = domain.TopProductsByRevenue
+	}
+	if by != domain.TopProductsByRevenue && by != domain.TopProductsByUnits {
+		return nil, ErrTopProductsBy
+	}
+	if limit <= 0 {
+		limit = DefaultTopProducts
+	}
+	if limit > MaxTopProducts {
+		limit = MaxTopProducts
+	}
+
+	products, err := s.repo.TopProducts(filter, by, limit)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return products, nil
+}
+
+// total order, revenue, rata-rata nilai order dan tingkat repeat customer
+func (s *analyticsService) Summary(filter domain.AnalyticsFilter) (*domain.SalesSummary, error) {
+	summary, err := s.repo.Summary(filter)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+
+	summary.AverageOrderValue = money.New(0, filter.Currency)
+	if summary.Orders > 0 {
+		orders := int64(summary.Orders)
+		// dibulatkan ke minor unit terdekat
+		summary.AverageOrderValue.MinorUnits = (summary.Revenue.MinorUnits + orders/2) / orders
+	}
+	if summary.Customers > 0 {
+		rate := float64(summary.RepeatCustomers) / float64(summary.Customers)
+		summary.RepeatCustomerRate = math.Round(rate*10000) / 10000
+	}
+	return summary, nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"errors"
+
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+type CartService interface {
+	GetCart(email string) (*domain.Cart, error)
+	AddItem(email, productID string, quantity int) (*domain.Cart, error)
+	UpdateItem(email, productID string, quantity int) (*domain.Cart, error)
+	RemoveItem(email, productID string) (*domain.Cart, error)
+	Clear(email string) error
+}
+
+type cartService struct {
+	repo     infra.CartRepository
+	products *infra.ProductRepo
+}
+
+func NewCartService(repo infra.CartRepository, products *infra.ProductRepo) CartService {
+	return &cartService{repo: repo, products: products}
+}
+
+// ambil cart user
+func (s *cartService) GetCart(email string) (*domain.Cart, error) {
+	return s.repo.FindByEmail(email)
+}
+
+// tambah product ke cart. Stok dan harga baru dicek saat checkout,
+// di sini cukup pastikan product ada.
+func (s *cartService) AddItem(email, productID string, quantity int) (*domain.Cart, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if err := s.ensureProduct(productID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddItem(email, productID, quantity); err != nil {
+		return nil, ErrCartUpdate
+	}
+	return s.repo.FindByEmail(email)
+}
+
+// ganti quantity item yang sudah ada di cart
+func (s *cartService) UpdateItem(email, productID string, quantity int) (*domain.Cart, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	found, err := s.repo.SetItemQuantity(email, productID, quantity)
+	if err != nil {
+		return nil, ErrCartUpdate
+	}
+	if !found {
+		return nil, ErrCartItemNotFound
+	}
+	return s.repo.FindByEmail(email)
+}
+
+// hapus item dari cart
+func (s *cartService) RemoveItem(email, productID string) (*domain.Cart, error) {
+	found, err := s.repo.RemoveItem(email, productID)
+	if err != nil {
+		return nil, ErrCartUpdate
+	}
+	if !found {
+		return nil, ErrCartItemNotFound
+	}
+	return s.repo.FindByEmail(email)
+}
+
+// kosongkan cart
+func (s *cartService) Clear(email string) error {
+	if err := s.repo.Clear(email); err != nil {
+		return ErrCartUpdate
+	}
+	return nil
+}
+
+func (s *cartService) ensureProduct(productID string) error {
+	if productID == "" {
+		return ErrProductIDMissing
+	}
+	if _, err := s.products.GetProductByID(productID); err != nil {
+		if errors.Is(err, infra.ErrInvalidProductID) {
+			return ErrInvalidProductID
+		}
+		return ErrProductNotFound
+	}
+	return nil
+}
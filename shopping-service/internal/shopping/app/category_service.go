@@ -0,0 +1,102 @@
+package app
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+type CategoryService interface {
+	Create(category *domain.Category) error
+	// semua kategori dalam bentuk tree, root diurutkan by nama
+	GetTree() ([]*domain.CategoryNode, error)
+	GetByID(id string) (*domain.Category, error)
+}
+
+type categoryService struct {
+	repo infra.CategoryRepository
+}
+
+func NewCategoryService(repo infra.CategoryRepository) CategoryService {
+	return &categoryService{repo: repo}
+}
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug dari nama, misalnya "Baju Anak" -> "baju-anak"
+func slugify(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func (s *categoryService) Create(category *domain.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return ErrCategoryNameEmpty
+	}
+	if category.Slug == "" {
+		category.Slug = category.Name
+	}
+	category.Slug = slugify(category.Slug)
+	if category.Slug == "" {
+		return ErrCategoryNameEmpty
+	}
+
+	// leluhur diambil dari parent supaya sub tree bisa dicari by prefix path
+	category.Ancestors = []string{}
+	if category.ParentID != "" {
+		parent, err := s.GetByID(category.ParentID)
+		if err != nil {
+			return err
+		}
+		category.Ancestors = parent.Path()
+	}
+
+	if err := s.repo.Insert(category); err != nil {
+		if errors.Is(err, infra.ErrCategorySlugExists) {
+			return ErrCategorySlugTaken
+		}
+		return ErrCategoryInsert
+	}
+	return nil
+}
+
+func (s *categoryService) GetTree() ([]*domain.CategoryNode, error) {
+	categories, err := s.repo.FindAll()
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+
+	nodes := make(map[string]*domain.CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID.Hex()] = &domain.CategoryNode{Category: c, Children: []*domain.CategoryNode{}}
+	}
+
+	// FindAll sudah urut by nama, jadi urutan children ikut urut
+	roots := []*domain.CategoryNode{}
+	for _, c := range categories {
+		node := nodes[c.ID.Hex()]
+		if parent, ok := nodes[c.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
+
+func (s *categoryService) GetByID(id string) (*domain.Category, error) {
+	category, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidCategoryID) {
+			return nil, ErrInvalidCategoryID
+		}
+		return nil, ErrFailedDecode
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
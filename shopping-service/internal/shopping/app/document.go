@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"shopping-service/internal/pdf"
+	"shopping-service/internal/shopping/domain"
+)
+
+// Format dokumen invoice dan statement
+const (
+	DocumentJSON = "json"
+	DocumentHTML = "html"
+	DocumentPDF  = "pdf"
+)
+
+// Template HTML untuk browser, template teks (kolom monospace) untuk PDF
+//
+//go:embed templates
+var templateFiles embed.FS
+
+var documentFuncs = map[string]any{
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+	// basis point ke persen, 1100 -> 11%
+	"percent": func(bp int64) string {
+		return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%d.%02d", bp/100, bp%100), "0"), ".") + "%"
+	},
+	"line": func(width int) string { return strings.Repeat("-", width) },
+	// hari terakhir periode yang batas akhirnya eksklusif
+	"lastDay": func(to time.Time) time.Time { return to.AddDate(0, 0, -1) },
+	"details": func(e domain.StatementEntry) string {
+		if e.Kind == domain.StatementRefund {
+			return e.Reason
+		}
+		return e.Method
+	},
+	"signed": func(e domain.StatementEntry) string {
+		if e.Kind == domain.StatementRefund {
+			return "-" + e.Amount.String()
+		}
+		return e.Amount.String()
+	},
+}
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(documentFuncs).ParseFS(templateFiles, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(documentFuncs).ParseFS(templateFiles, "templates/*.txt"))
+)
+
+// Render invoice sebagai HTML atau PDF
+func RenderInvoice(w io.Writer, format string, invoice *domain.Invoice) error {
+	return renderDocument(w, format, "invoice", invoice)
+}
+
+// Render statement sebagai HTML atau PDF
+func RenderStatement(w io.Writer, format string, statement *domain.Statement) error {
+	return renderDocument(w, format, "statement", statement)
+}
+
+func renderDocument(w io.Writer, format, name string, data any) error {
+	switch format {
+	case DocumentHTML:
+		return htmlTemplates.ExecuteTemplate(w, name+".html", data)
+	case DocumentPDF:
+		var text bytes.Buffer
+		if err := textTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+			return err
+		}
+		doc := pdf.New()
+		doc.WriteText(text.String())
+		_, err := doc.WriteTo(w)
+		return err
+	default:
+		return ErrDocumentFormat
+	}
+}
@@ -6,6 +6,7 @@ import "errors"
 var (
 	ErrNameEmpty        = errors.New("product name is required")
 	ErrPriceInvalid     = errors.New("price must be > 0")
+	ErrCurrencyInvalid  = errors.New("currency must be a supported ISO-4217 code")
 	ErrStockInvalid     = errors.New("stock must be >= 0")
 	ErrInvalidEmail     = errors.New("invalid email format")
 	ErrInvalidQuantity  = errors.New("quantity must be > 0")
@@ -45,3 +46,143 @@ var (
 	ErrPaymentFailed      = errors.New("payment failed")
 	ErrPaymentRequest     = errors.New("payment service not reachable")
 )
+
+// cart / order
+var (
+	ErrCartEmpty         = errors.New("cart is empty")
+	ErrCartItemNotFound  = errors.New("product is not in cart")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrMixedCurrency     = errors.New("cart items must use the same currency")
+	ErrInvalidOrderID    = errors.New("invalid order ID")
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrCartUpdate        = errors.New("failed to update cart")
+	ErrOrderInsert       = errors.New("failed to insert order")
+	// checkout gagal setelah payment berhasil, payment sudah di-refund
+	ErrCheckoutReverted = errors.New("checkout could not be completed, payment has been refunded")
+	// sama seperti di atas tapi refund gagal, ditangani lewat rekonsiliasi
+	ErrCheckoutRefundFailed = errors.New("checkout could not be completed and the refund is pending, contact support")
+)
+
+// promo / kupon
+var (
+	ErrPromoCodeEmpty      = errors.New("promotion code is required")
+	ErrPromoTypeInvalid    = errors.New("promotion type must be percentage or fixed")
+	ErrPromoValueInvalid   = errors.New("percent_off must be 1-10000 basis points, amount_off must be > 0")
+	ErrPromoScopeInvalid   = errors.New("promotion scope must be order or product (with product_ids)")
+	ErrPromoWindowInvalid  = errors.New("starts_at must be before ends_at")
+	ErrPromoLimitInvalid   = errors.New("usage limits must be >= 0")
+	ErrPromoCodeTaken      = errors.New("promotion code already exists")
+	ErrPromotionInsert     = errors.New("failed to insert promotion")
+	ErrPromotionNotFound   = errors.New("promotion not found")
+	ErrCouponNotFound      = errors.New("coupon code not found")
+	ErrCouponNotStarted    = errors.New("coupon is not active yet")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponExhausted     = errors.New("coupon usage limit reached")
+	ErrCouponUserLimit     = errors.New("coupon usage limit per user reached")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to these items")
+)
+
+// pricing (pajak & ongkir)
+var (
+	ErrWeightInvalid         = errors.New("weight must be >= 0")
+	ErrRegionInvalid         = errors.New("unknown tax region")
+	ErrShippingMethodInvalid = errors.New("unknown shipping method")
+	ErrPricingFailed         = errors.New("failed to calculate price")
+)
+
+// inventory
+var (
+	ErrAdjustmentInvalid    = errors.New("adjustment quantity must not be 0")
+	ErrThresholdInvalid     = errors.New("low_stock_threshold must be >= 0")
+	ErrStockUpdate          = errors.New("failed to update stock")
+	ErrReservationFailed    = errors.New("failed to reserve stock")
+	ErrReservationNotActive = errors.New("stock reservation is no longer active")
+)
+
+// catalog
+var (
+	ErrCategoryNameEmpty   = errors.New("category name is required")
+	ErrCategorySlugTaken   = errors.New("category slug already exists")
+	ErrCategoryInsert      = errors.New("failed to insert category")
+	ErrInvalidCategoryID   = errors.New("invalid category ID")
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrVariantInvalid      = errors.New("variant sku is required and variant price/stock must be valid")
+	ErrVariantSKUDuplicate = errors.New("variant sku already used")
+	ErrSKUDuplicate        = errors.New("sku or variant sku already used")
+	ErrImageInvalid        = errors.New("image url is required and width/height must be >= 0")
+	ErrSearchQueryEmpty    = errors.New("search query is required")
+)
+
+// bulk import product
+var (
+	ErrImportFormat       = errors.New("format must be csv or ndjson")
+	ErrImportEmpty        = errors.New("import file has no rows")
+	ErrImportTooLarge     = errors.New("import file has too many rows")
+	ErrImportHeader       = errors.New("invalid csv header")
+	ErrImportFile         = errors.New("import file could not be read")
+	ErrImportJobInsert    = errors.New("failed to create import job")
+	ErrInvalidImportJobID = errors.New("invalid import job ID")
+	ErrImportJobNotFound  = errors.New("import job not found")
+	ErrImportUnavailable  = errors.New("import is unavailable, service is shutting down")
+	ErrSKURequired        = errors.New("sku is required")
+)
+
+// optimistic concurrency
+var (
+	ErrVersionConflict = errors.New("resource was modified by another request, reload and retry")
+)
+
+// workflow status transaksi
+var (
+	ErrInvalidStatusTransition   = errors.New("status transition not allowed")
+	ErrTrackingMissing           = errors.New("carrier and tracking_number are required to ship")
+	ErrActorMissing              = errors.New("acting user is required")
+	ErrTransactionItemsLocked    = errors.New("product and quantity can only be changed on unpaid transactions without a coupon")
+	ErrTransactionEmailLocked    = errors.New("email can no longer be changed after the transaction is shipped, cancelled or returned")
+	ErrTransactionNotOwner       = errors.New("only the buyer can change this transaction")
+	ErrTransactionEmailAdminOnly = errors.New("only an admin can change the buyer email")
+	ErrCancelNoPayment           = errors.New("paid transaction has no payment to refund")
+	ErrCancelRefundFailed        = errors.New("refund failed, cancel again to retry")
+)
+
+// retur & refund
+var (
+	ErrReturnReasonInvalid    = errors.New("reason must be damaged, wrong_item, not_as_described, changed_mind or other")
+	ErrReturnNotAllowed       = errors.New("only delivered transactions can be returned")
+	ErrReturnNotOwner         = errors.New("return belongs to another buyer")
+	ErrReturnNoPayment        = errors.New("transaction has no payment to refund")
+	ErrReturnQuantityExceeded = errors.New("return quantity exceeds quantity not yet returned")
+	ErrInvalidReturnID        = errors.New("invalid return ID")
+	ErrReturnNotFound         = errors.New("return request not found")
+	ErrReturnInsert           = errors.New("failed to insert return request")
+	ErrReturnUpdate           = errors.New("failed to update return request")
+	ErrReturnDecided          = errors.New("return request was already decided")
+	ErrRefundFailed           = errors.New("refund failed, approve again to retry")
+)
+
+// rekonsiliasi payment
+var (
+	ErrReconciliationRunning   = errors.New("a reconciliation is already running")
+	ErrReconciliationInsert    = errors.New("failed to create reconciliation report")
+	ErrInvalidReconciliationID = errors.New("invalid reconciliation ID")
+	ErrReconciliationNotFound  = errors.New("reconciliation report not found")
+)
+
+// laporan penjualan
+var (
+	ErrTimezoneInvalid = errors.New("tz must be an IANA timezone, e.g. Asia/Jakarta")
+	ErrAnalyticsDate   = errors.New("from and to must be dates in YYYY-MM-DD format")
+	ErrAnalyticsRange  = errors.New("from must be before to and the range at most 3 years")
+	ErrIntervalInvalid = errors.New("interval must be day, week or month")
+	ErrTopProductsBy   = errors.New("by must be revenue or units")
+)
+
+// invoice & statement
+var (
+	ErrInvoiceNotPaid   = errors.New("invoice is only available for paid transactions")
+	ErrInvoiceInsert    = errors.New("failed to create invoice")
+	ErrInvalidInvoiceID = errors.New("invalid invoice ID")
+	ErrInvoiceNotFound  = errors.New("invoice not found")
+	ErrDocumentFormat   = errors.New("format must be json, html or pdf")
+	ErrStatementMonth   = errors.New("month must be in YYYY-MM format")
+)
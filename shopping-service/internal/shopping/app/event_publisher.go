@@ -0,0 +1,24 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	"shared/events"
+)
+
+// Nama service sebagai source di envelope event
+const eventSource = "shopping-service"
+
+// publish event domain ke broker. Gagal publish tidak membatalkan operasi
+// yang sudah tersimpan, cukup dicatat di log.
+func publish(ctx context.Context, publisher events.Publisher, eventType string, version int, payload any) {
+	env, err := events.NewEnvelope(eventType, version, eventSource, payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build event", "event_type", eventType, "error", err)
+		return
+	}
+	if err := events.PublishWithRetry(ctx, publisher, env, 3); err != nil {
+		slog.ErrorContext(ctx, "failed to publish event", "event_type", eventType, "event_id", env.ID, "error", err)
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"log/slog"
+	"time"
+
+	"shared/metrics"
+
+	"github.com/robfig/cron/v3"
+)
+
+// StartInventoryCron menjalankan cron job tiap menit
+// untuk melepas reservasi stok dari checkout yang tidak selesai
+func StartInventoryCron(service InventoryService) *cron.Cron {
+	c := cron.New()
+
+	c.AddFunc("@every 1m", func() {
+		start := time.Now()
+		expired, err := service.ExpireReservations()
+		metrics.ObserveJob("inventory_expire", start, err)
+		if err != nil {
+			slog.Error("expire stock reservations failed", "job", "inventory_expire", "error", err)
+			return
+		}
+		if expired > 0 {
+			slog.Info("expired stock reservations released", "job", "inventory_expire", "count", expired)
+		}
+	})
+
+	c.Start()
+	return c
+}
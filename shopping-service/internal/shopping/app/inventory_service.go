@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"shared/events"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lama reservasi stok ditahan kalau checkout tidak selesai
+const DefaultReservationTTL = 15 * time.Minute
+
+// jumlah maksimal reservasi yang di-expire per putaran cron
+const expireBatchSize = 100
+
+// posisi stok product beserta riwayat ledger-nya
+type StockHistory struct {
+	ProductID         string                 `json:"product_id"`
+	Stock             int                    `json:"stock"`
+	Reserved          int                    `json:"reserved"`
+	Available         int                    `json:"available"`
+	LowStockThreshold int                    `json:"low_stock_threshold"`
+	Movements         []domain.StockMovement `json:"movements"`
+}
+
+// Semua perubahan stok lewat service ini supaya setiap perubahan tercatat
+// di ledger stock_movements.
+type InventoryService interface {
+	// catat stok awal product baru sebagai restock
+	Initialize(product *domain.Product)
+	Restock(productID string, quantity int, note string) (*domain.Product, error)
+	// koreksi stok manual, delta boleh negatif
+	Adjust(productID string, delta int, note string) (*domain.Product, error)
+	// stok kembali dari retur, reference ID retur
+	Return(productID string, quantity int, reference, note string) (*domain.Product, error)
+	// tahan stok untuk checkout sampai Commit, Release atau expired
+	Reserve(productID string, quantity int, reference string) (*domain.StockReservation, error)
+	// reservasi jadi penjualan, stok fisik berkurang
+	Commit(reservation *domain.StockReservation, reference string) error
+	Release(reservation *domain.StockReservation) error
+	// lepas reservasi aktif yang sudah lewat batas waktu, dipanggil cron
+	ExpireReservations() (int, error)
+	History(productID string, limit int64) (*StockHistory, error)
+}
+
+type inventoryService struct {
+	repo      infra.InventoryRepository
+	products  *infra.ProductRepo
+	publisher events.Publisher
+	ttl       time.Duration
+}
+
+func NewInventoryService(repo infra.InventoryRepository, products *infra.ProductRepo, publisher events.Publisher, ttl time.Duration) InventoryService {
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	return &inventoryService{repo: repo, products: products, publisher: publisher, ttl: ttl}
+}
+
+func (s *inventoryService) Initialize(product *domain.Product) {
+	movement := &domain.StockMovement{
+		ProductID:     product.ID.Hex(),
+		Type:          domain.MovementRestock,
+		StockDelta:    product.Stock,
+		StockAfter:    product.Stock,
+		ReservedAfter: product.Reserved,
+		Note:          "initial stock",
+	}
+	if err := s.repo.InsertMovement(movement); err != nil {
+		slog.Error("insert initial stock movement failed", "product_id", movement.ProductID, "error", err)
+	}
+
+	s.publishChange(product, product.Stock, 0)
+}
+
+func (s *inventoryService) Restock(productID string, quantity int, note string) (*domain.Product, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	return s.move(productID, domain.MovementRestock, quantity, 0, "", note)
+}
+
+func (s *inventoryService) Adjust(productID string, delta int, note string) (*domain.Product, error) {
+	if delta == 0 {
+		return nil, ErrAdjustmentInvalid
+	}
+	return s.move(productID, domain.MovementAdjustment, delta, 0, "", note)
+}
+
+func (s *inventoryService) Return(productID string, quantity int, reference, note string) (*domain.Product, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	return s.move(productID, domain.MovementReturn, quantity, 0, reference, note)
+}
+
+func (s *inventoryService) Reserve(productID string, quantity int, reference string) (*domain.StockReservation, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	// ID dibuat lebih dulu supaya ledger bisa merujuk reservasi
+	reservation := &domain.StockReservation{
+		ID:        primitive.NewObjectID(),
+		ProductID: productID,
+		Quantity:  quantity,
+		Reference: reference,
+		Status:    domain.ReservationActive,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+
+	if _, err := s.move(productID, domain.MovementReservation, 0, quantity, reservation.ID.Hex(), reference); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.InsertReservation(reservation); err != nil {
+		// tanpa dokumen reservasi cron tidak bisa melepasnya, jadi lepas sekarang
+		slog.Error("insert reservation failed", "reservation_id", reservation.ID.Hex(), "error", err)
+		if _, err := s.move(productID, domain.MovementRelease, 0, -quantity, reservation.ID.Hex(), "reservation not stored"); err != nil {
+			slog.Error("release unstored reservation failed", "reservation_id", reservation.ID.Hex(), "error", err)
+		}
+		return nil, ErrReservationFailed
+	}
+	return reservation, nil
+}
+
+// Reservasi diklaim (committing) dulu supaya tidak dilepas cron, lalu stok
+// dipindah. Kalau pemindahan stok gagal reservasi kembali aktif, jadi stok
+// yang ditahan tetap bisa dilepas dan tidak tertahan selamanya.
+func (s *inventoryService) Commit(reservation *domain.StockReservation, reference string) error {
+	ok, err := s.repo.TransitionReservation(reservation.ID, domain.ReservationActive, domain.ReservationCommitting)
+	if err != nil {
+		return ErrStockUpdate
+	}
+	if !ok {
+		return ErrReservationNotActive
+	}
+
+	if _, err := s.move(reservation.ProductID, domain.MovementSale, -reservation.Quantity, -reservation.Quantity, reservation.ID.Hex(), reference); err != nil {
+		s.reactivate(reservation.ID, domain.ReservationCommitting)
+		return err
+	}
+
+	// stok sudah terjual, gagal di sini hanya menyisakan status committing
+	if _, err := s.repo.TransitionReservation(reservation.ID, domain.ReservationCommitting, domain.ReservationCommitted); err != nil {
+		slog.Error("mark reservation committed failed", "reservation_id", reservation.ID.Hex(), "error", err)
+	}
+	reservation.Status = domain.ReservationCommitted
+	return nil
+}
+
+func (s *inventoryService) Release(reservation *domain.StockReservation) error {
+	return s.release(reservation, domain.ReservationReleased, "checkout cancelled")
+}
+
+func (s *inventoryService) ExpireReservations() (int, error) {
+	reservations, err := s.repo.FindExpiredReservations(time.Now(), expireBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range reservations {
+		err := s.release(&reservations[i], domain.ReservationExpired, "reservation expired")
+		if err == ErrReservationNotActive {
+			// sudah di-commit/release checkout di antara find dan update
+			continue
+		}
+		if err != nil {
+			slog.Error("expire reservation failed", "reservation_id", reservations[i].ID.Hex(), "error", err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *inventoryService) History(productID string, limit int64) (*StockHistory, error) {
+	product, err := s.products.GetProductByID(productID)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidProductID) {
+			return nil, ErrInvalidProductID
+		}
+		return nil, ErrProductNotFound
+	}
+
+	movements, err := s.repo.FindMovements(productID, limit)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+
+	return &StockHistory{
+		ProductID:         productID,
+		Stock:             product.Stock,
+		Reserved:          product.Reserved,
+		Available:         product.Available(),
+		LowStockThreshold: product.LowStockThreshold,
+		Movements:         movements,
+	}, nil
+}
+
+// status aktif -> status, lalu kembalikan stok yang ditahan
+func (s *inventoryService) release(reservation *domain.StockReservation, status, note string) error {
+	ok, err := s.repo.TransitionReservation(reservation.ID, domain.ReservationActive, status)
+	if err != nil {
+		return ErrStockUpdate
+	}
+	if !ok {
+		return ErrReservationNotActive
+	}
+
+	if _, err := s.move(reservation.ProductID, domain.MovementRelease, 0, -reservation.Quantity, reservation.ID.Hex(), note); err != nil {
+		s.reactivate(reservation.ID, status)
+		return err
+	}
+	reservation.Status = status
+	return nil
+}
+
+// kembalikan reservasi ke aktif setelah pemindahan stok gagal, supaya
+// dicoba lagi oleh checkout atau cron expire
+func (s *inventoryService) reactivate(id primitive.ObjectID, from string) {
+	if _, err := s.repo.TransitionReservation(id, from, domain.ReservationActive); err != nil {
+		slog.Error("reactivate reservation failed", "reservation_id", id.Hex(), "status", from, "error", err)
+	}
+}
+
+// Ubah stok secara atomic lalu catat ke ledger. Ledger ditulis setelah
+// stok berubah, gagal tulis ledger hanya dicatat di log.
+func (s *inventoryService) move(productID, kind string, stockDelta, reservedDelta int, reference, note string) (*domain.Product, error) {
+	product, err := s.repo.ApplyDelta(productID, stockDelta, reservedDelta)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrInvalidProductID):
+			return nil, ErrInvalidProductID
+		case errors.Is(err, infra.ErrProductNotFound):
+			return nil, ErrProductNotFound
+		case errors.Is(err, infra.ErrInsufficientStock):
+			return nil, ErrInsufficientStock
+		}
+		return nil, ErrStockUpdate
+	}
+
+	movement := &domain.StockMovement{
+		ProductID:     productID,
+		Type:          kind,
+		StockDelta:    stockDelta,
+		ReservedDelta: reservedDelta,
+		StockAfter:    product.Stock,
+		ReservedAfter: product.Reserved,
+		Reference:     reference,
+		Note:          note,
+	}
+	if err := s.repo.InsertMovement(movement); err != nil {
+		slog.Error("insert stock movement failed", "product_id", productID, "kind", kind, "stock_delta", stockDelta, "reserved_delta", reservedDelta, "error", err)
+	}
+
+	if stockDelta != 0 {
+		s.publishChange(product, product.Stock, product.Stock-stockDelta)
+	}
+	s.checkLowStock(product, product.Available()-stockDelta+reservedDelta)
+
+	return product, nil
+}
+
+func (s *inventoryService) publishChange(product *domain.Product, newStock, oldStock int) {
+	publish(context.Background(), s.publisher, events.TypeProductStockChanged, events.VersionProductStockChanged, events.ProductStockChanged{
+		ProductID: product.ID.Hex(),
+		OldStock:  oldStock,
+		NewStock:  newStock,
+	})
+}
+
+// alert hanya saat stok tersedia melewati batas, bukan setiap perubahan di bawahnya
+func (s *inventoryService) checkLowStock(product *domain.Product, availableBefore int) {
+	threshold := product.LowStockThreshold
+	if threshold <= 0 || product.Available() > threshold || availableBefore <= threshold {
+		return
+	}
+	publish(context.Background(), s.publisher, events.TypeProductStockLow, events.VersionProductStockLow, events.ProductStockLow{
+		ProductID: product.ID.Hex(),
+		Available: product.Available(),
+		Threshold: threshold,
+	})
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shared/events"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// repository inventory di memory dengan aturan stok yang sama dengan Mongo
+type memoryInventoryRepo struct {
+	products     map[string]*domain.Product
+	movements    []domain.StockMovement
+	reservations map[primitive.ObjectID]*domain.StockReservation
+	// error untuk ApplyDelta berikutnya
+	failDelta error
+}
+
+func newMemoryInventoryRepo(productID string, stock int) *memoryInventoryRepo {
+	return &memoryInventoryRepo{
+		products:     map[string]*domain.Product{productID: {Name: productID, Stock: stock}},
+		reservations: map[primitive.ObjectID]*domain.StockReservation{},
+	}
+}
+
+func (r *memoryInventoryRepo) ApplyDelta(productID string, stockDelta, reservedDelta int) (*domain.Product, error) {
+	if err := r.failDelta; err != nil {
+		r.failDelta = nil
+		return nil, err
+	}
+	product, ok := r.products[productID]
+	if !ok {
+		return nil, infra.ErrProductNotFound
+	}
+	stock, reserved := product.Stock+stockDelta, product.Reserved+reservedDelta
+	if reserved < 0 || stock-reserved < 0 {
+		return nil, infra.ErrInsufficientStock
+	}
+	product.Stock, product.Reserved = stock, reserved
+	p := *product
+	return &p, nil
+}
+
+func (r *memoryInventoryRepo) InsertMovement(movement *domain.StockMovement) error {
+	r.movements = append(r.movements, *movement)
+	return nil
+}
+
+func (r *memoryInventoryRepo) FindMovements(productID string, limit int64) ([]domain.StockMovement, error) {
+	return r.movements, nil
+}
+
+func (r *memoryInventoryRepo) InsertReservation(reservation *domain.StockReservation) error {
+	stored := *reservation
+	r.reservations[reservation.ID] = &stored
+	return nil
+}
+
+func (r *memoryInventoryRepo) TransitionReservation(id primitive.ObjectID, from, to string) (bool, error) {
+	reservation, ok := r.reservations[id]
+	if !ok || reservation.Status != from {
+		return false, nil
+	}
+	reservation.Status = to
+	return true, nil
+}
+
+func (r *memoryInventoryRepo) FindExpiredReservations(now time.Time, limit int64) ([]domain.StockReservation, error) {
+	var result []domain.StockReservation
+	for _, reservation := range r.reservations {
+		if reservation.Status == domain.ReservationActive && reservation.ExpiresAt.Before(now) {
+			result = append(result, *reservation)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryInventoryRepo) movementTypes() []string {
+	types := make([]string, 0, len(r.movements))
+	for _, m := range r.movements {
+		types = append(types, m.Type)
+	}
+	return types
+}
+
+func newTestInventory(repo *memoryInventoryRepo, ttl time.Duration) InventoryService {
+	return NewInventoryService(repo, nil, events.NewMemoryBroker(), ttl)
+}
+
+func TestInventory_ReservationLedger(t *testing.T) {
+	tests := []struct {
+		name          string
+		finish        func(InventoryService, *domain.StockReservation) error
+		wantStock     int
+		wantReserved  int
+		wantStatus    string
+		wantMovements []string
+	}{
+		{
+			name: "commit jadi penjualan",
+			finish: func(s InventoryService, r *domain.StockReservation) error {
+				return s.Commit(r, "order-1")
+			},
+			wantStock:     7,
+			wantReserved:  0,
+			wantStatus:    domain.ReservationCommitted,
+			wantMovements: []string{domain.MovementReservation, domain.MovementSale},
+		},
+		{
+			name: "release mengembalikan stok tersedia",
+			finish: func(s InventoryService, r *domain.StockReservation) error {
+				return s.Release(r)
+			},
+			wantStock:     10,
+			wantReserved:  0,
+			wantStatus:    domain.ReservationReleased,
+			wantMovements: []string{domain.MovementReservation, domain.MovementRelease},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryInventoryRepo("prod-1", 10)
+			service := newTestInventory(repo, time.Minute)
+
+			reservation, err := service.Reserve("prod-1", 3, "buyer@example.com")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 3, repo.products["prod-1"].Reserved)
+			assert.Equal(t, 7, repo.products["prod-1"].Available())
+
+			assert.NoError(t, tt.finish(service, reservation))
+			assert.Equal(t, tt.wantStock, repo.products["prod-1"].Stock)
+			assert.Equal(t, tt.wantReserved, repo.products["prod-1"].Reserved)
+			assert.Equal(t, tt.wantStatus, repo.reservations[reservation.ID].Status)
+			assert.Equal(t, tt.wantMovements, repo.movementTypes())
+
+			// reservasi yang sudah selesai tidak bisa diproses lagi
+			assert.ErrorIs(t, service.Commit(reservation, "order-2"), ErrReservationNotActive)
+			assert.ErrorIs(t, service.Release(reservation), ErrReservationNotActive)
+		})
+	}
+}
+
+func TestInventory_ReserveInsufficientStock(t *testing.T) {
+	repo := newMemoryInventoryRepo("prod-1", 2)
+	service := newTestInventory(repo, time.Minute)
+
+	_, err := service.Reserve("prod-1", 3, "buyer@example.com")
+
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+	assert.Empty(t, repo.reservations)
+	assert.Empty(t, repo.movements)
+}
+
+func TestInventory_CommitFailureKeepsReservationActive(t *testing.T) {
+	repo := newMemoryInventoryRepo("prod-1", 10)
+	service := newTestInventory(repo, time.Minute)
+	reservation, _ := service.Reserve("prod-1", 3, "buyer@example.com")
+
+	repo.failDelta = errors.New("mongo down")
+	err := service.Commit(reservation, "order-1")
+
+	assert.ErrorIs(t, err, ErrStockUpdate)
+	// stok tidak berubah dan reservasi masih bisa dilepas
+	assert.Equal(t, 10, repo.products["prod-1"].Stock)
+	assert.Equal(t, 3, repo.products["prod-1"].Reserved)
+	assert.Equal(t, domain.ReservationActive, repo.reservations[reservation.ID].Status)
+	assert.NoError(t, service.Release(reservation))
+	assert.Equal(t, 0, repo.products["prod-1"].Reserved)
+}
+
+func TestInventory_ExpireReservations(t *testing.T) {
+	repo := newMemoryInventoryRepo("prod-1", 10)
+	service := newTestInventory(repo, time.Hour)
+	old, _ := service.Reserve("prod-1", 2, "a@example.com")
+	fresh, _ := service.Reserve("prod-1", 3, "b@example.com")
+	repo.reservations[old.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	n, err := service.ExpireReservations()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, domain.ReservationExpired, repo.reservations[old.ID].Status)
+	assert.Equal(t, domain.ReservationActive, repo.reservations[fresh.ID].Status)
+	assert.Equal(t, 3, repo.products["prod-1"].Reserved)
+}
@@ -0,0 +1,436 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"shared/events"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+// jumlah payment per request saat menyusun statement
+const statementPageSize = 200
+
+// Invoice transaksi dan order cart yang sudah dibayar serta statement
+// bulanan customer
+type InvoiceService interface {
+	// buat invoice transaksi, idempotent: invoice yang sudah ada dikembalikan
+	Generate(transactionID string) (*domain.Invoice, error)
+	// buat invoice order cart, idempotent seperti Generate
+	GenerateForOrder(orderID string) (*domain.Invoice, error)
+	// invoice milik email, invoice email lain dianggap tidak ada
+	GetByID(id, email string) (*domain.Invoice, error)
+	// invoice transaksi milik email, dibuat dulu kalau belum ada
+	ForTransaction(transactionID, email string) (*domain.Invoice, error)
+	// invoice order milik email, dibuat dulu kalau belum ada
+	ForOrder(orderID, email string) (*domain.Invoice, error)
+	ListByEmail(email string) ([]domain.Invoice, error)
+	// payment dan refund email di bulan month (2006-01, kosong = bulan ini)
+	Statement(email, month string) (*domain.Statement, error)
+	// buat invoice saat transaksi dibayar atau order cart dibuat
+	HandleEvent(ctx context.Context, env events.Envelope) error
+}
+
+type invoiceService struct {
+	repo         infra.InvoiceRepository
+	transactions infra.TransactionRepository
+	orders       infra.OrderRepository
+	products     *infra.ProductRepo
+	now          func() time.Time
+	payments     *PaymentClient
+}
+
+func NewInvoiceService(repo infra.InvoiceRepository, transactions infra.TransactionRepository, orders infra.OrderRepository, products *infra.ProductRepo, payments *PaymentClient) InvoiceService {
+	return &invoiceService{repo: repo, transactions: transactions, orders: orders, products: products, now: time.Now, payments: payments}
+}
+
+// subscribe event transaksi dan order untuk membuat invoice. Generate
+// idempotent, jadi event yang terkirim ulang tidak membuat invoice ganda.
+func SubscribeInvoiceEvents(broker events.Broker, service InvoiceService) error {
+	for _, subject := range []string{"transaction.>", "order.>"} {
+		if _, err := broker.Subscribe(subject, "invoice", service.HandleEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *invoiceService) HandleEvent(ctx context.Context, env events.Envelope) error {
+	var transactionID, orderID string
+	switch env.Type {
+	case events.TypeTransactionCreated:
+		var payload events.TransactionCreated
+		if err := env.Decode(&payload); err != nil {
+			slog.WarnContext(ctx, "invoice: drop undecodable event", "event_type", env.Type, "event_id", env.ID, "error", err)
+			return nil
+		}
+		if payload.Status != domain.TransactionPaid {
+			return nil
+		}
+		transactionID = payload.TransactionID
+	case events.TypeTransactionStatusChanged:
+		var payload events.TransactionStatusChanged
+		if err := env.Decode(&payload); err != nil {
+			slog.WarnContext(ctx, "invoice: drop undecodable event", "event_type", env.Type, "event_id", env.ID, "error", err)
+			return nil
+		}
+		if payload.To != domain.TransactionPaid {
+			return nil
+		}
+		transactionID = payload.TransactionID
+	case events.TypeOrderCreated:
+		// order cart baru dibuat setelah payment berhasil
+		var payload events.OrderCreated
+		if err := env.Decode(&payload); err != nil {
+			slog.WarnContext(ctx, "invoice: drop undecodable event", "event_type", env.Type, "event_id", env.ID, "error", err)
+			return nil
+		}
+		orderID = payload.OrderID
+	default:
+		return nil
+	}
+
+	var err error
+	if orderID != "" {
+		_, err = s.GenerateForOrder(orderID)
+	} else {
+		_, err = s.Generate(transactionID)
+	}
+	switch err {
+	case nil:
+		return nil
+	case ErrTransactionNotFound, ErrInvalidTransactionID, ErrInvoiceNotPaid, ErrOrderNotFound, ErrInvalidOrderID:
+		// tidak akan berhasil walaupun dicoba ulang
+		slog.WarnContext(ctx, "invoice not generated", "transaction_id", transactionID, "order_id", orderID, "error", err)
+		return nil
+	}
+	return err
+}
+
+func (s *invoiceService) Generate(transactionID string) (*domain.Invoice, error) {
+	existing, err := s.repo.FindByTransaction(transactionID)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	transaction, err := s.transactions.FindByID(transactionID)
+	if err != nil {
+		return nil, ErrInvalidTransactionID
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	paidAt, ok := paidAt(transaction)
+	if !ok {
+		return nil, ErrInvoiceNotPaid
+	}
+
+	return s.insert(s.buildInvoice(transaction, paidAt), func() (*domain.Invoice, error) {
+		return s.repo.FindByTransaction(transactionID)
+	})
+}
+
+func (s *invoiceService) GenerateForOrder(orderID string) (*domain.Invoice, error) {
+	existing, err := s.repo.FindByOrder(orderID)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	order, err := s.orders.FindByID(orderID)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidOrderID) {
+			return nil, ErrInvalidOrderID
+		}
+		return nil, ErrFailedDecode
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	// order hanya disimpan setelah payment berhasil
+	if order.Status != domain.OrderStatusPaid {
+		return nil, ErrInvoiceNotPaid
+	}
+
+	return s.insert(s.buildOrderInvoice(order), func() (*domain.Invoice, error) {
+		return s.repo.FindByOrder(orderID)
+	})
+}
+
+// simpan invoice baru. Kalau invoice yang sama sudah dibuat bersamaan oleh
+// request/event lain, invoice itu yang dikembalikan.
+func (s *invoiceService) insert(invoice *domain.Invoice, findExisting func() (*domain.Invoice, error)) (*domain.Invoice, error) {
+	if err := s.repo.Insert(invoice); err != nil {
+		if errors.Is(err, infra.ErrInvoiceExists) {
+			if existing, findErr := findExisting(); findErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
+		return nil, ErrInvoiceInsert
+	}
+	return invoice, nil
+}
+
+// waktu transaksi dibayar dari riwayat status. Transaksi yang dibatalkan
+// setelah dibayar tetap punya invoice.
+func paidAt(t *domain.Transaction) (time.Time, bool) {
+	for _, change := range t.StatusHistory {
+		if change.To == domain.TransactionPaid {
+			return change.At, true
+		}
+	}
+	// transaksi lama tanpa riwayat status
+	switch t.Status {
+	case domain.TransactionPaid, domain.TransactionPacked, domain.TransactionShipped,
+		domain.TransactionDelivered, domain.TransactionReturned:
+		return t.CreatedAt, true
+	}
+	return time.Time{}, false
+}
+
+// isi invoice dari transaksi, baris item dari product dan rincian pricing
+func (s *invoiceService) buildInvoice(t *domain.Transaction, paidAt time.Time) *domain.Invoice {
+	breakdown := t.Pricing
+	// transaksi lama tanpa rincian pricing
+	if breakdown.Total.IsZero() && breakdown.Subtotal.IsZero() {
+		breakdown.Subtotal = t.Total
+		breakdown.Total = t.Total
+	}
+	currency := breakdown.Total.Currency
+
+	description := "Product " + t.ProductID
+	if product, err := s.products.GetProductByID(t.ProductID); err == nil {
+		description = product.Name
+	}
+	unitPrice := breakdown.Subtotal
+	if t.Quantity > 0 {
+		unitPrice = money.New(breakdown.Subtotal.MinorUnits/int64(t.Quantity), currency)
+	}
+
+	return &domain.Invoice{
+		TransactionID: t.ID,
+		Email:         t.Email,
+		PaymentID:     t.PaymentID,
+		PaymentMethod: t.PaymentMethod,
+		Lines: []domain.InvoiceLine{{
+			Description: description,
+			ProductID:   t.ProductID,
+			Quantity:    t.Quantity,
+			UnitPrice:   unitPrice,
+			Amount:      breakdown.Subtotal,
+		}},
+		Subtotal: breakdown.Subtotal,
+		Discount: zeroIfEmpty(breakdown.Discount, currency),
+		Tax:      zeroIfEmpty(breakdown.Tax, currency),
+		TaxRate:  breakdown.TaxRate,
+		Shipping: zeroIfEmpty(breakdown.Shipping, currency),
+		Total:    breakdown.Total,
+		PaidAt:   paidAt,
+		IssuedAt: s.now(),
+	}
+}
+
+// isi invoice dari order cart, satu baris per item dengan harga saat checkout
+func (s *invoiceService) buildOrderInvoice(order *domain.Order) *domain.Invoice {
+	breakdown := order.Pricing
+	currency := breakdown.Total.Currency
+
+	lines := make([]domain.InvoiceLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, domain.InvoiceLine{
+			Description: item.Name,
+			ProductID:   item.ProductID,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Subtotal,
+		})
+	}
+
+	return &domain.Invoice{
+		OrderID:   order.ID.Hex(),
+		Email:     order.Email,
+		PaymentID: order.PaymentID,
+		Lines:     lines,
+		Subtotal:  breakdown.Subtotal,
+		Discount:  zeroIfEmpty(breakdown.Discount, currency),
+		Tax:       zeroIfEmpty(breakdown.Tax, currency),
+		TaxRate:   breakdown.TaxRate,
+		Shipping:  zeroIfEmpty(breakdown.Shipping, currency),
+		Total:     breakdown.Total,
+		// order dibuat sesaat setelah payment berhasil
+		PaidAt:   order.CreatedAt,
+		IssuedAt: s.now(),
+	}
+}
+
+// nominal kosong (currency belum diisi) jadi nol dengan currency invoice
+func zeroIfEmpty(m money.Money, currency string) money.Money {
+	if m.Currency == "" {
+		return money.New(0, currency)
+	}
+	return m
+}
+
+func (s *invoiceService) GetByID(id, email string) (*domain.Invoice, error) {
+	invoice, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidInvoiceID) {
+			return nil, ErrInvalidInvoiceID
+		}
+		return nil, ErrFailedDecode
+	}
+	if invoice == nil || invoice.Email != email {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *invoiceService) ForTransaction(transactionID, email string) (*domain.Invoice, error) {
+	transaction, err := s.transactions.FindByID(transactionID)
+	if err != nil {
+		return nil, ErrInvalidTransactionID
+	}
+	if transaction == nil || transaction.Email != email {
+		return nil, ErrTransactionNotFound
+	}
+	return s.Generate(transactionID)
+}
+
+func (s *invoiceService) ForOrder(orderID, email string) (*domain.Invoice, error) {
+	order, err := s.orders.FindByID(orderID)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidOrderID) {
+			return nil, ErrInvalidOrderID
+		}
+		return nil, ErrFailedDecode
+	}
+	if order == nil || order.Email != email {
+		return nil, ErrOrderNotFound
+	}
+	return s.GenerateForOrder(orderID)
+}
+
+func (s *invoiceService) ListByEmail(email string) ([]domain.Invoice, error) {
+	invoices, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return invoices, nil
+}
+
+// Payment yang dibuat dalam bulan itu dan refund yang diproses dalam bulan
+// itu (termasuk refund atas payment bulan sebelumnya). Periode memakai UTC.
+func (s *invoiceService) Statement(email, month string) (*domain.Statement, error) {
+	var from time.Time
+	if month == "" {
+		now := s.now().UTC()
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	} else {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			return nil, ErrStatementMonth
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 1, 0)
+
+	statement := &domain.Statement{
+		Email:   email,
+		Month:   from.Format("2006-01"),
+		From:    from,
+		To:      to,
+		Entries: []domain.StatementEntry{},
+		Totals:  []domain.StatementTotal{},
+	}
+
+	// refund bisa terjadi jauh setelah payment, jadi semua payment sampai
+	// akhir periode diambil
+	filter := paymentFilter{Email: email, CreatedTo: to}
+
+	inPeriod := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	after := ""
+	for {
+		payments, next, err := s.payments.listPayments(context.Background(), filter, after, statementPageSize)
+		if err != nil {
+			slog.Error("list payments for statement failed", "email", email, "error", err)
+			return nil, ErrPaymentRequest
+		}
+		for _, p := range payments {
+			if inPeriod(p.CreatedAt) {
+				statement.Entries = append(statement.Entries, domain.StatementEntry{
+					Date:      p.CreatedAt,
+					Kind:      domain.StatementPayment,
+					PaymentID: p.ID,
+					Method:    p.Method,
+					Status:    p.Status,
+					Amount:    p.Amount,
+				})
+			}
+			for _, r := range p.Refunds {
+				if r.succeeded() && inPeriod(r.CreatedAt) {
+					statement.Entries = append(statement.Entries, domain.StatementEntry{
+						Date:      r.CreatedAt,
+						Kind:      domain.StatementRefund,
+						PaymentID: p.ID,
+						RefundID:  r.ID,
+						Reason:    r.Reason,
+						Amount:    r.Amount,
+					})
+				}
+			}
+		}
+		if next == "" {
+			break
+		}
+		after = next
+	}
+
+	sort.SliceStable(statement.Entries, func(i, j int) bool {
+		return statement.Entries[i].Date.Before(statement.Entries[j].Date)
+	})
+	statement.Totals = statementTotals(statement.Entries)
+	return statement, nil
+}
+
+// total paid, refunded dan net per currency, urut currency
+func statementTotals(entries []domain.StatementEntry) []domain.StatementTotal {
+	byCurrency := map[string]*domain.StatementTotal{}
+	currencies := []string{}
+	for _, e := range entries {
+		currency := e.Amount.Currency
+		total, ok := byCurrency[currency]
+		if !ok {
+			total = &domain.StatementTotal{
+				Currency: currency,
+				Paid:     money.New(0, currency),
+				Refunded: money.New(0, currency),
+			}
+			byCurrency[currency] = total
+			currencies = append(currencies, currency)
+		}
+		if e.Kind == domain.StatementRefund {
+			total.Refunded.MinorUnits += e.Amount.MinorUnits
+		} else {
+			total.Paid.MinorUnits += e.Amount.MinorUnits
+		}
+	}
+
+	sort.Strings(currencies)
+	totals := make([]domain.StatementTotal, 0, len(currencies))
+	for _, currency := range currencies {
+		total := byCurrency[currency]
+		total.Net = money.New(total.Paid.MinorUnits-total.Refunded.MinorUnits, currency)
+		totals = append(totals, *total)
+	}
+	return totals
+}
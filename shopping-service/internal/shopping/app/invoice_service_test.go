@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shared/events"
+	"shared/money"
+	"shopping-service/internal/pricing"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+	"shopping-service/proto"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type memoryInvoiceRepo struct {
+	infra.InvoiceRepository
+	invoices []domain.Invoice
+}
+
+func (r *memoryInvoiceRepo) Insert(invoice *domain.Invoice) error {
+	invoice.ID = primitive.NewObjectID()
+	invoice.Year = domain.InvoiceYear(invoice.IssuedAt)
+	invoice.Sequence = int64(len(r.invoices) + 1)
+	invoice.Number = domain.InvoiceNumber(invoice.Year, invoice.Sequence)
+	r.invoices = append(r.invoices, *invoice)
+	return nil
+}
+
+func (r *memoryInvoiceRepo) FindByOrder(orderID string) (*domain.Invoice, error) {
+	for i := range r.invoices {
+		if r.invoices[i].OrderID == orderID {
+			return &r.invoices[i], nil
+		}
+	}
+	return nil, nil
+}
+
+type memoryOrders struct {
+	infra.OrderRepository
+	items []domain.Order
+}
+
+func (m *memoryOrders) FindByID(id string) (*domain.Order, error) {
+	for i := range m.items {
+		if m.items[i].ID.Hex() == id {
+			return &m.items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func TestStatement_ReadsPaymentsOverGRPC(t *testing.T) {
+	at := func(s string) *timestamppb.Timestamp {
+		parsed, _ := time.Parse(time.RFC3339, s)
+		return timestamppb.New(parsed)
+	}
+	idr := func(v int64) *proto.Money { return &proto.Money{MinorUnits: v, Currency: "IDR"} }
+
+	payments := &fakePaymentServer{payments: []*proto.Payment{
+		// payment bulan lalu, refund bulan ini ikut statement
+		{Id: "pay-1", Amount: idr(10000), Status: "partially_refunded", CreatedAt: at("2026-08-30T10:00:00Z"), Refunds: []*proto.Refund{
+			{Id: "ref-1", Amount: idr(4000), Status: "succeeded", CreatedAt: at("2026-09-02T10:00:00Z")},
+		}},
+		// refund pending belum mengembalikan dana
+		{Id: "pay-2", Amount: idr(5000), Status: "paid", CreatedAt: at("2026-09-10T10:00:00Z"), Refunds: []*proto.Refund{
+			{Id: "ref-2", Amount: idr(5000), Status: "pending", CreatedAt: at("2026-09-11T10:00:00Z")},
+		}},
+	}}
+	service := NewInvoiceService(nil, nil, nil, nil, newTestPaymentClient(t, payments))
+
+	statement, err := service.Statement("buyer@example.com", "2026-09")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, payments.filters, 1) {
+		assert.Equal(t, "buyer@example.com", payments.filters[0].GetEmail())
+		assert.Equal(t, "2026-10-01T00:00:00Z", payments.filters[0].GetCreatedTo().AsTime().Format(time.RFC3339))
+	}
+	if assert.Len(t, statement.Entries, 2) {
+		assert.Equal(t, "ref-1", statement.Entries[0].RefundID)
+		assert.Equal(t, "pay-2", statement.Entries[1].PaymentID)
+	}
+	assert.Equal(t, []domain.StatementTotal{{
+		Currency: "IDR",
+		Paid:     money.New(5000, "IDR"),
+		Refunded: money.New(4000, "IDR"),
+		Net:      money.New(1000, "IDR"),
+	}}, statement.Totals)
+}
+
+func TestInvoicePaidAt(t *testing.T) {
+	created := time.Date(2025, 12, 31, 22, 0, 0, 0, time.UTC)
+	paid := time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)
+	history := []domain.TransactionStatusChange{
+		{To: domain.TransactionPaid, At: paid},
+		{From: domain.TransactionPaid, To: domain.TransactionCancelled, At: paid.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name        string
+		transaction domain.Transaction
+		want        time.Time
+		wantOK      bool
+	}{
+		{name: "dari riwayat status", transaction: domain.Transaction{Status: domain.TransactionCancelled, CreatedAt: created, StatusHistory: history}, want: paid, wantOK: true},
+		{name: "transaksi lama tanpa riwayat", transaction: domain.Transaction{Status: domain.TransactionDelivered, CreatedAt: created}, want: created, wantOK: true},
+		{name: "belum dibayar", transaction: domain.Transaction{Status: domain.TransactionPendingPayment, CreatedAt: created}},
+		{name: "batal sebelum dibayar", transaction: domain.Transaction{Status: domain.TransactionCancelled, CreatedAt: created}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := paidAt(&tt.transaction)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInvoiceHandleEvent_OrderCreated(t *testing.T) {
+	paid := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	order := domain.Order{
+		ID:    primitive.NewObjectID(),
+		Email: "buyer@example.com",
+		Items: []domain.OrderItem{
+			{ProductID: "p1", Name: "Kopi", UnitPrice: money.New(5000, "IDR"), Quantity: 2, Subtotal: money.New(10000, "IDR")},
+			{ProductID: "p2", Name: "Teh", UnitPrice: money.New(3000, "IDR"), Quantity: 1, Subtotal: money.New(3000, "IDR")},
+		},
+		Pricing: pricing.Breakdown{
+			Subtotal: money.New(13000, "IDR"),
+			Discount: money.New(1000, "IDR"),
+			Total:    money.New(12000, "IDR"),
+		},
+		PaymentID: "pay-1",
+		Status:    domain.OrderStatusPaid,
+		CreatedAt: paid,
+	}
+	repo := &memoryInvoiceRepo{}
+	service := NewInvoiceService(repo, nil, &memoryOrders{items: []domain.Order{order}}, nil, nil)
+
+	env, err := events.NewEnvelope(events.TypeOrderCreated, events.VersionOrderCreated, "test", events.OrderCreated{OrderID: order.ID.Hex(), PaymentID: "pay-1", Email: order.Email})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// event terkirim ulang tidak membuat invoice ganda
+	assert.NoError(t, service.HandleEvent(context.Background(), env))
+	assert.NoError(t, service.HandleEvent(context.Background(), env))
+
+	if !assert.Len(t, repo.invoices, 1) {
+		return
+	}
+	invoice := repo.invoices[0]
+	assert.Equal(t, order.ID.Hex(), invoice.OrderID)
+	assert.Empty(t, invoice.TransactionID)
+	assert.Equal(t, "pay-1", invoice.PaymentID)
+	assert.Equal(t, paid, invoice.PaidAt)
+	assert.Len(t, invoice.Lines, 2)
+	assert.Equal(t, money.New(12000, "IDR"), invoice.Total)
+	assert.Equal(t, money.New(0, "IDR"), invoice.Tax)
+
+	// order milik buyer lain dianggap tidak ada
+	_, err = service.ForOrder(order.ID.Hex(), "other@example.com")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	got, err := service.ForOrder(order.ID.Hex(), order.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, invoice.Number, got.Number)
+}
@@ -0,0 +1,13 @@
+package app
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// transaksi yang masuk ke tiap status (paid saat dibuat, lalu tiap
+// perpindahan status)
+var transactionsByStatus = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "shopping_transactions_total",
+	Help: "Jumlah transaksi yang masuk ke status tertentu.",
+}, []string{"status"})
@@ -0,0 +1,303 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"shared/events"
+	"shared/money"
+	"shopping-service/internal/pricing"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// data pembayaran yang dipakai saat checkout
+type CheckoutInput struct {
+	PaymentMethod string
+	CardToken     string
+	CouponCode    string
+	// tujuan pajak dan metode ongkir, kosong berarti default dari config pricing
+	Region         string
+	ShippingMethod string
+}
+
+type OrderService interface {
+	Checkout(ctx context.Context, email string, input CheckoutInput) (*domain.Order, error)
+	GetOrders(email string) ([]domain.Order, error)
+	GetOrder(email, id string) (*domain.Order, error)
+}
+
+type orderService struct {
+	repo       infra.OrderRepository
+	carts      infra.CartRepository
+	products   *infra.ProductRepo
+	inventory  InventoryService
+	promotions PromotionService
+	pricing    *pricing.Pipeline
+	publisher  events.Publisher
+	payments   *PaymentClient
+}
+
+func NewOrderService(repo infra.OrderRepository, carts infra.CartRepository, products *infra.ProductRepo, inventory InventoryService, promotions PromotionService, pricer *pricing.Pipeline, publisher events.Publisher, payments *PaymentClient) OrderService {
+	return &orderService{repo: repo, carts: carts, products: products, inventory: inventory, promotions: promotions, pricing: pricer, publisher: publisher, payments: payments}
+}
+
+// Checkout mengubah cart jadi order: cek harga dan stok terbaru, tahan
+// stok lewat reservasi, bayar total dengan satu payment, lalu reservasi
+// jadi penjualan dan cart dikosongkan. Kupon (opsional) dipesan sebelum
+// stok ditahan, lalu total dihitung pipeline pricing (subtotal, discount,
+// pajak, ongkir). Kalau ada langkah yang gagal, reservasi dan pemakaian
+// kupon dilepas, dan kalau gagal setelah payment berhasil payment
+// di-refund. Reservasi yang tertinggal (misalnya proses mati di tengah
+// checkout) dilepas cron inventory setelah expired. Request ID dan trace
+// dari ctx ikut ke Payment Service, tapi checkout tetap diselesaikan walau
+// client memutus request.
+func (s *orderService) Checkout(ctx context.Context, email string, input CheckoutInput) (*domain.Order, error) {
+	ctx = context.WithoutCancel(ctx)
+
+	cart, err := s.carts.FindByEmail(email)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	// kunci harga saat ini ke item order
+	// ID dibuat di awal supaya ledger stok bisa merujuk order
+	order := &domain.Order{ID: primitive.NewObjectID(), Email: email}
+	for _, item := range cart.Items {
+		product, err := s.products.GetProductByID(item.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrProductNotFound, item.ProductID)
+		}
+		if product.Available() < item.Quantity {
+			return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, product.Name)
+		}
+
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductID:   item.ProductID,
+			Name:        product.Name,
+			UnitPrice:   product.Price,
+			Quantity:    item.Quantity,
+			WeightGrams: product.WeightGrams,
+			Subtotal:    product.Price.Mul(int64(item.Quantity)),
+			Discount:    money.New(0, product.Price.Currency),
+		})
+	}
+
+	if err := sameCurrency(order.Items); err != nil {
+		return nil, err
+	}
+
+	applied, err := s.applyCoupon(email, input.CouponCode, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.priceOrder(order, input); err != nil {
+		s.promotions.Release(applied)
+		return nil, err
+	}
+
+	// tahan stok secara atomic, stok bisa berubah sejak dicek di atas
+	reserved := make([]*domain.StockReservation, 0, len(order.Items))
+	for _, item := range order.Items {
+		reservation, err := s.inventory.Reserve(item.ProductID, item.Quantity, email)
+		if err != nil {
+			s.releaseStock(ctx, reserved)
+			s.promotions.Release(applied)
+			if err == ErrInsufficientStock {
+				return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, item.Name)
+			}
+			return nil, err
+		}
+		reserved = append(reserved, reservation)
+	}
+
+	// satu payment untuk seluruh order
+	var status string
+	if err := s.payments.callPayment(ctx, email, order.Total, input.PaymentMethod, input.CardToken, &order.PaymentID, &status); err != nil {
+		s.releaseStock(ctx, reserved)
+		s.promotions.Release(applied)
+		return nil, ErrPaymentRequest
+	}
+	if !paymentSucceeded(status) {
+		s.releaseStock(ctx, reserved)
+		s.promotions.Release(applied)
+		return nil, ErrPaymentFailed
+	}
+
+	// payment sudah terjadi, reservasi jadi penjualan. Reservasi yang gagal
+	// di-commit (misalnya expired karena payment terlalu lama) stoknya bisa
+	// sudah dipakai checkout lain, jadi order dibatalkan.
+	committed, err := s.commitStock(ctx, reserved, order.ID.Hex())
+	if err != nil {
+		return nil, s.revert(ctx, order, reserved, committed, applied, err)
+	}
+
+	order.Status = domain.OrderStatusPaid
+	if err := s.repo.Insert(order); err != nil {
+		slog.ErrorContext(ctx, "insert order failed after payment", "payment_id", order.PaymentID, "error", err)
+		return nil, s.revert(ctx, order, reserved, committed, applied, ErrOrderInsert)
+	}
+
+	s.promotions.Confirm(applied, order.ID.Hex())
+
+	if err := s.carts.Clear(email); err != nil {
+		slog.ErrorContext(ctx, "clear cart after order failed", "email", email, "order_id", order.ID.Hex(), "error", err)
+	}
+
+	s.publishOrder(ctx, order)
+
+	return order, nil
+}
+
+// ambil semua order milik user
+func (s *orderService) GetOrders(email string) ([]domain.Order, error) {
+	orders, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return orders, nil
+}
+
+// ambil order by ID, hanya kalau milik user
+func (s *orderService) GetOrder(email, id string) (*domain.Order, error) {
+	order, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidOrderID) {
+			return nil, ErrInvalidOrderID
+		}
+		return nil, ErrFailedDecode
+	}
+	if order == nil || order.Email != email {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// Pasang kupon ke order: isi discount per item. Tanpa kupon discount item
+// tetap nol.
+func (s *orderService) applyCoupon(email, code string, order *domain.Order) (*AppliedPromotion, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	lines := make([]PromotionLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, PromotionLine{ProductID: item.ProductID, UnitPrice: item.UnitPrice, Quantity: item.Quantity})
+	}
+	applied, err := s.promotions.Apply(email, code, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range order.Items {
+		order.Items[i].Discount = applied.LineDiscounts[i]
+	}
+	order.CouponCode = applied.Promotion.Code
+	return applied, nil
+}
+
+// hitung rincian harga order, total yang dibayar = Pricing.Total
+func (s *orderService) priceOrder(order *domain.Order, input CheckoutInput) error {
+	lines := make([]pricing.Line, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, pricing.Line{
+			ProductID:   item.ProductID,
+			UnitPrice:   item.UnitPrice,
+			Quantity:    item.Quantity,
+			WeightGrams: item.WeightGrams,
+			Discount:    item.Discount,
+		})
+	}
+
+	breakdown, err := priceQuote(s.pricing, pricing.Quote{
+		Region:         input.Region,
+		ShippingMethod: input.ShippingMethod,
+		Lines:          lines,
+	})
+	if err != nil {
+		return err
+	}
+	order.Pricing = breakdown
+	order.Total = breakdown.Total
+	return nil
+}
+
+// semua item order harus satu currency
+func sameCurrency(items []domain.OrderItem) error {
+	subtotals := make([]money.Money, 0, len(items))
+	for _, item := range items {
+		subtotals = append(subtotals, item.Subtotal)
+	}
+
+	if _, err := money.Sum(items[0].UnitPrice.Currency, subtotals...); err != nil {
+		return ErrMixedCurrency
+	}
+	return nil
+}
+
+// lepas reservasi checkout yang batal
+func (s *orderService) releaseStock(ctx context.Context, reserved []*domain.StockReservation) {
+	for _, r := range reserved {
+		// reservasi yang sudah expired sudah dilepas cron
+		if err := s.inventory.Release(r); err != nil && !errors.Is(err, ErrReservationNotActive) {
+			slog.ErrorContext(ctx, "release reservation failed", "reservation_id", r.ID.Hex(), "product_id", r.ProductID, "quantity", r.Quantity, "error", err)
+		}
+	}
+}
+
+// Reservasi jadi penjualan sesuai urutan. Berhenti di reservasi pertama
+// yang gagal, hasilnya jumlah reservasi yang sudah di-commit.
+func (s *orderService) commitStock(ctx context.Context, reserved []*domain.StockReservation, orderID string) (int, error) {
+	for i, r := range reserved {
+		if err := s.inventory.Commit(r, orderID); err != nil {
+			slog.ErrorContext(ctx, "commit reservation failed", "reservation_id", r.ID.Hex(), "order_id", orderID, "error", err)
+			return i, err
+		}
+	}
+	return len(reserved), nil
+}
+
+// Batalkan checkout yang gagal setelah payment berhasil: stok yang sudah
+// terjual dikembalikan, reservasi sisanya dan kupon dilepas, lalu payment
+// di-refund penuh. Refund yang gagal dicatat dan muncul di rekonsiliasi.
+func (s *orderService) revert(ctx context.Context, order *domain.Order, reserved []*domain.StockReservation, committed int, applied *AppliedPromotion, cause error) error {
+	for _, r := range reserved[:committed] {
+		if _, err := s.inventory.Return(r.ProductID, r.Quantity, order.ID.Hex(), "checkout reverted"); err != nil {
+			slog.ErrorContext(ctx, "return stock of reverted checkout failed", "product_id", r.ProductID, "quantity", r.Quantity, "order_id", order.ID.Hex(), "error", err)
+		}
+	}
+	s.releaseStock(ctx, reserved[committed:])
+	s.promotions.Release(applied)
+
+	if err := s.payments.revertPayment(ctx, order.Email, order.PaymentID, order.Total); err != nil {
+		slog.ErrorContext(ctx, "refund of reverted checkout failed", "payment_id", order.PaymentID, "order_id", order.ID.Hex(), "error", err)
+		return fmt.Errorf("%w: %w", ErrCheckoutRefundFailed, cause)
+	}
+	return fmt.Errorf("%w: %w", ErrCheckoutReverted, cause)
+}
+
+func (s *orderService) publishOrder(ctx context.Context, order *domain.Order) {
+	items := make([]events.OrderCreatedItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, events.OrderCreatedItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		})
+	}
+	publish(ctx, s.publisher, events.TypeOrderCreated, events.VersionOrderCreated, events.OrderCreated{
+		OrderID:   order.ID.Hex(),
+		PaymentID: order.PaymentID,
+		Email:     order.Email,
+		Items:     items,
+		Total:     order.Total,
+	})
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inventory yang mencatat commit/release/return, commit gagal untuk
+// product di failCommit
+type recordingInventory struct {
+	InventoryService
+	failCommit string
+	committed  []string
+	released   []string
+	returned   []string
+}
+
+func (r *recordingInventory) Commit(reservation *domain.StockReservation, reference string) error {
+	if reservation.ProductID == r.failCommit {
+		return ErrReservationNotActive
+	}
+	r.committed = append(r.committed, reservation.ProductID)
+	return nil
+}
+
+func (r *recordingInventory) Release(reservation *domain.StockReservation) error {
+	r.released = append(r.released, reservation.ProductID)
+	return nil
+}
+
+func (r *recordingInventory) Return(productID string, quantity int, reference, note string) (*domain.Product, error) {
+	r.returned = append(r.returned, productID)
+	return nil, nil
+}
+
+type recordingPromotions struct {
+	PromotionService
+	released int
+}
+
+func (r *recordingPromotions) Release(applied *AppliedPromotion) {
+	if applied != nil {
+		r.released++
+	}
+}
+
+func newRevertFixture(t *testing.T, payments *fakePaymentServer, failCommit string) (*orderService, *recordingInventory, *recordingPromotions, *domain.Order, []*domain.StockReservation) {
+	inventory := &recordingInventory{failCommit: failCommit}
+	promotions := &recordingPromotions{}
+	service := &orderService{inventory: inventory, promotions: promotions, payments: newTestPaymentClient(t, payments)}
+
+	order := &domain.Order{ID: primitive.NewObjectID(), Email: "buyer@example.com", PaymentID: "pay-1", Total: money.New(30000, "IDR")}
+	reserved := []*domain.StockReservation{
+		{ID: primitive.NewObjectID(), ProductID: "prod-1", Quantity: 1},
+		{ID: primitive.NewObjectID(), ProductID: "prod-2", Quantity: 2},
+		{ID: primitive.NewObjectID(), ProductID: "prod-3", Quantity: 1},
+	}
+	return service, inventory, promotions, order, reserved
+}
+
+func TestCheckoutRevert_CommitFailureRefundsPayment(t *testing.T) {
+	payments := &fakePaymentServer{}
+	service, inventory, promotions, order, reserved := newRevertFixture(t, payments, "prod-2")
+
+	committed, err := service.commitStock(context.TODO(), reserved, order.ID.Hex())
+	assert.ErrorIs(t, err, ErrReservationNotActive)
+	assert.Equal(t, 1, committed)
+
+	err = service.revert(context.TODO(), order, reserved, committed, &AppliedPromotion{}, err)
+
+	assert.ErrorIs(t, err, ErrCheckoutReverted)
+	assert.ErrorIs(t, err, ErrReservationNotActive)
+	// yang sudah terjual dikembalikan, sisanya dilepas
+	assert.Equal(t, []string{"prod-1"}, inventory.returned)
+	assert.Equal(t, []string{"prod-2", "prod-3"}, inventory.released)
+	assert.Equal(t, 1, promotions.released)
+	if assert.Len(t, payments.refunds, 1) {
+		assert.Equal(t, "pay-1", payments.refunds[0].GetId())
+		assert.Equal(t, "revert-pay-1", payments.refunds[0].GetReference())
+		assert.Equal(t, int64(30000), payments.refunds[0].GetAmount().GetMinorUnits())
+	}
+	assert.Equal(t, []string{"buyer@example.com"}, payments.actors)
+}
+
+func TestCheckoutRevert_RefundFailure(t *testing.T) {
+	payments := &fakePaymentServer{failWith: status.Error(codes.Unavailable, "payment down")}
+	service, inventory, _, order, reserved := newRevertFixture(t, payments, "")
+
+	err := service.revert(context.TODO(), order, reserved, len(reserved), nil, ErrOrderInsert)
+
+	assert.ErrorIs(t, err, ErrCheckoutRefundFailed)
+	assert.ErrorIs(t, err, ErrOrderInsert)
+	assert.Equal(t, []string{"prod-1", "prod-2", "prod-3"}, inventory.returned)
+	assert.Empty(t, inventory.released)
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shared/audit"
+	"shared/money"
+	"shopping-service/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	paymentCallTimeout = 10 * time.Second
+	paymentPageTimeout = 30 * time.Second
+
+	// metadata actor yang dicatat payment-service di audit log
+	metadataUserEmail = "x-user-email"
+)
+
+// PaymentClient memanggil Payment Service lewat gRPC. Request ID dari ctx
+// dikirim interceptor koneksi, email actor (audit.ActorFrom) lewat metadata.
+type PaymentClient struct {
+	client proto.PaymentServiceClient
+}
+
+func NewPaymentClient(client proto.PaymentServiceClient) *PaymentClient {
+	return &PaymentClient{client: client}
+}
+
+// panggil Payment Service untuk membayar amount. status diisi "failed"
+// kalau payment ditolak, error hanya untuk kegagalan koneksi/provider.
+func (p *PaymentClient) callPayment(ctx context.Context, email string, amount money.Money, method, cardToken string, paymentID *string, status *string) error {
+	ctx, cancel := context.WithTimeout(ctx, paymentCallTimeout)
+	defer cancel()
+
+	// pembeli yang membayar dicatat sebagai actor
+	ctx = metadata.AppendToOutgoingContext(ctx, metadataUserEmail, email)
+	result, err := p.client.AddPayment(ctx, &proto.AddPaymentRequest{
+		Email:     email,
+		Amount:    toMoneyPB(amount),
+		Method:    method,
+		CardToken: cardToken,
+	})
+	if err != nil {
+		// input ditolak atau payment di-decline provider
+		switch grpcCode(err) {
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			*status = "failed"
+			return nil
+		}
+		return err
+	}
+
+	*status = result.GetStatus()
+	*paymentID = result.GetId()
+	return nil
+}
+
+// Minta Payment Service me-refund amount dari paymentID. reference dipakai
+// sebagai kunci idempotensi, refund ulang dengan reference sama tidak
+// mengembalikan dana dua kali. Hasilnya ID refund di payment.
+func (p *PaymentClient) callRefund(ctx context.Context, paymentID string, amount money.Money, reason, reference string) (string, error) {
+	ctx, cancel := context.WithTimeout(actorContext(ctx), paymentCallTimeout)
+	defer cancel()
+
+	resp, err := p.client.RefundPayment(ctx, &proto.RefundPaymentRequest{
+		Id:        paymentID,
+		Amount:    toMoneyPB(amount),
+		Reason:    reason,
+		Reference: reference,
+	})
+	if err != nil {
+		// pesan error dari payment diteruskan tanpa prefix rpc error
+		return "", statusError(err)
+	}
+	return resp.GetRefund().GetId(), nil
+}
+
+// Refund penuh payment yang transaksi/order-nya gagal dibuat. Pembeli
+// dicatat sebagai actor, reference per payment supaya retry tidak
+// me-refund dua kali.
+func (p *PaymentClient) revertPayment(ctx context.Context, email, paymentID string, amount money.Money) error {
+	ctx = audit.WithActor(ctx, audit.Actor{Email: email})
+	_, err := p.callRefund(ctx, paymentID, amount, "checkout reverted", "revert-"+paymentID)
+	return err
+}
+
+// payment dari list Payment Service, hanya field yang dipakai rekonsiliasi
+// dan statement
+type paymentRecord struct {
+	ID        string
+	Amount    money.Money
+	Status    string
+	Method    string
+	CreatedAt time.Time
+	Refunds   []refundRecord
+}
+
+// refund di dalam payment, refund pending belum dikembalikan provider
+type refundRecord struct {
+	ID        string
+	Amount    money.Money
+	Reason    string
+	Status    string
+	CreatedAt time.Time
+}
+
+// filter list payment, field kosong diabaikan
+type paymentFilter struct {
+	Email     string
+	CreatedTo time.Time
+}
+
+// Ambil satu halaman payment urut ID setelah after (kosong untuk halaman
+// pertama). next kosong berarti sudah halaman terakhir.
+func (p *PaymentClient) listPayments(ctx context.Context, filter paymentFilter, after string, limit int) (payments []paymentRecord, next string, err error) {
+	ctx, cancel := context.WithTimeout(actorContext(ctx), paymentPageTimeout)
+	defer cancel()
+
+	req := &proto.GetAllPaymentsRequest{
+		Filter:    &proto.PaymentFilter{Email: filter.Email},
+		PageSize:  int64(limit),
+		PageToken: after,
+	}
+	if !filter.CreatedTo.IsZero() {
+		req.Filter.CreatedTo = timestamppb.New(filter.CreatedTo)
+	}
+
+	resp, err := p.client.GetAllPayments(ctx, req)
+	if err != nil {
+		return nil, "", statusError(err)
+	}
+
+	payments = make([]paymentRecord, 0, len(resp.GetPayments()))
+	for _, pb := range resp.GetPayments() {
+		payments = append(payments, toPaymentRecord(pb))
+	}
+	return payments, resp.GetNextPageToken(), nil
+}
+
+func toPaymentRecord(pb *proto.Payment) paymentRecord {
+	record := paymentRecord{
+		ID:     pb.GetId(),
+		Amount: toMoney(pb.GetAmount()),
+		Status: pb.GetStatus(),
+		Method: pb.GetMethod(),
+	}
+	if pb.GetCreatedAt() != nil {
+		record.CreatedAt = pb.GetCreatedAt().AsTime()
+	}
+	for _, r := range pb.GetRefunds() {
+		refund := refundRecord{
+			ID:     r.GetId(),
+			Amount: toMoney(r.GetAmount()),
+			Reason: r.GetReason(),
+			Status: r.GetStatus(),
+		}
+		if r.GetCreatedAt() != nil {
+			refund.CreatedAt = r.GetCreatedAt().AsTime()
+		}
+		record.Refunds = append(record.Refunds, refund)
+	}
+	return record
+}
+
+// refund yang dananya sudah dikembalikan provider
+func (r refundRecord) succeeded() bool {
+	return r.Status == "" || r.Status == "succeeded"
+}
+
+func toMoneyPB(m money.Money) *proto.Money {
+	return &proto.Money{MinorUnits: m.MinorUnits, Currency: m.Currency}
+}
+
+func toMoney(m *proto.Money) money.Money {
+	return money.New(m.GetMinorUnits(), m.GetCurrency())
+}
+
+// kirim email actor dari ctx sebagai metadata kalau ada
+func actorContext(ctx context.Context) context.Context {
+	if email := audit.ActorFrom(ctx).Email; email != "" {
+		return metadata.AppendToOutgoingContext(ctx, metadataUserEmail, email)
+	}
+	return ctx
+}
+
+// kode status gRPC, parameter status di callPayment menutupi nama package
+func grpcCode(err error) codes.Code {
+	return status.Code(err)
+}
+
+// error gRPC jadi error biasa berisi pesan dari Payment Service
+func statusError(err error) error {
+	return errors.New(status.Convert(err).Message())
+}
+
+// status sukses dari Payment Service ("paid", versi lama "success")
+func paymentSucceeded(status string) bool {
+	return status == "paid" || status == "success"
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"shopping-service/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Payment Service palsu, mencatat refund yang diterima lewat gRPC
+type fakePaymentServer struct {
+	proto.UnimplementedPaymentServiceServer
+
+	mu       sync.Mutex
+	refunds  []*proto.RefundPaymentRequest
+	actors   []string
+	failWith error
+
+	// data GetAllPayments urut ID, pageSizes mencatat page_size per call
+	payments  []*proto.Payment
+	pageSizes []int64
+	filters   []*proto.PaymentFilter
+}
+
+func (f *fakePaymentServer) RefundPayment(ctx context.Context, req *proto.RefundPaymentRequest) (*proto.RefundPaymentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failWith != nil {
+		return nil, f.failWith
+	}
+	f.refunds = append(f.refunds, req)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		f.actors = append(f.actors, md.Get(metadataUserEmail)...)
+	}
+	return &proto.RefundPaymentResponse{
+		Payment: &proto.Payment{Id: req.GetId(), Status: "partially_refunded"},
+		Refund:  &proto.Refund{Id: "refund-1", Amount: req.GetAmount(), Reference: req.GetReference(), Status: "succeeded"},
+	}, nil
+}
+
+// jalankan fakePaymentServer di bufconn, hasilnya client yang tersambung
+func newTestPaymentClient(t *testing.T, server proto.PaymentServiceServer) *PaymentClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	proto.RegisterPaymentServiceServer(s, server)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewPaymentClient(proto.NewPaymentServiceClient(conn))
+}
+
+func (f *fakePaymentServer) GetAllPayments(ctx context.Context, req *proto.GetAllPaymentsRequest) (*proto.GetAllPaymentsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pageSizes = append(f.pageSizes, req.GetPageSize())
+	f.filters = append(f.filters, req.GetFilter())
+
+	start := 0
+	for i, p := range f.payments {
+		if p.GetId() == req.GetPageToken() {
+			start = i + 1
+		}
+	}
+	end := min(start+int(req.GetPageSize()), len(f.payments))
+	resp := &proto.GetAllPaymentsResponse{Payments: f.payments[start:end]}
+	if end < len(f.payments) {
+		resp.NextPageToken = f.payments[end-1].GetId()
+	}
+	return resp, nil
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+
+	"shared/money"
+	"shopping-service/internal/pricing"
+)
+
+// hitung rincian harga lewat pipeline, error pipeline diterjemahkan ke error app
+func priceQuote(pipeline *pricing.Pipeline, quote pricing.Quote) (pricing.Breakdown, error) {
+	breakdown, err := pipeline.Price(quote)
+	if err != nil {
+		switch {
+		case errors.Is(err, pricing.ErrUnknownRegion):
+			return pricing.Breakdown{}, ErrRegionInvalid
+		case errors.Is(err, pricing.ErrUnknownShippingMethod):
+			return pricing.Breakdown{}, ErrShippingMethodInvalid
+		case errors.Is(err, money.ErrCurrencyMismatch):
+			return pricing.Breakdown{}, ErrMixedCurrency
+		}
+		slog.Error("pricing quote failed", "error", err)
+		return pricing.Breakdown{}, ErrPricingFailed
+	}
+	return breakdown, nil
+}
@@ -0,0 +1,515 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"shared/audit"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+// batas bulk import
+const (
+	MaxImportRows = 10000
+	// error per baris yang disimpan di job, sisanya hanya dihitung
+	maxImportErrors = 500
+	// progress job disimpan setiap sekian baris
+	importProgressEvery = 100
+	// pesan job yang berhenti karena service dimatikan
+	importInterruptedMessage = "import interrupted by service shutdown"
+)
+
+// Kolom CSV import/export. Import hanya butuh sku, kolom lain boleh tidak
+// ada: saat update kolom yang tidak ada tidak diubah.
+var ProductCSVColumns = []string{
+	"sku", "name", "description", "price_minor_units", "currency",
+	"stock", "weight_grams", "low_stock_threshold", "category_id",
+}
+
+// Satu baris export product. NDJSON memakai struct ini apa adanya, CSV
+// memakai kolom ProductCSVColumns (tanpa varian & gambar).
+type ProductRow struct {
+	SKU               string                  `json:"sku"`
+	Name              string                  `json:"name"`
+	Description       string                  `json:"description,omitempty"`
+	Price             money.Money             `json:"price"`
+	Stock             int                     `json:"stock"`
+	WeightGrams       int                     `json:"weight_grams"`
+	LowStockThreshold int                     `json:"low_stock_threshold"`
+	CategoryID        string                  `json:"category_id,omitempty"`
+	Variants          []domain.ProductVariant `json:"variants,omitempty"`
+	Images            []domain.ProductImage   `json:"images,omitempty"`
+}
+
+func ToProductRow(p *domain.Product) ProductRow {
+	return ProductRow{
+		SKU:               p.SKU,
+		Name:              p.Name,
+		Description:       p.Description,
+		Price:             p.Price,
+		Stock:             p.Stock,
+		WeightGrams:       p.WeightGrams,
+		LowStockThreshold: p.LowStockThreshold,
+		CategoryID:        p.CategoryID,
+		Variants:          p.Variants,
+		Images:            p.Images,
+	}
+}
+
+// nilai kolom sesuai urutan ProductCSVColumns
+func (r ProductRow) CSVRecord() []string {
+	return []string{
+		r.SKU,
+		r.Name,
+		r.Description,
+		strconv.FormatInt(r.Price.MinorUnits, 10),
+		r.Price.Currency,
+		strconv.Itoa(r.Stock),
+		strconv.Itoa(r.WeightGrams),
+		strconv.Itoa(r.LowStockThreshold),
+		r.CategoryID,
+	}
+}
+
+// Baris import, field nil berarti kolom/key tidak ada di file.
+type productPatch struct {
+	SKU               *string                  `json:"sku"`
+	Name              *string                  `json:"name"`
+	Description       *string                  `json:"description"`
+	Price             *money.Money             `json:"price"`
+	Stock             *int                     `json:"stock"`
+	WeightGrams       *int                     `json:"weight_grams"`
+	LowStockThreshold *int                     `json:"low_stock_threshold"`
+	CategoryID        *string                  `json:"category_id"`
+	Variants          *[]domain.ProductVariant `json:"variants"`
+	Images            *[]domain.ProductImage   `json:"images"`
+}
+
+// timpa field product dengan field yang ada di baris
+func (p *productPatch) apply(product *domain.Product) {
+	if p.SKU != nil {
+		product.SKU = *p.SKU
+	}
+	if p.Name != nil {
+		product.Name = *p.Name
+	}
+	if p.Description != nil {
+		product.Description = *p.Description
+	}
+	if p.Price != nil {
+		// currency kosong di baris berarti currency lama tetap dipakai
+		currency := p.Price.Currency
+		if currency == "" {
+			currency = product.Price.Currency
+		}
+		product.Price = money.New(p.Price.MinorUnits, currency)
+	}
+	if p.Stock != nil {
+		product.Stock = *p.Stock
+	}
+	if p.WeightGrams != nil {
+		product.WeightGrams = *p.WeightGrams
+	}
+	if p.LowStockThreshold != nil {
+		product.LowStockThreshold = *p.LowStockThreshold
+	}
+	if p.CategoryID != nil {
+		product.CategoryID = *p.CategoryID
+	}
+	if p.Variants != nil {
+		product.Variants = *p.Variants
+	}
+	if p.Images != nil {
+		product.Images = *p.Images
+	}
+}
+
+// satu baris hasil parsing, err diisi kalau baris tidak bisa dibaca
+type importRow struct {
+	patch *productPatch
+	err   error
+}
+
+// Bulk import product. Start membaca file dan membuat job, baris
+// diproses di background dengan upsert by SKU memakai validasi yang sama
+// dengan ProductService.CreateProduct/UpdateProduct. Shutdown menghentikan
+// job yang berjalan (ditandai failed), FailInterrupted dipanggil saat start
+// untuk job yang terputus karena proses sebelumnya mati.
+type ProductImportService interface {
+	Start(format string, dryRun bool, data []byte, actor audit.Actor) (*domain.ImportJob, error)
+	GetJob(id string) (*domain.ImportJob, error)
+	FailInterrupted() error
+	Shutdown(ctx context.Context) error
+}
+
+type productImportService struct {
+	jobs     infra.ImportJobRepository
+	products *ProductService
+
+	// job background yang sedang berjalan, stop ditutup saat shutdown
+	mu      sync.Mutex
+	running sync.WaitGroup
+	stop    chan struct{}
+	stopped bool
+}
+
+func NewProductImportService(jobs infra.ImportJobRepository, products *ProductService) ProductImportService {
+	return &productImportService{jobs: jobs, products: products, stop: make(chan struct{})}
+}
+
+func (s *productImportService) Start(format string, dryRun bool, data []byte, actor audit.Actor) (*domain.ImportJob, error) {
+	if s.isStopped() {
+		return nil, ErrImportUnavailable
+	}
+
+	var rows []importRow
+	var err error
+	switch format {
+	case domain.ImportFormatCSV:
+		rows, err = parseCSVRows(data)
+	case domain.ImportFormatNDJSON:
+		rows, err = parseNDJSONRows(data)
+	default:
+		return nil, ErrImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	job := &domain.ImportJob{
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    domain.ImportPending,
+		TotalRows: len(rows),
+		CreatedBy: actor.Email,
+		Errors:    []domain.ImportRowError{},
+	}
+	if err := s.jobs.Insert(job); err != nil {
+		return nil, ErrImportJobInsert
+	}
+
+	// job didaftarkan di bawah lock supaya Shutdown tidak terlewat menunggu
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		job.Status = domain.ImportFailed
+		job.Message = importInterruptedMessage
+		s.finish(job)
+		return nil, ErrImportUnavailable
+	}
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.running.Done()
+		s.run(job, rows, actor)
+	}()
+
+	return job, nil
+}
+
+// Tandai job yang masih pending/running di database sebagai failed. Saat
+// start belum ada job yang berjalan, jadi job seperti itu pasti terputus.
+func (s *productImportService) FailInterrupted() error {
+	n, err := s.jobs.FailUnfinished(importInterruptedMessage)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Warn("interrupted import jobs marked failed", "count", n)
+	}
+	return nil
+}
+
+// Hentikan job yang berjalan lalu tunggu progress terakhirnya tersimpan.
+// Import baru ditolak dengan ErrImportUnavailable.
+func (s *productImportService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *productImportService) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *productImportService) GetJob(id string) (*domain.ImportJob, error) {
+	job, err := s.jobs.FindByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidImportJobID) {
+			return nil, ErrInvalidImportJobID
+		}
+		return nil, ErrFailedDecode
+	}
+	if job == nil {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// proses semua baris, progress disimpan berkala supaya bisa dipantau
+func (s *productImportService) run(job *domain.ImportJob, rows []importRow, actor audit.Actor) {
+	job.Status = domain.ImportRunning
+	s.save(job)
+
+	// SKU yang sudah muncul di file, dipakai dry-run untuk menghitung
+	// baris kedua dengan SKU yang sama sebagai update
+	seen := make(map[string]bool)
+
+	for i, row := range rows {
+		select {
+		case <-s.stop:
+			job.Status = domain.ImportFailed
+			job.Message = fmt.Sprintf("%s after row %d", importInterruptedMessage, i)
+			s.finish(job)
+			return
+		default:
+		}
+
+		sku, created, err := s.importRow(row, job.DryRun, seen, actor)
+		switch {
+		case errors.Is(err, ErrFailedDecode):
+			// database tidak bisa dibaca, baris berikutnya pasti gagal juga
+			job.Status = domain.ImportFailed
+			job.Message = fmt.Sprintf("row %d: %v", i+1, err)
+			s.finish(job)
+			return
+		case err != nil:
+			job.Failed++
+			if len(job.Errors) < maxImportErrors {
+				job.Errors = append(job.Errors, domain.ImportRowError{Row: i + 1, SKU: sku, Message: err.Error()})
+			}
+		case created:
+			job.Created++
+		default:
+			job.Updated++
+		}
+
+		if (i+1)%importProgressEvery == 0 {
+			s.save(job)
+		}
+	}
+
+	job.Status = domain.ImportCompleted
+	s.finish(job)
+}
+
+// Upsert satu baris by SKU. created false berarti product lama diupdate.
+func (s *productImportService) importRow(row importRow, dryRun bool, seen map[string]bool, actor audit.Actor) (sku string, created bool, err error) {
+	if row.patch.SKU != nil {
+		sku = strings.ToUpper(strings.TrimSpace(*row.patch.SKU))
+	}
+	if row.err != nil {
+		return sku, false, row.err
+	}
+	if sku == "" {
+		return sku, false, ErrSKURequired
+	}
+
+	existing, err := s.products.Repo.GetProductBySKU(sku)
+	if err != nil {
+		return sku, false, ErrFailedDecode
+	}
+
+	product := &domain.Product{}
+	if existing != nil {
+		*product = *existing
+	}
+	row.patch.apply(product)
+	product.SKU = sku
+
+	if dryRun {
+		if err := s.products.ValidateProduct(product); err != nil {
+			return sku, false, err
+		}
+		created = existing == nil && !seen[sku]
+		seen[sku] = true
+		return sku, created, nil
+	}
+
+	if existing == nil {
+		return sku, true, s.products.CreateProduct(product, actor)
+	}
+	return sku, false, s.products.UpdateProduct(existing.ID.Hex(), product, actor)
+}
+
+func (s *productImportService) save(job *domain.ImportJob) {
+	if err := s.jobs.Update(job); err != nil {
+		slog.Error("save import job failed", "job_id", job.ID.Hex(), "error", err)
+	}
+}
+
+func (s *productImportService) finish(job *domain.ImportJob) {
+	now := time.Now()
+	job.FinishedAt = &now
+	s.save(job)
+}
+
+// Baca CSV dengan header. Kolom dikenali dari nama header, urutannya
+// bebas. Baris yang tidak bisa dibaca jadi error baris, bukan error file.
+func parseCSVRows(data []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrImportEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportHeader, err)
+	}
+
+	known := make(map[string]bool, len(ProductCSVColumns))
+	for _, col := range ProductCSVColumns {
+		known[col] = true
+	}
+	columns := make([]string, len(header))
+	hasSKU := false
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !known[col] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrImportHeader, col)
+		}
+		hasSKU = hasSKU || col == "sku"
+		columns[i] = col
+	}
+	if !hasSKU {
+		return nil, fmt.Errorf("%w: sku column is required", ErrImportHeader)
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) == MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("%w: %v", ErrImportFile, err)
+			}
+			rows = append(rows, importRow{patch: &productPatch{}, err: fmt.Errorf("invalid csv row: %v", parseErr.Err)})
+			continue
+		}
+
+		patch, err := csvPatch(columns, record)
+		rows = append(rows, importRow{patch: patch, err: err})
+	}
+	return rows, nil
+}
+
+// ubah satu record CSV jadi patch
+func csvPatch(columns, record []string) (*productPatch, error) {
+	patch := &productPatch{}
+	var price *money.Money
+	var priceErr error
+
+	for i, col := range columns {
+		value := strings.TrimSpace(record[i])
+		switch col {
+		case "sku":
+			patch.SKU = &value
+		case "name":
+			patch.Name = &value
+		case "description":
+			patch.Description = &value
+		case "category_id":
+			patch.CategoryID = &value
+		case "price_minor_units":
+			if price == nil {
+				price = &money.Money{}
+			}
+			price.MinorUnits, priceErr = strconv.ParseInt(value, 10, 64)
+		case "currency":
+			if price == nil {
+				price = &money.Money{}
+			}
+			price.Currency = strings.ToUpper(value)
+		case "stock", "weight_grams", "low_stock_threshold":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return patch, fmt.Errorf("%s must be an integer", col)
+			}
+			switch col {
+			case "stock":
+				patch.Stock = &n
+			case "weight_grams":
+				patch.WeightGrams = &n
+			default:
+				patch.LowStockThreshold = &n
+			}
+		}
+	}
+
+	if priceErr != nil {
+		return patch, errors.New("price_minor_units must be an integer")
+	}
+	patch.Price = price
+	return patch, nil
+}
+
+// Baca NDJSON, satu object product per baris. Baris kosong dilewati.
+func parseNDJSONRows(data []byte) ([]importRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+
+		patch := &productPatch{}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		var err error
+		if decodeErr := decoder.Decode(patch); decodeErr != nil {
+			err = fmt.Errorf("invalid json: %v", decodeErr)
+		}
+		rows = append(rows, importRow{patch: patch, err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportFile, err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"shared/audit"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// repository job import di memory
+type memoryImportJobRepo struct {
+	jobs map[primitive.ObjectID]domain.ImportJob
+}
+
+func (r *memoryImportJobRepo) Insert(job *domain.ImportJob) error {
+	job.ID = primitive.NewObjectID()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryImportJobRepo) Update(job *domain.ImportJob) error {
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryImportJobRepo) FindByID(id string) (*domain.ImportJob, error) {
+	objID, _ := primitive.ObjectIDFromHex(id)
+	job, ok := r.jobs[objID]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (r *memoryImportJobRepo) FailUnfinished(message string) (int64, error) {
+	var n int64
+	for id, job := range r.jobs {
+		if job.Status == domain.ImportPending || job.Status == domain.ImportRunning {
+			job.Status = domain.ImportFailed
+			job.Message = message
+			r.jobs[id] = job
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestProductImport_FailInterrupted(t *testing.T) {
+	repo := &memoryImportJobRepo{jobs: map[primitive.ObjectID]domain.ImportJob{}}
+	running := &domain.ImportJob{Status: domain.ImportRunning}
+	done := &domain.ImportJob{Status: domain.ImportCompleted}
+	repo.Insert(running)
+	repo.Insert(done)
+
+	service := NewProductImportService(repo, nil)
+	assert.NoError(t, service.FailInterrupted())
+
+	assert.Equal(t, domain.ImportFailed, repo.jobs[running.ID].Status)
+	assert.Equal(t, importInterruptedMessage, repo.jobs[running.ID].Message)
+	assert.Equal(t, domain.ImportCompleted, repo.jobs[done.ID].Status)
+}
+
+func TestProductImport_RejectsAfterShutdown(t *testing.T) {
+	repo := &memoryImportJobRepo{jobs: map[primitive.ObjectID]domain.ImportJob{}}
+	service := NewProductImportService(repo, nil)
+
+	assert.NoError(t, service.Shutdown(context.Background()))
+	_, err := service.Start(domain.ImportFormatCSV, true, []byte("sku\nA-1\n"), audit.Actor{})
+
+	assert.ErrorIs(t, err, ErrImportUnavailable)
+	assert.Empty(t, repo.jobs)
+}
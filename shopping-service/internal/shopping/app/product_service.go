@@ -1,57 +1,214 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"shared/audit"
+	"shared/logging"
+	"shared/money"
 	"shopping-service/internal/shopping/domain"
 	"shopping-service/internal/shopping/infra"
 )
 
 // service product
 type ProductService struct {
-	Repo *infra.ProductRepo
+	Repo       *infra.ProductRepo
+	Categories CategoryService
+	Inventory  InventoryService
+	Audit      audit.Log
 }
 
 // init service
-func NewProductService(repo *infra.ProductRepo) *ProductService {
-	return &ProductService{Repo: repo}
+func NewProductService(repo *infra.ProductRepo, categories CategoryService, inventory InventoryService, auditLog audit.Log) *ProductService {
+	return &ProductService{Repo: repo, Categories: categories, Inventory: inventory, Audit: auditLog}
 }
 
+// nama resource product di audit log
+const productAuditResource = "product"
+
+// batas bawah bucket harga untuk facet search, dalam satuan major
+var priceBucketBounds = []string{"0", "50000", "100000", "250000", "500000", "1000000"}
+
 // logika simpan product
-func (s *ProductService) CreateProduct(product *domain.Product) error {
-	// validasi manual
+func (s *ProductService) CreateProduct(product *domain.Product, actor audit.Actor) error {
+	if err := s.ValidateProduct(product); err != nil {
+		return err
+	}
+	product.Reserved = 0
+	product.Archived = false
+
+	// simpan ke repo
+	err := s.Repo.CreateProduct(product)
+	if err != nil {
+		if errors.Is(err, infra.ErrDuplicateSKU) {
+			return ErrSKUDuplicate
+		}
+		return ErrProductInsert
+	}
+
+	// stok awal masuk ledger sebagai restock
+	s.Inventory.Initialize(product)
+
+	s.record(product.ID.Hex(), audit.ActionCreate, actor, nil, product)
+	return nil
+}
+
+// Validasi product sebelum disimpan, dipakai create, update dan dry-run
+// bulk import. SKU dan SKU varian dinormalisasi ke huruf besar.
+func (s *ProductService) ValidateProduct(product *domain.Product) error {
 	if product.Name == "" {
 		return ErrNameEmpty
 	}
-	if product.Price <= 0 {
-		return ErrPriceInvalid
+	product.SKU = strings.ToUpper(strings.TrimSpace(product.SKU))
+	if err := validatePrice(&product.Price); err != nil {
+		return err
 	}
 	if product.Stock < 0 {
 		return ErrStockInvalid
 	}
+	if product.WeightGrams < 0 {
+		return ErrWeightInvalid
+	}
+	if product.LowStockThreshold < 0 {
+		return ErrThresholdInvalid
+	}
+	return s.validateCatalog(product)
+}
 
-	// simpan ke repo
-	err := s.Repo.CreateProduct(product)
-	if err != nil {
-		return ErrProductInsert
+// validasi harga, currency kosong dianggap IDR
+func validatePrice(price *money.Money) error {
+	if !price.IsPositive() {
+		return ErrPriceInvalid
+	}
+	if price.Currency == "" {
+		price.Currency = money.DefaultCurrency
+	}
+	if err := price.Validate(); err != nil {
+		return ErrCurrencyInvalid
+	}
+	return nil
+}
+
+// Validasi kategori, varian dan gambar. Path kategori diisi dari
+// kategori supaya filter kategori mencakup sub kategori.
+func (s *ProductService) validateCatalog(product *domain.Product) error {
+	product.CategoryPath = nil
+	if product.CategoryID != "" {
+		category, err := s.Categories.GetByID(product.CategoryID)
+		if err != nil {
+			return err
+		}
+		product.CategoryPath = category.Path()
 	}
 
+	skus := make(map[string]bool, len(product.Variants))
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		variant.SKU = strings.ToUpper(strings.TrimSpace(variant.SKU))
+		if variant.SKU == "" || variant.Stock < 0 {
+			return ErrVariantInvalid
+		}
+		if skus[variant.SKU] {
+			return ErrVariantSKUDuplicate
+		}
+		skus[variant.SKU] = true
+
+		// varian tanpa harga memakai harga product
+		if variant.Price.IsZero() {
+			variant.Price = product.Price
+		}
+		if err := validatePrice(&variant.Price); err != nil {
+			return ErrVariantInvalid
+		}
+	}
+
+	for i := range product.Images {
+		image := &product.Images[i]
+		if strings.TrimSpace(image.URL) == "" || image.Width < 0 || image.Height < 0 {
+			return ErrImageInvalid
+		}
+		if image.Position == 0 {
+			image.Position = i + 1
+		}
+	}
 	return nil
 }
 
-// ambil semua product
-func (s *ProductService) GetAllProducts() ([]domain.Product, error) {
-	products, err := s.Repo.GetAllProducts()
+// ambil product sesuai filter
+func (s *ProductService) GetAllProducts(filter domain.ProductFilter) ([]domain.Product, error) {
+	if filter.Currency == "" {
+		filter.Currency = money.DefaultCurrency
+	}
+	products, err := s.Repo.GetAllProducts(filter)
 	if err != nil {
 		return nil, ErrFailedDecode
 	}
 	return products, nil
 }
 
+// Panggil fn untuk setiap product sesuai filter secara berurutan, dipakai
+// export supaya data tidak ditampung di memori.
+func (s *ProductService) ExportProducts(ctx context.Context, filter domain.ProductFilter, fn func(*domain.Product) error) error {
+	if filter.Currency == "" {
+		filter.Currency = money.DefaultCurrency
+	}
+	return s.Repo.StreamProducts(ctx, filter, fn)
+}
+
+// search full-text, hasil diurutkan by relevansi beserta facet kategori & harga
+func (s *ProductService) SearchProducts(query string, filter domain.ProductFilter) (*domain.ProductSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrSearchQueryEmpty
+	}
+	if filter.Currency == "" {
+		filter.Currency = money.DefaultCurrency
+	}
+	if !money.ValidCurrency(filter.Currency) {
+		return nil, ErrCurrencyInvalid
+	}
+
+	bounds := make([]int64, 0, len(priceBucketBounds))
+	for _, b := range priceBucketBounds {
+		m, _ := money.Parse(b, filter.Currency)
+		bounds = append(bounds, m.MinorUnits)
+	}
+
+	result, err := s.Repo.SearchProducts(query, filter, bounds)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+
+	// lengkapi nama kategori di facet
+	if len(result.Categories) > 0 {
+		tree, err := s.Categories.GetTree()
+		if err == nil {
+			names := make(map[string]string)
+			var walk func(nodes []*domain.CategoryNode)
+			walk = func(nodes []*domain.CategoryNode) {
+				for _, n := range nodes {
+					names[n.ID.Hex()] = n.Name
+					walk(n.Children)
+				}
+			}
+			walk(tree)
+			for i := range result.Categories {
+				result.Categories[i].Name = names[result.Categories[i].CategoryID]
+			}
+		}
+	}
+	return result, nil
+}
+
 // ambil product by ID
 func (s *ProductService) GetProductByID(id string) (*domain.Product, error) {
 	product, err := s.Repo.GetProductByID(id)
 	if err != nil {
 		// jika format ObjectID salah
-		if err.Error() == "invalid product ID" {
+		if errors.Is(err, infra.ErrInvalidProductID) {
 			return nil, ErrInvalidProductID
 		}
 		// jika data tidak ditemukan
@@ -61,37 +218,103 @@ func (s *ProductService) GetProductByID(id string) (*domain.Product, error) {
 }
 
 // update product by ID
-func (s *ProductService) UpdateProduct(id string, product *domain.Product) error {
-	// validasi
-	if product.Name == "" {
-		return ErrNameEmpty
-	}
-	if product.Price <= 0 {
-		return ErrPriceInvalid
+func (s *ProductService) UpdateProduct(id string, product *domain.Product, actor audit.Actor) error {
+	if err := s.ValidateProduct(product); err != nil {
+		return err
 	}
-	if product.Stock < 0 {
-		return ErrStockInvalid
+
+	// ambil stok lama untuk menghitung selisih stok
+	existing, err := s.Repo.GetProductByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidProductID) {
+			return ErrInvalidProductID
+		}
+		return ErrProductNotFound
 	}
 
-	err := s.Repo.UpdateProduct(id, product)
+	err = s.Repo.UpdateProduct(id, product)
 	if err != nil {
-		if err.Error() == "invalid product ID" {
+		switch {
+		case errors.Is(err, infra.ErrInvalidProductID):
 			return ErrInvalidProductID
+		case errors.Is(err, infra.ErrDuplicateSKU):
+			return ErrSKUDuplicate
+		case errors.Is(err, infra.ErrVersionConflict):
+			return ErrVersionConflict
+		case errors.Is(err, infra.ErrProductNotFound):
+			return ErrProductNotFound
 		}
 		return ErrProductUpdate
 	}
 
+	// stok baru dicatat sebagai adjustment sebesar selisihnya, bukan ditimpa,
+	// supaya penjualan yang terjadi bersamaan tidak hilang
+	if existing.Stock != product.Stock {
+		if _, err := s.Inventory.Adjust(id, product.Stock-existing.Stock, "product update"); err != nil {
+			return err
+		}
+	}
+
+	// dibaca ulang supaya diff memuat version dan stok setelah adjustment
+	if updated, err := s.Repo.GetProductByID(id); err == nil {
+		s.record(id, audit.ActionUpdate, actor, existing, updated)
+	}
 	return nil
 }
 
-// delete product by ID
-func (s *ProductService) DeleteProduct(id string) error {
-	err := s.Repo.DeleteProduct(id)
+// delete product by ID, product hanya diarsipkan
+func (s *ProductService) DeleteProduct(id string, actor audit.Actor) error {
+	before, err := s.Repo.ArchiveProduct(id, actor.Email)
 	if err != nil {
-		if err.Error() == "invalid product ID" {
+		switch {
+		case errors.Is(err, infra.ErrInvalidProductID):
 			return ErrInvalidProductID
+		case errors.Is(err, infra.ErrProductNotFound):
+			return ErrProductNotFound
 		}
 		return ErrProductDelete
 	}
+	s.record(id, audit.ActionDelete, actor, before, archivedCopy(before, actor.Email))
 	return nil
 }
+
+// kembalikan product yang diarsipkan
+func (s *ProductService) RestoreProduct(id string, actor audit.Actor) error {
+	before, err := s.Repo.RestoreProduct(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, infra.ErrInvalidProductID):
+			return ErrInvalidProductID
+		case errors.Is(err, infra.ErrProductNotFound):
+			return ErrProductNotFound
+		}
+		return ErrProductUpdate
+	}
+	restored := *before
+	restored.Archived, restored.ArchivedAt, restored.ArchivedBy = false, nil, ""
+	s.record(id, audit.ActionRestore, actor, before, &restored)
+	return nil
+}
+
+// product yang diarsipkan, untuk admin
+func (s *ProductService) ArchivedProducts() ([]domain.Product, error) {
+	products, err := s.Repo.GetArchivedProducts()
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return products, nil
+}
+
+// product setelah diarsipkan, dibentuk dari dokumen sebelum update
+func archivedCopy(before *domain.Product, archivedBy string) *domain.Product {
+	archived := *before
+	now := time.Now()
+	archived.Archived, archived.ArchivedAt, archived.ArchivedBy = true, &now, archivedBy
+	return &archived
+}
+
+// catat perubahan ke audit log, gagal mencatat tidak menggagalkan perubahan
+func (s *ProductService) record(id, action string, actor audit.Actor, before, after *domain.Product) {
+	ctx := logging.WithRequestID(context.Background(), actor.RequestID)
+	s.Audit.Record(ctx, audit.NewEntry(productAuditResource, id, action, actor, before, after))
+}
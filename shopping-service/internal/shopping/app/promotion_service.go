@@ -0,0 +1,311 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+// satu baris belanja yang dihitung potongannya
+type PromotionLine struct {
+	ProductID string
+	UnitPrice money.Money
+	Quantity  int
+}
+
+// hasil Apply: promo yang sudah dipesan pemakaiannya beserta potongannya
+type AppliedPromotion struct {
+	Promotion *domain.Promotion
+	Email     string
+	Discount  money.Money
+	// potongan per baris, urutan sama dengan lines di Apply
+	LineDiscounts []money.Money
+}
+
+type PromotionService interface {
+	Create(promotion *domain.Promotion) error
+	GetAll() ([]domain.Promotion, error)
+	GetByCode(code string) (*domain.Promotion, error)
+	Deactivate(id string) error
+	// validasi kupon, hitung potongan, lalu pesan satu pemakaian secara atomic
+	Apply(email, code string, lines []PromotionLine) (*AppliedPromotion, error)
+	// kembalikan pemakaian kalau order/transaksi batal
+	Release(applied *AppliedPromotion)
+	// catat redemption setelah order/transaksi tersimpan
+	Confirm(applied *AppliedPromotion, reference string)
+}
+
+type promotionService struct {
+	repo infra.PromotionRepository
+}
+
+func NewPromotionService(repo infra.PromotionRepository) PromotionService {
+	return &promotionService{repo: repo}
+}
+
+func (s *promotionService) Create(promotion *domain.Promotion) error {
+	promotion.Code = normalizeCode(promotion.Code)
+	if promotion.Code == "" {
+		return ErrPromoCodeEmpty
+	}
+
+	switch promotion.Type {
+	case domain.PromotionPercentage:
+		if promotion.PercentOff <= 0 || promotion.PercentOff > 10000 {
+			return ErrPromoValueInvalid
+		}
+		promotion.AmountOff = money.Money{}
+	case domain.PromotionFixed:
+		if err := validatePrice(&promotion.AmountOff); err != nil {
+			if err == ErrPriceInvalid {
+				return ErrPromoValueInvalid
+			}
+			return err
+		}
+		promotion.PercentOff = 0
+	default:
+		return ErrPromoTypeInvalid
+	}
+
+	switch promotion.Scope {
+	case "", domain.PromotionScopeOrder:
+		promotion.Scope = domain.PromotionScopeOrder
+		promotion.ProductIDs = nil
+	case domain.PromotionScopeProduct:
+		if len(promotion.ProductIDs) == 0 {
+			return ErrPromoScopeInvalid
+		}
+	default:
+		return ErrPromoScopeInvalid
+	}
+
+	if !promotion.StartsAt.IsZero() && !promotion.EndsAt.IsZero() && !promotion.StartsAt.Before(promotion.EndsAt) {
+		return ErrPromoWindowInvalid
+	}
+	if promotion.MaxUses < 0 || promotion.MaxUsesPerUser < 0 {
+		return ErrPromoLimitInvalid
+	}
+
+	promotion.UsedCount = 0
+	promotion.Active = true
+
+	if err := s.repo.Insert(promotion); err != nil {
+		if errors.Is(err, infra.ErrPromotionCodeExists) {
+			return ErrPromoCodeTaken
+		}
+		return ErrPromotionInsert
+	}
+	return nil
+}
+
+// ambil semua promo
+func (s *promotionService) GetAll() ([]domain.Promotion, error) {
+	promotions, err := s.repo.FindAll()
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return promotions, nil
+}
+
+// ambil promo by kode
+func (s *promotionService) GetByCode(code string) (*domain.Promotion, error) {
+	promotion, err := s.repo.FindByCode(normalizeCode(code))
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if promotion == nil {
+		return nil, ErrPromotionNotFound
+	}
+	return promotion, nil
+}
+
+// nonaktifkan promo, kupon tidak bisa dipakai lagi
+func (s *promotionService) Deactivate(id string) error {
+	if err := s.repo.Deactivate(id); err != nil {
+		if errors.Is(err, infra.ErrPromotionNotFound) || errors.Is(err, infra.ErrInvalidPromotionID) {
+			return ErrPromotionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *promotionService) Apply(email, code string, lines []PromotionLine) (*AppliedPromotion, error) {
+	promotion, err := s.repo.FindByCode(normalizeCode(code))
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if promotion == nil || !promotion.Active {
+		return nil, ErrCouponNotFound
+	}
+	if err := checkPromotionWindow(promotion, time.Now()); err != nil {
+		return nil, err
+	}
+
+	discount, lineDiscounts, err := computeDiscount(promotion, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// limit per user dipesan dulu, lalu limit total. keduanya atomic di database
+	// jadi request paralel tidak bisa melewati batas
+	if promotion.MaxUsesPerUser > 0 {
+		ok, err := s.repo.ReserveUserUse(promotion.ID, email, promotion.MaxUsesPerUser)
+		if err != nil {
+			return nil, ErrFailedDecode
+		}
+		if !ok {
+			return nil, ErrCouponUserLimit
+		}
+	}
+
+	ok, err := s.repo.ReserveUse(promotion.ID)
+	if err != nil || !ok {
+		if promotion.MaxUsesPerUser > 0 {
+			if err := s.repo.ReleaseUserUse(promotion.ID, email); err != nil {
+				slog.Error("release user coupon usage failed", "code", promotion.Code, "email", email, "error", err)
+			}
+		}
+		if err != nil {
+			return nil, ErrFailedDecode
+		}
+		return nil, ErrCouponExhausted
+	}
+
+	return &AppliedPromotion{
+		Promotion:     promotion,
+		Email:         email,
+		Discount:      discount,
+		LineDiscounts: lineDiscounts,
+	}, nil
+}
+
+func (s *promotionService) Release(applied *AppliedPromotion) {
+	if applied == nil {
+		return
+	}
+	id := applied.Promotion.ID
+	if err := s.repo.ReleaseUse(id); err != nil {
+		slog.Error("release coupon usage failed", "code", applied.Promotion.Code, "error", err)
+	}
+	if applied.Promotion.MaxUsesPerUser > 0 {
+		if err := s.repo.ReleaseUserUse(id, applied.Email); err != nil {
+			slog.Error("release user coupon usage failed", "code", applied.Promotion.Code, "email", applied.Email, "error", err)
+		}
+	}
+}
+
+func (s *promotionService) Confirm(applied *AppliedPromotion, reference string) {
+	if applied == nil {
+		return
+	}
+	// pemakaian sudah terhitung saat Apply, redemption hanya catatan
+	err := s.repo.InsertRedemption(&domain.PromotionRedemption{
+		PromotionID: applied.Promotion.ID,
+		Code:        applied.Promotion.Code,
+		Email:       applied.Email,
+		Reference:   reference,
+		Discount:    applied.Discount,
+	})
+	if err != nil {
+		slog.Error("insert coupon redemption failed", "code", applied.Promotion.Code, "reference", reference, "error", err)
+	}
+}
+
+// kode kupon tidak case sensitive
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// cek masa berlaku kupon
+func checkPromotionWindow(promotion *domain.Promotion, now time.Time) error {
+	if !promotion.StartsAt.IsZero() && now.Before(promotion.StartsAt) {
+		return ErrCouponNotStarted
+	}
+	if !promotion.EndsAt.IsZero() && !now.Before(promotion.EndsAt) {
+		return ErrCouponExpired
+	}
+	return nil
+}
+
+// Hitung potongan promo untuk lines. Scope order menghitung potongan dari
+// subtotal lalu membaginya ke tiap baris sesuai bobot subtotalnya, scope
+// product hanya memotong baris yang product-nya terdaftar. Potongan tidak
+// pernah melebihi harga.
+func computeDiscount(promotion *domain.Promotion, lines []PromotionLine) (money.Money, []money.Money, error) {
+	if len(lines) == 0 {
+		return money.Money{}, nil, ErrCouponNotApplicable
+	}
+
+	currency := lines[0].UnitPrice.Currency
+	if promotion.Type == domain.PromotionFixed && promotion.AmountOff.Currency != currency {
+		return money.Money{}, nil, ErrCouponNotApplicable
+	}
+
+	subtotals := make([]money.Money, len(lines))
+	for i, line := range lines {
+		if line.UnitPrice.Currency != currency {
+			return money.Money{}, nil, ErrMixedCurrency
+		}
+		subtotals[i] = line.UnitPrice.Mul(int64(line.Quantity))
+	}
+
+	lineDiscounts := make([]money.Money, len(lines))
+	switch promotion.Scope {
+	case domain.PromotionScopeProduct:
+		eligible := make(map[string]bool, len(promotion.ProductIDs))
+		for _, id := range promotion.ProductIDs {
+			eligible[id] = true
+		}
+		for i, line := range lines {
+			lineDiscounts[i] = money.New(0, currency)
+			if !eligible[line.ProductID] {
+				continue
+			}
+			if promotion.Type == domain.PromotionPercentage {
+				lineDiscounts[i] = subtotals[i].MulBasisPoints(promotion.PercentOff)
+			} else {
+				lineDiscounts[i] = minMoney(promotion.AmountOff, line.UnitPrice).Mul(int64(line.Quantity))
+			}
+		}
+	default:
+		subtotal, _ := money.Sum(currency, subtotals...)
+
+		var discount money.Money
+		if promotion.Type == domain.PromotionPercentage {
+			discount = subtotal.MulBasisPoints(promotion.PercentOff)
+		} else {
+			discount = promotion.AmountOff
+		}
+		discount = minMoney(discount, subtotal)
+
+		weights := make([]int64, len(lines))
+		for i, s := range subtotals {
+			weights[i] = s.MinorUnits
+		}
+		parts, err := discount.Allocate(weights...)
+		if err != nil {
+			return money.Money{}, nil, ErrCouponNotApplicable
+		}
+		copy(lineDiscounts, parts)
+	}
+
+	total, _ := money.Sum(currency, lineDiscounts...)
+	if !total.IsPositive() {
+		return money.Money{}, nil, ErrCouponNotApplicable
+	}
+	return total, lineDiscounts, nil
+}
+
+// nilai terkecil dari dua money dengan currency sama
+func minMoney(a, b money.Money) money.Money {
+	if a.MinorUnits < b.MinorUnits {
+		return a
+	}
+	return b
+}
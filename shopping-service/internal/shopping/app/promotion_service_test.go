@@ -0,0 +1,292 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"shared/money"
+	"shopping-service/internal/pricing"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// repository promo di memory, pemakaian dihitung seperti $inc bersyarat di Mongo
+type memoryPromotionRepo struct {
+	promotions map[string]*domain.Promotion
+	userUses   map[string]int
+}
+
+func newMemoryPromotionRepo(promotions ...domain.Promotion) *memoryPromotionRepo {
+	repo := &memoryPromotionRepo{promotions: map[string]*domain.Promotion{}, userUses: map[string]int{}}
+	for i := range promotions {
+		p := promotions[i]
+		if p.ID.IsZero() {
+			p.ID = primitive.NewObjectID()
+		}
+		repo.promotions[p.Code] = &p
+	}
+	return repo
+}
+
+func (r *memoryPromotionRepo) byID(id primitive.ObjectID) *domain.Promotion {
+	for _, p := range r.promotions {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (r *memoryPromotionRepo) Insert(promotion *domain.Promotion) error {
+	r.promotions[promotion.Code] = promotion
+	return nil
+}
+
+func (r *memoryPromotionRepo) FindAll() ([]domain.Promotion, error) {
+	promotions := make([]domain.Promotion, 0, len(r.promotions))
+	for _, p := range r.promotions {
+		promotions = append(promotions, *p)
+	}
+	return promotions, nil
+}
+
+func (r *memoryPromotionRepo) FindByCode(code string) (*domain.Promotion, error) {
+	p, ok := r.promotions[code]
+	if !ok {
+		return nil, nil
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *memoryPromotionRepo) Deactivate(id string) error {
+	return nil
+}
+
+func (r *memoryPromotionRepo) ReserveUse(id primitive.ObjectID) (bool, error) {
+	p := r.byID(id)
+	if p.MaxUses > 0 && p.UsedCount >= p.MaxUses {
+		return false, nil
+	}
+	p.UsedCount++
+	return true, nil
+}
+
+func (r *memoryPromotionRepo) ReleaseUse(id primitive.ObjectID) error {
+	r.byID(id).UsedCount--
+	return nil
+}
+
+func (r *memoryPromotionRepo) ReserveUserUse(id primitive.ObjectID, email string, max int) (bool, error) {
+	key := id.Hex() + "|" + email
+	if r.userUses[key] >= max {
+		return false, nil
+	}
+	r.userUses[key]++
+	return true, nil
+}
+
+func (r *memoryPromotionRepo) ReleaseUserUse(id primitive.ObjectID, email string) error {
+	r.userUses[id.Hex()+"|"+email]--
+	return nil
+}
+
+func (r *memoryPromotionRepo) InsertRedemption(redemption *domain.PromotionRedemption) error {
+	return nil
+}
+
+func idr(minor int64) money.Money {
+	return money.New(minor, "IDR")
+}
+
+// dua baris: A 100.000 x2 dan B 50.000 x1, subtotal 250.000
+func promotionTestLines() []PromotionLine {
+	return []PromotionLine{
+		{ProductID: "A", UnitPrice: idr(100000), Quantity: 2},
+		{ProductID: "B", UnitPrice: idr(50000), Quantity: 1},
+	}
+}
+
+func TestComputeDiscount(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion domain.Promotion
+		lines     []PromotionLine
+		want      []money.Money
+		wantErr   error
+	}{
+		{
+			name:      "persen order dibagi sesuai bobot baris",
+			promotion: domain.Promotion{Type: domain.PromotionPercentage, PercentOff: 1000, Scope: domain.PromotionScopeOrder},
+			want:      []money.Money{idr(20000), idr(5000)},
+		},
+		{
+			name:      "nominal order tidak melebihi subtotal",
+			promotion: domain.Promotion{Type: domain.PromotionFixed, AmountOff: idr(300000), Scope: domain.PromotionScopeOrder},
+			want:      []money.Money{idr(200000), idr(50000)},
+		},
+		{
+			name:      "persen product hanya baris terdaftar",
+			promotion: domain.Promotion{Type: domain.PromotionPercentage, PercentOff: 2000, Scope: domain.PromotionScopeProduct, ProductIDs: []string{"B"}},
+			want:      []money.Money{idr(0), idr(10000)},
+		},
+		{
+			name:      "nominal product per unit tidak melebihi harga unit",
+			promotion: domain.Promotion{Type: domain.PromotionFixed, AmountOff: idr(70000), Scope: domain.PromotionScopeProduct, ProductIDs: []string{"A", "B"}},
+			want:      []money.Money{idr(140000), idr(50000)},
+		},
+		{
+			name:      "product tidak terdaftar",
+			promotion: domain.Promotion{Type: domain.PromotionPercentage, PercentOff: 2000, Scope: domain.PromotionScopeProduct, ProductIDs: []string{"C"}},
+			wantErr:   ErrCouponNotApplicable,
+		},
+		{
+			name:      "currency nominal berbeda",
+			promotion: domain.Promotion{Type: domain.PromotionFixed, AmountOff: money.New(500, "USD"), Scope: domain.PromotionScopeOrder},
+			wantErr:   ErrCouponNotApplicable,
+		},
+		{
+			name:      "baris beda currency",
+			promotion: domain.Promotion{Type: domain.PromotionPercentage, PercentOff: 1000, Scope: domain.PromotionScopeOrder},
+			lines:     []PromotionLine{{ProductID: "A", UnitPrice: idr(100000), Quantity: 1}, {ProductID: "B", UnitPrice: money.New(500, "USD"), Quantity: 1}},
+			wantErr:   ErrMixedCurrency,
+		},
+		{
+			name:      "tanpa baris",
+			promotion: domain.Promotion{Type: domain.PromotionPercentage, PercentOff: 1000, Scope: domain.PromotionScopeOrder},
+			lines:     []PromotionLine{},
+			wantErr:   ErrCouponNotApplicable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := tt.lines
+			if lines == nil {
+				lines = promotionTestLines()
+			}
+			total, parts, err := computeDiscount(&tt.promotion, lines)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, parts)
+			sum, _ := money.Sum("IDR", tt.want...)
+			assert.Equal(t, sum, total)
+		})
+	}
+}
+
+// potongan kupon masuk ke pipeline sebelum pajak dan ongkir
+func TestPromotionApply_StacksIntoPricing(t *testing.T) {
+	svc := NewPromotionService(newMemoryPromotionRepo(domain.Promotion{
+		Code: "HEMAT10", Type: domain.PromotionPercentage, PercentOff: 1000, Scope: domain.PromotionScopeOrder, Active: true,
+	}))
+	lines := promotionTestLines()
+	applied, err := svc.Apply("buyer@mail.com", " hemat10 ", lines)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	quote := pricing.Quote{}
+	for i, line := range lines {
+		quote.Lines = append(quote.Lines, pricing.Line{ProductID: line.ProductID, UnitPrice: line.UnitPrice, Quantity: line.Quantity, Discount: applied.LineDiscounts[i]})
+	}
+	pipeline := pricing.NewPipeline(
+		pricing.SubtotalCalculator{},
+		pricing.DiscountCalculator{},
+		pricing.TaxCalculator{DefaultRegion: "ID", Rates: map[string]int64{"ID": 1100}},
+		pricing.ShippingCalculator{DefaultMethod: "flat", Methods: map[string]pricing.ShippingRate{"flat": {Type: pricing.ShippingFlat, Amount: idr(15000)}}},
+	)
+	breakdown, err := priceQuote(pipeline, quote)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, idr(250000), breakdown.Subtotal)
+	assert.Equal(t, applied.Discount, breakdown.Discount)
+	assert.Equal(t, idr(24750), breakdown.Tax)
+	assert.Equal(t, idr(15000), breakdown.Shipping)
+	assert.Equal(t, idr(264750), breakdown.Total)
+}
+
+func TestPromotionApply_Limits(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		promotion domain.Promotion
+		// pemakaian sebelumnya oleh email yang sama
+		previous int
+		inactive bool
+		wantErr  error
+		// used_count dan pemakaian user setelah Apply
+		wantUsed  int
+		wantUsers int
+	}{
+		{
+			name:      "masih dalam batas",
+			promotion: domain.Promotion{MaxUses: 2, MaxUsesPerUser: 1},
+			wantUsed:  1,
+			wantUsers: 1,
+		},
+		{
+			name:      "batas per user habis",
+			promotion: domain.Promotion{MaxUses: 5, MaxUsesPerUser: 1},
+			previous:  1,
+			wantErr:   ErrCouponUserLimit,
+			wantUsed:  1,
+			wantUsers: 1,
+		},
+		{
+			name:      "kuota total habis mengembalikan pemakaian user",
+			promotion: domain.Promotion{MaxUses: 1, MaxUsesPerUser: 2, UsedCount: 1},
+			wantErr:   ErrCouponExhausted,
+			wantUsed:  1,
+			wantUsers: 0,
+		},
+		{
+			name:      "belum mulai",
+			promotion: domain.Promotion{StartsAt: now.Add(time.Hour)},
+			wantErr:   ErrCouponNotStarted,
+		},
+		{
+			name:      "sudah berakhir",
+			promotion: domain.Promotion{EndsAt: now.Add(-time.Hour)},
+			wantErr:   ErrCouponExpired,
+		},
+		{
+			name:     "tidak aktif",
+			inactive: true,
+			wantErr:  ErrCouponNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := tt.promotion
+			promotion.ID = primitive.NewObjectID()
+			promotion.Code = "LIMIT"
+			promotion.Type = domain.PromotionPercentage
+			promotion.PercentOff = 1000
+			promotion.Scope = domain.PromotionScopeOrder
+			promotion.Active = !tt.inactive
+			repo := newMemoryPromotionRepo(promotion)
+			key := promotion.ID.Hex() + "|buyer@mail.com"
+			repo.userUses[key] = tt.previous
+			if tt.previous > 0 {
+				repo.promotions["LIMIT"].UsedCount += tt.previous
+			}
+
+			_, err := NewPromotionService(repo).Apply("buyer@mail.com", "limit", promotionTestLines())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantUsed, repo.promotions["LIMIT"].UsedCount)
+			assert.Equal(t, tt.wantUsers, repo.userUses[key])
+		})
+	}
+}
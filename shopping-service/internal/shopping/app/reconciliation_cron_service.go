@@ -0,0 +1,30 @@
+package app
+
+import (
+	"log/slog"
+	"time"
+
+	"shared/metrics"
+
+	"github.com/robfig/cron/v3"
+)
+
+// StartReconciliationCron menjalankan rekonsiliasi payment setiap hari
+// pukul 02:00, setelah cron pembersih transaksi failed
+func StartReconciliationCron(service ReconciliationService) *cron.Cron {
+	c := cron.New()
+
+	c.AddFunc("0 2 * * *", func() {
+		start := time.Now()
+		report, err := service.Run("cron")
+		metrics.ObserveJob("reconciliation", start, err)
+		if err != nil {
+			slog.Error("payment reconciliation failed", "job", "reconciliation", "error", err)
+			return
+		}
+		slog.Info("payment reconciliation finished", "job", "reconciliation", "report_id", report.ID.Hex(), "status", report.Status, "mismatches", report.MismatchCount)
+	})
+
+	c.Start()
+	return c
+}
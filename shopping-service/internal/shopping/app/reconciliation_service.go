@@ -0,0 +1,300 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"shared/events"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+// batas rekonsiliasi
+const (
+	// jumlah dokumen per halaman saat membaca transaksi, order dan payment
+	reconcilePageSize = 200
+	// mismatch yang disimpan di report, sisanya hanya dihitung
+	maxReconcileMismatches = 5000
+	// report di list admin
+	DefaultReconciliationListLimit = 30
+)
+
+// Rekonsiliasi payment: setiap transaksi dan order harus punya payment
+// dengan nominal dan status yang sesuai di Payment Service, dan setiap
+// payment harus dipakai transaksi atau order. Semua data dibaca per
+// halaman, payment lewat GetAllPayments gRPC Payment Service.
+type ReconciliationService interface {
+	// jalankan di background, hanya satu rekonsiliasi dalam satu waktu
+	Start(triggeredBy string) (*domain.ReconciliationReport, error)
+	// jalankan dan tunggu selesai, dipakai cron
+	Run(triggeredBy string) (*domain.ReconciliationReport, error)
+	GetByID(id string) (*domain.ReconciliationReport, error)
+	List(limit int64) ([]domain.ReconciliationReport, error)
+}
+
+type reconciliationService struct {
+	repo         infra.ReconciliationRepository
+	transactions infra.TransactionRepository
+	orders       infra.OrderRepository
+	publisher    events.Publisher
+	payments     *PaymentClient
+
+	mu      sync.Mutex
+	running bool
+}
+
+func NewReconciliationService(repo infra.ReconciliationRepository, transactions infra.TransactionRepository, orders infra.OrderRepository, publisher events.Publisher, payments *PaymentClient) ReconciliationService {
+	return &reconciliationService{
+		repo:         repo,
+		transactions: transactions,
+		orders:       orders,
+		publisher:    publisher,
+		payments:     payments,
+	}
+}
+
+// transaksi/order yang memakai satu payment
+type paymentRef struct {
+	source   string
+	sourceID string
+	amount   money.Money
+	status   string
+}
+
+func (s *reconciliationService) Start(triggeredBy string) (*domain.ReconciliationReport, error) {
+	report, err := s.begin(triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	// salinan supaya response tidak ikut berubah saat report diproses
+	started := *report
+	go s.run(report)
+	return &started, nil
+}
+
+func (s *reconciliationService) Run(triggeredBy string) (*domain.ReconciliationReport, error) {
+	report, err := s.begin(triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	s.run(report)
+	return report, nil
+}
+
+func (s *reconciliationService) GetByID(id string) (*domain.ReconciliationReport, error) {
+	report, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidReconciliationID) {
+			return nil, ErrInvalidReconciliationID
+		}
+		return nil, ErrFailedDecode
+	}
+	if report == nil {
+		return nil, ErrReconciliationNotFound
+	}
+	return report, nil
+}
+
+func (s *reconciliationService) List(limit int64) ([]domain.ReconciliationReport, error) {
+	if limit <= 0 {
+		limit = DefaultReconciliationListLimit
+	}
+	reports, err := s.repo.FindRecent(limit)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return reports, nil
+}
+
+// buat report running, gagal kalau rekonsiliasi lain belum selesai
+func (s *reconciliationService) begin(triggeredBy string) (*domain.ReconciliationReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return nil, ErrReconciliationRunning
+	}
+
+	report := &domain.ReconciliationReport{
+		Status:         domain.ReconciliationRunning,
+		TriggeredBy:    triggeredBy,
+		MismatchByKind: map[string]int{},
+		Mismatches:     []domain.ReconciliationMismatch{},
+	}
+	if err := s.repo.Insert(report); err != nil {
+		return nil, ErrReconciliationInsert
+	}
+	s.running = true
+	return report, nil
+}
+
+func (s *reconciliationService) run(report *domain.ReconciliationReport) {
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	if err := s.reconcile(report); err != nil {
+		report.Status = domain.ReconciliationFailed
+		report.Message = err.Error()
+	} else {
+		report.Status = domain.ReconciliationCompleted
+	}
+
+	now := time.Now()
+	report.FinishedAt = &now
+	if err := s.repo.Update(report); err != nil {
+		slog.Error("save reconciliation report failed", "report_id", report.ID.Hex(), "error", err)
+	}
+
+	if report.Status == domain.ReconciliationCompleted && report.MismatchCount > 0 {
+		publish(context.Background(), s.publisher, events.TypeReconciliationMismatch, events.VersionReconciliationMismatch, events.ReconciliationMismatch{
+			ReportID:       report.ID.Hex(),
+			Transactions:   report.TransactionsChecked,
+			Payments:       report.PaymentsChecked,
+			Mismatches:     report.MismatchCount,
+			MismatchByKind: report.MismatchByKind,
+			FinishedAt:     now,
+		})
+	}
+}
+
+// Kumpulkan payment yang dipakai transaksi dan order, lalu cocokkan
+// dengan payment dari Payment Service halaman per halaman. Yang tersisa
+// di refs setelah semua payment dibaca tidak punya payment.
+func (s *reconciliationService) reconcile(report *domain.ReconciliationReport) error {
+	refs := make(map[string]paymentRef)
+
+	after := ""
+	for {
+		page, err := s.transactions.FindPage(after, reconcilePageSize)
+		if err != nil {
+			return fmt.Errorf("read transactions: %w", err)
+		}
+		for _, t := range page {
+			report.TransactionsChecked++
+			if t.PaymentID == "" && !transactionExpectsPayment(t.Status) {
+				continue
+			}
+			s.addRef(report, refs, t.PaymentID, paymentRef{domain.ReconciliationSourceTransaction, t.ID, t.Total, t.Status})
+		}
+		if len(page) < reconcilePageSize {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+
+	after = ""
+	for {
+		page, err := s.orders.FindPage(after, reconcilePageSize)
+		if err != nil {
+			return fmt.Errorf("read orders: %w", err)
+		}
+		for _, o := range page {
+			report.OrdersChecked++
+			s.addRef(report, refs, o.PaymentID, paymentRef{domain.ReconciliationSourceOrder, o.ID.Hex(), o.Total, o.Status})
+		}
+		if len(page) < reconcilePageSize {
+			break
+		}
+		after = page[len(page)-1].ID.Hex()
+	}
+
+	after = ""
+	for {
+		payments, next, err := s.payments.listPayments(context.Background(), paymentFilter{}, after, reconcilePageSize)
+		if err != nil {
+			return fmt.Errorf("list payments: %w", err)
+		}
+		for _, p := range payments {
+			report.PaymentsChecked++
+			ref, ok := refs[p.ID]
+			// payment tanpa transaksi yang sudah di-refund penuh adalah
+			// checkout yang dibatalkan setelah payment
+			if !ok && p.Status == "refunded" {
+				continue
+			}
+			if !ok {
+				addMismatch(report, domain.ReconciliationMismatch{
+					Kind:          domain.MismatchOrphanPayment,
+					PaymentID:     p.ID,
+					PaymentAmount: p.Amount,
+					PaymentStatus: p.Status,
+				})
+				continue
+			}
+			delete(refs, p.ID)
+
+			mismatch := ref.mismatch("", p.ID)
+			mismatch.PaymentAmount = p.Amount
+			mismatch.PaymentStatus = p.Status
+			if p.Amount != ref.amount {
+				mismatch.Kind = domain.MismatchAmount
+				addMismatch(report, mismatch)
+			}
+			if !paymentStatusMatches(ref.status, p.Status) {
+				mismatch.Kind = domain.MismatchStatus
+				addMismatch(report, mismatch)
+			}
+		}
+		if next == "" {
+			break
+		}
+		after = next
+	}
+
+	for paymentID, ref := range refs {
+		addMismatch(report, ref.mismatch(domain.MismatchMissingPayment, paymentID))
+	}
+	return nil
+}
+
+// daftarkan payment transaksi/order, tanpa payment langsung jadi mismatch
+func (s *reconciliationService) addRef(report *domain.ReconciliationReport, refs map[string]paymentRef, paymentID string, ref paymentRef) {
+	if paymentID == "" {
+		addMismatch(report, ref.mismatch(domain.MismatchMissingPayment, ""))
+		return
+	}
+	refs[paymentID] = ref
+}
+
+func (r paymentRef) mismatch(kind, paymentID string) domain.ReconciliationMismatch {
+	return domain.ReconciliationMismatch{
+		Kind:           kind,
+		Source:         r.source,
+		SourceID:       r.sourceID,
+		PaymentID:      paymentID,
+		ExpectedAmount: r.amount,
+		SourceStatus:   r.status,
+	}
+}
+
+func addMismatch(report *domain.ReconciliationReport, mismatch domain.ReconciliationMismatch) {
+	report.MismatchCount++
+	report.MismatchByKind[mismatch.Kind]++
+	if len(report.Mismatches) < maxReconcileMismatches {
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+}
+
+// Transaksi yang menunggu pembayaran atau dibatalkan sebelum dibayar
+// memang belum punya payment
+func transactionExpectsPayment(status string) bool {
+	return status != domain.TransactionPendingPayment && status != domain.TransactionCancelled
+}
+
+// Transaksi yang sudah diretur seluruhnya atau dibatalkan setelah dibayar
+// harus punya payment refunded, selain itu payment masih paid atau baru
+// sebagian di-refund.
+func paymentStatusMatches(sourceStatus, paymentStatus string) bool {
+	switch sourceStatus {
+	case domain.TransactionReturned, domain.TransactionCancelled:
+		return paymentStatus == "refunded"
+	}
+	return paymentSucceeded(paymentStatus) || paymentStatus == "partially_refunded"
+}
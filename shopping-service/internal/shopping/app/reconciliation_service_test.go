@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"shared/events"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+	"shopping-service/proto"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type pagedTransactions struct {
+	infra.TransactionRepository
+	items []domain.Transaction
+}
+
+func (p *pagedTransactions) FindPage(afterID string, limit int64) ([]domain.Transaction, error) {
+	start := 0
+	for i, t := range p.items {
+		if t.ID == afterID {
+			start = i + 1
+		}
+	}
+	return p.items[start:min(start+int(limit), len(p.items))], nil
+}
+
+type pagedOrders struct {
+	infra.OrderRepository
+	items []domain.Order
+}
+
+func (p *pagedOrders) FindPage(afterID string, limit int64) ([]domain.Order, error) {
+	if afterID != "" {
+		return nil, nil
+	}
+	return p.items[:min(int(limit), len(p.items))], nil
+}
+
+type memoryReconciliationRepo struct {
+	infra.ReconciliationRepository
+	saved *domain.ReconciliationReport
+}
+
+func (r *memoryReconciliationRepo) Insert(report *domain.ReconciliationReport) error {
+	report.ID = primitive.NewObjectID()
+	return nil
+}
+
+func (r *memoryReconciliationRepo) Update(report *domain.ReconciliationReport) error {
+	r.saved = report
+	return nil
+}
+
+func paymentPB(id, status string, minorUnits int64) *proto.Payment {
+	return &proto.Payment{Id: id, Status: status, Amount: &proto.Money{MinorUnits: minorUnits, Currency: "IDR"}}
+}
+
+func TestReconcile_PagesPaymentsOverGRPC(t *testing.T) {
+	idr := func(v int64) money.Money { return money.New(v, "IDR") }
+	transactions := &pagedTransactions{items: []domain.Transaction{
+		{ID: "t1", PaymentID: "pay-1", Total: idr(100), Status: domain.TransactionPaid},
+		{ID: "t2", Total: idr(100), Status: domain.TransactionPendingPayment},
+		{ID: "t3", Total: idr(100), Status: domain.TransactionCancelled},
+		{ID: "t4", PaymentID: "pay-4", Total: idr(100), Status: domain.TransactionCancelled},
+		{ID: "t5", PaymentID: "pay-5", Total: idr(100), Status: domain.TransactionCancelled},
+		{ID: "t6", PaymentID: "pay-6", Total: idr(100), Status: domain.TransactionReturned},
+		{ID: "t7", PaymentID: "pay-7", Total: idr(100), Status: domain.TransactionPaid},
+		{ID: "t8", Total: idr(100), Status: domain.TransactionPaid},
+	}}
+	orders := &pagedOrders{items: []domain.Order{
+		{ID: primitive.NewObjectID(), PaymentID: "pay-8", Total: idr(300), Status: domain.OrderStatusPaid},
+	}}
+
+	payments := &fakePaymentServer{payments: []*proto.Payment{
+		paymentPB("pay-1", "paid", 100),
+		paymentPB("pay-4", "refunded", 100),
+		paymentPB("pay-5", "paid", 100),
+		paymentPB("pay-6", "partially_refunded", 100),
+		paymentPB("pay-8", "paid", 250),
+	}}
+	// payment tanpa transaksi, cukup banyak untuk tiga halaman
+	for i := 0; i < 2*reconcilePageSize; i++ {
+		payments.payments = append(payments.payments, paymentPB(fmt.Sprintf("pay-x%03d", i), "paid", 100))
+	}
+
+	repo := &memoryReconciliationRepo{}
+	service := NewReconciliationService(repo, transactions, orders, events.NewMemoryBroker(), newTestPaymentClient(t, payments))
+
+	report, err := service.Run("test")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, domain.ReconciliationCompleted, report.Status, report.Message)
+	assert.Equal(t, len(payments.payments), report.PaymentsChecked)
+	assert.Equal(t, []int64{reconcilePageSize, reconcilePageSize, reconcilePageSize}, payments.pageSizes)
+	assert.Equal(t, map[string]int{
+		// t5 dibatalkan tapi payment belum di-refund, t6 diretur tapi baru sebagian
+		domain.MismatchStatus: 2,
+		// t7 payment tidak ada, t8 paid tanpa payment
+		domain.MismatchMissingPayment: 2,
+		domain.MismatchAmount:         1,
+		domain.MismatchOrphanPayment:  2 * reconcilePageSize,
+	}, report.MismatchByKind)
+}
+
+func TestPaymentStatusMatches(t *testing.T) {
+	tests := []struct {
+		source, payment string
+		want            bool
+	}{
+		{domain.TransactionPaid, "paid", true},
+		{domain.TransactionDelivered, "partially_refunded", true},
+		{domain.TransactionPaid, "refunded", false},
+		{domain.TransactionReturned, "refunded", true},
+		{domain.TransactionReturned, "partially_refunded", false},
+		{domain.TransactionCancelled, "refunded", true},
+		{domain.TransactionCancelled, "paid", false},
+		{domain.OrderStatusPaid, "success", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, paymentStatusMatches(tt.source, tt.payment), "%s/%s", tt.source, tt.payment)
+	}
+}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"shared/audit"
+	"shared/logging"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+)
+
+// Retur transaksi: pembeli mengajukan, lalu disetujui (refund lewat
+// Payment Service + stok kembali) atau ditolak.
+type ReturnService interface {
+	Request(transactionID string, input ReturnInput) (*domain.ReturnRequest, error)
+	// owner diisi untuk user biasa, retur harus milik owner. Kosong untuk admin
+	GetByID(id, owner string) (*domain.ReturnRequest, error)
+	ListByTransaction(transactionID, owner string) ([]domain.ReturnRequest, error)
+	// refund gagal membuat retur tetap approved, Approve bisa dipanggil ulang
+	Approve(id string, actor audit.Actor, note string) (*domain.ReturnRequest, error)
+	Reject(id string, actor audit.Actor, note string) (*domain.ReturnRequest, error)
+}
+
+// data permintaan retur, Actor harus pembeli transaksi
+type ReturnInput struct {
+	Actor    string
+	Quantity int
+	Reason   string
+	Note     string
+}
+
+type returnService struct {
+	repo         infra.ReturnRepository
+	transactions infra.TransactionRepository
+	workflow     TransactionService
+	inventory    InventoryService
+	payments     *PaymentClient
+}
+
+func NewReturnService(repo infra.ReturnRepository, transactions infra.TransactionRepository, workflow TransactionService, inventory InventoryService, payments *PaymentClient) ReturnService {
+	return &returnService{repo: repo, transactions: transactions, workflow: workflow, inventory: inventory, payments: payments}
+}
+
+// Ajukan retur. Quantity langsung dipesan di transaksi supaya total retur
+// tidak melebihi quantity yang dibeli.
+func (s *returnService) Request(transactionID string, input ReturnInput) (*domain.ReturnRequest, error) {
+	if input.Actor == "" {
+		return nil, ErrActorMissing
+	}
+	if input.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if !domain.ValidReturnReason(input.Reason) {
+		return nil, ErrReturnReasonInvalid
+	}
+
+	transaction, err := s.transactions.FindByID(transactionID)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if transaction.Email != input.Actor {
+		return nil, ErrReturnNotOwner
+	}
+	if transaction.Status != domain.TransactionDelivered {
+		return nil, ErrReturnNotAllowed
+	}
+	if transaction.PaymentID == "" {
+		return nil, ErrReturnNoPayment
+	}
+	if input.Quantity > transaction.Quantity-transaction.ReturnedQuantity {
+		return nil, ErrReturnQuantityExceeded
+	}
+
+	if err := s.transactions.AddReturnedQuantity(transactionID, input.Quantity); err != nil {
+		switch {
+		case errors.Is(err, infra.ErrReturnQuantityExceeded):
+			// retur lain masuk lebih dulu atau status sudah berubah
+			return nil, ErrReturnQuantityExceeded
+		case errors.Is(err, infra.ErrTransactionNotFound):
+			return nil, ErrTransactionNotFound
+		}
+		return nil, ErrTransactionUpdate
+	}
+
+	ret := &domain.ReturnRequest{
+		TransactionID: transaction.ID,
+		PaymentID:     transaction.PaymentID,
+		ProductID:     transaction.ProductID,
+		Email:         transaction.Email,
+		Quantity:      input.Quantity,
+		Reason:        input.Reason,
+		Note:          strings.TrimSpace(input.Note),
+		Status:        domain.ReturnRequested,
+		RefundAmount:  money.New(0, transaction.Total.Currency),
+	}
+	if err := s.repo.Insert(ret); err != nil {
+		s.releaseQuantity(transactionID, input.Quantity)
+		return nil, ErrReturnInsert
+	}
+	return ret, nil
+}
+
+func (s *returnService) GetByID(id, owner string) (*domain.ReturnRequest, error) {
+	ret, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, infra.ErrInvalidReturnID) {
+			return nil, ErrInvalidReturnID
+		}
+		return nil, ErrFailedDecode
+	}
+	if ret == nil {
+		return nil, ErrReturnNotFound
+	}
+	if owner != "" && ret.Email != owner {
+		return nil, ErrReturnNotOwner
+	}
+	return ret, nil
+}
+
+func (s *returnService) ListByTransaction(transactionID, owner string) ([]domain.ReturnRequest, error) {
+	transaction, err := s.transactions.FindByID(transactionID)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if owner != "" && transaction.Email != owner {
+		return nil, ErrReturnNotOwner
+	}
+	return s.repo.FindByTransaction(transactionID)
+}
+
+// Setujui retur lalu refund. Refund memakai ID retur sebagai reference
+// sehingga approve ulang setelah refund gagal (atau timeout) tidak
+// me-refund dua kali. Stok dikembalikan sekali, oleh request yang berhasil
+// memindahkan status ke refunded.
+func (s *returnService) Approve(id string, actor audit.Actor, note string) (*domain.ReturnRequest, error) {
+	if actor.Email == "" {
+		return nil, ErrActorMissing
+	}
+	ctx := audit.WithActor(logging.WithRequestID(context.Background(), actor.RequestID), actor)
+
+	ret, err := s.GetByID(id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	switch ret.Status {
+	case domain.ReturnRequested:
+		now := time.Now()
+		ret.Status = domain.ReturnApproved
+		ret.DecidedBy = actor.Email
+		ret.DecisionNote = strings.TrimSpace(note)
+		ret.DecidedAt = &now
+		if err := s.save(ret, domain.ReturnRequested); err != nil {
+			return nil, err
+		}
+	case domain.ReturnApproved:
+		// percobaan refund ulang
+	default:
+		return nil, ErrReturnDecided
+	}
+
+	transaction, err := s.transactions.FindByID(ret.TransactionID)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	amount, err := s.refundAmount(transaction, ret)
+	if err != nil {
+		return nil, err
+	}
+
+	refundID, err := s.payments.callRefund(ctx, ret.PaymentID, amount, ret.Reason, ret.ID.Hex())
+	if err != nil {
+		ret.LastError = err.Error()
+		if saveErr := s.save(ret, domain.ReturnApproved); saveErr != nil {
+			slog.ErrorContext(ctx, "record refund error failed", "return_id", ret.ID.Hex(), "error", saveErr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	now := time.Now()
+	ret.Status = domain.ReturnRefunded
+	ret.RefundAmount = amount
+	ret.RefundID = refundID
+	ret.RefundedAt = &now
+	ret.LastError = ""
+	if err := s.save(ret, domain.ReturnApproved); err != nil {
+		if errors.Is(err, ErrReturnDecided) {
+			// approve bersamaan sudah menyelesaikan retur ini
+			return s.GetByID(id, "")
+		}
+		return nil, err
+	}
+
+	if _, err := s.inventory.Return(ret.ProductID, ret.Quantity, ret.ID.Hex(), "return "+ret.Reason); err != nil {
+		slog.ErrorContext(ctx, "restock return failed", "return_id", ret.ID.Hex(), "product_id", ret.ProductID, "quantity", ret.Quantity, "error", err)
+	}
+
+	s.completeTransaction(ctx, transaction, actor)
+	return ret, nil
+}
+
+// Tolak retur, quantity yang dipesan dikembalikan ke transaksi
+func (s *returnService) Reject(id string, actor audit.Actor, note string) (*domain.ReturnRequest, error) {
+	if actor.Email == "" {
+		return nil, ErrActorMissing
+	}
+
+	ret, err := s.GetByID(id, "")
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != domain.ReturnRequested {
+		return nil, ErrReturnDecided
+	}
+
+	now := time.Now()
+	ret.Status = domain.ReturnRejected
+	ret.DecidedBy = actor.Email
+	ret.DecisionNote = strings.TrimSpace(note)
+	ret.DecidedAt = &now
+	if err := s.save(ret, domain.ReturnRequested); err != nil {
+		return nil, err
+	}
+
+	s.releaseQuantity(ret.TransactionID, ret.Quantity)
+	return ret, nil
+}
+
+// Nominal refund sebanding quantity retur. Retur yang melengkapi seluruh
+// quantity mendapat sisa total supaya pembulatan tidak menyisakan selisih.
+func (s *returnService) refundAmount(transaction *domain.Transaction, ret *domain.ReturnRequest) (money.Money, error) {
+	returns, err := s.repo.FindByTransaction(transaction.ID)
+	if err != nil {
+		return money.Money{}, ErrFailedDecode
+	}
+
+	refunded := money.New(0, transaction.Total.Currency)
+	refundedQty := 0
+	for _, r := range returns {
+		if r.Status != domain.ReturnRefunded || r.ID == ret.ID {
+			continue
+		}
+		refunded.MinorUnits += r.RefundAmount.MinorUnits
+		refundedQty += r.Quantity
+	}
+
+	if refundedQty+ret.Quantity >= transaction.Quantity {
+		return transaction.Total.Sub(refunded)
+	}
+	parts, err := transaction.Total.Allocate(int64(ret.Quantity), int64(transaction.Quantity-ret.Quantity))
+	if err != nil {
+		return money.Money{}, err
+	}
+	return parts[0], nil
+}
+
+// transaksi jadi returned setelah seluruh quantity ter-refund
+func (s *returnService) completeTransaction(ctx context.Context, transaction *domain.Transaction, actor audit.Actor) {
+	returns, err := s.repo.FindByTransaction(transaction.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "list returns of transaction failed", "transaction_id", transaction.ID, "error", err)
+		return
+	}
+	refundedQty := 0
+	for _, r := range returns {
+		if r.Status == domain.ReturnRefunded {
+			refundedQty += r.Quantity
+		}
+	}
+	if refundedQty < transaction.Quantity {
+		return
+	}
+
+	_, err = s.workflow.Transition(transaction.ID, domain.TransactionReturned, TransitionInput{Actor: actor.Email, Note: "all items returned", RequestID: actor.RequestID})
+	if err != nil && !errors.Is(err, ErrInvalidStatusTransition) {
+		slog.ErrorContext(ctx, "mark transaction returned failed", "transaction_id", transaction.ID, "error", err)
+	}
+}
+
+func (s *returnService) releaseQuantity(transactionID string, quantity int) {
+	if err := s.transactions.AddReturnedQuantity(transactionID, -quantity); err != nil {
+		slog.Error("release returned quantity failed", "transaction_id", transactionID, "error", err)
+	}
+}
+
+func (s *returnService) save(ret *domain.ReturnRequest, from string) error {
+	if err := s.repo.Save(ret, from); err != nil {
+		switch {
+		case errors.Is(err, infra.ErrInvalidReturnStatus):
+			return ErrReturnDecided
+		case errors.Is(err, infra.ErrReturnNotFound):
+			return ErrReturnNotFound
+		}
+		return ErrReturnUpdate
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"testing"
+
+	"shared/audit"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// repository retur di memory, Save hanya kalau status tersimpan masih from
+type memoryReturnRepo struct {
+	returns map[primitive.ObjectID]domain.ReturnRequest
+}
+
+func (r *memoryReturnRepo) Insert(ret *domain.ReturnRequest) error {
+	ret.ID = primitive.NewObjectID()
+	r.returns[ret.ID] = *ret
+	return nil
+}
+
+func (r *memoryReturnRepo) FindByID(id string) (*domain.ReturnRequest, error) {
+	objID, _ := primitive.ObjectIDFromHex(id)
+	ret, ok := r.returns[objID]
+	if !ok {
+		return nil, nil
+	}
+	return &ret, nil
+}
+
+func (r *memoryReturnRepo) FindByTransaction(transactionID string) ([]domain.ReturnRequest, error) {
+	var result []domain.ReturnRequest
+	for _, ret := range r.returns {
+		if ret.TransactionID == transactionID {
+			result = append(result, ret)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryReturnRepo) Save(ret *domain.ReturnRequest, from string) error {
+	if r.returns[ret.ID].Status != from {
+		return infra.ErrInvalidReturnStatus
+	}
+	r.returns[ret.ID] = *ret
+	return nil
+}
+
+type stubTransactions struct {
+	infra.TransactionRepository
+	transaction domain.Transaction
+}
+
+func (s *stubTransactions) FindByID(id string) (*domain.Transaction, error) {
+	t := s.transaction
+	return &t, nil
+}
+
+type stubWorkflow struct {
+	TransactionService
+	transitions []string
+}
+
+func (s *stubWorkflow) Transition(id, to string, input TransitionInput) (*domain.Transaction, error) {
+	s.transitions = append(s.transitions, to)
+	return nil, nil
+}
+
+type stubInventory struct {
+	InventoryService
+	returned int
+}
+
+func (s *stubInventory) Return(productID string, quantity int, reference, note string) (*domain.Product, error) {
+	s.returned += quantity
+	return nil, nil
+}
+
+func newApproveFixture(t *testing.T, payments *fakePaymentServer) (ReturnService, *memoryReturnRepo, *stubWorkflow, *stubInventory, string) {
+	repo := &memoryReturnRepo{returns: map[primitive.ObjectID]domain.ReturnRequest{}}
+	transactions := &stubTransactions{transaction: domain.Transaction{
+		ID:        "trx-1",
+		ProductID: "prod-1",
+		PaymentID: "pay-1",
+		Email:     "buyer@example.com",
+		Quantity:  2,
+		Total:     money.New(10000, "IDR"),
+		Status:    domain.TransactionDelivered,
+	}}
+	workflow := &stubWorkflow{}
+	inventory := &stubInventory{}
+	service := NewReturnService(repo, transactions, workflow, inventory, newTestPaymentClient(t, payments))
+
+	ret := &domain.ReturnRequest{TransactionID: "trx-1", PaymentID: "pay-1", ProductID: "prod-1", Email: "buyer@example.com", Quantity: 1, Reason: domain.ReturnReasonDamaged, Status: domain.ReturnRequested}
+	repo.Insert(ret)
+	return service, repo, workflow, inventory, ret.ID.Hex()
+}
+
+func TestReturnApprove_RefundsThroughPaymentGRPC(t *testing.T) {
+	payments := &fakePaymentServer{}
+	service, _, workflow, inventory, id := newApproveFixture(t, payments)
+
+	ret, err := service.Approve(id, audit.Actor{Email: "admin@example.com", RequestID: "req-1"}, "ok")
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, domain.ReturnRefunded, ret.Status)
+	assert.Equal(t, "refund-1", ret.RefundID)
+	assert.Equal(t, money.New(5000, "IDR"), ret.RefundAmount)
+	assert.Equal(t, 1, inventory.returned)
+	// baru 1 dari 2 unit diretur, transaksi belum returned
+	assert.Empty(t, workflow.transitions)
+
+	if assert.Len(t, payments.refunds, 1) {
+		req := payments.refunds[0]
+		assert.Equal(t, "pay-1", req.GetId())
+		assert.Equal(t, id, req.GetReference())
+		assert.Equal(t, int64(5000), req.GetAmount().GetMinorUnits())
+	}
+	assert.Equal(t, []string{"admin@example.com"}, payments.actors)
+}
+
+func TestReturnApprove_RefundFailureKeepsApproved(t *testing.T) {
+	payments := &fakePaymentServer{failWith: status.Error(codes.Aborted, "payment was refunded concurrently, retry")}
+	service, repo, _, inventory, id := newApproveFixture(t, payments)
+
+	_, err := service.Approve(id, audit.Actor{Email: "admin@example.com"}, "")
+
+	assert.ErrorIs(t, err, ErrRefundFailed)
+	stored, _ := repo.FindByID(id)
+	assert.Equal(t, domain.ReturnApproved, stored.Status)
+	assert.Equal(t, "payment was refunded concurrently, retry", stored.LastError)
+	assert.Zero(t, inventory.returned)
+
+	// approve ulang setelah payment pulih memakai reference yang sama
+	payments.failWith = nil
+	ret, err := service.Approve(id, audit.Actor{Email: "admin@example.com"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ReturnRefunded, ret.Status)
+	assert.Equal(t, id, payments.refunds[0].GetReference())
+}
+
+func TestReturnRead_OwnerOrAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		owner   string
+		wantErr error
+	}{
+		{name: "pembeli", owner: "buyer@example.com"},
+		{name: "admin", owner: ""},
+		{name: "user lain", owner: "other@example.com", wantErr: ErrReturnNotOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _, id := newApproveFixture(t, &fakePaymentServer{})
+
+			ret, err := service.GetByID(id, tt.owner)
+			returns, listErr := service.ListByTransaction("trx-1", tt.owner)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.ErrorIs(t, listErr, tt.wantErr)
+				assert.Nil(t, ret)
+				assert.Nil(t, returns)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, listErr)
+			assert.Equal(t, id, ret.ID.Hex())
+			assert.Len(t, returns, 1)
+		})
+	}
+}
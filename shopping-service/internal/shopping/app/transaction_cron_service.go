@@ -1,9 +1,10 @@
 package app
 
 import (
-	"fmt"
+	"log/slog"
 	"time"
 
+	"shared/metrics"
 	"shopping-service/internal/shopping/infra"
 
 	"github.com/robfig/cron/v3"
@@ -11,20 +12,23 @@ import (
 
 // StartTransactionCron menjalankan cron job harian
 // untuk menghapus transaksi dengan status "failed" yang usianya lebih dari 24 jam
-func StartTransactionCron(repo infra.TransactionRepository) {
+func StartTransactionCron(repo infra.TransactionRepository) *cron.Cron {
 	c := cron.New()
 
 	// jalankan setiap hari pukul 01:00
 	spec := "0 1 * * *"
 
 	c.AddFunc(spec, func() {
+		start := time.Now()
 		err := repo.DeleteFailedOlderThan(24 * time.Hour)
+		metrics.ObserveJob("transaction_cleanup", start, err)
 		if err != nil {
-			fmt.Printf("[CRON ERROR] Gagal hapus transaksi failed: %v\n", err)
+			slog.Error("delete failed transactions failed", "job", "transaction_cleanup", "error", err)
 			return
 		}
-		fmt.Println("[CRON INFO] Transaksi failed > 24 jam berhasil dihapus")
+		slog.Info("failed transactions older than 24h deleted", "job", "transaction_cleanup")
 	})
 
 	c.Start()
+	return c
 }
@@ -1,35 +1,67 @@
 package app
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
-	"net/http"
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
+	"shared/audit"
+	"shared/events"
+	"shared/logging"
+	"shopping-service/internal/pricing"
 	"shopping-service/internal/shopping/domain"
 	"shopping-service/internal/shopping/infra"
 )
 
 type TransactionService interface {
-	Create(transaction *domain.Transaction) error
+	Create(ctx context.Context, transaction *domain.Transaction, actor audit.Actor) error
 	GetAll() ([]domain.Transaction, error)
 	GetByID(id string) (*domain.Transaction, error)
-	Update(id string, transaction *domain.Transaction) error
-	Delete(id string) error
+	// owner diisi untuk user biasa: transaksi harus miliknya dan email tidak bisa diubah
+	Update(id string, transaction *domain.Transaction, owner string, actor audit.Actor) error
+	// soft delete, transaksi bisa dikembalikan lewat Restore
+	Delete(id string, actor audit.Actor) error
+	GetDeleted() ([]domain.Transaction, error)
+	Restore(id string, actor audit.Actor) error
+	// pindahkan status transaksi sesuai workflow, lihat domain.Transaction.CanTransitionTo
+	Transition(id, to string, input TransitionInput) (*domain.Transaction, error)
+}
+
+// data perpindahan status, Carrier dan TrackingNumber wajib untuk shipped
+type TransitionInput struct {
+	Actor          string
+	RequestID      string
+	Note           string
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+
+	// kalau diisi, transaksi harus milik email ini (aksi pembeli)
+	Owner string
 }
 
 type transactionService struct {
-	repo infra.TransactionRepository
+	repo       infra.TransactionRepository
+	products   *infra.ProductRepo
+	inventory  InventoryService
+	promotions PromotionService
+	pricing    *pricing.Pipeline
+	publisher  events.Publisher
+	audit      audit.Log
+	payments   *PaymentClient
 }
 
-func NewTransactionService(repo infra.TransactionRepository) TransactionService {
-	return &transactionService{repo: repo}
+// nama resource transaksi di audit log
+const transactionAuditResource = "transaction"
+
+func NewTransactionService(repo infra.TransactionRepository, products *infra.ProductRepo, inventory InventoryService, promotions PromotionService, pricer *pricing.Pipeline, publisher events.Publisher, auditLog audit.Log, payments *PaymentClient) TransactionService {
+	return &transactionService{repo: repo, products: products, inventory: inventory, promotions: promotions, pricing: pricer, publisher: publisher, audit: auditLog, payments: payments}
 }
 
-func (s *transactionService) Create(transaction *domain.Transaction) error {
+func (s *transactionService) Create(ctx context.Context, transaction *domain.Transaction, actor audit.Actor) error {
 	// validasi email harus mengandung @
 	if !strings.Contains(transaction.Email, "@") {
 		return ErrInvalidEmail
@@ -45,80 +77,122 @@ func (s *transactionService) Create(transaction *domain.Transaction) error {
 		return ErrInvalidQuantity
 	}
 
-	// hitung total = harga * quantity
-	hargaSatuan := 10000.0
-	transaction.Total = float64(transaction.Quantity) * hargaSatuan
-
-	// panggil Payment Service
-	err := s.callPayment(transaction.Email, transaction.Total, &transaction.PaymentID, &transaction.Status)
+	// harga satuan dan berat untuk ongkir dari product saat ini
+	product, err := s.products.GetProductByID(transaction.ProductID)
 	if err != nil {
-		return ErrPaymentRequest
-	}
-
-	// transaksi gagal jika status bukan success
-	if transaction.Status != "success" {
-		return ErrPaymentFailed
+		return fmt.Errorf("%w: %s", ErrProductNotFound, transaction.ProductID)
 	}
 
-	// simpan transaksi ke database
-	return s.repo.Insert(transaction)
-}
-
-func (s *transactionService) callPayment(email string, amount float64, paymentID *string, status *string) error {
-	// ambil URL payment dari ENV atau default
-	paymentURL := os.Getenv("PAYMENT_URL")
-	if paymentURL == "" {
-		paymentURL = "http://localhost:8081/payments"
+	// potongan kupon, pemakaian langsung dipesan
+	line := pricing.Line{ProductID: transaction.ProductID, UnitPrice: product.Price, Quantity: transaction.Quantity, WeightGrams: product.WeightGrams}
+	var applied *AppliedPromotion
+	if transaction.CouponCode != "" {
+		applied, err = s.promotions.Apply(transaction.Email, transaction.CouponCode, []PromotionLine{
+			{ProductID: line.ProductID, UnitPrice: line.UnitPrice, Quantity: line.Quantity},
+		})
+		if err != nil {
+			return err
+		}
+		transaction.CouponCode = applied.Promotion.Code
+		line.Discount = applied.Discount
 	}
 
-	// buat body request
-	payload := map[string]interface{}{
-		"email":  email,
-		"amount": amount,
-	}
-	jsonBody, err := json.Marshal(payload)
+	// total = subtotal - discount + pajak + ongkir
+	breakdown, err := priceQuote(s.pricing, pricing.Quote{
+		Region:         transaction.Region,
+		ShippingMethod: transaction.ShippingMethod,
+		Lines:          []pricing.Line{line},
+	})
 	if err != nil {
+		s.promotions.Release(applied)
 		return err
 	}
+	transaction.Pricing = breakdown
+	transaction.Total = breakdown.Total
 
-	// kirim POST request
-	req, err := http.NewRequest("POST", paymentURL, bytes.NewBuffer(jsonBody))
+	// tahan stok lewat reservasi (tercatat di ledger) sebelum payment
+	ctx = context.WithoutCancel(ctx)
+	reservation, err := s.inventory.Reserve(transaction.ProductID, transaction.Quantity, transaction.Email)
 	if err != nil {
+		s.promotions.Release(applied)
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	// panggil Payment Service (request ID dan trace dari ctx), tetap
+	// diselesaikan walau client memutus request
+	err = s.payments.callPayment(ctx, transaction.Email, transaction.Total, transaction.PaymentMethod, transaction.CardToken, &transaction.PaymentID, &transaction.Status)
 	if err != nil {
-		return err
+		s.releaseReservation(ctx, reservation)
+		s.promotions.Release(applied)
+		return ErrPaymentRequest
 	}
-	defer resp.Body.Close()
 
-	// jika status bukan 200, anggap gagal
-	if resp.StatusCode != http.StatusOK {
-		*status = "failed"
-		return nil
+	// transaksi gagal jika payment tidak berhasil
+	if !paymentSucceeded(transaction.Status) {
+		s.releaseReservation(ctx, reservation)
+		s.promotions.Release(applied)
+		return ErrPaymentFailed
 	}
 
-	// decode response dari payment service
-	var result struct {
-		Status    string `json:"status"`
-		PaymentID string `json:"payment_id"`
+	// payment sudah terjadi, reservasi jadi penjualan dengan reference payment
+	// (ID transaksi baru ada setelah insert)
+	if err := s.inventory.Commit(reservation, transaction.PaymentID); err != nil {
+		slog.ErrorContext(ctx, "commit reservation failed", "reservation_id", reservation.ID.Hex(), "payment_id", transaction.PaymentID, "error", err)
+		s.releaseReservation(ctx, reservation)
+		return s.revert(ctx, transaction, nil, applied, err)
 	}
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	transaction.Status = domain.TransactionPaid
+	transaction.StatusHistory = []domain.TransactionStatusChange{
+		{To: domain.TransactionPaid, Actor: transaction.Email, At: time.Now()},
 	}
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return err
+
+	// simpan transaksi ke database. Gagal di sini payment sudah terjadi,
+	// jadi stok dikembalikan, kupon dilepas dan payment di-refund
+	if err := s.repo.Insert(transaction); err != nil {
+		slog.ErrorContext(ctx, "insert transaction failed after payment", "payment_id", transaction.PaymentID, "error", err)
+		return s.revert(ctx, transaction, reservation, applied, ErrTransactionInsert)
 	}
+	s.promotions.Confirm(applied, transaction.ID)
+	transactionsByStatus.WithLabelValues(transaction.Status).Inc()
+	s.record(transaction.ID, audit.ActionCreate, actor, nil, transaction)
+
+	publish(ctx, s.publisher, events.TypeTransactionCreated, events.VersionTransactionCreated, events.TransactionCreated{
+		TransactionID: transaction.ID,
+		PaymentID:     transaction.PaymentID,
+		ProductID:     transaction.ProductID,
+		Email:         transaction.Email,
+		Quantity:      transaction.Quantity,
+		Total:         transaction.Total,
+		Status:        transaction.Status,
+	})
 
-	*status = result.Status
-	*paymentID = result.PaymentID
 	return nil
 }
 
+// lepas reservasi transaksi yang batal, yang sudah expired dilepas cron
+func (s *transactionService) releaseReservation(ctx context.Context, reservation *domain.StockReservation) {
+	if err := s.inventory.Release(reservation); err != nil && !errors.Is(err, ErrReservationNotActive) {
+		slog.ErrorContext(ctx, "release reservation failed", "reservation_id", reservation.ID.Hex(), "error", err)
+	}
+}
+
+// Batalkan transaksi yang gagal setelah payment berhasil: stok yang sudah
+// terjual (sold) dikembalikan, kupon dilepas dan payment di-refund penuh
+func (s *transactionService) revert(ctx context.Context, transaction *domain.Transaction, sold *domain.StockReservation, applied *AppliedPromotion, cause error) error {
+	if sold != nil {
+		if _, err := s.inventory.Return(sold.ProductID, sold.Quantity, transaction.PaymentID, "transaction reverted"); err != nil {
+			slog.ErrorContext(ctx, "return stock of reverted transaction failed", "product_id", sold.ProductID, "quantity", sold.Quantity, "payment_id", transaction.PaymentID, "error", err)
+		}
+	}
+	s.promotions.Release(applied)
+
+	if err := s.payments.revertPayment(ctx, transaction.Email, transaction.PaymentID, transaction.Total); err != nil {
+		slog.ErrorContext(ctx, "refund of reverted transaction failed", "payment_id", transaction.PaymentID, "error", err)
+		return fmt.Errorf("%w: %w", ErrCheckoutRefundFailed, cause)
+	}
+	return fmt.Errorf("%w: %w", ErrCheckoutReverted, cause)
+}
+
 // ambil semua transaksi
 func (s *transactionService) GetAll() ([]domain.Transaction, error) {
 	return s.repo.FindAll()
@@ -129,12 +203,265 @@ func (s *transactionService) GetByID(id string) (*domain.Transaction, error) {
 	return s.repo.FindByID(id)
 }
 
-// update transaksi
-func (s *transactionService) Update(id string, transaction *domain.Transaction) error {
-	return s.repo.Update(id, transaction)
+// Update email, product dan quantity transaksi sesuai status (lihat
+// ItemsEditable dan EmailEditable). Kalau owner diisi (bukan admin)
+// transaksi harus milik owner dan email tidak boleh diubah. Product/quantity
+// yang berubah dihitung ulang totalnya. transaction.Version harus sama
+// dengan versi yang tersimpan, versi baru diisi ke transaction.
+func (s *transactionService) Update(id string, transaction *domain.Transaction, owner string, actor audit.Actor) error {
+	if !strings.Contains(transaction.Email, "@") {
+		return ErrInvalidEmail
+	}
+	if transaction.ProductID == "" {
+		return ErrProductIDMissing
+	}
+	if transaction.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	existing, err := s.repo.FindByID(id)
+	if err != nil {
+		return ErrFailedDecode
+	}
+	if existing == nil {
+		return ErrTransactionNotFound
+	}
+	if owner != "" && existing.Email != owner {
+		return ErrTransactionNotOwner
+	}
+	if owner != "" && transaction.Email != existing.Email {
+		return ErrTransactionEmailAdminOnly
+	}
+
+	itemsChanged := transaction.ProductID != existing.ProductID || transaction.Quantity != existing.Quantity
+	if itemsChanged && !existing.ItemsEditable() {
+		return ErrTransactionItemsLocked
+	}
+	if transaction.Email != existing.Email && !existing.EmailEditable() {
+		return ErrTransactionEmailLocked
+	}
+
+	// quantity tidak boleh kurang dari yang sudah diretur
+	if transaction.Quantity < existing.ReturnedQuantity {
+		return ErrReturnQuantityExceeded
+	}
+
+	// field lain (status, payment) tetap dari data tersimpan
+	updated := *existing
+	updated.Email = transaction.Email
+	updated.ProductID = transaction.ProductID
+	updated.Quantity = transaction.Quantity
+	updated.Version = transaction.Version
+
+	// belum dibayar, total dihitung ulang dengan region & method yang sama
+	if itemsChanged {
+		product, err := s.products.GetProductByID(updated.ProductID)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrProductNotFound, updated.ProductID)
+		}
+		breakdown, err := priceQuote(s.pricing, pricing.Quote{
+			Region:         existing.Pricing.TaxRegion,
+			ShippingMethod: existing.Pricing.ShippingMethod,
+			Lines:          []pricing.Line{{ProductID: updated.ProductID, UnitPrice: product.Price, Quantity: updated.Quantity, WeightGrams: product.WeightGrams}},
+		})
+		if err != nil {
+			return err
+		}
+		updated.Pricing = breakdown
+		updated.Total = breakdown.Total
+	}
+
+	if err := s.repo.Update(id, &updated); err != nil {
+		switch {
+		case errors.Is(err, infra.ErrVersionConflict):
+			return ErrVersionConflict
+		case errors.Is(err, infra.ErrTransactionNotFound):
+			return ErrTransactionNotFound
+		}
+		return ErrTransactionUpdate
+	}
+
+	s.record(id, audit.ActionUpdate, actor, existing, &updated)
+	*transaction = updated
+	return nil
+}
+
+// hapus transaksi (soft delete)
+func (s *transactionService) Delete(id string, actor audit.Actor) error {
+	before, err := s.repo.Delete(id, actor.Email)
+	if err != nil {
+		return transactionRepoError(err, ErrTransactionDelete)
+	}
+
+	deleted := *before
+	now := time.Now()
+	deleted.DeletedAt, deleted.DeletedBy = &now, actor.Email
+	s.record(id, audit.ActionDelete, actor, before, &deleted)
+	return nil
+}
+
+// transaksi yang di-soft delete, untuk admin
+func (s *transactionService) GetDeleted() ([]domain.Transaction, error) {
+	transactions, err := s.repo.FindDeleted()
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	return transactions, nil
 }
 
-// hapus transaksi
-func (s *transactionService) Delete(id string) error {
-	return s.repo.Delete(id)
+// kembalikan transaksi yang di-soft delete
+func (s *transactionService) Restore(id string, actor audit.Actor) error {
+	before, err := s.repo.Restore(id)
+	if err != nil {
+		return transactionRepoError(err, ErrTransactionUpdate)
+	}
+
+	restored := *before
+	restored.DeletedAt, restored.DeletedBy = nil, ""
+	s.record(id, audit.ActionRestore, actor, before, &restored)
+	return nil
+}
+
+// ubah error repo jadi error app, fallback untuk error database
+func transactionRepoError(err error, fallback error) error {
+	switch {
+	case errors.Is(err, infra.ErrInvalidTransactionID):
+		return ErrInvalidTransactionID
+	case errors.Is(err, infra.ErrTransactionNotFound):
+		return ErrTransactionNotFound
+	}
+	return fallback
+}
+
+// catat perubahan ke audit log, gagal mencatat tidak menggagalkan perubahan
+func (s *transactionService) record(id, action string, actor audit.Actor, before, after *domain.Transaction) {
+	ctx := logging.WithRequestID(context.Background(), actor.RequestID)
+	s.audit.Record(ctx, audit.NewEntry(transactionAuditResource, id, action, actor, before, after))
+}
+
+// Pindahkan status transaksi. Perpindahan dicek terhadap workflow dan
+// disimpan atomic (status lama ikut di filter), lalu event
+// transaction.status_changed dikirim. Cancel setelah dibayar me-refund
+// payment sebelum status disimpan dan mengembalikan stok sesudahnya.
+func (s *transactionService) Transition(id, to string, input TransitionInput) (*domain.Transaction, error) {
+	if input.Actor == "" {
+		return nil, ErrActorMissing
+	}
+
+	transaction, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, ErrFailedDecode
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if input.Owner != "" && transaction.Email != input.Owner {
+		return nil, ErrTransactionNotOwner
+	}
+	if !transaction.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, transaction.Status, to)
+	}
+
+	// dana dikembalikan dulu supaya transaksi tidak pernah cancelled tanpa
+	// refund. Reference per transaksi, cancel ulang tidak me-refund dua kali.
+	ctx := audit.WithActor(logging.WithRequestID(context.Background(), input.RequestID), audit.Actor{Email: input.Actor, RequestID: input.RequestID})
+	refunded := to == domain.TransactionCancelled && transaction.Status != domain.TransactionPendingPayment
+	if refunded {
+		if err := s.refundCancelled(ctx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	change := domain.TransactionStatusChange{
+		From:  transaction.Status,
+		To:    to,
+		Actor: input.Actor,
+		Note:  strings.TrimSpace(input.Note),
+		At:    now,
+	}
+
+	var shipment *domain.Shipment
+	switch to {
+	case domain.TransactionShipped:
+		if strings.TrimSpace(input.Carrier) == "" || strings.TrimSpace(input.TrackingNumber) == "" {
+			return nil, ErrTrackingMissing
+		}
+		shipment = &domain.Shipment{
+			Carrier:        strings.TrimSpace(input.Carrier),
+			TrackingNumber: strings.TrimSpace(input.TrackingNumber),
+			TrackingURL:    strings.TrimSpace(input.TrackingURL),
+			ShippedAt:      now,
+		}
+	case domain.TransactionDelivered:
+		if transaction.Shipment != nil {
+			delivered := *transaction.Shipment
+			delivered.DeliveredAt = &now
+			shipment = &delivered
+		}
+	}
+
+	if err := s.repo.Transition(id, transaction.Status, change, shipment); err != nil {
+		if refunded {
+			slog.ErrorContext(ctx, "transaction refunded but cancel was not saved", "transaction_id", id, "payment_id", transaction.PaymentID, "error", err)
+		}
+		switch {
+		case errors.Is(err, infra.ErrInvalidStatusTransition):
+			// status sudah diubah request lain sejak dibaca
+			return nil, fmt.Errorf("%w: %s changed concurrently", ErrInvalidStatusTransition, transaction.Status)
+		case errors.Is(err, infra.ErrTransactionNotFound):
+			return nil, ErrTransactionNotFound
+		}
+		return nil, ErrTransactionUpdate
+	}
+
+	before := *transaction
+	transaction.Status = to
+	transaction.StatusHistory = append(transaction.StatusHistory, change)
+	if shipment != nil {
+		transaction.Shipment = shipment
+	}
+	transaction.Version++
+	transactionsByStatus.WithLabelValues(to).Inc()
+	if refunded {
+		s.restockCancelled(ctx, transaction)
+	}
+	s.record(id, audit.ActionUpdate, audit.Actor{Email: input.Actor, RequestID: input.RequestID}, &before, transaction)
+
+	payload := events.TransactionStatusChanged{
+		TransactionID: transaction.ID,
+		Email:         transaction.Email,
+		From:          change.From,
+		To:            change.To,
+		Actor:         change.Actor,
+		Note:          change.Note,
+		ChangedAt:     now,
+	}
+	if transaction.Shipment != nil {
+		payload.Carrier = transaction.Shipment.Carrier
+		payload.TrackingNumber = transaction.Shipment.TrackingNumber
+	}
+	publish(ctx, s.publisher, events.TypeTransactionStatusChanged, events.VersionTransactionStatusChanged, payload)
+
+	return transaction, nil
+}
+
+// refund penuh transaksi yang dibatalkan setelah dibayar, actor dari ctx
+func (s *transactionService) refundCancelled(ctx context.Context, transaction *domain.Transaction) error {
+	if transaction.PaymentID == "" {
+		return ErrCancelNoPayment
+	}
+	if _, err := s.payments.callRefund(ctx, transaction.PaymentID, transaction.Total, "transaction cancelled", "cancel-"+transaction.ID); err != nil {
+		slog.ErrorContext(ctx, "refund of cancelled transaction failed", "transaction_id", transaction.ID, "payment_id", transaction.PaymentID, "error", err)
+		return fmt.Errorf("%w: %w", ErrCancelRefundFailed, err)
+	}
+	return nil
+}
+
+// Kembalikan stok yang sudah terjual ke inventory (tercatat di ledger).
+// Status sudah cancelled, gagal di sini hanya dicatat untuk ditangani manual.
+func (s *transactionService) restockCancelled(ctx context.Context, transaction *domain.Transaction) {
+	if _, err := s.inventory.Return(transaction.ProductID, transaction.Quantity, transaction.ID, "transaction cancelled"); err != nil {
+		slog.ErrorContext(ctx, "return stock of cancelled transaction failed", "transaction_id", transaction.ID, "product_id", transaction.ProductID, "error", err)
+	}
 }
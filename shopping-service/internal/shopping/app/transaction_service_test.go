@@ -0,0 +1,230 @@
+package app
+
+import (
+	"testing"
+
+	"shared/audit"
+	"shared/events"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"shopping-service/internal/shopping/infra"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// repository transaksi di memory, Update mengikuti version seperti Mongo
+type memoryTransactionRepo struct {
+	infra.TransactionRepository
+	transactions map[string]domain.Transaction
+}
+
+func newMemoryTransactionRepo(transactions ...domain.Transaction) *memoryTransactionRepo {
+	repo := &memoryTransactionRepo{transactions: map[string]domain.Transaction{}}
+	for _, t := range transactions {
+		repo.transactions[t.ID] = t
+	}
+	return repo
+}
+
+func (r *memoryTransactionRepo) FindByID(id string) (*domain.Transaction, error) {
+	t, ok := r.transactions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (r *memoryTransactionRepo) Update(id string, transaction *domain.Transaction) error {
+	stored, ok := r.transactions[id]
+	if !ok {
+		return infra.ErrTransactionNotFound
+	}
+	if stored.Version != transaction.Version {
+		return infra.ErrVersionConflict
+	}
+	transaction.Version++
+	r.transactions[id] = *transaction
+	return nil
+}
+
+// status disimpan hanya kalau status tersimpan masih from
+func (r *memoryTransactionRepo) Transition(id, from string, change domain.TransactionStatusChange, shipment *domain.Shipment) error {
+	stored, ok := r.transactions[id]
+	if !ok {
+		return infra.ErrTransactionNotFound
+	}
+	if stored.Status != from {
+		return infra.ErrInvalidStatusTransition
+	}
+	stored.Status = change.To
+	stored.StatusHistory = append(stored.StatusHistory, change)
+	if shipment != nil {
+		stored.Shipment = shipment
+	}
+	stored.Version++
+	r.transactions[id] = stored
+	return nil
+}
+
+func newTransitionFixture(t *testing.T, payments *fakePaymentServer, status string) (*transactionService, *memoryTransactionRepo, *recordingInventory) {
+	repo := newMemoryTransactionRepo(domain.Transaction{
+		ID:        "trx-1",
+		ProductID: "prod-1",
+		PaymentID: "pay-1",
+		Email:     "buyer@example.com",
+		Quantity:  2,
+		Total:     money.New(20000, "IDR"),
+		Status:    status,
+	})
+	inventory := &recordingInventory{}
+	service := &transactionService{
+		repo:      repo,
+		inventory: inventory,
+		publisher: events.NewMemoryBroker(),
+		audit:     audit.NewMemoryLog(),
+		payments:  newTestPaymentClient(t, payments),
+	}
+	return service, repo, inventory
+}
+
+func TestTransactionCancel_PaidRefundsAndReturnsStock(t *testing.T) {
+	payments := &fakePaymentServer{}
+	service, repo, inventory := newTransitionFixture(t, payments, domain.TransactionPaid)
+
+	tx, err := service.Transition("trx-1", domain.TransactionCancelled, TransitionInput{Actor: "buyer@example.com", Owner: "buyer@example.com"})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, domain.TransactionCancelled, tx.Status)
+	assert.Equal(t, domain.TransactionCancelled, repo.transactions["trx-1"].Status)
+	assert.Equal(t, []string{"prod-1"}, inventory.returned)
+	if assert.Len(t, payments.refunds, 1) {
+		req := payments.refunds[0]
+		assert.Equal(t, "pay-1", req.GetId())
+		assert.Equal(t, "cancel-trx-1", req.GetReference())
+		assert.Equal(t, int64(20000), req.GetAmount().GetMinorUnits())
+	}
+	assert.Equal(t, []string{"buyer@example.com"}, payments.actors)
+}
+
+func TestTransactionCancel_RefundFailureKeepsStatus(t *testing.T) {
+	payments := &fakePaymentServer{failWith: status.Error(codes.Unavailable, "provider down")}
+	service, repo, inventory := newTransitionFixture(t, payments, domain.TransactionPacked)
+
+	_, err := service.Transition("trx-1", domain.TransactionCancelled, TransitionInput{Actor: "buyer@example.com"})
+
+	assert.ErrorIs(t, err, ErrCancelRefundFailed)
+	assert.Equal(t, domain.TransactionPacked, repo.transactions["trx-1"].Status)
+	assert.Empty(t, inventory.returned)
+
+	// cancel ulang setelah payment pulih memakai reference yang sama
+	payments.failWith = nil
+	_, err = service.Transition("trx-1", domain.TransactionCancelled, TransitionInput{Actor: "buyer@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cancel-trx-1", payments.refunds[0].GetReference())
+}
+
+func TestTransactionTransition_BuyerOnly(t *testing.T) {
+	payments := &fakePaymentServer{}
+	service, repo, _ := newTransitionFixture(t, payments, domain.TransactionPendingPayment)
+
+	_, err := service.Transition("trx-1", domain.TransactionPaid, TransitionInput{Actor: "other@example.com", Owner: "other@example.com"})
+	assert.ErrorIs(t, err, ErrTransactionNotOwner)
+	assert.Equal(t, domain.TransactionPendingPayment, repo.transactions["trx-1"].Status)
+
+	// belum dibayar, cancel tidak me-refund
+	_, err = service.Transition("trx-1", domain.TransactionCancelled, TransitionInput{Actor: "buyer@example.com", Owner: "buyer@example.com"})
+	assert.NoError(t, err)
+	assert.Empty(t, payments.refunds)
+}
+
+func TestTransactionUpdate_MutableFieldsByStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		coupon   string
+		email    string
+		quantity int
+		wantErr  error
+	}{
+		{name: "paid ganti quantity", status: domain.TransactionPaid, email: "buyer@example.com", quantity: 3, wantErr: ErrTransactionItemsLocked},
+		{name: "pending berkupon ganti quantity", status: domain.TransactionPendingPayment, coupon: "HEMAT", email: "buyer@example.com", quantity: 3, wantErr: ErrTransactionItemsLocked},
+		{name: "paid ganti email", status: domain.TransactionPaid, email: "new@example.com", quantity: 2},
+		{name: "packed ganti email", status: domain.TransactionPacked, email: "new@example.com", quantity: 2},
+		{name: "shipped ganti email", status: domain.TransactionShipped, email: "new@example.com", quantity: 2, wantErr: ErrTransactionEmailLocked},
+		{name: "cancelled ganti email", status: domain.TransactionCancelled, email: "new@example.com", quantity: 2, wantErr: ErrTransactionEmailLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryTransactionRepo(domain.Transaction{
+				ID:         "trx-1",
+				ProductID:  "prod-1",
+				Email:      "buyer@example.com",
+				Quantity:   2,
+				Total:      money.New(20000, "IDR"),
+				Status:     tt.status,
+				CouponCode: tt.coupon,
+			})
+			service := &transactionService{repo: repo, audit: audit.NewMemoryLog()}
+
+			update := &domain.Transaction{ProductID: "prod-1", Email: tt.email, Quantity: tt.quantity}
+			err := service.Update("trx-1", update, "", audit.Actor{Email: "admin@example.com"})
+
+			stored := repo.transactions["trx-1"]
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, "buyer@example.com", stored.Email)
+				assert.Equal(t, 2, stored.Quantity)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.email, stored.Email)
+			// total dan status tidak ikut berubah
+			assert.Equal(t, money.New(20000, "IDR"), stored.Total)
+			assert.Equal(t, tt.status, stored.Status)
+			assert.Equal(t, int64(1), update.Version)
+		})
+	}
+}
+
+func TestTransactionUpdate_Owner(t *testing.T) {
+	tests := []struct {
+		name    string
+		owner   string
+		email   string
+		wantErr error
+	}{
+		{name: "pembeli tanpa ganti email", owner: "buyer@example.com", email: "buyer@example.com"},
+		{name: "bukan pembeli", owner: "other@example.com", email: "buyer@example.com", wantErr: ErrTransactionNotOwner},
+		{name: "bukan pembeli ambil alih email", owner: "other@example.com", email: "other@example.com", wantErr: ErrTransactionNotOwner},
+		{name: "pembeli ganti email", owner: "buyer@example.com", email: "new@example.com", wantErr: ErrTransactionEmailAdminOnly},
+		{name: "admin ganti email", email: "new@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryTransactionRepo(domain.Transaction{
+				ID:        "trx-1",
+				ProductID: "prod-1",
+				Email:     "buyer@example.com",
+				Quantity:  2,
+				Total:     money.New(20000, "IDR"),
+				Status:    domain.TransactionPaid,
+			})
+			service := &transactionService{repo: repo, audit: audit.NewMemoryLog()}
+
+			update := &domain.Transaction{ProductID: "prod-1", Email: tt.email, Quantity: 2}
+			err := service.Update("trx-1", update, tt.owner, audit.Actor{Email: tt.owner})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, "buyer@example.com", repo.transactions["trx-1"].Email)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.email, repo.transactions["trx-1"].Email)
+		})
+	}
+}
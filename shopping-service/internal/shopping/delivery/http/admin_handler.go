@@ -0,0 +1,221 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"shared/audit"
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handler admin: data yang dihapus, restore dan audit log. Akses admin
+// dicek di gateway (ADMIN_EMAILS).
+type AdminHandler struct {
+	Products     *app.ProductService
+	Transactions app.TransactionService
+	Audit        audit.Log
+}
+
+// init handler admin
+func NewAdminHandler(products *app.ProductService, transactions app.TransactionService, auditLog audit.Log) *AdminHandler {
+	return &AdminHandler{Products: products, Transactions: transactions, Audit: auditLog}
+}
+
+// ListDeletedProducts godoc
+// @Summary Daftar product yang dihapus (admin)
+// @Description Product yang diarsipkan beserta archived_at dan archived_by, terbaru dulu
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} domain.Product
+// @Failure 500 {object} map[string]any
+// @Router /admin/products/deleted [get]
+func (h *AdminHandler) ListDeletedProducts(c echo.Context) error {
+	products, err := h.Products.ArchivedProducts()
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, products)
+}
+
+// DeleteProduct godoc
+// @Summary Hapus product (admin)
+// @Description Soft delete product, sama dengan DELETE /products/{id}
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /admin/products/{id} [delete]
+func (h *AdminHandler) DeleteProduct(c echo.Context) error {
+	if err := h.Products.DeleteProduct(c.Param("id"), auditActor(c)); err != nil {
+		return productAdminError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"message": "product deleted"})
+}
+
+// RestoreProduct godoc
+// @Summary Kembalikan product yang dihapus (admin)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /admin/products/{id}/restore [post]
+func (h *AdminHandler) RestoreProduct(c echo.Context) error {
+	if err := h.Products.RestoreProduct(c.Param("id"), auditActor(c)); err != nil {
+		return productAdminError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"message": "product restored"})
+}
+
+// ListDeletedTransactions godoc
+// @Summary Daftar transaksi yang dihapus (admin)
+// @Description Transaksi yang di-soft delete beserta deleted_at dan deleted_by, terbaru dulu
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} domain.Transaction
+// @Failure 500 {object} map[string]any
+// @Router /admin/transactions/deleted [get]
+func (h *AdminHandler) ListDeletedTransactions(c echo.Context) error {
+	transactions, err := h.Transactions.GetDeleted()
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, transactions)
+}
+
+// DeleteTransaction godoc
+// @Summary Hapus transaksi (admin)
+// @Description Soft delete transaksi, sama dengan DELETE /transactions/{id}
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /admin/transactions/{id} [delete]
+func (h *AdminHandler) DeleteTransaction(c echo.Context) error {
+	if err := h.Transactions.Delete(c.Param("id"), auditActor(c)); err != nil {
+		return transactionAdminError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"message": "transaction deleted"})
+}
+
+// RestoreTransaction godoc
+// @Summary Kembalikan transaksi yang dihapus (admin)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /admin/transactions/{id}/restore [post]
+func (h *AdminHandler) RestoreTransaction(c echo.Context) error {
+	if err := h.Transactions.Restore(c.Param("id"), auditActor(c)); err != nil {
+		return transactionAdminError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"message": "transaction restored"})
+}
+
+// CancelTransaction godoc
+// @Summary Batalkan transaksi (admin)
+// @Description Sama dengan POST /transactions/{id}/cancel tanpa cek pemilik: transaksi yang sudah dibayar di-refund penuh lalu stoknya dikembalikan
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body TransitionRequest false "Catatan"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 502 {object} map[string]any
+// @Router /admin/transactions/{id}/cancel [post]
+func (h *AdminHandler) CancelTransaction(c echo.Context) error {
+	id := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
+	}
+	var req TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+	actor, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	tx, err := h.Transactions.Transition(id, domain.TransactionCancelled, app.TransitionInput{
+		Actor:     actor,
+		RequestID: auditActor(c).RequestID,
+		Note:      req.Note,
+	})
+	if err != nil {
+		return transitionError(c, err)
+	}
+	setETag(c, tx.Version)
+	return c.JSON(http.StatusOK, tx)
+}
+
+// ListAuditLogs godoc
+// @Summary Audit log product dan transaksi (admin)
+// @Description Perubahan (create, update, delete, restore) beserta actor, request id dan diff before/after, terbaru dulu
+// @Tags Admin
+// @Produce json
+// @Param resource query string false "product atau transaction"
+// @Param resource_id query string false "ID product/transaksi"
+// @Param actor query string false "Email actor"
+// @Param request_id query string false "X-Request-ID"
+// @Param limit query int false "Jumlah maksimal (default 100, maks 500)"
+// @Success 200 {array} audit.Entry
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /admin/audit-logs [get]
+func (h *AdminHandler) ListAuditLogs(c echo.Context) error {
+	filter := audit.Filter{
+		Resource:   c.QueryParam("resource"),
+		ResourceID: c.QueryParam("resource_id"),
+		Actor:      c.QueryParam("actor"),
+		RequestID:  c.QueryParam("request_id"),
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit <= 0 {
+			return ErrorResponse(c, http.StatusBadRequest, "limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.Audit.List(c.Request().Context(), filter)
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, "failed to list audit logs")
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+func productAdminError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, app.ErrProductNotFound):
+		return ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, app.ErrInvalidProductID):
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
+
+func transactionAdminError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, app.ErrTransactionNotFound):
+		return ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, app.ErrInvalidTransactionID):
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
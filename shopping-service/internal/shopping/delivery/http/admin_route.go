@@ -0,0 +1,25 @@
+package http
+
+import (
+	"shared/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+// setup route admin, dibatasi gateway untuk ADMIN_EMAILS
+func AdminRoute(e *echo.Echo, handler *AdminHandler) {
+	route := e.Group("/admin")
+
+	route.GET("/products/deleted", handler.ListDeletedProducts)
+	route.DELETE("/products/:id", handler.DeleteProduct)
+	route.POST("/products/:id/restore", handler.RestoreProduct)
+	route.GET("/transactions/deleted", handler.ListDeletedTransactions)
+	route.DELETE("/transactions/:id", handler.DeleteTransaction)
+	route.POST("/transactions/:id/restore", handler.RestoreTransaction)
+	route.POST("/transactions/:id/cancel", handler.CancelTransaction) // tanpa cek pemilik
+	route.GET("/audit-logs", handler.ListAuditLogs)                   // filter resource, actor, request_id
+
+	// level log runtime, GET atau PUT {"level":"debug"}
+	route.GET("/log-level", echo.WrapHandler(logging.LevelHandler()))
+	route.PUT("/log-level", echo.WrapHandler(logging.LevelHandler()))
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler laporan penjualan (admin)
+type AnalyticsHandler struct {
+	Service app.AnalyticsService
+}
+
+// init handler laporan penjualan
+func NewAnalyticsHandler(service app.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{Service: service}
+}
+
+// RevenueReport godoc
+// @Summary Revenue dan jumlah order per periode (admin)
+// @Description Penjualan = transaksi paid/packed/shipped/delivered dan order checkout. Periode dipotong di timezone tz, minggu dimulai Senin
+// @Tags Analytics
+// @Produce json
+// @Param interval query string false "day (default), week atau month"
+// @Param from query string false "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param to query string false "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param tz query string false "Timezone IANA, misalnya Asia/Jakarta (default UTC)"
+// @Param currency query string false "Currency (default IDR)"
+// @Success 200 {array} domain.RevenuePoint
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /admin/analytics/revenue [get]
+func (h *AnalyticsHandler) RevenueReport(c echo.Context) error {
+	filter, err := analyticsFilter(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	interval := c.QueryParam("interval")
+	if interval == "" {
+		interval = domain.IntervalDay
+	}
+
+	points, err := h.Service.Revenue(filter, interval)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	return c.JSON(http.StatusOK, points)
+}
+
+// TopProductsReport godoc
+// @Summary Product terlaris (admin)
+// @Description Revenue product setelah diskon, sebelum pajak dan ongkir
+// @Tags Analytics
+// @Produce json
+// @Param by query string false "revenue (default) atau units"
+// @Param limit query int false "Jumlah product (default 10, maks 100)"
+// @Param from query string false "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param to query string false "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param tz query string false "Timezone IANA (default UTC)"
+// @Param currency query string false "Currency (default IDR)"
+// @Success 200 {array} domain.ProductSales
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /admin/analytics/top-products [get]
+func (h *AnalyticsHandler) TopProductsReport(c echo.Context) error {
+	filter, err := analyticsFilter(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	var limit int64
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || limit <= 0 {
+			return ErrorResponse(c, http.StatusBadRequest, "limit must be a positive integer")
+		}
+	}
+
+	products, err := h.Service.TopProducts(filter, c.QueryParam("by"), limit)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	return c.JSON(http.StatusOK, products)
+}
+
+// SalesSummaryReport godoc
+// @Summary Ringkasan penjualan (admin)
+// @Description Jumlah order, revenue, rata-rata nilai order dan tingkat repeat customer (customer dengan lebih dari satu order di rentang)
+// @Tags Analytics
+// @Produce json
+// @Param from query string false "Tanggal awal YYYY-MM-DD (default 30 hari terakhir)"
+// @Param to query string false "Tanggal akhir YYYY-MM-DD, inklusif (default hari ini)"
+// @Param tz query string false "Timezone IANA (default UTC)"
+// @Param currency query string false "Currency (default IDR)"
+// @Success 200 {object} domain.SalesSummary
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /admin/analytics/summary [get]
+func (h *AnalyticsHandler) SalesSummaryReport(c echo.Context) error {
+	filter, err := analyticsFilter(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	summary, err := h.Service.Summary(filter)
+	if err != nil {
+		return analyticsError(c, err)
+	}
+	return c.JSON(http.StatusOK, summary)
+}
+
+// rentang, timezone dan currency dari query
+func analyticsFilter(c echo.Context) (domain.AnalyticsFilter, error) {
+	return app.ParseAnalyticsFilter(c.QueryParam("from"), c.QueryParam("to"), c.QueryParam("tz"), c.QueryParam("currency"), time.Now())
+}
+
+func analyticsError(c echo.Context, err error) error {
+	if errors.Is(err, app.ErrIntervalInvalid) || errors.Is(err, app.ErrTopProductsBy) {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
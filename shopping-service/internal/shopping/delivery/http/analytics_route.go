@@ -0,0 +1,12 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route laporan penjualan, dibatasi gateway untuk ADMIN_EMAILS
+func AnalyticsRoute(e *echo.Echo, handler *AnalyticsHandler) {
+	route := e.Group("/admin/analytics")
+
+	route.GET("/revenue", handler.RevenueReport)          // per day/week/month
+	route.GET("/top-products", handler.TopProductsReport) // by revenue/units
+	route.GET("/summary", handler.SalesSummaryReport)     // AOV & repeat customer
+}
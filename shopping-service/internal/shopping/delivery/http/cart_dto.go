@@ -0,0 +1,23 @@
+package http
+
+// struct request tambah item ke cart
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// struct request ubah quantity item cart
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// struct request checkout, semua field opsional
+type CheckoutRequest struct {
+	PaymentMethod string `json:"payment_method"`
+	CardToken     string `json:"card_token"`
+	CouponCode    string `json:"coupon_code"`
+
+	// kosong berarti default dari config pricing
+	Region         string `json:"region"`
+	ShippingMethod string `json:"shipping_method"`
+}
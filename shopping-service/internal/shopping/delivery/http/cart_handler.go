@@ -0,0 +1,166 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"shopping-service/internal/shopping/app"
+
+	"github.com/labstack/echo/v4"
+)
+
+// struct handler cart
+type CartHandler struct {
+	Service app.CartService
+}
+
+// init handler cart
+func NewCartHandler(service app.CartService) *CartHandler {
+	return &CartHandler{Service: service}
+}
+
+// GetCart godoc
+// @Summary Ambil cart user
+// @Description Menampilkan isi cart milik user dari header X-User-Email
+// @Tags Cart
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Success 200 {object} domain.Cart
+// @Failure 401 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /cart [get]
+func (h *CartHandler) GetCart(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	cart, err := h.Service.GetCart(email)
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, cart)
+}
+
+// AddCartItem godoc
+// @Summary Tambah item ke cart
+// @Description Menambahkan product ke cart, quantity dijumlahkan kalau product sudah ada
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param request body AddCartItemRequest true "Item cart"
+// @Success 200 {object} domain.Cart
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /cart/items [post]
+func (h *CartHandler) AddCartItem(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	var req AddCartItemRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	cart, err := h.Service.AddItem(email, req.ProductID, req.Quantity)
+	if err != nil {
+		return ErrorResponse(c, cartErrorStatus(err), err.Error())
+	}
+	return c.JSON(http.StatusOK, cart)
+}
+
+// UpdateCartItem godoc
+// @Summary Ubah quantity item cart
+// @Description Mengganti quantity product yang sudah ada di cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param productId path string true "Product ID"
+// @Param request body UpdateCartItemRequest true "Quantity baru"
+// @Success 200 {object} domain.Cart
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /cart/items/{productId} [put]
+func (h *CartHandler) UpdateCartItem(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	var req UpdateCartItemRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	cart, err := h.Service.UpdateItem(email, c.Param("productId"), req.Quantity)
+	if err != nil {
+		return ErrorResponse(c, cartErrorStatus(err), err.Error())
+	}
+	return c.JSON(http.StatusOK, cart)
+}
+
+// RemoveCartItem godoc
+// @Summary Hapus item dari cart
+// @Description Menghapus product dari cart
+// @Tags Cart
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} domain.Cart
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /cart/items/{productId} [delete]
+func (h *CartHandler) RemoveCartItem(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	cart, err := h.Service.RemoveItem(email, c.Param("productId"))
+	if err != nil {
+		return ErrorResponse(c, cartErrorStatus(err), err.Error())
+	}
+	return c.JSON(http.StatusOK, cart)
+}
+
+// ClearCart godoc
+// @Summary Kosongkan cart
+// @Description Menghapus semua item di cart user
+// @Tags Cart
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Success 200 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /cart [delete]
+func (h *CartHandler) ClearCart(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	if err := h.Service.Clear(email); err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "cart cleared",
+	})
+}
+
+// mapping error cart ke HTTP status
+func cartErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, app.ErrProductNotFound), errors.Is(err, app.ErrCartItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrCartUpdate):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route cart
+func CartRoute(e *echo.Echo, handler *CartHandler, orderHandler *OrderHandler) {
+	route := e.Group("/cart")
+
+	route.GET("", handler.GetCart)                            // ambil cart
+	route.DELETE("", handler.ClearCart)                       // kosongkan cart
+	route.POST("/items", handler.AddCartItem)                 // tambah item
+	route.PUT("/items/:productId", handler.UpdateCartItem)    // ubah quantity
+	route.DELETE("/items/:productId", handler.RemoveCartItem) // hapus item
+	route.POST("/checkout", orderHandler.Checkout)            // checkout jadi order
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// struct handler kategori
+type CategoryHandler struct {
+	Service app.CategoryService
+}
+
+// init handler kategori
+func NewCategoryHandler(service app.CategoryService) *CategoryHandler {
+	return &CategoryHandler{Service: service}
+}
+
+// CreateCategory godoc
+// @Summary Tambah kategori
+// @Description Menambahkan kategori, isi parent_id untuk sub kategori
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param request body CreateCategoryRequest true "Kategori data"
+// @Success 201 {object} domain.Category
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c echo.Context) error {
+	var req CreateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	category := &domain.Category{Name: req.Name, Slug: req.Slug, ParentID: req.ParentID}
+	if err := h.Service.Create(category); err != nil {
+		switch {
+		case errors.Is(err, app.ErrCategoryNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, app.ErrCategorySlugTaken):
+			return ErrorResponse(c, http.StatusConflict, err.Error())
+		case errors.Is(err, app.ErrCategoryInsert), errors.Is(err, app.ErrFailedDecode):
+			return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusCreated, category)
+}
+
+// GetCategoryTree godoc
+// @Summary Ambil tree kategori
+// @Description Menampilkan semua kategori dalam bentuk tree
+// @Tags Categories
+// @Produce json
+// @Success 200 {object} []domain.CategoryNode
+// @Failure 500 {object} map[string]any
+// @Router /categories [get]
+func (h *CategoryHandler) GetCategoryTree(c echo.Context) error {
+	tree, err := h.Service.GetTree()
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, tree)
+}
+
+// GetCategoryByID godoc
+// @Summary Ambil kategori berdasarkan ID
+// @Description Menampilkan kategori spesifik beserta leluhurnya
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetCategoryByID(c echo.Context) error {
+	category, err := h.Service.GetByID(c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrInvalidCategoryID):
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, app.ErrCategoryNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, category)
+}
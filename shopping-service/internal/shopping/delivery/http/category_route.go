@@ -0,0 +1,12 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route kategori
+func CategoryRoute(e *echo.Echo, handler *CategoryHandler) {
+	route := e.Group("/categories")
+
+	route.POST("", handler.CreateCategory)     // tambah kategori
+	route.GET("", handler.GetCategoryTree)     // tree kategori
+	route.GET("/:id", handler.GetCategoryByID) // ambil by id
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// content type JSON Merge Patch (RFC 7396)
+const mimeMergePatch = "application/merge-patch+json"
+
+// pesan error untuk precondition
+const (
+	msgIfMatchRequired = "If-Match header is required, use the ETag from GET"
+	msgIfMatchInvalid  = "invalid If-Match header"
+)
+
+// ETag dari versi dokumen
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// Versi dari header If-Match. ok false kalau header tidak dikirim.
+// Menerima "3", W/"3" atau 3.
+func ifMatchVersion(c echo.Context) (version int64, ok bool, err error) {
+	raw := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if raw == "" {
+		return 0, false, nil
+	}
+	raw = strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+	version, err = strconv.ParseInt(raw, 10, 64)
+	if err != nil || version < 0 {
+		return 0, true, errors.New(msgIfMatchInvalid)
+	}
+	return version, true, nil
+}
+
+// Terapkan body PATCH (JSON Merge Patch) ke current, lalu decode hasilnya
+// ke out. Key yang tidak dikenal ditolak.
+func applyMergePatch(c echo.Context, current any, out any) (int, error) {
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, mimeMergePatch) && !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return http.StatusUnsupportedMediaType, errors.New("content type must be " + mimeMergePatch)
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return http.StatusBadRequest, errors.New("invalid request body")
+	}
+	var patch any
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return http.StatusBadRequest, errors.New("invalid request body")
+	}
+	if _, ok := patch.(map[string]any); !ok {
+		return http.StatusBadRequest, errors.New("merge patch must be a JSON object")
+	}
+
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return http.StatusBadRequest, errors.New("invalid patch: " + err.Error())
+	}
+	return http.StatusOK, nil
+}
+
+// RFC 7396: object digabung per key, null menghapus key, nilai lain
+// (termasuk array) menggantikan nilai lama
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
@@ -0,0 +1,8 @@
+package http
+
+// struct request restock / adjustment stok
+type StockChangeRequest struct {
+	// restock harus > 0, adjustment boleh negatif
+	Quantity int    `json:"quantity"`
+	Note     string `json:"note"`
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// jumlah default riwayat stok yang ditampilkan
+const defaultHistoryLimit = 50
+
+// struct handler inventory
+type InventoryHandler struct {
+	Service app.InventoryService
+}
+
+// init handler inventory
+func NewInventoryHandler(service app.InventoryService) *InventoryHandler {
+	return &InventoryHandler{Service: service}
+}
+
+// GetStockHistory godoc
+// @Summary Ambil riwayat stok product
+// @Description Menampilkan stok, stok yang ditahan reservasi dan ledger pergerakan stok, terbaru dulu
+// @Tags Inventory
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param limit query int false "Jumlah movement (default 50, max 500)"
+// @Success 200 {object} app.StockHistory
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /inventory/{productId} [get]
+func (h *InventoryHandler) GetStockHistory(c echo.Context) error {
+	limit := int64(defaultHistoryLimit)
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 || n > 500 {
+			return ErrorResponse(c, http.StatusBadRequest, "limit must be 1-500")
+		}
+		limit = n
+	}
+
+	history, err := h.Service.History(c.Param("productId"), limit)
+	if err != nil {
+		return ErrorResponse(c, inventoryErrorStatus(err), err.Error())
+	}
+	return c.JSON(http.StatusOK, history)
+}
+
+// Restock godoc
+// @Summary Tambah stok product
+// @Description Menambah stok dan mencatatnya sebagai restock di ledger
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param request body StockChangeRequest true "Jumlah restock"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /inventory/{productId}/restock [post]
+func (h *InventoryHandler) Restock(c echo.Context) error {
+	return h.change(c, h.Service.Restock)
+}
+
+// AdjustStock godoc
+// @Summary Koreksi stok product
+// @Description Mengubah stok sebesar quantity (boleh negatif) dan mencatatnya sebagai adjustment. Stok tidak bisa turun di bawah stok yang ditahan reservasi.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param request body StockChangeRequest true "Selisih stok"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /inventory/{productId}/adjust [post]
+func (h *InventoryHandler) AdjustStock(c echo.Context) error {
+	return h.change(c, h.Service.Adjust)
+}
+
+func (h *InventoryHandler) change(c echo.Context, apply func(productID string, quantity int, note string) (*domain.Product, error)) error {
+	var req StockChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	product, err := apply(c.Param("productId"), req.Quantity, req.Note)
+	if err != nil {
+		return ErrorResponse(c, inventoryErrorStatus(err), err.Error())
+	}
+	return c.JSON(http.StatusOK, product)
+}
+
+// mapping error inventory ke HTTP status
+func inventoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, app.ErrInvalidProductID), errors.Is(err, app.ErrInvalidQuantity), errors.Is(err, app.ErrAdjustmentInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrInsufficientStock):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
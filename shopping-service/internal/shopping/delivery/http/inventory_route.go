@@ -0,0 +1,12 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route inventory
+func InventoryRoute(e *echo.Echo, handler *InventoryHandler) {
+	route := e.Group("/inventory")
+
+	route.GET("/:productId", handler.GetStockHistory)     // stok & riwayat
+	route.POST("/:productId/restock", handler.Restock)    // tambah stok
+	route.POST("/:productId/adjust", handler.AdjustStock) // koreksi stok
+}
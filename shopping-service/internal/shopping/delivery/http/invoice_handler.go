@@ -0,0 +1,234 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler invoice dan statement customer
+type InvoiceHandler struct {
+	Service app.InvoiceService
+}
+
+// init handler invoice
+func NewInvoiceHandler(service app.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{Service: service}
+}
+
+// GetInvoices godoc
+// @Summary Daftar invoice user
+// @Description Invoice transaksi dan order yang sudah dibayar milik user, terbaru dulu
+// @Tags Invoices
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Success 200 {array} domain.Invoice
+// @Failure 401 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /invoices [get]
+func (h *InvoiceHandler) GetInvoices(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	invoices, err := h.Service.ListByEmail(email)
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, invoices)
+}
+
+// GetInvoiceByID godoc
+// @Summary Ambil invoice
+// @Description Invoice milik user. format=html atau format=pdf merender invoice dari template
+// @Tags Invoices
+// @Produce json
+// @Produce text/html
+// @Produce application/pdf
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param id path string true "Invoice ID"
+// @Param format query string false "json (default), html atau pdf"
+// @Success 200 {object} domain.Invoice
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetInvoiceByID(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+	format, err := documentFormat(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	invoice, err := h.Service.GetByID(c.Param("id"), email)
+	if err != nil {
+		return ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+	}
+	return writeInvoice(c, format, invoice)
+}
+
+// GetTransactionInvoice godoc
+// @Summary Ambil invoice transaksi
+// @Description Invoice transaksi milik user, dibuat saat itu juga kalau belum ada. format=html atau format=pdf merender invoice dari template
+// @Tags Invoices
+// @Produce json
+// @Produce text/html
+// @Produce application/pdf
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param id path string true "Transaction ID"
+// @Param format query string false "json (default), html atau pdf"
+// @Success 200 {object} domain.Invoice
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /transactions/{id}/invoice [get]
+func (h *InvoiceHandler) GetTransactionInvoice(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+	format, err := documentFormat(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	invoice, err := h.Service.ForTransaction(c.Param("id"), email)
+	if err != nil {
+		return ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+	}
+	return writeInvoice(c, format, invoice)
+}
+
+// GetOrderInvoice godoc
+// @Summary Ambil invoice order
+// @Description Invoice order cart milik user, dibuat saat itu juga kalau belum ada. format=html atau format=pdf merender invoice dari template
+// @Tags Invoices
+// @Produce json
+// @Produce text/html
+// @Produce application/pdf
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param id path string true "Order ID"
+// @Param format query string false "json (default), html atau pdf"
+// @Success 200 {object} domain.Invoice
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /orders/{id}/invoice [get]
+func (h *InvoiceHandler) GetOrderInvoice(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+	format, err := documentFormat(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	invoice, err := h.Service.ForOrder(c.Param("id"), email)
+	if err != nil {
+		return ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+	}
+	return writeInvoice(c, format, invoice)
+}
+
+// GetStatement godoc
+// @Summary Statement bulanan user
+// @Description Payment dan refund user dalam satu bulan (UTC) beserta total per currency. format=html atau format=pdf merender statement dari template
+// @Tags Invoices
+// @Produce json
+// @Produce text/html
+// @Produce application/pdf
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param month query string false "YYYY-MM, default bulan ini"
+// @Param format query string false "json (default), html atau pdf"
+// @Success 200 {object} domain.Statement
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 502 {object} map[string]any
+// @Router /statements [get]
+func (h *InvoiceHandler) GetStatement(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+	format, err := documentFormat(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	statement, err := h.Service.Statement(email, c.QueryParam("month"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrStatementMonth):
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, app.ErrPaymentRequest):
+			return ErrorResponse(c, http.StatusBadGateway, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+
+	if format == app.DocumentJSON {
+		return c.JSON(http.StatusOK, statement)
+	}
+	var body bytes.Buffer
+	if err := app.RenderStatement(&body, format, statement); err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return writeDocument(c, format, "statement-"+statement.Month, body.Bytes())
+}
+
+// format dokumen dari query, default json
+func documentFormat(c echo.Context) (string, error) {
+	switch format := c.QueryParam("format"); format {
+	case "":
+		return app.DocumentJSON, nil
+	case app.DocumentJSON, app.DocumentHTML, app.DocumentPDF:
+		return format, nil
+	}
+	return "", app.ErrDocumentFormat
+}
+
+func writeInvoice(c echo.Context, format string, invoice *domain.Invoice) error {
+	if format == app.DocumentJSON {
+		return c.JSON(http.StatusOK, invoice)
+	}
+	var body bytes.Buffer
+	if err := app.RenderInvoice(&body, format, invoice); err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return writeDocument(c, format, invoice.Number, body.Bytes())
+}
+
+// Render dulu ke buffer supaya error template masih bisa dikirim sebagai
+// JSON. PDF diunduh sebagai attachment, HTML ditampilkan di browser.
+func writeDocument(c echo.Context, format, name string, body []byte) error {
+	if format == app.DocumentPDF {
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`.pdf"`)
+		return c.Blob(http.StatusOK, "application/pdf", body)
+	}
+	return c.HTMLBlob(http.StatusOK, body)
+}
+
+// mapping error invoice ke HTTP status
+func invoiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, app.ErrInvalidInvoiceID), errors.Is(err, app.ErrInvalidTransactionID), errors.Is(err, app.ErrInvalidOrderID):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrInvoiceNotFound), errors.Is(err, app.ErrTransactionNotFound), errors.Is(err, app.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrInvoiceNotPaid):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,15 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route invoice & statement
+func InvoiceRoute(e *echo.Echo, handler *InvoiceHandler) {
+	e.GET("/transactions/:id/invoice", handler.GetTransactionInvoice) // invoice transaksi
+	e.GET("/orders/:id/invoice", handler.GetOrderInvoice)             // invoice order cart
+
+	route := e.Group("/invoices")
+	route.GET("", handler.GetInvoices)        // invoice user
+	route.GET("/:id", handler.GetInvoiceByID) // json, html atau pdf
+
+	e.GET("/statements", handler.GetStatement) // statement bulanan
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"shopping-service/internal/shopping/app"
+
+	"github.com/labstack/echo/v4"
+)
+
+// struct handler order
+type OrderHandler struct {
+	Service app.OrderService
+}
+
+// init handler order
+func NewOrderHandler(service app.OrderService) *OrderHandler {
+	return &OrderHandler{Service: service}
+}
+
+// Checkout godoc
+// @Summary Checkout cart
+// @Description Mengubah cart jadi order. Harga dan stok dicek ulang, total dihitung dengan pajak region dan ongkir, lalu seluruh order dibayar dengan satu payment.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param request body CheckoutRequest false "Data pembayaran, kupon, region dan shipping"
+// @Success 201 {object} domain.Order
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 402 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 422 {object} map[string]any
+// @Failure 502 {object} map[string]any
+// @Router /cart/checkout [post]
+func (h *OrderHandler) Checkout(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	var req CheckoutRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	order, err := h.Service.Checkout(c.Request().Context(), email, app.CheckoutInput{
+		PaymentMethod: req.PaymentMethod,
+		CardToken:     req.CardToken,
+		CouponCode:    req.CouponCode,
+
+		Region:         req.Region,
+		ShippingMethod: req.ShippingMethod,
+	})
+	if err != nil {
+		return ErrorResponse(c, checkoutErrorStatus(err), err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, order)
+}
+
+// GetOrders godoc
+// @Summary Ambil order user
+// @Description Menampilkan semua order milik user, terbaru dulu
+// @Tags Orders
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Success 200 {object} []domain.Order
+// @Failure 401 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /orders [get]
+func (h *OrderHandler) GetOrders(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	orders, err := h.Service.GetOrders(email)
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, orders)
+}
+
+// GetOrderByID godoc
+// @Summary Ambil order berdasarkan ID
+// @Description Menampilkan order spesifik milik user
+// @Tags Orders
+// @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param id path string true "Order ID"
+// @Success 200 {object} domain.Order
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /orders/{id} [get]
+func (h *OrderHandler) GetOrderByID(c echo.Context) error {
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	order, err := h.Service.GetOrder(email, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrInvalidOrderID):
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, app.ErrOrderNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, order)
+}
+
+// mapping error checkout ke HTTP status
+func checkoutErrorStatus(err error) int {
+	if status, ok := couponErrorStatus(err); ok {
+		return status
+	}
+	switch {
+	case errors.Is(err, app.ErrCartEmpty), errors.Is(err, app.ErrMixedCurrency),
+		errors.Is(err, app.ErrRegionInvalid), errors.Is(err, app.ErrShippingMethodInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrProductNotFound), errors.Is(err, app.ErrInsufficientStock),
+		errors.Is(err, app.ErrReservationNotActive):
+		return http.StatusConflict
+	case errors.Is(err, app.ErrPaymentFailed):
+		return http.StatusPaymentRequired
+	case errors.Is(err, app.ErrPaymentRequest):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,11 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route order
+func OrderRoute(e *echo.Echo, handler *OrderHandler) {
+	route := e.Group("/orders")
+
+	route.GET("", handler.GetOrders)        // ambil order user
+	route.GET("/:id", handler.GetOrderByID) // ambil by id
+}
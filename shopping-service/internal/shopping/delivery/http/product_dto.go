@@ -1,8 +1,31 @@
 package http
 
+import (
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+)
+
 // struct request product
 type CreateProductRequest struct {
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
-	Stock int     `json:"stock"`
+	Name  string      `json:"name"`
+	SKU   string      `json:"sku"` // opsional, harus unik
+	Price money.Money `json:"price"`
+	Stock int         `json:"stock"`
+	// opsional, berat per unit dalam gram
+	WeightGrams int `json:"weight_grams"`
+	// opsional, batas alert stok menipis (0 = mati)
+	LowStockThreshold int `json:"low_stock_threshold"`
+
+	// opsional, data catalog
+	Description string                  `json:"description"`
+	CategoryID  string                  `json:"category_id"`
+	Variants    []domain.ProductVariant `json:"variants"`
+	Images      []domain.ProductImage   `json:"images"`
+}
+
+// struct request kategori
+type CreateCategoryRequest struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`      // opsional, default dari nama
+	ParentID string `json:"parent_id"` // opsional, kosong berarti root
 }
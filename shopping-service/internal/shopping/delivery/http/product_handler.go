@@ -1,13 +1,23 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"shared/money"
 	"shopping-service/internal/shopping/app"
 	"shopping-service/internal/shopping/domain"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
+// batas jumlah product per halaman
+const (
+	defaultSearchLimit = 20
+	maxProductLimit    = 100
+)
+
 // struct handler
 type ProductHandler struct {
 	Service *app.ProductService
@@ -35,39 +45,41 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "invalid request format")
 	}
 
-	product := &domain.Product{
-		Name:  req.Name,
-		Price: req.Price,
-		Stock: req.Stock,
-	}
+	product := req.toProduct()
 
-	err := h.Service.CreateProduct(product)
+	err := h.Service.CreateProduct(product, auditActor(c))
 	if err != nil {
 		return ErrorResponse(c, http.StatusBadRequest, err.Error())
 	}
 
 	return c.JSON(http.StatusCreated, map[string]any{
 		"message": "product created",
-		"data": map[string]any{
-			"id":         product.ID.Hex(),
-			"name":       product.Name,
-			"price":      product.Price,
-			"stock":      product.Stock,
-			"created_at": product.CreatedAt,
-		},
+		"data":    productResponse(product),
 	})
 }
 
 // GetAllProducts godoc
 // @Summary Ambil semua product
-// @Description Menampilkan seluruh daftar product
+// @Description Menampilkan daftar product yang tidak diarsipkan, terbaru dulu. Filter kategori ikut mencakup sub kategori.
 // @Tags Products
 // @Produce json
+// @Param category query string false "Category ID"
+// @Param currency query string false "Currency filter harga (default IDR)"
+// @Param min_price query string false "Harga minimal, contoh 50000.00"
+// @Param max_price query string false "Harga maksimal"
+// @Param page query int false "Halaman, mulai 1"
+// @Param limit query int false "Jumlah per halaman (max 100), kosong berarti semua"
 // @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
 // @Failure 500 {object} map[string]any
 // @Router /products [get]
 func (h *ProductHandler) GetAllProducts(c echo.Context) error {
-	products, err := h.Service.GetAllProducts()
+	filter, err := productFilter(c, 0)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	products, err := h.Service.GetAllProducts(filter)
 	if err != nil {
 		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
 	}
@@ -77,13 +89,46 @@ func (h *ProductHandler) GetAllProducts(c echo.Context) error {
 	})
 }
 
+// SearchProducts godoc
+// @Summary Search product
+// @Description Search full-text di nama, deskripsi dan SKU varian, diurutkan by relevansi. Response berisi facet jumlah per kategori dan rentang harga.
+// @Tags Products
+// @Produce json
+// @Param q query string true "Kata kunci"
+// @Param category query string false "Category ID"
+// @Param currency query string false "Currency filter dan facet harga (default IDR)"
+// @Param min_price query string false "Harga minimal"
+// @Param max_price query string false "Harga maksimal"
+// @Param page query int false "Halaman, mulai 1"
+// @Param limit query int false "Jumlah per halaman (default 20, max 100)"
+// @Success 200 {object} domain.ProductSearchResult
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /products/search [get]
+func (h *ProductHandler) SearchProducts(c echo.Context) error {
+	filter, err := productFilter(c, defaultSearchLimit)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.Service.SearchProducts(c.QueryParam("q"), filter)
+	if err != nil {
+		if errors.Is(err, app.ErrSearchQueryEmpty) || errors.Is(err, app.ErrCurrencyInvalid) {
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
 // GetProductByID godoc
 // @Summary Ambil product berdasarkan ID
-// @Description Menampilkan data product spesifik berdasarkan ID
+// @Description Menampilkan data product spesifik berdasarkan ID. Header ETag berisi versi product untuk If-Match saat update.
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product ID"
 // @Success 200 {object} map[string]any
+// @Header 200 {string} ETag "Versi product"
 // @Failure 404 {object} map[string]any
 // @Router /products/{id} [get]
 func (h *ProductHandler) GetProductByID(c echo.Context) error {
@@ -94,70 +139,265 @@ func (h *ProductHandler) GetProductByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusNotFound, err.Error())
 	}
 
+	setETag(c, product.Version)
 	return c.JSON(http.StatusOK, map[string]any{
-		"data": map[string]any{
-			"id":         product.ID.Hex(),
-			"name":       product.Name,
-			"price":      product.Price,
-			"stock":      product.Stock,
-			"created_at": product.CreatedAt,
-		},
+		"data": productResponse(product),
 	})
 }
 
 // UpdateProduct godoc
 // @Summary Update product berdasarkan ID
-// @Description Update data product spesifik
+// @Description Update seluruh data product. Header If-Match wajib berisi ETag dari GET, kalau product sudah diubah request lain response 412.
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param If-Match header string true "ETag product"
 // @Param request body CreateProductRequest true "Updated product data"
 // @Success 200 {object} map[string]any
+// @Header 200 {string} ETag "Versi product yang baru"
 // @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 412 {object} map[string]any
+// @Failure 428 {object} map[string]any
 // @Router /products/{id} [put]
 func (h *ProductHandler) UpdateProduct(c echo.Context) error {
 	id := c.Param("id")
-	var req CreateProductRequest
 
+	version, ok, err := ifMatchVersion(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	if !ok {
+		return ErrorResponse(c, http.StatusPreconditionRequired, msgIfMatchRequired)
+	}
+
+	var req CreateProductRequest
 	if err := c.Bind(&req); err != nil {
 		return ErrorResponse(c, http.StatusBadRequest, "invalid request format")
 	}
 
-	product := &domain.Product{
-		Name:  req.Name,
-		Price: req.Price,
-		Stock: req.Stock,
+	product := req.toProduct()
+	product.Version = version
+
+	return h.update(c, id, product)
+}
+
+// PatchProduct godoc
+// @Summary Update sebagian data product
+// @Description Update product dengan JSON Merge Patch (RFC 7396): hanya field yang dikirim yang diubah, null menghapus field opsional, array (variants, images) diganti utuh. If-Match opsional, kalau dikirim harus sama dengan ETag product.
+// @Tags Products
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param If-Match header string false "ETag product"
+// @Param request body CreateProductRequest true "Field yang diubah"
+// @Success 200 {object} map[string]any
+// @Header 200 {string} ETag "Versi product yang baru"
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 412 {object} map[string]any
+// @Failure 415 {object} map[string]any
+// @Router /products/{id} [patch]
+func (h *ProductHandler) PatchProduct(c echo.Context) error {
+	id := c.Param("id")
+
+	version, ok, err := ifMatchVersion(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
 	}
 
-	err := h.Service.UpdateProduct(id, product)
+	current, err := h.Service.GetProductByID(id)
 	if err != nil {
+		if errors.Is(err, app.ErrInvalidProductID) {
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
+		return ErrorResponse(c, http.StatusNotFound, err.Error())
+	}
+	if !ok {
+		version = current.Version
+	}
+	if version != current.Version {
+		return ErrorResponse(c, http.StatusPreconditionFailed, app.ErrVersionConflict.Error())
+	}
+
+	var req CreateProductRequest
+	if status, err := applyMergePatch(c, productRequest(current), &req); err != nil {
+		return ErrorResponse(c, status, err.Error())
+	}
+
+	product := req.toProduct()
+	product.Version = version
+
+	return h.update(c, id, product)
+}
+
+// simpan update product, ETag response berisi versi baru
+func (h *ProductHandler) update(c echo.Context, id string, product *domain.Product) error {
+	err := h.Service.UpdateProduct(id, product, auditActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrVersionConflict):
+			return ErrorResponse(c, http.StatusPreconditionFailed, err.Error())
+		case errors.Is(err, app.ErrProductNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
 		return ErrorResponse(c, http.StatusBadRequest, err.Error())
 	}
 
+	setETag(c, product.Version)
 	return c.JSON(http.StatusOK, map[string]any{
 		"message": "product updated",
+		"version": product.Version,
 	})
 }
 
 // DeleteProduct godoc
-// @Summary Hapus product berdasarkan ID
-// @Description Menghapus data product dari database
+// @Summary Arsipkan product berdasarkan ID
+// @Description Product tidak dihapus dari database, hanya diarsipkan dan tidak tampil lagi di daftar/search
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product ID"
 // @Success 200 {object} map[string]any
 // @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
 // @Router /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c echo.Context) error {
 	id := c.Param("id")
 
-	err := h.Service.DeleteProduct(id)
+	err := h.Service.DeleteProduct(id, auditActor(c))
+	if err != nil {
+		if errors.Is(err, app.ErrProductNotFound) {
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "product archived",
+	})
+}
+
+// RestoreProduct godoc
+// @Summary Kembalikan product yang diarsipkan
+// @Description Product yang diarsipkan tampil dan bisa dibeli lagi
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]any
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(c echo.Context) error {
+	err := h.Service.RestoreProduct(c.Param("id"), auditActor(c))
 	if err != nil {
+		if errors.Is(err, app.ErrProductNotFound) {
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
 		return ErrorResponse(c, http.StatusBadRequest, err.Error())
 	}
 
 	return c.JSON(http.StatusOK, map[string]any{
-		"message": "product deleted",
+		"message": "product restored",
 	})
 }
+
+// ubah request jadi domain product
+func (req CreateProductRequest) toProduct() *domain.Product {
+	return &domain.Product{
+		Name:        req.Name,
+		SKU:         req.SKU,
+		Description: req.Description,
+		Price:       req.Price,
+		Stock:       req.Stock,
+
+		WeightGrams:       req.WeightGrams,
+		LowStockThreshold: req.LowStockThreshold,
+
+		CategoryID: req.CategoryID,
+		Variants:   req.Variants,
+		Images:     req.Images,
+	}
+}
+
+// kebalikan toProduct, dasar untuk merge patch
+func productRequest(product *domain.Product) CreateProductRequest {
+	return CreateProductRequest{
+		Name:  product.Name,
+		SKU:   product.SKU,
+		Price: product.Price,
+		Stock: product.Stock,
+
+		WeightGrams:       product.WeightGrams,
+		LowStockThreshold: product.LowStockThreshold,
+
+		Description: product.Description,
+		CategoryID:  product.CategoryID,
+		Variants:    product.Variants,
+		Images:      product.Images,
+	}
+}
+
+// data product untuk response
+func productResponse(product *domain.Product) map[string]any {
+	return map[string]any{
+		"id":                  product.ID.Hex(),
+		"name":                product.Name,
+		"sku":                 product.SKU,
+		"description":         product.Description,
+		"price":               product.Price,
+		"stock":               product.Stock,
+		"weight_grams":        product.WeightGrams,
+		"reserved":            product.Reserved,
+		"low_stock_threshold": product.LowStockThreshold,
+		"category_id":         product.CategoryID,
+		"variants":            product.Variants,
+		"images":              product.Images,
+		"created_at":          product.CreatedAt,
+		"version":             product.Version,
+	}
+}
+
+// Baca filter product dari query string. defaultLimit 0 berarti tanpa
+// batas kalau limit tidak diisi.
+func productFilter(c echo.Context, defaultLimit int64) (domain.ProductFilter, error) {
+	filter := domain.ProductFilter{
+		CategoryID: c.QueryParam("category"),
+		Currency:   strings.ToUpper(c.QueryParam("currency")),
+		Limit:      defaultLimit,
+	}
+	if filter.Currency == "" {
+		filter.Currency = money.DefaultCurrency
+	}
+
+	for param, target := range map[string]**int64{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		raw := c.QueryParam(param)
+		if raw == "" {
+			continue
+		}
+		m, err := money.Parse(raw, filter.Currency)
+		if err != nil {
+			return filter, errors.New(param + " must be a decimal amount in " + filter.Currency)
+		}
+		*target = &m.MinorUnits
+	}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 || n > maxProductLimit {
+			return filter, errors.New("limit must be 1-100")
+		}
+		filter.Limit = n
+	}
+	if raw := c.QueryParam("page"); raw != "" {
+		page, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || page <= 0 {
+			return filter, errors.New("page must be >= 1")
+		}
+		if filter.Limit == 0 {
+			filter.Limit = maxProductLimit
+		}
+		filter.Skip = (page - 1) * filter.Limit
+	}
+	return filter, nil
+}
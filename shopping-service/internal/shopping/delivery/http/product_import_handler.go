@@ -0,0 +1,279 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// batas ukuran file bulk import
+const maxImportBytes = 10 << 20
+
+// struct handler bulk import/export product
+type ProductImportHandler struct {
+	Service  app.ProductImportService
+	Products *app.ProductService
+}
+
+// init handler bulk import/export
+func NewProductImportHandler(service app.ProductImportService, products *app.ProductService) *ProductImportHandler {
+	return &ProductImportHandler{Service: service, Products: products}
+}
+
+// ImportProducts godoc
+// @Summary Bulk import product
+// @Description Upload CSV (header wajib, kolom: sku, name, description, price_minor_units, currency, stock, weight_grams, low_stock_threshold, category_id) atau NDJSON (satu product per baris). Upsert by SKU: SKU yang sudah ada diupdate, kolom yang tidak ada tidak diubah. Diproses di background, hasil per baris dibaca dari GET /products/import/{id}. File dikirim sebagai multipart field "file" atau langsung sebagai body (max 10MB, 10000 baris).
+// @Tags Products
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "csv atau ndjson, default dari nama file / content type"
+// @Param dry_run query bool false "Hanya validasi, tidak ada yang disimpan"
+// @Param file formData file false "File import"
+// @Success 202 {object} domain.ImportJob
+// @Failure 400 {object} map[string]any
+// @Failure 413 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Failure 503 {object} map[string]any
+// @Router /products/import [post]
+func (h *ProductImportHandler) ImportProducts(c echo.Context) error {
+	dryRun := false
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return ErrorResponse(c, http.StatusBadRequest, "dry_run must be true or false")
+		}
+		dryRun = b
+	}
+
+	data, filename, err := readImportFile(c)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return ErrorResponse(c, http.StatusRequestEntityTooLarge, "import file must be at most 10MB")
+		}
+		return ErrorResponse(c, http.StatusBadRequest, "failed to read import file")
+	}
+
+	format := importFormat(c.QueryParam("format"), filename, c.Request().Header.Get(echo.HeaderContentType))
+
+	job, err := h.Service.Start(format, dryRun, data, auditActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrImportJobInsert):
+			return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		case errors.Is(err, app.ErrImportUnavailable):
+			return ErrorResponse(c, http.StatusServiceUnavailable, err.Error())
+		}
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// GetImportJob godoc
+// @Summary Ambil status bulk import
+// @Description Menampilkan status job import, jumlah product yang dibuat/diupdate dan error per baris
+// @Tags Products
+// @Produce json
+// @Param id path string true "Import job ID"
+// @Success 200 {object} domain.ImportJob
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /products/import/{id} [get]
+func (h *ProductImportHandler) GetImportJob(c echo.Context) error {
+	job, err := h.Service.GetJob(c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrInvalidImportJobID):
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, app.ErrImportJobNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// ExportProducts godoc
+// @Summary Export product
+// @Description Download product yang tidak diarsipkan sebagai CSV (kolom sama dengan import) atau NDJSON (termasuk varian & gambar). Data dikirim per baris tanpa ditampung di memori, hasilnya bisa langsung di-import lagi.
+// @Tags Products
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "csv atau ndjson (default ndjson)"
+// @Param category query string false "Category ID"
+// @Param currency query string false "Currency filter harga (default IDR)"
+// @Param min_price query string false "Harga minimal"
+// @Param max_price query string false "Harga maksimal"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /products/export [get]
+func (h *ProductImportHandler) ExportProducts(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = domain.ImportFormatNDJSON
+	}
+	if format != domain.ImportFormatNDJSON && format != domain.ImportFormatCSV {
+		return ErrorResponse(c, http.StatusBadRequest, app.ErrImportFormat.Error())
+	}
+
+	filter, err := productFilter(c, 0)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	res := c.Response()
+	writer := newProductRowWriter(format, res)
+
+	// header response baru dikirim saat product pertama siap, supaya error
+	// query masih bisa dikembalikan sebagai JSON
+	started := false
+	start := func() error {
+		started = true
+		res.Header().Set(echo.HeaderContentType, writer.contentType())
+		res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="products.`+format+`"`)
+		res.WriteHeader(http.StatusOK)
+		return writer.writeHeader()
+	}
+
+	// pakai context request supaya query berhenti saat client disconnect
+	err = h.Products.ExportProducts(c.Request().Context(), filter, func(p *domain.Product) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.write(app.ToProductRow(p)); err != nil {
+			return err
+		}
+		res.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			return ErrorResponse(c, http.StatusInternalServerError, "failed to export products")
+		}
+		// header sudah terkirim, cukup log dan putus response
+		slog.ErrorContext(c.Request().Context(), "export products failed", "error", err)
+		return nil
+	}
+
+	if !started {
+		// tidak ada product, tetap kirim file kosong (CSV hanya header)
+		if err := start(); err != nil {
+			slog.ErrorContext(c.Request().Context(), "export products failed", "error", err)
+		}
+	}
+	return nil
+}
+
+// Ambil isi file dari multipart field "file", kalau tidak ada pakai body
+// request apa adanya.
+func readImportFile(c echo.Context) ([]byte, string, error) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxImportBytes)
+
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		if header.Size > maxImportBytes {
+			return nil, "", &http.MaxBytesError{Limit: maxImportBytes}
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxImportBytes))
+		return data, header.Filename, err
+	}
+
+	data, err := io.ReadAll(req.Body)
+	return data, "", err
+}
+
+// Format import dari query, lalu ekstensi file, lalu content type.
+// Kosong kalau tidak bisa ditentukan.
+func importFormat(query, filename, contentType string) string {
+	if query != "" {
+		return strings.ToLower(query)
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return domain.ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return domain.ImportFormatNDJSON
+	}
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return domain.ImportFormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"):
+		return domain.ImportFormatNDJSON
+	}
+	return ""
+}
+
+// productRowWriter menulis satu product per baris ke response
+type productRowWriter interface {
+	contentType() string
+	writeHeader() error
+	write(row app.ProductRow) error
+}
+
+func newProductRowWriter(format string, w io.Writer) productRowWriter {
+	if format == domain.ImportFormatCSV {
+		return &csvProductWriter{w: csv.NewWriter(w)}
+	}
+	return &ndjsonProductWriter{enc: json.NewEncoder(w)}
+}
+
+type ndjsonProductWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonProductWriter) contentType() string { return "application/x-ndjson" }
+
+func (w *ndjsonProductWriter) writeHeader() error { return nil }
+
+func (w *ndjsonProductWriter) write(row app.ProductRow) error {
+	return w.enc.Encode(row)
+}
+
+type csvProductWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvProductWriter) contentType() string { return "text/csv" }
+
+func (w *csvProductWriter) writeHeader() error {
+	return w.flush(app.ProductCSVColumns)
+}
+
+func (w *csvProductWriter) write(row app.ProductRow) error {
+	return w.flush(row.CSVRecord())
+}
+
+// tulis satu record lalu flush buffer csv ke response
+func (w *csvProductWriter) flush(record []string) error {
+	if err := w.w.Write(record); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,12 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route bulk import/export product
+func ProductImportRoute(e *echo.Echo, handler *ProductImportHandler) {
+	route := e.Group("/products")
+
+	route.POST("/import", handler.ImportProducts)  // upload CSV/NDJSON
+	route.GET("/import/:id", handler.GetImportJob) // status & error per baris
+	route.GET("/export", handler.ExportProducts)   // download CSV/NDJSON
+}
@@ -6,9 +6,12 @@ import "github.com/labstack/echo/v4"
 func ProductRoute(e *echo.Echo, handler *ProductHandler) {
 	route := e.Group("/products")
 
-	route.POST("", handler.CreateProduct)       // tambah product
-	route.GET("", handler.GetAllProducts)       // ambil semua
-	route.GET("/:id", handler.GetProductByID)   // ambil by id
-	route.PUT("/:id", handler.UpdateProduct)    // update by id
-	route.DELETE("/:id", handler.DeleteProduct) // hapus by id
+	route.POST("", handler.CreateProduct)              // tambah product
+	route.GET("", handler.GetAllProducts)              // ambil semua (filter)
+	route.GET("/search", handler.SearchProducts)       // search full-text
+	route.GET("/:id", handler.GetProductByID)          // ambil by id
+	route.PUT("/:id", handler.UpdateProduct)           // update by id (If-Match)
+	route.PATCH("/:id", handler.PatchProduct)          // update sebagian (merge patch)
+	route.DELETE("/:id", handler.DeleteProduct)        // arsipkan by id
+	route.POST("/:id/restore", handler.RestoreProduct) // batal arsip
 }
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"time"
+
+	"shared/money"
+)
+
+// struct request promo
+type CreatePromotionRequest struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Type        string `json:"type"` // percentage atau fixed
+	// basis point, 1000 = 10%
+	PercentOff int64       `json:"percent_off"`
+	AmountOff  money.Money `json:"amount_off"`
+	Scope      string      `json:"scope"` // order (default) atau product
+	ProductIDs []string    `json:"product_ids"`
+	StartsAt   time.Time   `json:"starts_at"`
+	EndsAt     time.Time   `json:"ends_at"`
+	// 0 berarti tanpa batas
+	MaxUses        int `json:"max_uses"`
+	MaxUsesPerUser int `json:"max_uses_per_user"`
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// struct handler promo
+type PromotionHandler struct {
+	Service app.PromotionService
+}
+
+// init handler promo
+func NewPromotionHandler(service app.PromotionService) *PromotionHandler {
+	return &PromotionHandler{Service: service}
+}
+
+// CreatePromotion godoc
+// @Summary Tambah promo
+// @Description Membuat kupon potongan persen atau nominal, untuk seluruh order atau product tertentu
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Param request body CreatePromotionRequest true "Promo data"
+// @Success 201 {object} domain.Promotion
+// @Failure 400 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /promotions [post]
+func (h *PromotionHandler) CreatePromotion(c echo.Context) error {
+	var req CreatePromotionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	promotion := &domain.Promotion{
+		Code:           req.Code,
+		Description:    req.Description,
+		Type:           req.Type,
+		PercentOff:     req.PercentOff,
+		AmountOff:      req.AmountOff,
+		Scope:          req.Scope,
+		ProductIDs:     req.ProductIDs,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+	}
+
+	if err := h.Service.Create(promotion); err != nil {
+		switch {
+		case errors.Is(err, app.ErrPromoCodeTaken):
+			return ErrorResponse(c, http.StatusConflict, err.Error())
+		case errors.Is(err, app.ErrPromotionInsert):
+			return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, promotion)
+}
+
+// GetAllPromotions godoc
+// @Summary Ambil semua promo
+// @Description Menampilkan seluruh promo beserta jumlah pemakaiannya
+// @Tags Promotions
+// @Produce json
+// @Success 200 {object} []domain.Promotion
+// @Failure 500 {object} map[string]any
+// @Router /promotions [get]
+func (h *PromotionHandler) GetAllPromotions(c echo.Context) error {
+	promotions, err := h.Service.GetAll()
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, promotions)
+}
+
+// GetPromotionByCode godoc
+// @Summary Ambil promo berdasarkan kode
+// @Description Menampilkan promo spesifik, kode tidak case sensitive
+// @Tags Promotions
+// @Produce json
+// @Param code path string true "Kode kupon"
+// @Success 200 {object} domain.Promotion
+// @Failure 404 {object} map[string]any
+// @Router /promotions/{code} [get]
+func (h *PromotionHandler) GetPromotionByCode(c echo.Context) error {
+	promotion, err := h.Service.GetByCode(c.Param("code"))
+	if err != nil {
+		if errors.Is(err, app.ErrPromotionNotFound) {
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, promotion)
+}
+
+// DeactivatePromotion godoc
+// @Summary Nonaktifkan promo
+// @Description Kupon yang nonaktif tidak bisa dipakai lagi, riwayat pemakaian tetap ada
+// @Tags Promotions
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /promotions/{id} [delete]
+func (h *PromotionHandler) DeactivatePromotion(c echo.Context) error {
+	if err := h.Service.Deactivate(c.Param("id")); err != nil {
+		if errors.Is(err, app.ErrPromotionNotFound) {
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "promotion deactivated",
+	})
+}
+
+// mapping error kupon ke HTTP status, false kalau bukan error kupon
+func couponErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, app.ErrCouponNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, app.ErrCouponExhausted), errors.Is(err, app.ErrCouponUserLimit):
+		return http.StatusConflict, true
+	case errors.Is(err, app.ErrCouponNotStarted), errors.Is(err, app.ErrCouponExpired), errors.Is(err, app.ErrCouponNotApplicable):
+		return http.StatusUnprocessableEntity, true
+	}
+	return 0, false
+}
@@ -0,0 +1,13 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route promo
+func PromotionRoute(e *echo.Echo, handler *PromotionHandler) {
+	route := e.Group("/promotions")
+
+	route.POST("", handler.CreatePromotion)           // tambah promo
+	route.GET("", handler.GetAllPromotions)           // ambil semua
+	route.GET("/:code", handler.GetPromotionByCode)   // ambil by kode
+	route.DELETE("/:id", handler.DeactivatePromotion) // nonaktifkan by id
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// kolom CSV mismatch rekonsiliasi
+var reconciliationCSVColumns = []string{
+	"kind", "source", "source_id", "payment_id",
+	"expected_minor_units", "expected_currency", "payment_minor_units", "payment_currency",
+	"source_status", "payment_status",
+}
+
+// handler rekonsiliasi payment (admin)
+type ReconciliationHandler struct {
+	Service app.ReconciliationService
+}
+
+// init handler rekonsiliasi
+func NewReconciliationHandler(service app.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{Service: service}
+}
+
+// StartReconciliation godoc
+// @Summary Jalankan rekonsiliasi payment (admin)
+// @Description Mencocokkan transaksi dan order dengan payment di Payment Service di background. Cek hasilnya di GET /admin/reconciliations/{id}
+// @Tags Admin
+// @Produce json
+// @Success 202 {object} domain.ReconciliationReport
+// @Failure 409 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /admin/reconciliations [post]
+func (h *ReconciliationHandler) StartReconciliation(c echo.Context) error {
+	report, err := h.Service.Start(auditActor(c).Email)
+	if err != nil {
+		if errors.Is(err, app.ErrReconciliationRunning) {
+			return ErrorResponse(c, http.StatusConflict, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, report)
+}
+
+// ListReconciliations godoc
+// @Summary Daftar report rekonsiliasi (admin)
+// @Description Report terbaru dulu, tanpa detail mismatch
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Jumlah report (default 30)"
+// @Success 200 {array} domain.ReconciliationReport
+// @Failure 400 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /admin/reconciliations [get]
+func (h *ReconciliationHandler) ListReconciliations(c echo.Context) error {
+	var limit int64
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 || n > 100 {
+			return ErrorResponse(c, http.StatusBadRequest, "limit must be between 1 and 100")
+		}
+		limit = n
+	}
+
+	reports, err := h.Service.List(limit)
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, reports)
+}
+
+// GetReconciliation godoc
+// @Summary Ambil report rekonsiliasi (admin)
+// @Description Report beserta mismatch (missing_payment, orphan_payment, amount_differs, status_differs). format=csv mengunduh mismatch sebagai CSV
+// @Tags Admin
+// @Produce json
+// @Produce text/csv
+// @Param id path string true "Reconciliation ID"
+// @Param format query string false "json (default) atau csv"
+// @Success 200 {object} domain.ReconciliationReport
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /admin/reconciliations/{id} [get]
+func (h *ReconciliationHandler) GetReconciliation(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return ErrorResponse(c, http.StatusBadRequest, "format must be json or csv")
+	}
+
+	report, err := h.Service.GetByID(c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrInvalidReconciliationID):
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, app.ErrReconciliationNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+
+	if format != "csv" {
+		return c.JSON(http.StatusOK, report)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="reconciliation-`+report.ID.Hex()+`.csv"`)
+	res.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(res)
+	writer.Write(reconciliationCSVColumns)
+	for _, m := range report.Mismatches {
+		writer.Write(mismatchRecord(m))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		slog.ErrorContext(c.Request().Context(), "write reconciliation csv failed", "report_id", report.ID.Hex(), "error", err)
+	}
+	return nil
+}
+
+// nilai kolom sesuai urutan reconciliationCSVColumns
+func mismatchRecord(m domain.ReconciliationMismatch) []string {
+	return []string{
+		m.Kind, m.Source, m.SourceID, m.PaymentID,
+		strconv.FormatInt(m.ExpectedAmount.MinorUnits, 10), m.ExpectedAmount.Currency,
+		strconv.FormatInt(m.PaymentAmount.MinorUnits, 10), m.PaymentAmount.Currency,
+		m.SourceStatus, m.PaymentStatus,
+	}
+}
@@ -0,0 +1,12 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route rekonsiliasi payment, dibatasi gateway untuk ADMIN_EMAILS
+func ReconciliationRoute(e *echo.Echo, handler *ReconciliationHandler) {
+	route := e.Group("/admin/reconciliations")
+
+	route.POST("", handler.StartReconciliation)  // jalankan sekarang
+	route.GET("", handler.ListReconciliations)   // report terbaru
+	route.GET("/:id", handler.GetReconciliation) // detail, format=csv untuk unduh
+}
@@ -0,0 +1,13 @@
+package http
+
+// struct request pengajuan retur
+type CreateReturnRequest struct {
+	Quantity int    `json:"quantity"`
+	Reason   string `json:"reason"` // damaged, wrong_item, not_as_described, changed_mind, other
+	Note     string `json:"note"`   // opsional
+}
+
+// struct request approve/reject retur, note opsional
+type DecideReturnRequest struct {
+	Note string `json:"note"`
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"shared/audit"
+	"shopping-service/internal/shopping/app"
+	"shopping-service/internal/shopping/domain"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// struct handler retur
+type ReturnHandler struct {
+	Service app.ReturnService
+}
+
+// init handler retur
+func NewReturnHandler(service app.ReturnService) *ReturnHandler {
+	return &ReturnHandler{Service: service}
+}
+
+// CreateReturn godoc
+// @Summary Ajukan retur
+// @Description Pembeli mengajukan retur sebagian atau seluruh quantity transaksi yang sudah delivered. Refund diproses setelah retur disetujui.
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body CreateReturnRequest true "Data retur"
+// @Success 201 {object} domain.ReturnRequest
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /transactions/{id}/returns [post]
+func (h *ReturnHandler) CreateReturn(c echo.Context) error {
+	id := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
+	}
+
+	actor, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	var req CreateReturnRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	ret, err := h.Service.Request(id, app.ReturnInput{
+		Actor:    actor,
+		Quantity: req.Quantity,
+		Reason:   req.Reason,
+		Note:     req.Note,
+	})
+	if err != nil {
+		return returnError(c, err)
+	}
+	return c.JSON(http.StatusCreated, ret)
+}
+
+// ListReturns godoc
+// @Summary Ambil retur transaksi
+// @Description Menampilkan semua retur satu transaksi, terlama dulu. Hanya pembeli transaksi atau admin.
+// @Tags Returns
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Success 200 {array} domain.ReturnRequest
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /transactions/{id}/returns [get]
+func (h *ReturnHandler) ListReturns(c echo.Context) error {
+	id := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
+	}
+
+	owner, ok := ownerFilter(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	returns, err := h.Service.ListByTransaction(id, owner)
+	if err != nil {
+		return returnError(c, err)
+	}
+	return c.JSON(http.StatusOK, returns)
+}
+
+// GetReturnByID godoc
+// @Summary Ambil retur berdasarkan ID
+// @Description Menampilkan retur beserta status refund-nya. Hanya pembeli atau admin.
+// @Tags Returns
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Success 200 {object} domain.ReturnRequest
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /returns/{id} [get]
+func (h *ReturnHandler) GetReturnByID(c echo.Context) error {
+	owner, ok := ownerFilter(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	ret, err := h.Service.GetByID(c.Param("id"), owner)
+	if err != nil {
+		return returnError(c, err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+// ApproveReturn godoc
+// @Summary Setujui retur
+// @Description Refund nominal sebanding quantity lewat Payment Service (ke payment asal transaksi) lalu mengembalikan stok. Kalau refund gagal retur tetap approved dan endpoint ini bisa dipanggil ulang tanpa refund ganda. Transaksi jadi returned setelah seluruh quantity ter-refund.
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param request body DecideReturnRequest false "Catatan"
+// @Success 200 {object} domain.ReturnRequest
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 502 {object} map[string]any
+// @Router /returns/{id}/approve [post]
+func (h *ReturnHandler) ApproveReturn(c echo.Context) error {
+	return h.decide(c, h.Service.Approve)
+}
+
+// RejectReturn godoc
+// @Summary Tolak retur
+// @Description Menolak retur yang masih requested, quantity-nya bisa diajukan lagi
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Param id path string true "Return ID"
+// @Param request body DecideReturnRequest false "Catatan"
+// @Success 200 {object} domain.ReturnRequest
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /returns/{id}/reject [post]
+func (h *ReturnHandler) RejectReturn(c echo.Context) error {
+	return h.decide(c, h.Service.Reject)
+}
+
+// approve/reject, body boleh kosong
+func (h *ReturnHandler) decide(c echo.Context, decide func(id string, actor audit.Actor, note string) (*domain.ReturnRequest, error)) error {
+	if _, ok := userEmail(c); !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	var req DecideReturnRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	ret, err := decide(c.Param("id"), auditActor(c), req.Note)
+	if err != nil {
+		return returnError(c, err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+// mapping error retur ke status HTTP
+func returnError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, app.ErrInvalidReturnID), errors.Is(err, app.ErrInvalidQuantity), errors.Is(err, app.ErrReturnReasonInvalid):
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, app.ErrReturnNotOwner):
+		return ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, app.ErrTransactionNotFound), errors.Is(err, app.ErrReturnNotFound):
+		return ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, app.ErrReturnNotAllowed), errors.Is(err, app.ErrReturnNoPayment),
+		errors.Is(err, app.ErrReturnQuantityExceeded), errors.Is(err, app.ErrReturnDecided):
+		return ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, app.ErrRefundFailed):
+		return ErrorResponse(c, http.StatusBadGateway, err.Error())
+	}
+	return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
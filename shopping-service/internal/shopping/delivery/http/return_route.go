@@ -0,0 +1,14 @@
+package http
+
+import "github.com/labstack/echo/v4"
+
+// setup route retur
+func ReturnRoute(e *echo.Echo, handler *ReturnHandler) {
+	e.POST("/transactions/:id/returns", handler.CreateReturn) // ajukan retur
+	e.GET("/transactions/:id/returns", handler.ListReturns)   // retur satu transaksi
+
+	route := e.Group("/returns")
+	route.GET("/:id", handler.GetReturnByID)
+	route.POST("/:id/approve", handler.ApproveReturn) // refund + stok kembali
+	route.POST("/:id/reject", handler.RejectReturn)
+}
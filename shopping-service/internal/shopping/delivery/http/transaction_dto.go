@@ -1,8 +1,38 @@
 package http
 
-// struct request transaksi
+// struct request transaksi, pembeli dari header X-User-Email
 type CreateTransactionRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+
+	// Opsional, diteruskan ke Payment Service
+	PaymentMethod string `json:"payment_method"`
+	CardToken     string `json:"card_token"`
+
+	// Opsional, kode kupon promo
+	CouponCode string `json:"coupon_code"`
+
+	// Opsional, region pajak dan metode ongkir (default dari config pricing)
+	Region         string `json:"region"`
+	ShippingMethod string `json:"shipping_method"`
+}
+
+// field transaksi yang bisa diubah lewat PUT dan PATCH
+type UpdateTransactionRequest struct {
 	Email     string `json:"email"`
 	ProductID string `json:"product_id"`
 	Quantity  int    `json:"quantity"`
 }
+
+// struct request perpindahan status, note opsional
+type TransitionRequest struct {
+	Note string `json:"note"`
+}
+
+// struct request kirim transaksi
+type ShipTransactionRequest struct {
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+	TrackingURL    string `json:"tracking_url"` // opsional
+	Note           string `json:"note"`
+}
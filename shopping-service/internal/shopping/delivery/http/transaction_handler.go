@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"shopping-service/internal/shopping/app"
@@ -22,33 +23,53 @@ func NewTransactionHandler(service *app.TransactionService) *TransactionHandler
 
 // CreateTransaction godoc
 // @Summary Tambah transaksi
-// @Description Menambahkan transaksi dan memanggil Payment Service
+// @Description Menambahkan transaksi milik user dari header X-User-Email dengan rincian subtotal, discount, pajak dan ongkir, lalu memanggil Payment Service. Transaksi langsung berstatus paid kalau payment berhasil.
 // @Tags Transactions
 // @Accept json
 // @Produce json
+// @Param X-User-Email header string true "Email user (diisi gateway)"
 // @Param request body CreateTransactionRequest true "Transaksi data"
 // @Success 201 {object} map[string]any
 // @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 402 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 422 {object} map[string]any
 // @Failure 500 {object} map[string]any
+// @Failure 502 {object} map[string]any
 // @Router /transactions [post]
 func (h *TransactionHandler) CreateTransaction(c echo.Context) error {
+	// pembeli selalu user dari JWT, bukan dari body
+	email, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
 	var req CreateTransactionRequest
 	if err := c.Bind(&req); err != nil {
 		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Email == "" || req.ProductID == "" || req.Quantity <= 0 {
-		return ErrorResponse(c, http.StatusBadRequest, "email, product_id, and quantity are required")
+	if req.ProductID == "" || req.Quantity <= 0 {
+		return ErrorResponse(c, http.StatusBadRequest, "product_id and quantity are required")
 	}
 
 	tx := &domain.Transaction{
-		Email:     req.Email,
-		ProductID: req.ProductID,
-		Quantity:  req.Quantity,
+		Email:         email,
+		ProductID:     req.ProductID,
+		Quantity:      req.Quantity,
+		PaymentMethod: req.PaymentMethod,
+		CardToken:     req.CardToken,
+		CouponCode:    req.CouponCode,
+
+		Region:         req.Region,
+		ShippingMethod: req.ShippingMethod,
 	}
 
-	if err := (*h.Service).Create(tx); err != nil {
-		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := (*h.Service).Create(c.Request().Context(), tx, auditActor(c)); err != nil {
+		// error pricing, kupon dan payment sama dengan checkout
+		return ErrorResponse(c, checkoutErrorStatus(err), err.Error())
 	}
 
 	return c.JSON(http.StatusCreated, map[string]any{
@@ -75,11 +96,12 @@ func (h *TransactionHandler) GetAllTransactions(c echo.Context) error {
 
 // GetTransactionByID godoc
 // @Summary Ambil transaksi berdasarkan ID
-// @Description Menampilkan transaksi spesifik berdasarkan ID
+// @Description Menampilkan transaksi spesifik berdasarkan ID. Header ETag berisi versi transaksi untuk If-Match saat update.
 // @Tags Transactions
 // @Produce json
 // @Param id path string true "Transaction ID"
 // @Success 200 {object} domain.Transaction
+// @Header 200 {string} ETag "Versi transaksi"
 // @Failure 400 {object} map[string]any
 // @Failure 404 {object} map[string]any
 // @Router /transactions/{id} [get]
@@ -97,19 +119,29 @@ func (h *TransactionHandler) GetTransactionByID(c echo.Context) error {
 		return ErrorResponse(c, http.StatusNotFound, "transaction not found")
 	}
 
+	setETag(c, result.Version)
 	return c.JSON(http.StatusOK, result)
 }
 
 // UpdateTransaction godoc
 // @Summary Update transaksi berdasarkan ID
-// @Description Update data transaksi tertentu
+// @Description Update email, product dan quantity transaksi, status diubah lewat endpoint /pack, /ship, /deliver, /cancel dan /return. Hanya pembeli (dari header X-User-Email) atau admin, selain itu response 403. Email hanya bisa diubah admin sampai dikirim. Product dan quantity hanya bisa diubah sebelum dibayar (total dihitung ulang), selain itu response 409. Header If-Match wajib berisi ETag dari GET, kalau transaksi sudah diubah request lain response 412.
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Param id path string true "Transaction ID"
-// @Param request body CreateTransactionRequest true "Updated transaksi data"
+// @Param If-Match header string true "ETag transaksi"
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param request body UpdateTransactionRequest true "Updated transaksi data"
 // @Success 200 {object} map[string]any
+// @Header 200 {string} ETag "Versi transaksi yang baru"
 // @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 412 {object} map[string]any
+// @Failure 428 {object} map[string]any
 // @Failure 500 {object} map[string]any
 // @Router /transactions/{id} [put]
 func (h *TransactionHandler) UpdateTransaction(c echo.Context) error {
@@ -118,7 +150,15 @@ func (h *TransactionHandler) UpdateTransaction(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
 	}
 
-	var req CreateTransactionRequest
+	version, ok, err := ifMatchVersion(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+	if !ok {
+		return ErrorResponse(c, http.StatusPreconditionRequired, msgIfMatchRequired)
+	}
+
+	var req UpdateTransactionRequest
 	if err := c.Bind(&req); err != nil {
 		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
 	}
@@ -130,25 +170,280 @@ func (h *TransactionHandler) UpdateTransaction(c echo.Context) error {
 		Email:     req.Email,
 		ProductID: req.ProductID,
 		Quantity:  req.Quantity,
+		Version:   version,
+	}
+
+	return h.update(c, id, tx)
+}
+
+// PatchTransaction godoc
+// @Summary Update sebagian transaksi
+// @Description Update email, product atau quantity dengan JSON Merge Patch (RFC 7396), hanya field yang dikirim yang diubah. Aturan pemilik dan status sama dengan PUT (403 bukan pembeli/admin, 409 kalau field terkunci). If-Match opsional, kalau dikirim harus sama dengan ETag transaksi.
+// @Tags Transactions
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param If-Match header string false "ETag transaksi"
+// @Param X-User-Email header string true "Email user (diisi gateway)"
+// @Param request body UpdateTransactionRequest true "Field yang diubah"
+// @Success 200 {object} map[string]any
+// @Header 200 {string} ETag "Versi transaksi yang baru"
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 412 {object} map[string]any
+// @Failure 415 {object} map[string]any
+// @Failure 500 {object} map[string]any
+// @Router /transactions/{id} [patch]
+func (h *TransactionHandler) PatchTransaction(c echo.Context) error {
+	id := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
+	}
+
+	version, ok, err := ifMatchVersion(c)
+	if err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	current, err := (*h.Service).GetByID(id)
+	if err != nil {
+		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	if current == nil {
+		return ErrorResponse(c, http.StatusNotFound, "transaction not found")
+	}
+	// versi transaksi orang lain tidak dibocorkan lewat 412
+	if owner, _ := ownerFilter(c); owner != "" && current.Email != owner {
+		return ErrorResponse(c, http.StatusForbidden, app.ErrTransactionNotOwner.Error())
+	}
+	if !ok {
+		version = current.Version
+	}
+	if version != current.Version {
+		return ErrorResponse(c, http.StatusPreconditionFailed, app.ErrVersionConflict.Error())
+	}
+
+	req := UpdateTransactionRequest{Email: current.Email, ProductID: current.ProductID, Quantity: current.Quantity}
+	if status, err := applyMergePatch(c, req, &req); err != nil {
+		return ErrorResponse(c, status, err.Error())
+	}
+
+	tx := &domain.Transaction{
+		Email:     req.Email,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		Version:   version,
 	}
 
-	if err := (*h.Service).Update(id, tx); err != nil {
+	return h.update(c, id, tx)
+}
+
+// simpan update transaksi, ETag response berisi versi baru. Selain admin
+// hanya pembeli yang boleh mengubah transaksinya.
+func (h *TransactionHandler) update(c echo.Context, id string, tx *domain.Transaction) error {
+	owner, ok := ownerFilter(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+
+	if err := (*h.Service).Update(id, tx, owner, auditActor(c)); err != nil {
+		switch {
+		case errors.Is(err, app.ErrTransactionNotOwner), errors.Is(err, app.ErrTransactionEmailAdminOnly):
+			return ErrorResponse(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, app.ErrVersionConflict):
+			return ErrorResponse(c, http.StatusPreconditionFailed, err.Error())
+		case errors.Is(err, app.ErrTransactionNotFound), errors.Is(err, app.ErrProductNotFound):
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, app.ErrTransactionItemsLocked), errors.Is(err, app.ErrTransactionEmailLocked):
+			return ErrorResponse(c, http.StatusConflict, err.Error())
+		case errors.Is(err, app.ErrInvalidEmail), errors.Is(err, app.ErrProductIDMissing), errors.Is(err, app.ErrInvalidQuantity), errors.Is(err, app.ErrReturnQuantityExceeded):
+			return ErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
 		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
 	}
 
+	setETag(c, tx.Version)
 	return c.JSON(http.StatusOK, map[string]any{
 		"message": "transaction updated",
+		"data":    tx,
 	})
 }
 
+// PackTransaction godoc
+// @Summary Tandai transaksi sudah dikemas
+// @Description Pindah status paid ke packed (admin).
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body TransitionRequest false "Catatan"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /transactions/{id}/pack [post]
+func (h *TransactionHandler) PackTransaction(c echo.Context) error {
+	var req TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+	return h.transition(c, domain.TransactionPacked, app.TransitionInput{Note: req.Note}, false)
+}
+
+// ShipTransaction godoc
+// @Summary Kirim transaksi
+// @Description Pindah status packed ke shipped dengan kurir dan nomor resi (admin).
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body ShipTransactionRequest true "Data pengiriman"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /transactions/{id}/ship [post]
+func (h *TransactionHandler) ShipTransaction(c echo.Context) error {
+	var req ShipTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+	return h.transition(c, domain.TransactionShipped, app.TransitionInput{
+		Note:           req.Note,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		TrackingURL:    req.TrackingURL,
+	}, false)
+}
+
+// DeliverTransaction godoc
+// @Summary Tandai transaksi sudah diterima
+// @Description Pindah status shipped ke delivered, waktu diterima dicatat di shipment (admin).
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body TransitionRequest false "Catatan"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /transactions/{id}/deliver [post]
+func (h *TransactionHandler) DeliverTransaction(c echo.Context) error {
+	var req TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+	return h.transition(c, domain.TransactionDelivered, app.TransitionInput{Note: req.Note}, false)
+}
+
+// CancelTransaction godoc
+// @Summary Batalkan transaksi
+// @Description Pindah status ke cancelled, hanya oleh pembeli dan sebelum dikirim (pending_payment, paid, packed). Transaksi yang sudah dibayar di-refund penuh lewat Payment Service lalu stoknya dikembalikan, kalau refund gagal status tidak berubah (502) dan cancel bisa diulang.
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body TransitionRequest false "Catatan"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Failure 502 {object} map[string]any
+// @Router /transactions/{id}/cancel [post]
+func (h *TransactionHandler) CancelTransaction(c echo.Context) error {
+	var req TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+	return h.transition(c, domain.TransactionCancelled, app.TransitionInput{Note: req.Note}, true)
+}
+
+// ReturnTransaction godoc
+// @Summary Tandai transaksi diretur
+// @Description Pindah status delivered ke returned tanpa refund dan tanpa mengembalikan stok, hanya oleh pembeli. Retur dengan refund lewat POST /transactions/{id}/returns, status returned diisi otomatis setelah seluruh quantity ter-refund.
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body TransitionRequest false "Catatan"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 403 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Failure 409 {object} map[string]any
+// @Router /transactions/{id}/return [post]
+func (h *TransactionHandler) ReturnTransaction(c echo.Context) error {
+	var req TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid request body")
+	}
+	return h.transition(c, domain.TransactionReturned, app.TransitionInput{Note: req.Note}, true)
+}
+
+// Jalankan perpindahan status dengan user dari header gateway. buyerOnly
+// untuk aksi pembeli (cancel, return): transaksi harus milik user.
+// pack, ship dan deliver dibatasi gateway untuk admin.
+func (h *TransactionHandler) transition(c echo.Context, to string, input app.TransitionInput, buyerOnly bool) error {
+	id := c.Param("id")
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
+	}
+
+	actor, ok := userEmail(c)
+	if !ok {
+		return ErrorResponse(c, http.StatusUnauthorized, "missing user")
+	}
+	input.Actor = actor
+	input.RequestID = auditActor(c).RequestID
+	if buyerOnly {
+		input.Owner = actor
+	}
+
+	tx, err := (*h.Service).Transition(id, to, input)
+	if err != nil {
+		return transitionError(c, err)
+	}
+
+	setETag(c, tx.Version)
+	return c.JSON(http.StatusOK, tx)
+}
+
+// mapping error perpindahan status ke status HTTP
+func transitionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, app.ErrTransactionNotFound):
+		return ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, app.ErrTransactionNotOwner):
+		return ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, app.ErrInvalidStatusTransition), errors.Is(err, app.ErrCancelNoPayment):
+		return ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, app.ErrTrackingMissing):
+		return ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, app.ErrCancelRefundFailed):
+		return ErrorResponse(c, http.StatusBadGateway, err.Error())
+	}
+	return ErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
+
 // DeleteTransaction godoc
 // @Summary Hapus transaksi berdasarkan ID
-// @Description Menghapus transaksi dari database
+// @Description Soft delete: transaksi tidak tampil lagi tapi tetap tersimpan dengan deleted_at dan deleted_by, bisa dikembalikan admin
 // @Tags Transactions
 // @Produce json
 // @Param id path string true "Transaction ID"
 // @Success 200 {object} map[string]any
 // @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
 // @Failure 500 {object} map[string]any
 // @Router /transactions/{id} [delete]
 func (h *TransactionHandler) DeleteTransaction(c echo.Context) error {
@@ -157,7 +452,10 @@ func (h *TransactionHandler) DeleteTransaction(c echo.Context) error {
 		return ErrorResponse(c, http.StatusBadRequest, "invalid transaction id")
 	}
 
-	if err := (*h.Service).Delete(id); err != nil {
+	if err := (*h.Service).Delete(id, auditActor(c)); err != nil {
+		if errors.Is(err, app.ErrTransactionNotFound) {
+			return ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
 		return ErrorResponse(c, http.StatusInternalServerError, err.Error())
 	}
 
@@ -9,6 +9,14 @@ func TransactionRoute(e *echo.Echo, handler *TransactionHandler) {
 	route.POST("", handler.CreateTransaction)       // tambah transaksi
 	route.GET("", handler.GetAllTransactions)       // ambil semua
 	route.GET("/:id", handler.GetTransactionByID)   // ambil by id
-	route.PUT("/:id", handler.UpdateTransaction)    // update by id
+	route.PUT("/:id", handler.UpdateTransaction)    // update by id (If-Match)
+	route.PATCH("/:id", handler.PatchTransaction)   // update sebagian (merge patch)
 	route.DELETE("/:id", handler.DeleteTransaction) // hapus by id
+
+	// workflow status, status tidak bisa diubah lewat PUT/PATCH
+	route.POST("/:id/pack", handler.PackTransaction)
+	route.POST("/:id/ship", handler.ShipTransaction)
+	route.POST("/:id/deliver", handler.DeliverTransaction)
+	route.POST("/:id/cancel", handler.CancelTransaction)
+	route.POST("/:id/return", handler.ReturnTransaction)
 }
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"shared/audit"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Header email user yang diisi gateway dari JWT
+const HeaderUserEmail = "X-User-Email"
+
+// Header penanda admin yang diisi gateway dari ADMIN_EMAILS
+const HeaderUserAdmin = "X-User-Admin"
+
+// Header ID request dari gateway, dicatat di audit log
+const HeaderRequestID = "X-Request-ID"
+
+// ambil email user dari header gateway
+func userEmail(c echo.Context) (string, bool) {
+	email := c.Request().Header.Get(HeaderUserEmail)
+	return email, email != ""
+}
+
+// cek apakah user admin menurut header gateway
+func userIsAdmin(c echo.Context) bool {
+	return c.Request().Header.Get(HeaderUserAdmin) == "true"
+}
+
+// email pemilik yang wajib dicocokkan, kosong untuk admin (boleh semua)
+func ownerFilter(c echo.Context) (string, bool) {
+	if userIsAdmin(c) {
+		return "", true
+	}
+	return userEmail(c)
+}
+
+// actor audit log dari header gateway
+func auditActor(c echo.Context) audit.Actor {
+	return audit.Actor{
+		Email:     c.Request().Header.Get(HeaderUserEmail),
+		RequestID: c.Request().Header.Get(HeaderRequestID),
+	}
+}
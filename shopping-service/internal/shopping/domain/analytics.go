@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"shared/money"
+)
+
+// interval seri revenue, minggu dimulai hari Senin
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// urutan top product
+const (
+	TopProductsByRevenue = "revenue"
+	TopProductsByUnits   = "units"
+)
+
+// Rentang laporan [From, To) dalam satu currency. Timezone (IANA) dipakai
+// untuk memotong hari/minggu/bulan.
+type AnalyticsFilter struct {
+	From     time.Time
+	To       time.Time
+	Timezone string
+	Currency string
+}
+
+// revenue dan jumlah order dalam satu periode
+type RevenuePoint struct {
+	// awal periode di timezone laporan, misalnya "2025-01-06" atau "2025-01"
+	Period  string      `json:"period"`
+	Start   time.Time   `json:"start"`
+	Revenue money.Money `json:"revenue"`
+	Orders  int         `json:"orders"`
+}
+
+// penjualan satu product, revenue setelah diskon dan sebelum pajak/ongkir
+type ProductSales struct {
+	ProductID string      `json:"product_id"`
+	Name      string      `json:"name,omitempty"`
+	Units     int         `json:"units"`
+	Revenue   money.Money `json:"revenue"`
+}
+
+// ringkasan penjualan dalam rentang laporan
+type SalesSummary struct {
+	Orders            int         `json:"orders"`
+	Revenue           money.Money `json:"revenue"`
+	AverageOrderValue money.Money `json:"average_order_value"`
+	Customers         int         `json:"customers"`
+	// customer dengan lebih dari satu order di rentang laporan
+	RepeatCustomers    int     `json:"repeat_customers"`
+	RepeatCustomerRate float64 `json:"repeat_customer_rate"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// satu baris item di cart
+type CartItem struct {
+	ProductID string    `bson:"product_id" json:"product_id"`
+	Quantity  int       `bson:"quantity" json:"quantity"`
+	AddedAt   time.Time `bson:"added_at" json:"added_at"`
+}
+
+// cart milik satu user (email), satu dokumen per user
+type Cart struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Email     string             `bson:"email" json:"email"`
+	Items     []CartItem         `bson:"items" json:"items"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "shared/money"
+
+// product hasil search beserta skor relevansinya
+type ProductHit struct {
+	Product `bson:",inline"`
+	Score   float64 `bson:"score" json:"score"`
+}
+
+// jumlah hasil search per kategori
+type CategoryFacet struct {
+	CategoryID string `json:"category_id"`
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+}
+
+// jumlah hasil search per rentang harga, Max kosong berarti tanpa batas atas
+type PriceBucketFacet struct {
+	Min   money.Money  `json:"min"`
+	Max   *money.Money `json:"max,omitempty"`
+	Count int          `json:"count"`
+}
+
+// hasil search product
+type ProductSearchResult struct {
+	Total        int                `json:"total"`
+	Products     []ProductHit       `json:"products"`
+	Categories   []CategoryFacet    `json:"categories"`
+	PriceBuckets []PriceBucketFacet `json:"price_buckets"`
+}
+
+// filter daftar/search product, harga dalam minor unit Currency
+type ProductFilter struct {
+	CategoryID string
+	Currency   string
+	MinPrice   *int64
+	MaxPrice   *int64
+	Skip       int64
+	Limit      int64 // 0 berarti tanpa batas
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kategori product dalam bentuk tree. Ancestors berisi ID kategori dari
+// root sampai parent, jadi sub tree bisa dicari tanpa query rekursif.
+type Category struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Slug      string             `bson:"slug" json:"slug"`
+	ParentID  string             `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Ancestors []string           `bson:"ancestors" json:"ancestors"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// path kategori: leluhur ditambah kategori itu sendiri
+func (c Category) Path() []string {
+	path := make([]string, 0, len(c.Ancestors)+1)
+	path = append(path, c.Ancestors...)
+	return append(path, c.ID.Hex())
+}
+
+// satu node tree kategori untuk response API
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
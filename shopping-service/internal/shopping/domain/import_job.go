@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// format file bulk import/export product
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// status job import
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// Job bulk import product. Baris diproses di background, hasilnya
+// (jumlah create/update dan error per baris) dibaca lewat job ini.
+// Pada dry-run tidak ada yang disimpan, Created/Updated berarti jumlah
+// yang akan dibuat/diupdate.
+type ImportJob struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Format    string             `bson:"format" json:"format"`
+	DryRun    bool               `bson:"dry_run" json:"dry_run"`
+	Status    string             `bson:"status" json:"status"`
+	TotalRows int                `bson:"total_rows" json:"total_rows"`
+	Created   int                `bson:"created" json:"created"`
+	Updated   int                `bson:"updated" json:"updated"`
+	Failed    int                `bson:"failed" json:"failed"`
+	// user yang memulai import, dicatat sebagai actor di audit log
+	CreatedBy string `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	// error per baris, dibatasi supaya dokumen tidak terlalu besar
+	Errors []ImportRowError `bson:"errors" json:"errors"`
+	// alasan job gagal total, misalnya header CSV salah
+	Message    string     `bson:"message,omitempty" json:"message,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	FinishedAt *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+// error validasi satu baris, Row mulai 1 dan tidak menghitung header CSV
+type ImportRowError struct {
+	Row     int    `bson:"row" json:"row"`
+	SKU     string `bson:"sku,omitempty" json:"sku,omitempty"`
+	Message string `bson:"message" json:"message"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// jenis pergerakan stok di ledger
+const (
+	MovementRestock     = "restock"
+	MovementSale        = "sale"
+	MovementAdjustment  = "adjustment"
+	MovementReservation = "reservation"
+	MovementRelease     = "release"
+	MovementReturn      = "return"
+)
+
+// Satu baris ledger stok, hanya ditambah dan tidak pernah diubah.
+// StockDelta mengubah stok fisik, ReservedDelta mengubah stok yang sedang
+// ditahan checkout. Stok tersedia = stock - reserved.
+type StockMovement struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProductID     string             `bson:"product_id" json:"product_id"`
+	Type          string             `bson:"type" json:"type"`
+	StockDelta    int                `bson:"stock_delta" json:"stock_delta"`
+	ReservedDelta int                `bson:"reserved_delta" json:"reserved_delta"`
+	StockAfter    int                `bson:"stock_after" json:"stock_after"`
+	ReservedAfter int                `bson:"reserved_after" json:"reserved_after"`
+	Reference     string             `bson:"reference,omitempty" json:"reference,omitempty"` // ID reservasi/order
+	Note          string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// status reservasi stok
+const (
+	ReservationActive    = "active"
+	ReservationCommitted = "committed"
+	// sedang di-commit: stok dipindah ke penjualan, tidak dilepas cron
+	ReservationCommitting = "committing"
+	ReservationReleased   = "released"
+	ReservationExpired    = "expired"
+)
+
+// stok yang ditahan untuk checkout yang sedang berjalan
+type StockReservation struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProductID string             `bson:"product_id" json:"product_id"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	Reference string             `bson:"reference" json:"reference"` // misalnya email pemilik cart
+	Status    string             `bson:"status" json:"status"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// format nomor invoice, contoh INV-2025-000042
+const InvoiceNumberFormat = "INV-%d-%06d"
+
+// tahun penomoran invoice, dihitung dalam UTC supaya tidak bergantung
+// zona waktu server
+func InvoiceYear(issuedAt time.Time) int {
+	return issuedAt.UTC().Year()
+}
+
+// nomor invoice ke-sequence di tahun year
+func InvoiceNumber(year int, sequence int64) string {
+	return fmt.Sprintf(InvoiceNumberFormat, year, sequence)
+}
+
+// Invoice transaksi atau order cart yang sudah dibayar. Nomor urut per tahun
+// tanpa loncatan, satu transaksi atau order hanya punya satu invoice.
+type Invoice struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Number   string             `bson:"number" json:"number"`
+	Year     int                `bson:"year" json:"year"`
+	Sequence int64              `bson:"sequence" json:"sequence"`
+	// salah satu terisi: invoice transaksi atau invoice order cart
+	TransactionID string `bson:"transaction_id,omitempty" json:"transaction_id,omitempty"`
+	OrderID       string `bson:"order_id,omitempty" json:"order_id,omitempty"`
+	Email         string `bson:"email" json:"email"`
+	PaymentID     string `bson:"payment_id" json:"payment_id"`
+	PaymentMethod string `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+
+	Lines    []InvoiceLine `bson:"lines" json:"lines"`
+	Subtotal money.Money   `bson:"subtotal" json:"subtotal"`
+	Discount money.Money   `bson:"discount" json:"discount"`
+	Tax      money.Money   `bson:"tax" json:"tax"`
+	// tarif pajak dalam basis point, 1100 = 11%
+	TaxRate  int64       `bson:"tax_rate" json:"tax_rate"`
+	Shipping money.Money `bson:"shipping" json:"shipping"`
+	Total    money.Money `bson:"total" json:"total"`
+
+	// waktu transaksi dibayar
+	PaidAt   time.Time `bson:"paid_at" json:"paid_at"`
+	IssuedAt time.Time `bson:"issued_at" json:"issued_at"`
+}
+
+// satu baris item invoice
+type InvoiceLine struct {
+	Description string      `bson:"description" json:"description"`
+	ProductID   string      `bson:"product_id" json:"product_id"`
+	Quantity    int         `bson:"quantity" json:"quantity"`
+	UnitPrice   money.Money `bson:"unit_price" json:"unit_price"`
+	Amount      money.Money `bson:"amount" json:"amount"`
+}
+
+// jenis baris statement
+const (
+	StatementPayment = "payment"
+	StatementRefund  = "refund"
+)
+
+// Statement bulanan customer: payment dan refund dalam periode
+type Statement struct {
+	Email string    `json:"email"`
+	Month string    `json:"month"` // 2006-01
+	From  time.Time `json:"from"`
+	// eksklusif
+	To      time.Time        `json:"to"`
+	Entries []StatementEntry `json:"entries"`
+	Totals  []StatementTotal `json:"totals"`
+}
+
+// satu payment atau refund di statement
+type StatementEntry struct {
+	Date      time.Time   `json:"date"`
+	Kind      string      `json:"kind"`
+	PaymentID string      `json:"payment_id"`
+	RefundID  string      `json:"refund_id,omitempty"`
+	Method    string      `json:"method,omitempty"`
+	Status    string      `json:"status,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+	Amount    money.Money `json:"amount"`
+}
+
+// total statement per currency
+type StatementTotal struct {
+	Currency string      `json:"currency"`
+	Paid     money.Money `json:"paid"`
+	Refunded money.Money `json:"refunded"`
+	Net      money.Money `json:"net"`
+}
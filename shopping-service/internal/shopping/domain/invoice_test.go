@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceNumbering(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	tests := []struct {
+		name       string
+		issuedAt   time.Time
+		sequence   int64
+		wantYear   int
+		wantNumber string
+	}{
+		{name: "nomor pertama", issuedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), sequence: 1, wantYear: 2025, wantNumber: "INV-2025-000001"},
+		{name: "diisi nol sampai 6 digit", issuedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), sequence: 42, wantYear: 2025, wantNumber: "INV-2025-000042"},
+		{name: "lebih dari 6 digit tidak dipotong", issuedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC), sequence: 1234567, wantYear: 2025, wantNumber: "INV-2025-1234567"},
+		{name: "tahun baru lokal masih tahun lama di UTC", issuedAt: time.Date(2026, 1, 1, 5, 0, 0, 0, jakarta), sequence: 7, wantYear: 2025, wantNumber: "INV-2025-000007"},
+		{name: "akhir tahun UTC", issuedAt: time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), sequence: 3, wantYear: 2025, wantNumber: "INV-2025-000003"},
+		{name: "awal tahun UTC", issuedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), sequence: 1, wantYear: 2026, wantNumber: "INV-2026-000001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			year := InvoiceYear(tt.issuedAt)
+			assert.Equal(t, tt.wantYear, year)
+			assert.Equal(t, tt.wantNumber, InvoiceNumber(year, tt.sequence))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"shared/money"
+	"shopping-service/internal/pricing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status order
+const (
+	OrderStatusPaid = "paid"
+)
+
+// item order dengan harga yang dikunci saat checkout
+type OrderItem struct {
+	ProductID string      `bson:"product_id" json:"product_id"`
+	Name      string      `bson:"name" json:"name"`
+	UnitPrice money.Money `bson:"unit_price" json:"unit_price"`
+	Quantity  int         `bson:"quantity" json:"quantity"`
+	// berat per unit saat checkout, dasar ongkir berbasis berat
+	WeightGrams int         `bson:"weight_grams" json:"weight_grams"`
+	Subtotal    money.Money `bson:"subtotal" json:"subtotal"`
+	// bagian potongan kupon untuk item ini
+	Discount money.Money `bson:"discount" json:"discount"`
+}
+
+// order hasil checkout cart, dibayar dengan satu payment
+type Order struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email     string             `bson:"email" json:"email"`
+	Items     []OrderItem        `bson:"items" json:"items"`
+	Pricing   pricing.Breakdown  `bson:"pricing" json:"pricing"`
+	Total     money.Money        `bson:"total" json:"total"` // sama dengan Pricing.Total
+	PaymentID string             `bson:"payment_id" json:"payment_id"`
+	Status    string             `bson:"status" json:"status"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+
+	CouponCode string `bson:"coupon_code,omitempty" json:"coupon_code,omitempty"`
+}
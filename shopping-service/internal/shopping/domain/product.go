@@ -3,14 +3,63 @@ package domain
 import (
 	"time"
 
+	"shared/money"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // struct Product
 type Product struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name      string             `bson:"name" json:"name"`
-	Price     float64            `bson:"price" json:"price"`
-	Stock     int                `bson:"stock" json:"stock"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"`
+	// kode unik product, dipakai sebagai kunci upsert saat bulk import
+	SKU         string      `bson:"sku,omitempty" json:"sku,omitempty"`
+	Description string      `bson:"description,omitempty" json:"description,omitempty"`
+	Price       money.Money `bson:"price" json:"price"`
+	Stock       int         `bson:"stock" json:"stock"`
+	// stok yang ditahan checkout yang belum selesai
+	Reserved int `bson:"reserved" json:"reserved"`
+	// alert product.stock_low dikirim saat stok tersedia <= nilai ini, 0 berarti mati
+	LowStockThreshold int `bson:"low_stock_threshold" json:"low_stock_threshold"`
+	// berat per unit untuk ongkir berbasis berat
+	WeightGrams int `bson:"weight_grams" json:"weight_grams"`
+
+	// kategori product, CategoryPath berisi ID leluhur sampai kategori ini
+	// supaya filter kategori ikut mencakup sub kategori
+	CategoryID   string           `bson:"category_id,omitempty" json:"category_id,omitempty"`
+	CategoryPath []string         `bson:"category_path,omitempty" json:"-"`
+	Variants     []ProductVariant `bson:"variants,omitempty" json:"variants,omitempty"`
+	Images       []ProductImage   `bson:"images,omitempty" json:"images,omitempty"`
+
+	// product yang dihapus hanya diarsipkan
+	Archived   bool       `bson:"archived" json:"archived"`
+	ArchivedAt *time.Time `bson:"archived_at,omitempty" json:"archived_at,omitempty"`
+	ArchivedBy string     `bson:"archived_by,omitempty" json:"archived_by,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+
+	// naik setiap update, dipakai sebagai ETag untuk optimistic concurrency.
+	// Perubahan stok lewat ledger tidak menaikkan versi.
+	Version int64 `bson:"version" json:"version"`
+}
+
+// varian product (misalnya ukuran/warna) dengan SKU, harga dan stok sendiri
+type ProductVariant struct {
+	SKU        string            `bson:"sku" json:"sku"`
+	Attributes map[string]string `bson:"attributes,omitempty" json:"attributes,omitempty"` // contoh {"size": "L", "color": "red"}
+	Price      money.Money       `bson:"price" json:"price"`
+	Stock      int               `bson:"stock" json:"stock"`
+}
+
+// metadata gambar product, file-nya sendiri disimpan di luar service
+type ProductImage struct {
+	URL      string `bson:"url" json:"url"`
+	Alt      string `bson:"alt,omitempty" json:"alt,omitempty"`
+	Width    int    `bson:"width,omitempty" json:"width,omitempty"`
+	Height   int    `bson:"height,omitempty" json:"height,omitempty"`
+	Position int    `bson:"position" json:"position"`
+}
+
+// stok yang masih bisa dijual
+func (p Product) Available() int {
+	return p.Stock - p.Reserved
 }
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// jenis potongan promo
+const (
+	PromotionPercentage = "percentage"
+	PromotionFixed      = "fixed"
+)
+
+// cakupan promo: seluruh order atau product tertentu
+const (
+	PromotionScopeOrder   = "order"
+	PromotionScopeProduct = "product"
+)
+
+// struct Promotion (kode kupon)
+type Promotion struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Code        string             `bson:"code" json:"code"`
+	Description string             `bson:"description" json:"description"`
+	Type        string             `bson:"type" json:"type"`
+	// potongan persen dalam basis point, 1000 = 10%
+	PercentOff int64 `bson:"percent_off,omitempty" json:"percent_off,omitempty"`
+	// potongan nominal, untuk scope product berlaku per unit
+	AmountOff  money.Money `bson:"amount_off,omitempty" json:"amount_off,omitempty"`
+	Scope      string      `bson:"scope" json:"scope"`
+	ProductIDs []string    `bson:"product_ids,omitempty" json:"product_ids,omitempty"`
+	// masa berlaku, nilai kosong berarti tanpa batas
+	StartsAt time.Time `bson:"starts_at,omitempty" json:"starts_at,omitempty"`
+	EndsAt   time.Time `bson:"ends_at,omitempty" json:"ends_at,omitempty"`
+	// batas pemakaian, 0 berarti tanpa batas
+	MaxUses        int `bson:"max_uses" json:"max_uses"`
+	MaxUsesPerUser int `bson:"max_uses_per_user" json:"max_uses_per_user"`
+	UsedCount      int `bson:"used_count" json:"used_count"`
+
+	Active    bool      `bson:"active" json:"active"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// catatan pemakaian kupon pada satu order/transaksi
+type PromotionRedemption struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PromotionID primitive.ObjectID `bson:"promotion_id" json:"promotion_id"`
+	Code        string             `bson:"code" json:"code"`
+	Email       string             `bson:"email" json:"email"`
+	Reference   string             `bson:"reference" json:"reference"` // ID order atau transaksi
+	Discount    money.Money        `bson:"discount" json:"discount"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
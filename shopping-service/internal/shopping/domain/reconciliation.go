@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+
+	"shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status report rekonsiliasi
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+)
+
+// jenis selisih transaksi/order dengan payment
+const (
+	// transaksi/order tanpa payment di Payment Service
+	MismatchMissingPayment = "missing_payment"
+	// payment yang tidak dipakai transaksi/order manapun
+	MismatchOrphanPayment = "orphan_payment"
+	MismatchAmount        = "amount_differs"
+	MismatchStatus        = "status_differs"
+)
+
+// sumber payment di shopping
+const (
+	ReconciliationSourceTransaction = "transaction"
+	ReconciliationSourceOrder       = "order"
+)
+
+// Satu selisih. Source dan SourceID kosong untuk orphan_payment,
+// PaymentStatus dan PaymentAmount kosong untuk missing_payment.
+type ReconciliationMismatch struct {
+	Kind           string      `bson:"kind" json:"kind"`
+	Source         string      `bson:"source,omitempty" json:"source,omitempty"`
+	SourceID       string      `bson:"source_id,omitempty" json:"source_id,omitempty"`
+	PaymentID      string      `bson:"payment_id,omitempty" json:"payment_id,omitempty"`
+	ExpectedAmount money.Money `bson:"expected_amount" json:"expected_amount"`
+	PaymentAmount  money.Money `bson:"payment_amount" json:"payment_amount"`
+	SourceStatus   string      `bson:"source_status,omitempty" json:"source_status,omitempty"`
+	PaymentStatus  string      `bson:"payment_status,omitempty" json:"payment_status,omitempty"`
+}
+
+// hasil satu kali rekonsiliasi transaksi & order dengan Payment Service
+type ReconciliationReport struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Status string             `bson:"status" json:"status"`
+	// "cron" atau email admin yang menjalankan
+	TriggeredBy         string `bson:"triggered_by" json:"triggered_by"`
+	TransactionsChecked int    `bson:"transactions_checked" json:"transactions_checked"`
+	OrdersChecked       int    `bson:"orders_checked" json:"orders_checked"`
+	PaymentsChecked     int    `bson:"payments_checked" json:"payments_checked"`
+	// jumlah selisih per Kind, termasuk yang tidak disimpan di Mismatches
+	MismatchCount  int            `bson:"mismatch_count" json:"mismatch_count"`
+	MismatchByKind map[string]int `bson:"mismatch_by_kind" json:"mismatch_by_kind"`
+	// dibatasi supaya dokumen tidak terlalu besar
+	Mismatches []ReconciliationMismatch `bson:"mismatches" json:"mismatches"`
+	// alasan report gagal, misalnya Payment Service tidak bisa dihubungi
+	Message    string     `bson:"message,omitempty" json:"message,omitempty"`
+	StartedAt  time.Time  `bson:"started_at" json:"started_at"`
+	FinishedAt *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"shared/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status permintaan retur
+const (
+	ReturnRequested = "requested"
+	// disetujui, refund belum berhasil (approve bisa diulang)
+	ReturnApproved = "approved"
+	ReturnRejected = "rejected"
+	ReturnRefunded = "refunded"
+)
+
+// alasan retur
+const (
+	ReturnReasonDamaged        = "damaged"
+	ReturnReasonWrongItem      = "wrong_item"
+	ReturnReasonNotAsDescribed = "not_as_described"
+	ReturnReasonChangedMind    = "changed_mind"
+	ReturnReasonOther          = "other"
+)
+
+// Permintaan retur sebagian atau seluruh quantity transaksi. Refund
+// tercatat di payment dengan reference = ID retur, jadi RefundID di sini
+// dan refund di Payment Service selalu bisa dicocokkan.
+type ReturnRequest struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TransactionID string             `bson:"transaction_id" json:"transaction_id"`
+	PaymentID     string             `bson:"payment_id" json:"payment_id"`
+	ProductID     string             `bson:"product_id" json:"product_id"`
+	Email         string             `bson:"email" json:"email"`
+	Quantity      int                `bson:"quantity" json:"quantity"`
+	Reason        string             `bson:"reason" json:"reason"`
+	Note          string             `bson:"note,omitempty" json:"note,omitempty"`
+	Status        string             `bson:"status" json:"status"`
+
+	// keputusan approve/reject
+	DecidedBy    string     `bson:"decided_by,omitempty" json:"decided_by,omitempty"`
+	DecisionNote string     `bson:"decision_note,omitempty" json:"decision_note,omitempty"`
+	DecidedAt    *time.Time `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+
+	// diisi setelah refund berhasil
+	RefundAmount money.Money `bson:"refund_amount" json:"refund_amount"`
+	RefundID     string      `bson:"refund_id,omitempty" json:"refund_id,omitempty"`
+	RefundedAt   *time.Time  `bson:"refunded_at,omitempty" json:"refunded_at,omitempty"`
+	// error refund terakhir selama status masih approved
+	LastError string `bson:"last_error,omitempty" json:"last_error,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// cek alasan retur
+func ValidReturnReason(reason string) bool {
+	switch reason {
+	case ReturnReasonDamaged, ReturnReasonWrongItem, ReturnReasonNotAsDescribed, ReturnReasonChangedMind, ReturnReasonOther:
+		return true
+	}
+	return false
+}
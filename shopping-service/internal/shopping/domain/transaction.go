@@ -1,15 +1,109 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"shared/money"
+	"shopping-service/internal/pricing"
+)
+
+// status transaksi
+const (
+	TransactionPendingPayment = "pending_payment"
+	TransactionPaid           = "paid"
+	TransactionPacked         = "packed"
+	TransactionShipped        = "shipped"
+	TransactionDelivered      = "delivered"
+	TransactionCancelled      = "cancelled"
+	TransactionReturned       = "returned"
+)
+
+// perpindahan status yang diizinkan, cancelled dan returned status akhir
+var transactionTransitions = map[string][]string{
+	TransactionPendingPayment: {TransactionPaid, TransactionCancelled},
+	TransactionPaid:           {TransactionPacked, TransactionCancelled},
+	TransactionPacked:         {TransactionShipped, TransactionCancelled},
+	TransactionShipped:        {TransactionDelivered},
+	TransactionDelivered:      {TransactionReturned},
+}
 
 // struct untuk model Transaction
 type Transaction struct {
-	ID        string    `bson:"_id,omitempty" json:"id"`
-	ProductID string    `bson:"product_id" json:"product_id"`
-	PaymentID string    `bson:"payment_id" json:"payment_id"`
-	Email     string    `bson:"email" json:"email"`
-	Quantity  int       `bson:"quantity" json:"quantity"`
-	Total     float64   `bson:"total" json:"total"`
-	Status    string    `bson:"status" json:"status"`
-	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	ID        string      `bson:"_id,omitempty" json:"id"`
+	ProductID string      `bson:"product_id" json:"product_id"`
+	PaymentID string      `bson:"payment_id" json:"payment_id"`
+	Email     string      `bson:"email" json:"email"`
+	Quantity  int         `bson:"quantity" json:"quantity"`
+	Total     money.Money `bson:"total" json:"total"`
+	Status    string      `bson:"status" json:"status"`
+	CreatedAt time.Time   `bson:"created_at" json:"created_at"`
+	// naik setiap update, dipakai sebagai ETag untuk optimistic concurrency
+	Version int64 `bson:"version" json:"version"`
+
+	PaymentMethod string `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+	// Kupon yang dipakai, potongannya ada di Pricing.Discount
+	CouponCode string `bson:"coupon_code,omitempty" json:"coupon_code,omitempty"`
+	// Rincian subtotal, discount, pajak dan ongkir. Total sama dengan Pricing.Total
+	Pricing pricing.Breakdown `bson:"pricing" json:"pricing"`
+	// Input pricing, hasilnya tersimpan di Pricing
+	Region         string `bson:"-" json:"-"`
+	ShippingMethod string `bson:"-" json:"-"`
+
+	// Riwayat perpindahan status, terlama dulu
+	StatusHistory []TransactionStatusChange `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	// Data pengiriman, diisi saat status shipped
+	Shipment *Shipment `bson:"shipment,omitempty" json:"shipment,omitempty"`
+	// Unit yang sedang diajukan atau sudah diretur, tidak boleh melebihi Quantity
+	ReturnedQuantity int `bson:"returned_quantity" json:"returned_quantity"`
+
+	// Soft delete, transaksi yang dihapus hanya tampil di endpoint admin
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+
+	// Token kartu hanya diteruskan ke Payment Service, tidak disimpan
+	CardToken string `bson:"-" json:"-"`
+}
+
+// satu perpindahan status beserta user yang melakukannya
+type TransactionStatusChange struct {
+	From  string    `bson:"from,omitempty" json:"from,omitempty"`
+	To    string    `bson:"to" json:"to"`
+	Actor string    `bson:"actor" json:"actor"`
+	Note  string    `bson:"note,omitempty" json:"note,omitempty"`
+	At    time.Time `bson:"at" json:"at"`
+}
+
+// tracking pengiriman transaksi
+type Shipment struct {
+	Carrier        string     `bson:"carrier" json:"carrier"`
+	TrackingNumber string     `bson:"tracking_number" json:"tracking_number"`
+	TrackingURL    string     `bson:"tracking_url,omitempty" json:"tracking_url,omitempty"`
+	ShippedAt      time.Time  `bson:"shipped_at" json:"shipped_at"`
+	DeliveredAt    *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// cek apakah status boleh pindah ke status to
+func (t Transaction) CanTransitionTo(to string) bool {
+	for _, next := range transactionTransitions[t.Status] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Product dan quantity hanya boleh diubah sebelum dibayar, setelah itu
+// total, stok dan payment sudah tercatat. Potongan kupon dihitung dari
+// item awal, jadi transaksi berkupon juga dikunci.
+func (t Transaction) ItemsEditable() bool {
+	return t.Status == TransactionPendingPayment && t.CouponCode == ""
+}
+
+// Email pembeli boleh diubah sampai transaksi dikirim
+func (t Transaction) EmailEditable() bool {
+	switch t.Status {
+	case TransactionPendingPayment, TransactionPaid, TransactionPacked:
+		return true
+	}
+	return false
 }
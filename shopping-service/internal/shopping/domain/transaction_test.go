@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var allTransactionStatuses = []string{
+	TransactionPendingPayment, TransactionPaid, TransactionPacked, TransactionShipped,
+	TransactionDelivered, TransactionCancelled, TransactionReturned,
+}
+
+func TestTransaction_CanTransitionTo(t *testing.T) {
+	// perpindahan yang boleh, selain ini semua ditolak
+	allowed := map[string][]string{
+		TransactionPendingPayment: {TransactionPaid, TransactionCancelled},
+		TransactionPaid:           {TransactionPacked, TransactionCancelled},
+		TransactionPacked:         {TransactionShipped, TransactionCancelled},
+		TransactionShipped:        {TransactionDelivered},
+		TransactionDelivered:      {TransactionReturned},
+		TransactionCancelled:      nil,
+		TransactionReturned:       nil,
+	}
+	for _, from := range allTransactionStatuses {
+		for _, to := range allTransactionStatuses {
+			want := false
+			for _, next := range allowed[from] {
+				want = want || next == to
+			}
+			t.Run(from+"->"+to, func(t *testing.T) {
+				assert.Equal(t, want, Transaction{Status: from}.CanTransitionTo(to))
+			})
+		}
+	}
+}
+
+func TestTransaction_CanTransitionTo_UnknownStatus(t *testing.T) {
+	assert.False(t, Transaction{Status: "refunded"}.CanTransitionTo(TransactionCancelled))
+	assert.False(t, Transaction{Status: TransactionPaid}.CanTransitionTo("refunded"))
+}
+
+func TestTransaction_EditableFields(t *testing.T) {
+	tests := []struct {
+		status    string
+		coupon    string
+		wantItems bool
+		wantEmail bool
+	}{
+		{status: TransactionPendingPayment, wantItems: true, wantEmail: true},
+		{status: TransactionPendingPayment, coupon: "HEMAT", wantItems: false, wantEmail: true},
+		{status: TransactionPaid, wantEmail: true},
+		{status: TransactionPacked, wantEmail: true},
+		{status: TransactionShipped},
+		{status: TransactionDelivered},
+		{status: TransactionCancelled},
+		{status: TransactionReturned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status+"/"+tt.coupon, func(t *testing.T) {
+			transaction := Transaction{Status: tt.status, CouponCode: tt.coupon}
+			assert.Equal(t, tt.wantItems, transaction.ItemsEditable())
+			assert.Equal(t, tt.wantEmail, transaction.EmailEditable())
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package infra
+
+import (
+	"context"
+	"log/slog"
+	"shared/money"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// interface repository laporan penjualan. Penjualan adalah transaksi yang
+// sudah dibayar dan tidak dibatalkan/diretur, ditambah order checkout.
+type AnalyticsRepository interface {
+	RevenueSeries(filter domain.AnalyticsFilter, interval string) ([]domain.RevenuePoint, error)
+	TopProducts(filter domain.AnalyticsFilter, by string, limit int64) ([]domain.ProductSales, error)
+	Summary(filter domain.AnalyticsFilter) (*domain.SalesSummary, error)
+}
+
+// status transaksi yang dihitung sebagai penjualan
+var salesTransactionStatuses = bson.A{
+	domain.TransactionPaid, domain.TransactionPacked,
+	domain.TransactionShipped, domain.TransactionDelivered,
+}
+
+type analyticsRepository struct {
+	transactions *mongo.Collection
+}
+
+// inisialisasi repository beserta index created_at untuk filter rentang
+func NewAnalyticsRepo(db *mongo.Database) AnalyticsRepository {
+	r := &analyticsRepository{transactions: db.Collection("transactions")}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	model := mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "status", Value: 1}}}
+	for _, col := range []*mongo.Collection{r.transactions, db.Collection("orders")} {
+		if _, err := col.Indexes().CreateOne(ctx, model); err != nil {
+			slog.Error("failed to create analytics index", "error", err)
+		}
+	}
+
+	return r
+}
+
+// Pipeline dasar: satu dokumen per penjualan berisi email, created_at,
+// total (minor unit) dan items {product_id, quantity, revenue}.
+// Revenue item transaksi = subtotal - diskon, transaksi lama tanpa
+// pricing memakai total.
+func salesPipeline(filter domain.AnalyticsFilter) mongo.Pipeline {
+	createdAt := bson.M{"$gte": filter.From, "$lt": filter.To}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"created_at":     createdAt,
+			"status":         bson.M{"$in": salesTransactionStatuses},
+			"deleted_at":     notDeleted,
+			"total.currency": filter.Currency,
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"email":      1,
+			"created_at": 1,
+			"total":      "$total.minor_units",
+			"items": bson.A{bson.M{
+				"product_id": "$product_id",
+				"quantity":   "$quantity",
+				"revenue": bson.M{"$ifNull": bson.A{
+					bson.M{"$subtract": bson.A{"$pricing.subtotal.minor_units", bson.M{"$ifNull": bson.A{"$pricing.discount.minor_units", 0}}}},
+					"$total.minor_units",
+				}},
+			}},
+		}}},
+		{{Key: "$unionWith", Value: bson.M{
+			"coll": "orders",
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"created_at":     createdAt,
+					"status":         domain.OrderStatusPaid,
+					"total.currency": filter.Currency,
+				}},
+				bson.M{"$project": bson.M{
+					"email":      1,
+					"created_at": 1,
+					"total":      "$total.minor_units",
+					"items": bson.M{"$map": bson.M{
+						"input": "$items",
+						"as":    "item",
+						"in": bson.M{
+							"product_id": "$$item.product_id",
+							"quantity":   "$$item.quantity",
+							"revenue":    bson.M{"$subtract": bson.A{"$$item.subtotal.minor_units", bson.M{"$ifNull": bson.A{"$$item.discount.minor_units", 0}}}},
+						},
+					}},
+				}},
+			},
+		}}},
+	}
+}
+
+func (r *analyticsRepository) RevenueSeries(filter domain.AnalyticsFilter, interval string) ([]domain.RevenuePoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	trunc := bson.M{"date": "$created_at", "unit": interval, "timezone": filter.Timezone}
+	if interval == domain.IntervalWeek {
+		trunc["startOfWeek"] = "monday"
+	}
+
+	pipeline := append(salesPipeline(filter),
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"$dateTrunc": trunc},
+			"revenue": bson.M{"$sum": "$total"},
+			"orders":  bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	)
+
+	cursor, err := r.transactions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Start   time.Time `bson:"_id"`
+		Revenue int64     `bson:"revenue"`
+		Orders  int       `bson:"orders"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	points := make([]domain.RevenuePoint, 0, len(rows))
+	for _, row := range rows {
+		points = append(points, domain.RevenuePoint{
+			Start:   row.Start,
+			Revenue: money.New(row.Revenue, filter.Currency),
+			Orders:  row.Orders,
+		})
+	}
+	return points, nil
+}
+
+func (r *analyticsRepository) TopProducts(filter domain.AnalyticsFilter, by string, limit int64) ([]domain.ProductSales, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sort := bson.D{{Key: "revenue", Value: -1}, {Key: "units", Value: -1}, {Key: "_id", Value: 1}}
+	if by == domain.TopProductsByUnits {
+		sort = bson.D{{Key: "units", Value: -1}, {Key: "revenue", Value: -1}, {Key: "_id", Value: 1}}
+	}
+
+	pipeline := append(salesPipeline(filter),
+		bson.D{{Key: "$unwind", Value: "$items"}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     "$items.product_id",
+			"units":   bson.M{"$sum": "$items.quantity"},
+			"revenue": bson.M{"$sum": "$items.revenue"},
+		}}},
+		bson.D{{Key: "$sort", Value: sort}},
+		bson.D{{Key: "$limit", Value: limit}},
+		// nama product, product yang sudah diarsipkan tetap ikut
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from": "products",
+			"let":  bson.M{"productId": bson.M{"$convert": bson.M{"input": "$_id", "to": "objectId", "onError": nil, "onNull": nil}}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$productId"}}}},
+				bson.M{"$project": bson.M{"name": 1}},
+			},
+			"as": "product",
+		}}},
+		bson.D{{Key: "$set", Value: bson.M{"name": bson.M{"$first": "$product.name"}}}},
+		bson.D{{Key: "$project", Value: bson.M{"product": 0}}},
+	)
+
+	cursor, err := r.transactions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ProductID string `bson:"_id"`
+		Name      string `bson:"name"`
+		Units     int    `bson:"units"`
+		Revenue   int64  `bson:"revenue"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	products := make([]domain.ProductSales, 0, len(rows))
+	for _, row := range rows {
+		products = append(products, domain.ProductSales{
+			ProductID: row.ProductID,
+			Name:      row.Name,
+			Units:     row.Units,
+			Revenue:   money.New(row.Revenue, filter.Currency),
+		})
+	}
+	return products, nil
+}
+
+// Ringkasan order, revenue dan customer. AverageOrderValue dan
+// RepeatCustomerRate dihitung service.
+func (r *analyticsRepository) Summary(filter domain.AnalyticsFilter) (*domain.SalesSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pipeline := append(salesPipeline(filter),
+		// per customer dulu untuk menghitung repeat customer
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     "$email",
+			"orders":  bson.M{"$sum": 1},
+			"revenue": bson.M{"$sum": "$total"},
+		}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"orders":    bson.M{"$sum": "$orders"},
+			"revenue":   bson.M{"$sum": "$revenue"},
+			"customers": bson.M{"$sum": 1},
+			"repeat":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$orders", 1}}, 1, 0}}},
+		}}},
+	)
+
+	cursor, err := r.transactions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Orders    int   `bson:"orders"`
+		Revenue   int64 `bson:"revenue"`
+		Customers int   `bson:"customers"`
+		Repeat    int   `bson:"repeat"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	summary := &domain.SalesSummary{Revenue: money.New(0, filter.Currency)}
+	if len(rows) > 0 {
+		summary.Orders = rows[0].Orders
+		summary.Revenue.MinorUnits = rows[0].Revenue
+		summary.Customers = rows[0].Customers
+		summary.RepeatCustomers = rows[0].Repeat
+	}
+	return summary, nil
+}
@@ -0,0 +1,143 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository untuk cart
+type CartRepository interface {
+	FindByEmail(email string) (*domain.Cart, error)
+	AddItem(email, productID string, quantity int) error
+	SetItemQuantity(email, productID string, quantity int) (bool, error)
+	RemoveItem(email, productID string) (bool, error)
+	Clear(email string) error
+}
+
+type cartRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection "carts", satu dokumen per email
+func NewCartRepo(db *mongo.Database) CartRepository {
+	col := db.Collection("carts")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		slog.Error("failed to create carts index", "error", err)
+	}
+
+	return &cartRepository{col: col}
+}
+
+// ambil cart user, cart kosong kalau belum pernah dibuat
+func (r *cartRepository) FindByEmail(email string) (*domain.Cart, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var cart domain.Cart
+	err := r.col.FindOne(ctx, bson.M{"email": email}).Decode(&cart)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &domain.Cart{Email: email, Items: []domain.CartItem{}}, nil
+		}
+		return nil, err
+	}
+	if cart.Items == nil {
+		cart.Items = []domain.CartItem{}
+	}
+	return &cart, nil
+}
+
+// tambah quantity kalau product sudah ada di cart, kalau belum push item baru
+func (r *cartRepository) AddItem(email, productID string, quantity int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := r.col.UpdateOne(ctx,
+		bson.M{"email": email, "items.product_id": productID},
+		bson.M{
+			"$inc": bson.M{"items.$.quantity": quantity},
+			"$set": bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	item := domain.CartItem{ProductID: productID, Quantity: quantity, AddedAt: now}
+	_, err = r.col.UpdateOne(ctx,
+		bson.M{"email": email, "items.product_id": bson.M{"$ne": productID}},
+		bson.M{
+			"$push": bson.M{"items": item},
+			"$set":  bson.M{"updated_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// request lain menambahkan product yang sama bersamaan, ulangi sebagai $inc
+		return r.AddItem(email, productID, quantity)
+	}
+	return err
+}
+
+// set quantity item, false kalau item tidak ada di cart
+func (r *cartRepository) SetItemQuantity(email, productID string, quantity int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.col.UpdateOne(ctx,
+		bson.M{"email": email, "items.product_id": productID},
+		bson.M{"$set": bson.M{"items.$.quantity": quantity, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// hapus item, false kalau item tidak ada di cart
+func (r *cartRepository) RemoveItem(email, productID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.col.UpdateOne(ctx,
+		bson.M{"email": email, "items.product_id": productID},
+		bson.M{
+			"$pull": bson.M{"items": bson.M{"product_id": productID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// kosongkan cart
+func (r *cartRepository) Clear(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"email": email},
+		bson.M{"$set": bson.M{"items": []domain.CartItem{}, "updated_at": time.Now()}},
+	)
+	return err
+}
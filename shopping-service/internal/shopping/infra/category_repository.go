@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository kategori
+type CategoryRepository interface {
+	Insert(category *domain.Category) error
+	FindAll() ([]domain.Category, error)
+	FindByID(id string) (*domain.Category, error)
+}
+
+type categoryRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection kategori, slug unik
+func NewCategoryRepo(db *mongo.Database) CategoryRepository {
+	col := db.Collection("categories")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		slog.Error("failed to create category index", "error", err)
+	}
+
+	return &categoryRepository{col: col}
+}
+
+func (r *categoryRepository) Insert(category *domain.Category) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	category.CreatedAt = time.Now()
+
+	result, err := r.col.InsertOne(ctx, category)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCategorySlugExists
+		}
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		category.ID = oid
+	}
+	return nil
+}
+
+func (r *categoryRepository) FindAll() ([]domain.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.col.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []domain.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// ambil kategori by ID, nil kalau tidak ada
+func (r *categoryRepository) FindByID(id string) (*domain.Category, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidCategoryID
+	}
+
+	var category domain.Category
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &category, nil
+}
@@ -0,0 +1,43 @@
+package infra
+
+import "errors"
+
+// ID dari request tidak valid
+var (
+	ErrInvalidProductID        = errors.New("invalid product ID")
+	ErrInvalidTransactionID    = errors.New("invalid transaction ID")
+	ErrInvalidOrderID          = errors.New("invalid order ID")
+	ErrInvalidReturnID         = errors.New("invalid return ID")
+	ErrInvalidCategoryID       = errors.New("invalid category ID")
+	ErrInvalidInvoiceID        = errors.New("invalid invoice ID")
+	ErrInvalidPromotionID      = errors.New("invalid promotion ID")
+	ErrInvalidImportJobID      = errors.New("invalid import job ID")
+	ErrInvalidReconciliationID = errors.New("invalid reconciliation ID")
+	ErrInvalidPageToken        = errors.New("invalid page token")
+)
+
+// dokumen tidak ada
+var (
+	ErrProductNotFound     = errors.New("product not found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrReturnNotFound      = errors.New("return request not found")
+	ErrPromotionNotFound   = errors.New("promotion not found")
+)
+
+// update bersyarat ditolak
+var (
+	ErrVersionConflict         = errors.New("version conflict")
+	ErrInsufficientStock       = errors.New("insufficient stock")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrReturnQuantityExceeded  = errors.New("return quantity exceeded")
+	ErrInvalidReturnStatus     = errors.New("invalid return status")
+)
+
+// unique index
+var (
+	ErrDuplicateSKU        = errors.New("duplicate SKU")
+	ErrCategorySlugExists  = errors.New("category slug already exists")
+	ErrPromotionCodeExists = errors.New("promotion code already exists")
+	// transaksi sudah punya invoice dari proses lain
+	ErrInvoiceExists = errors.New("invoice already exists")
+)
@@ -0,0 +1,95 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// interface repository job bulk import
+type ImportJobRepository interface {
+	Insert(job *domain.ImportJob) error
+	Update(job *domain.ImportJob) error
+	FindByID(id string) (*domain.ImportJob, error)
+	FailUnfinished(message string) (int64, error)
+}
+
+type importJobRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection "import_jobs"
+func NewImportJobRepo(db *mongo.Database) ImportJobRepository {
+	col := db.Collection("import_jobs")
+	return &importJobRepository{col: col}
+}
+
+func (r *importJobRepository) Insert(job *domain.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job.CreatedAt = time.Now()
+
+	result, err := r.col.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		job.ID = oid
+	}
+	return nil
+}
+
+// simpan progress job, seluruh dokumen ditimpa
+func (r *importJobRepository) Update(job *domain.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	return err
+}
+
+// Tandai job pending/running sebagai failed, dipakai saat service start
+// untuk job yang terputus karena proses sebelumnya mati
+func (r *importJobRepository) FailUnfinished(message string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": bson.M{"$in": []string{domain.ImportPending, domain.ImportRunning}}}
+	update := bson.M{"$set": bson.M{
+		"status":      domain.ImportFailed,
+		"message":     message,
+		"finished_at": time.Now(),
+	}}
+	result, err := r.col.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// ambil job by ID, nil kalau tidak ada
+func (r *importJobRepository) FindByID(id string) (*domain.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidImportJobID
+	}
+
+	var job domain.ImportJob
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
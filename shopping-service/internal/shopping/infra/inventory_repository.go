@@ -0,0 +1,186 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository inventory: perubahan stok atomic, ledger dan reservasi
+type InventoryRepository interface {
+	// ubah stock dan reserved dalam satu operasi. Gagal dengan
+	// "insufficient stock" kalau hasilnya membuat stok tersedia atau
+	// stock negatif. Mengembalikan product setelah diubah.
+	ApplyDelta(productID string, stockDelta, reservedDelta int) (*domain.Product, error)
+	InsertMovement(movement *domain.StockMovement) error
+	FindMovements(productID string, limit int64) ([]domain.StockMovement, error)
+
+	InsertReservation(reservation *domain.StockReservation) error
+	// pindahkan status reservasi hanya kalau status sekarang == from,
+	// false kalau sudah diproses pihak lain (checkout atau cron)
+	TransitionReservation(id primitive.ObjectID, from, to string) (bool, error)
+	FindExpiredReservations(now time.Time, limit int64) ([]domain.StockReservation, error)
+}
+
+type inventoryRepository struct {
+	products     *mongo.Collection
+	movements    *mongo.Collection
+	reservations *mongo.Collection
+}
+
+// inisialisasi collection ledger dan reservasi beserta index
+func NewInventoryRepo(db *mongo.Database) InventoryRepository {
+	r := &inventoryRepository{
+		products:     db.Collection("products"),
+		movements:    db.Collection("stock_movements"),
+		reservations: db.Collection("stock_reservations"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	indexes := []struct {
+		col   *mongo.Collection
+		model mongo.IndexModel
+	}{
+		{r.movements, mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}}},
+		{r.reservations, mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}}},
+	}
+	for _, idx := range indexes {
+		if _, err := idx.col.Indexes().CreateOne(ctx, idx.model); err != nil {
+			slog.Error("failed to create inventory index", "error", err)
+		}
+	}
+
+	return r
+}
+
+func (r *inventoryRepository) ApplyDelta(productID string, stockDelta, reservedDelta int) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, ErrInvalidProductID
+	}
+
+	// dokumen lama belum punya field reserved, anggap 0
+	stock := bson.M{"$add": bson.A{"$stock", stockDelta}}
+	reserved := bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$reserved", 0}}, reservedDelta}}
+	filter := bson.M{
+		"_id": objID,
+		"$expr": bson.M{"$and": bson.A{
+			bson.M{"$gte": bson.A{reserved, 0}},
+			bson.M{"$gte": bson.A{bson.M{"$subtract": bson.A{stock, reserved}}, 0}},
+		}},
+	}
+
+	var product domain.Product
+	err = r.products.FindOneAndUpdate(ctx, filter,
+		bson.M{"$inc": bson.M{"stock": stockDelta, "reserved": reservedDelta}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// bedakan product tidak ada dengan stok kurang
+			if n, _ := r.products.CountDocuments(ctx, bson.M{"_id": objID}); n == 0 {
+				return nil, ErrProductNotFound
+			}
+			return nil, ErrInsufficientStock
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *inventoryRepository) InsertMovement(movement *domain.StockMovement) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	movement.CreatedAt = time.Now()
+
+	result, err := r.movements.InsertOne(ctx, movement)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		movement.ID = oid
+	}
+	return nil
+}
+
+// riwayat pergerakan stok product, terbaru dulu
+func (r *inventoryRepository) FindMovements(productID string, limit int64) ([]domain.StockMovement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit)
+	cursor, err := r.movements.Find(ctx, bson.M{"product_id": productID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	movements := []domain.StockMovement{}
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+func (r *inventoryRepository) InsertReservation(reservation *domain.StockReservation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reservation.CreatedAt = time.Now()
+	reservation.UpdatedAt = reservation.CreatedAt
+
+	result, err := r.reservations.InsertOne(ctx, reservation)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		reservation.ID = oid
+	}
+	return nil
+}
+
+func (r *inventoryRepository) TransitionReservation(id primitive.ObjectID, from, to string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.reservations.UpdateOne(ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// reservasi aktif yang sudah lewat expires_at
+func (r *inventoryRepository) FindExpiredReservations(now time.Time, limit int64) ([]domain.StockReservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": domain.ReservationActive, "expires_at": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}}).SetLimit(limit)
+	cursor, err := r.reservations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reservations := []domain.StockReservation{}
+	if err := cursor.All(ctx, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
@@ -0,0 +1,187 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// berapa kali alokasi nomor invoice dicoba saat bentrok dengan insert lain
+const invoiceNumberAttempts = 10
+
+// interface repository invoice
+type InvoiceRepository interface {
+	// simpan invoice dengan nomor urut berikutnya di tahun IssuedAt
+	Insert(invoice *domain.Invoice) error
+	FindByID(id string) (*domain.Invoice, error)
+	FindByTransaction(transactionID string) (*domain.Invoice, error)
+	FindByOrder(orderID string) (*domain.Invoice, error)
+	// invoice milik email, terbaru dulu
+	FindByEmail(email string) ([]domain.Invoice, error)
+}
+
+type invoiceRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection "invoices". Index unik (year, sequence) menjaga
+// nomor tidak dipakai dua kali, index unik transaction_id dan order_id
+// (hanya dokumen yang punya field itu) menjaga satu transaksi atau order
+// hanya punya satu invoice.
+func NewInvoiceRepo(db *mongo.Database) InvoiceRepository {
+	col := db.Collection("invoices")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "year", Value: 1}, {Key: "sequence", Value: 1}},
+			Options: options.Index().SetName("year_sequence_unique").SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "transaction_id", Value: 1}},
+			Options: options.Index().SetName("transaction_unique").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"transaction_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().SetName("order_unique").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"order_id": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "issued_at", Value: -1}}},
+	})
+	if err != nil {
+		slog.Error("failed to create invoices index", "error", err)
+	}
+
+	return &invoiceRepository{col: col}
+}
+
+// Nomor diambil dari sequence terbesar tahun itu + 1 lalu langsung di-insert.
+// Kalau insert lain memakai nomor yang sama lebih dulu, index unik menolak
+// dan nomor dihitung ulang. Nomor hanya terpakai kalau insert berhasil,
+// jadi tidak ada nomor yang terlewat.
+func (r *invoiceRepository) Insert(invoice *domain.Invoice) error {
+	if invoice.IssuedAt.IsZero() {
+		invoice.IssuedAt = time.Now()
+	}
+	invoice.Year = domain.InvoiceYear(invoice.IssuedAt)
+
+	for attempt := 0; attempt < invoiceNumberAttempts; attempt++ {
+		last, err := r.lastSequence(invoice.Year)
+		if err != nil {
+			return err
+		}
+		invoice.Sequence = last + 1
+		invoice.Number = domain.InvoiceNumber(invoice.Year, invoice.Sequence)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		result, err := r.col.InsertOne(ctx, invoice)
+		cancel()
+		if err == nil {
+			if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+				invoice.ID = oid
+			}
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+
+		// transaksi atau order sudah punya invoice dari proses lain
+		existing, findErr := r.findSource(invoice)
+		if findErr != nil {
+			return findErr
+		}
+		if existing != nil {
+			return ErrInvoiceExists
+		}
+	}
+	return errors.New("failed to allocate invoice number")
+}
+
+// sequence terbesar di tahun year, 0 kalau belum ada invoice
+func (r *invoiceRepository) lastSequence(year int) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "sequence", Value: -1}}).
+		SetProjection(bson.M{"sequence": 1})
+	var last domain.Invoice
+	err := r.col.FindOne(ctx, bson.M{"year": year}, opts).Decode(&last)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return last.Sequence, nil
+}
+
+// ambil invoice by ID, nil kalau tidak ada
+func (r *invoiceRepository) FindByID(id string) (*domain.Invoice, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidInvoiceID
+	}
+	return r.findOne(bson.M{"_id": objID})
+}
+
+// ambil invoice transaksi, nil kalau belum dibuat
+func (r *invoiceRepository) FindByTransaction(transactionID string) (*domain.Invoice, error) {
+	return r.findOne(bson.M{"transaction_id": transactionID})
+}
+
+// ambil invoice order cart, nil kalau belum dibuat
+func (r *invoiceRepository) FindByOrder(orderID string) (*domain.Invoice, error) {
+	return r.findOne(bson.M{"order_id": orderID})
+}
+
+// invoice lain untuk transaksi atau order yang sama
+func (r *invoiceRepository) findSource(invoice *domain.Invoice) (*domain.Invoice, error) {
+	if invoice.OrderID != "" {
+		return r.FindByOrder(invoice.OrderID)
+	}
+	return r.FindByTransaction(invoice.TransactionID)
+}
+
+func (r *invoiceRepository) findOne(filter bson.M) (*domain.Invoice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var invoice domain.Invoice
+	err := r.col.FindOne(ctx, filter).Decode(&invoice)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) FindByEmail(email string) ([]domain.Invoice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "issued_at", Value: -1}})
+	cursor, err := r.col.Find(ctx, bson.M{"email": email}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invoices := []domain.Invoice{}
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
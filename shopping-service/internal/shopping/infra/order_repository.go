@@ -0,0 +1,112 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository untuk order
+type OrderRepository interface {
+	Insert(order *domain.Order) error
+	FindByEmail(email string) ([]domain.Order, error)
+	FindByID(id string) (*domain.Order, error)
+	// satu halaman order urut _id, dipakai rekonsiliasi
+	FindPage(afterID string, limit int64) ([]domain.Order, error)
+}
+
+type orderRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection "orders"
+func NewOrderRepo(db *mongo.Database) OrderRepository {
+	col := db.Collection("orders")
+	return &orderRepository{col: col}
+}
+
+func (r *orderRepository) Insert(order *domain.Order) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	order.CreatedAt = time.Now()
+
+	result, err := r.col.InsertOne(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		order.ID = oid
+	}
+
+	return nil
+}
+
+// order milik user, terbaru dulu
+func (r *orderRepository) FindByEmail(email string) ([]domain.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.col.Find(ctx, bson.M{"email": email}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := []domain.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ambil order by ID, nil kalau tidak ada
+func (r *orderRepository) FindByID(id string) (*domain.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidOrderID
+	}
+
+	var order domain.Order
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&order)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) FindPage(afterID string, limit int64) ([]domain.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter, err := pageFilter(afterID)
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := []domain.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
@@ -0,0 +1,18 @@
+package infra
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Filter paging by _id: dokumen setelah afterID, semua kalau kosong
+func pageFilter(afterID string) (bson.M, error) {
+	if afterID == "" {
+		return bson.M{}, nil
+	}
+	objID, err := primitive.ObjectIDFromHex(afterID)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return bson.M{"_id": bson.M{"$gt": objID}}, nil
+}
@@ -3,13 +3,15 @@ package infra
 import (
 	"context"
 	"errors"
-	"shopping-service/config"
+	"log/slog"
+	"shared/money"
 	"shopping-service/internal/shopping/domain"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // struct repo
@@ -17,21 +19,56 @@ type ProductRepo struct {
 	collection *mongo.Collection
 }
 
-// init repo
-func NewProductRepo() *ProductRepo {
-	collection := config.DB.Collection("products")
+// init repo beserta index catalog
+func NewProductRepo(db *mongo.Database) *ProductRepo {
+	collection := db.Collection("products")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// satu-satunya text index di collection, nama paling berbobot
+			Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}, {Key: "variants.sku", Value: "text"}},
+			Options: options.Index().SetName("product_text").
+				SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "variants.sku", Value: 5}, {Key: "description", Value: 1}}),
+		},
+		{Keys: bson.D{{Key: "category_path", Value: 1}}},
+		{
+			// SKU product unik, product tanpa SKU tidak ikut index
+			Keys: bson.D{{Key: "sku", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"sku": bson.M{"$gt": ""}}),
+		},
+		{
+			// SKU varian unik antar product
+			Keys: bson.D{{Key: "variants.sku", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		slog.Error("failed to create product index", "error", err)
+	}
+
 	return &ProductRepo{collection: collection}
 }
 
+// product yang diarsipkan tidak ikut query biasa
+var notArchived = bson.M{"$ne": true}
+
 // simpan product
 func (r *ProductRepo) CreateProduct(product *domain.Product) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	product.CreatedAt = time.Now()
+	product.Version = 1
 
 	result, err := r.collection.InsertOne(ctx, product)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateSKU
+		}
 		return err
 	}
 
@@ -44,18 +81,23 @@ func (r *ProductRepo) CreateProduct(product *domain.Product) error {
 	return nil
 }
 
-// ambil semua product
-func (r *ProductRepo) GetAllProducts() ([]domain.Product, error) {
+// ambil product sesuai filter, terbaru dulu
+func (r *ProductRepo) GetAllProducts(filter domain.ProductFilter) ([]domain.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetSkip(filter.Skip)
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, productQuery(filter), opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var products []domain.Product
+	products := []domain.Product{}
 	for cursor.Next(ctx) {
 		var p domain.Product
 		if err := cursor.Decode(&p); err != nil {
@@ -64,10 +106,6 @@ func (r *ProductRepo) GetAllProducts() ([]domain.Product, error) {
 		products = append(products, p)
 	}
 
-	if len(products) == 0 {
-		return nil, errors.New("no products found")
-	}
-
 	return products, nil
 }
 
@@ -78,14 +116,14 @@ func (r *ProductRepo) GetProductByID(id string) (*domain.Product, error) {
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid product ID")
+		return nil, ErrInvalidProductID
 	}
 
 	var product domain.Product
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&product)
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID, "archived": notArchived}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("product not found")
+			return nil, ErrProductNotFound
 		}
 		return nil, err
 	}
@@ -93,54 +131,288 @@ func (r *ProductRepo) GetProductByID(id string) (*domain.Product, error) {
 	return &product, nil
 }
 
-// update product by ID
+// update product by ID. Stok tidak diubah di sini, perubahan stok
+// lewat inventory supaya tercatat di ledger.
 func (r *ProductRepo) UpdateProduct(id string, product *domain.Product) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid product ID")
+		return ErrInvalidProductID
 	}
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":  product.Name,
-			"price": product.Price,
-			"stock": product.Stock,
+			"name":                product.Name,
+			"sku":                 product.SKU,
+			"description":         product.Description,
+			"price":               product.Price,
+			"weight_grams":        product.WeightGrams,
+			"low_stock_threshold": product.LowStockThreshold,
+			"category_id":         product.CategoryID,
+			"category_path":       product.CategoryPath,
+			"variants":            product.Variants,
+			"images":              product.Images,
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	result, err := r.collection.UpdateByID(ctx, objID, update)
+	// update hanya kalau versi belum berubah sejak dibaca client
+	filter := bson.M{"_id": objID, "archived": notArchived, "version": versionFilter(product.Version)}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateSKU
+		}
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("product not found")
+		n, err := r.collection.CountDocuments(ctx, bson.M{"_id": objID, "archived": notArchived})
+		if err == nil && n > 0 {
+			return ErrVersionConflict
+		}
+		return ErrProductNotFound
 	}
 
+	product.Version++
 	return nil
 }
 
-// delete product by ID
-func (r *ProductRepo) DeleteProduct(id string) error {
+// ambil product aktif by SKU, nil kalau tidak ada
+func (r *ProductRepo) GetProductBySKU(sku string) (*domain.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	objID, err := primitive.ObjectIDFromHex(id)
+	var product domain.Product
+	err := r.collection.FindOne(ctx, bson.M{"sku": sku, "archived": notArchived}).Decode(&product)
 	if err != nil {
-		return errors.New("invalid product ID")
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// Panggil fn untuk setiap product sesuai filter tanpa menampung semuanya
+// di memori. Berhenti di error pertama dari fn.
+func (r *ProductRepo) StreamProducts(ctx context.Context, filter domain.ProductFilter, fn func(*domain.Product) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetSkip(filter.Skip)
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	cursor, err := r.collection.Find(ctx, productQuery(filter), opts)
 	if err != nil {
 		return err
 	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var p domain.Product
+		if err := cursor.Decode(&p); err != nil {
+			return err
+		}
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Arsipkan product by ID, data dan riwayatnya tetap ada. Hasilnya
+// product sebelum diarsipkan.
+func (r *ProductRepo) ArchiveProduct(id, archivedBy string) (*domain.Product, error) {
+	return r.setArchived(id, true, archivedBy)
+}
+
+// Kembalikan product yang diarsipkan. Hasilnya product sebelum
+// dikembalikan (masih dengan archived_at dan archived_by).
+func (r *ProductRepo) RestoreProduct(id string) (*domain.Product, error) {
+	return r.setArchived(id, false, "")
+}
+
+func (r *ProductRepo) setArchived(id string, archived bool, archivedBy string) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidProductID
+	}
 
-	if result.DeletedCount == 0 {
-		return errors.New("product not found")
+	update := bson.M{"$set": bson.M{"archived": true, "archived_at": time.Now(), "archived_by": archivedBy}}
+	filter := bson.M{"_id": objID, "archived": notArchived}
+	if !archived {
+		update = bson.M{"$set": bson.M{"archived": false}, "$unset": bson.M{"archived_at": "", "archived_by": ""}}
+		filter = bson.M{"_id": objID, "archived": true}
 	}
 
-	return nil
+	var before domain.Product
+	err = r.collection.FindOneAndUpdate(ctx, filter, update).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &before, nil
+}
+
+// product yang diarsipkan, terbaru diarsipkan dulu
+func (r *ProductRepo) GetArchivedProducts() ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "archived_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"archived": true}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := []domain.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Search full-text dengan skor relevansi, ditambah facet jumlah per
+// kategori dan per rentang harga. priceBounds (minimal dua, urut naik)
+// adalah batas bawah tiap bucket dalam minor unit filter.Currency, bucket
+// terakhir tanpa batas atas.
+func (r *ProductRepo) SearchProducts(text string, filter domain.ProductFilter, priceBounds []int64) (*domain.ProductSearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := productQuery(filter)
+	match["$text"] = bson.M{"$search": text}
+
+	page := bson.A{bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}}, bson.M{"$skip": filter.Skip}}
+	if filter.Limit > 0 {
+		page = append(page, bson.M{"$limit": filter.Limit})
+	}
+
+	bounds := bson.A{}
+	for _, b := range priceBounds {
+		bounds = append(bounds, b)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+		{{Key: "$facet", Value: bson.M{
+			"products": page,
+			"total":    bson.A{bson.M{"$count": "count"}},
+			"categories": bson.A{
+				bson.M{"$match": bson.M{"category_id": bson.M{"$nin": bson.A{nil, ""}}}},
+				bson.M{"$group": bson.M{"_id": "$category_id", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"price_buckets": bson.A{
+				bson.M{"$match": bson.M{"price.currency": filter.Currency}},
+				bson.M{"$bucket": bson.M{
+					"groupBy":    "$price.minor_units",
+					"boundaries": bounds,
+					"default":    "above",
+					"output":     bson.M{"count": bson.M{"$sum": 1}},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Products []domain.ProductHit `bson:"products"`
+		Total    []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Categories []struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		} `bson:"categories"`
+		PriceBuckets []struct {
+			ID    any `bson:"_id"`
+			Count int `bson:"count"`
+		} `bson:"price_buckets"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	result := &domain.ProductSearchResult{
+		Products:     []domain.ProductHit{},
+		Categories:   []domain.CategoryFacet{},
+		PriceBuckets: []domain.PriceBucketFacet{},
+	}
+	if len(facets) == 0 {
+		return result, nil
+	}
+
+	f := facets[0]
+	result.Products = append(result.Products, f.Products...)
+	if len(f.Total) > 0 {
+		result.Total = f.Total[0].Count
+	}
+	for _, c := range f.Categories {
+		result.Categories = append(result.Categories, domain.CategoryFacet{CategoryID: c.ID, Count: c.Count})
+	}
+
+	// _id bucket adalah batas bawahnya, "above" untuk harga >= batas terakhir
+	counts := make(map[int64]int, len(priceBounds))
+	above := 0
+	for _, b := range f.PriceBuckets {
+		switch v := b.ID.(type) {
+		case int64:
+			counts[v] = b.Count
+		case int32:
+			counts[int64(v)] = b.Count
+		default:
+			above = b.Count
+		}
+	}
+	for i, lower := range priceBounds {
+		count := counts[lower]
+		if i == len(priceBounds)-1 {
+			count += above
+		}
+		bucket := domain.PriceBucketFacet{Min: money.New(lower, filter.Currency), Count: count}
+		if i < len(priceBounds)-1 {
+			upper := money.New(priceBounds[i+1], filter.Currency)
+			bucket.Max = &upper
+		}
+		result.PriceBuckets = append(result.PriceBuckets, bucket)
+	}
+
+	return result, nil
+}
+
+// query dasar daftar/search product
+func productQuery(filter domain.ProductFilter) bson.M {
+	query := bson.M{"archived": notArchived}
+	if filter.CategoryID != "" {
+		query["category_path"] = filter.CategoryID
+	}
+
+	price := bson.M{}
+	if filter.MinPrice != nil {
+		price["$gte"] = *filter.MinPrice
+	}
+	if filter.MaxPrice != nil {
+		price["$lte"] = *filter.MaxPrice
+	}
+	if len(price) > 0 {
+		query["price.currency"] = filter.Currency
+		query["price.minor_units"] = price
+	}
+	return query
 }
@@ -0,0 +1,213 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository untuk promo
+type PromotionRepository interface {
+	Insert(promotion *domain.Promotion) error
+	FindAll() ([]domain.Promotion, error)
+	FindByCode(code string) (*domain.Promotion, error)
+	Deactivate(id string) error
+	// naikkan used_count kalau belum mencapai max_uses, false kalau habis
+	ReserveUse(id primitive.ObjectID) (bool, error)
+	ReleaseUse(id primitive.ObjectID) error
+	// naikkan pemakaian per user kalau belum mencapai max, false kalau habis
+	ReserveUserUse(id primitive.ObjectID, email string, max int) (bool, error)
+	ReleaseUserUse(id primitive.ObjectID, email string) error
+	InsertRedemption(redemption *domain.PromotionRedemption) error
+}
+
+type promotionRepository struct {
+	col         *mongo.Collection
+	usages      *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+// inisialisasi collection promo, pemakaian per user dan riwayat redeem
+func NewPromotionRepo(db *mongo.Database) PromotionRepository {
+	r := &promotionRepository{
+		col:         db.Collection("promotions"),
+		usages:      db.Collection("promotion_usages"),
+		redemptions: db.Collection("promotion_redemptions"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	indexes := []struct {
+		col   *mongo.Collection
+		model mongo.IndexModel
+	}{
+		{r.col, mongo.IndexModel{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)}},
+		{r.usages, mongo.IndexModel{Keys: bson.D{{Key: "promotion_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)}},
+		{r.redemptions, mongo.IndexModel{Keys: bson.D{{Key: "promotion_id", Value: 1}, {Key: "created_at", Value: -1}}}},
+	}
+	for _, idx := range indexes {
+		if _, err := idx.col.Indexes().CreateOne(ctx, idx.model); err != nil {
+			slog.Error("failed to create promotion index", "error", err)
+		}
+	}
+
+	return r
+}
+
+func (r *promotionRepository) Insert(promotion *domain.Promotion) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	promotion.CreatedAt = time.Now()
+
+	result, err := r.col.InsertOne(ctx, promotion)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrPromotionCodeExists
+		}
+		return err
+	}
+
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		promotion.ID = oid
+	}
+	return nil
+}
+
+func (r *promotionRepository) FindAll() ([]domain.Promotion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.col.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	promotions := []domain.Promotion{}
+	if err := cursor.All(ctx, &promotions); err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// ambil promo by kode, nil kalau tidak ada
+func (r *promotionRepository) FindByCode(code string) (*domain.Promotion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var promotion domain.Promotion
+	err := r.col.FindOne(ctx, bson.M{"code": code}).Decode(&promotion)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepository) Deactivate(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidPromotionID
+	}
+
+	result, err := r.col.UpdateByID(ctx, objID, bson.M{"$set": bson.M{"active": false}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *promotionRepository) ReserveUse(id primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// kondisi dan increment dalam satu operasi supaya tidak over-redeem
+	filter := bson.M{
+		"_id":    id,
+		"active": true,
+		"$or": bson.A{
+			bson.M{"max_uses": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$used_count", "$max_uses"}}},
+		},
+	}
+	result, err := r.col.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"used_count": 1}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *promotionRepository) ReleaseUse(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "used_count": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"used_count": -1}},
+	)
+	return err
+}
+
+func (r *promotionRepository) ReserveUserUse(id primitive.ObjectID, email string, max int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// kalau dokumen sudah ada dengan count >= max, filter tidak cocok dan upsert
+	// mencoba insert dokumen baru yang ditolak unique index (promotion_id, email)
+	_, err := r.usages.UpdateOne(ctx,
+		bson.M{"promotion_id": id, "email": email, "count": bson.M{"$lt": max}},
+		bson.M{"$inc": bson.M{"count": 1}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *promotionRepository) ReleaseUserUse(id primitive.ObjectID, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.usages.UpdateOne(ctx,
+		bson.M{"promotion_id": id, "email": email, "count": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"count": -1}},
+	)
+	return err
+}
+
+func (r *promotionRepository) InsertRedemption(redemption *domain.PromotionRedemption) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	redemption.CreatedAt = time.Now()
+
+	result, err := r.redemptions.InsertOne(ctx, redemption)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		redemption.ID = oid
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository report rekonsiliasi payment
+type ReconciliationRepository interface {
+	Insert(report *domain.ReconciliationReport) error
+	Update(report *domain.ReconciliationReport) error
+	FindByID(id string) (*domain.ReconciliationReport, error)
+	// report terbaru dulu, tanpa detail mismatch
+	FindRecent(limit int64) ([]domain.ReconciliationReport, error)
+}
+
+type reconciliationRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection "reconciliation_reports"
+func NewReconciliationRepo(db *mongo.Database) ReconciliationRepository {
+	col := db.Collection("reconciliation_reports")
+	return &reconciliationRepository{col: col}
+}
+
+func (r *reconciliationRepository) Insert(report *domain.ReconciliationReport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report.StartedAt = time.Now()
+
+	result, err := r.col.InsertOne(ctx, report)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		report.ID = oid
+	}
+	return nil
+}
+
+// simpan hasil report, seluruh dokumen ditimpa
+func (r *reconciliationRepository) Update(report *domain.ReconciliationReport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.ReplaceOne(ctx, bson.M{"_id": report.ID}, report)
+	return err
+}
+
+// ambil report by ID, nil kalau tidak ada
+func (r *reconciliationRepository) FindByID(id string) (*domain.ReconciliationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidReconciliationID
+	}
+
+	var report domain.ReconciliationReport
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *reconciliationRepository) FindRecent(limit int64) ([]domain.ReconciliationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"mismatches": 0})
+	cursor, err := r.col.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports := []domain.ReconciliationReport{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
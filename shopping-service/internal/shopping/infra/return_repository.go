@@ -0,0 +1,122 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"shopping-service/internal/shopping/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// interface repository permintaan retur
+type ReturnRepository interface {
+	Insert(ret *domain.ReturnRequest) error
+	FindByID(id string) (*domain.ReturnRequest, error)
+	FindByTransaction(transactionID string) ([]domain.ReturnRequest, error)
+	// simpan ret hanya kalau status tersimpan masih from
+	Save(ret *domain.ReturnRequest, from string) error
+}
+
+type returnRepository struct {
+	col *mongo.Collection
+}
+
+// inisialisasi collection "returns"
+func NewReturnRepo(db *mongo.Database) ReturnRepository {
+	col := db.Collection("returns")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "transaction_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		slog.Error("failed to create returns index", "error", err)
+	}
+
+	return &returnRepository{col: col}
+}
+
+func (r *returnRepository) Insert(ret *domain.ReturnRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret.CreatedAt = time.Now()
+	ret.UpdatedAt = ret.CreatedAt
+
+	result, err := r.col.InsertOne(ctx, ret)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		ret.ID = oid
+	}
+	return nil
+}
+
+// ambil retur by ID, nil kalau tidak ada
+func (r *returnRepository) FindByID(id string) (*domain.ReturnRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidReturnID
+	}
+
+	var ret domain.ReturnRequest
+	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&ret)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// semua retur satu transaksi, terlama dulu
+func (r *returnRepository) FindByTransaction(transactionID string) ([]domain.ReturnRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.col.Find(ctx, bson.M{"transaction_id": transactionID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	returns := []domain.ReturnRequest{}
+	if err := cursor.All(ctx, &returns); err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// Status lama ikut di filter supaya dua keputusan bersamaan tidak saling
+// menimpa, yang kalah mendapat "invalid return status".
+func (r *returnRepository) Save(ret *domain.ReturnRequest, from string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret.UpdatedAt = time.Now()
+
+	result, err := r.col.ReplaceOne(ctx, bson.M{"_id": ret.ID, "status": from}, ret)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		n, err := r.col.CountDocuments(ctx, bson.M{"_id": ret.ID})
+		if err == nil && n > 0 {
+			return ErrInvalidReturnStatus
+		}
+		return ErrReturnNotFound
+	}
+	return nil
+}
@@ -3,13 +3,13 @@ package infra
 import (
 	"context"
 	"errors"
-	"shopping-service/config"
 	"shopping-service/internal/shopping/domain"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // interface repository untuk transaksi
@@ -17,18 +17,31 @@ type TransactionRepository interface {
 	Insert(transaction *domain.Transaction) error
 	FindAll() ([]domain.Transaction, error)
 	FindByID(id string) (*domain.Transaction, error)
+	FindPage(afterID string, limit int64) ([]domain.Transaction, error)
 	Update(id string, transaction *domain.Transaction) error
-	Delete(id string) error
+	// pindah status hanya kalau status masih from, shipment nil berarti tidak diubah
+	Transition(id, from string, change domain.TransactionStatusChange, shipment *domain.Shipment) error
+	// tambah/kurangi unit yang diretur, penambahan hanya untuk transaksi
+	// delivered dan tidak boleh melebihi quantity
+	AddReturnedQuantity(id string, delta int) error
+	// soft delete, hasilnya transaksi sebelum dihapus
+	Delete(id, deletedBy string) (*domain.Transaction, error)
+	FindDeleted() ([]domain.Transaction, error)
+	// hasilnya transaksi sebelum dikembalikan (masih dengan deleted_at)
+	Restore(id string) (*domain.Transaction, error)
 	DeleteFailedOlderThan(duration time.Duration) error // untuk cron job
 }
 
+// transaksi yang di-soft delete tidak ikut query biasa
+var notDeleted = bson.M{"$exists": false}
+
 type transactionRepository struct {
 	col *mongo.Collection
 }
 
 // inisialisasi collection "transactions"
-func NewTransactionRepo() TransactionRepository {
-	col := config.DB.Collection("transactions")
+func NewTransactionRepo(db *mongo.Database) TransactionRepository {
+	col := db.Collection("transactions")
 	return &transactionRepository{col: col}
 }
 
@@ -37,17 +50,26 @@ func (r *transactionRepository) Insert(transaction *domain.Transaction) error {
 	defer cancel()
 
 	transaction.CreatedAt = time.Now()
-	transaction.Status = "success"
+	transaction.Version = 1
 
-	_, err := r.col.InsertOne(ctx, transaction)
-	return err
+	result, err := r.col.InsertOne(ctx, transaction)
+	if err != nil {
+		return err
+	}
+
+	// isi ID hasil insert supaya bisa dipakai caller
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		transaction.ID = oid.Hex()
+	}
+
+	return nil
 }
 
 func (r *transactionRepository) FindAll() ([]domain.Transaction, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := r.col.Find(ctx, bson.M{})
+	cursor, err := r.col.Find(ctx, bson.M{"deleted_at": notDeleted})
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +96,7 @@ func (r *transactionRepository) FindByID(id string) (*domain.Transaction, error)
 	}
 
 	var result domain.Transaction
-	err = r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&result)
+	err = r.col.FindOne(ctx, bson.M{"_id": objID, "deleted_at": notDeleted}).Decode(&result)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil
@@ -84,6 +106,31 @@ func (r *transactionRepository) FindByID(id string) (*domain.Transaction, error)
 	return &result, nil
 }
 
+// Satu halaman transaksi urut _id setelah afterID (kosong untuk halaman
+// pertama), dipakai rekonsiliasi. Transaksi yang di-soft delete ikut
+// karena payment-nya tetap ada.
+func (r *transactionRepository) FindPage(afterID string, limit int64) ([]domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter, err := pageFilter(afterID)
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	transactions := []domain.Transaction{}
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 func (r *transactionRepository) Update(id string, transaction *domain.Transaction) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -95,19 +142,36 @@ func (r *transactionRepository) Update(id string, transaction *domain.Transactio
 
 	update := bson.M{
 		"$set": bson.M{
+			"email":      transaction.Email,
 			"quantity":   transaction.Quantity,
-			"status":     transaction.Status,
 			"total":      transaction.Total,
+			"pricing":    transaction.Pricing,
 			"product_id": transaction.ProductID,
 			"payment_id": transaction.PaymentID,
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	_, err = r.col.UpdateOne(ctx, bson.M{"_id": objID}, update)
-	return err
+	// update hanya kalau versi belum berubah sejak dibaca client
+	result, err := r.col.UpdateOne(ctx, bson.M{"_id": objID, "deleted_at": notDeleted, "version": versionFilter(transaction.Version)}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		n, err := r.col.CountDocuments(ctx, bson.M{"_id": objID, "deleted_at": notDeleted})
+		if err == nil && n > 0 {
+			return ErrVersionConflict
+		}
+		return ErrTransactionNotFound
+	}
+
+	transaction.Version++
+	return nil
 }
 
-func (r *transactionRepository) Delete(id string) error {
+// Status dicek di filter supaya dua perpindahan bersamaan tidak saling
+// menimpa, yang kalah mendapat "invalid status transition".
+func (r *transactionRepository) Transition(id, from string, change domain.TransactionStatusChange, shipment *domain.Shipment) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -115,8 +179,115 @@ func (r *transactionRepository) Delete(id string) error {
 	if err != nil {
 		return err
 	}
-	_, err = r.col.DeleteOne(ctx, bson.M{"_id": objID})
-	return err
+
+	set := bson.M{"status": change.To}
+	if shipment != nil {
+		set["shipment"] = shipment
+	}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"status_history": change},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	result, err := r.col.UpdateOne(ctx, bson.M{"_id": objID, "deleted_at": notDeleted, "status": from}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		n, err := r.col.CountDocuments(ctx, bson.M{"_id": objID, "deleted_at": notDeleted})
+		if err == nil && n > 0 {
+			return ErrInvalidStatusTransition
+		}
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// Batas quantity dicek di filter supaya dua permintaan retur bersamaan
+// tidak meretur lebih dari yang dibeli
+func (r *transactionRepository) AddReturnedQuantity(id string, delta int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID, "deleted_at": notDeleted}
+	if delta > 0 {
+		filter["status"] = domain.TransactionDelivered
+		filter["$expr"] = bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$returned_quantity", 0}}, delta}},
+			"$quantity",
+		}}
+	}
+
+	result, err := r.col.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"returned_quantity": delta}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		n, err := r.col.CountDocuments(ctx, bson.M{"_id": objID})
+		if err == nil && n > 0 {
+			return ErrReturnQuantityExceeded
+		}
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// Soft delete: dokumen tetap ada dengan deleted_at dan deleted_by
+func (r *transactionRepository) Delete(id, deletedBy string) (*domain.Transaction, error) {
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}}
+	return r.findAndUpdate(id, bson.M{"deleted_at": notDeleted}, update)
+}
+
+// transaksi yang di-soft delete, terbaru dihapus dulu
+func (r *transactionRepository) FindDeleted() ([]domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	cursor, err := r.col.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	transactions := []domain.Transaction{}
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (r *transactionRepository) Restore(id string) (*domain.Transaction, error) {
+	update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+	return r.findAndUpdate(id, bson.M{"deleted_at": bson.M{"$exists": true}}, update)
+}
+
+// update satu transaksi, hasilnya dokumen sebelum update
+func (r *transactionRepository) findAndUpdate(id string, filter, update bson.M) (*domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidTransactionID
+	}
+	filter["_id"] = objID
+
+	var before domain.Transaction
+	err = r.col.FindOneAndUpdate(ctx, filter, update).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	return &before, nil
 }
 
 // untuk cron job: hapus transaksi failed lebih dari durasi tertentu
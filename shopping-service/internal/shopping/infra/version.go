@@ -0,0 +1,12 @@
+package infra
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Filter versi untuk optimistic concurrency. Dokumen lama yang belum
+// punya field version dianggap versi 0.
+func versionFilter(version int64) any {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}